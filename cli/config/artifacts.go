@@ -0,0 +1,150 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/internal/fileutils"
+	"github.com/sirupsen/logrus"
+)
+
+const artifactsRefSuffix = ".artifacts"
+
+// parseConsumesRef extracts the producing operation from a reference such
+// as "build.artifacts".
+func parseConsumesRef(ref string) (string, error) {
+	producer, ok := strings.CutSuffix(ref, artifactsRefSuffix)
+	if !ok || producer == "" {
+		return "", fmt.Errorf("invalid consumes reference '%s', expected '<operation>%s'", ref, artifactsRefSuffix)
+	}
+	return producer, nil
+}
+
+// MatchArtifacts expands the artifact patterns of an operation into the
+// list of existing paths, sorted and without duplicates. A "**" segment
+// matches any number of directories. Paths use forward slashes on every
+// platform.
+func (op *Operation) MatchArtifacts() ([]string, error) {
+	seen := map[string]bool{}
+	matches := []string{}
+	for _, pattern := range op.Artifacts {
+		found, err := fileutils.Glob(os.DirFS("."), pattern, ".git", WorkDir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid artifact pattern '%s': %w", pattern, err)
+		}
+		for _, path := range found {
+			if !seen[path] {
+				seen[path] = true
+				matches = append(matches, path)
+			}
+		}
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// stageConsumed makes sure the artifacts of every operation consumed by op
+// exist, running the producer first if they do not, and copies them into
+// the artifacts directory under the producer's name.
+func (d *ProjectDefinition) stageConsumed(ctx context.Context, op Operation, shellExecutor ShellExecutor, visiting map[string]bool) error {
+	logger := logging.FromContext(ctx)
+	for _, ref := range op.Consumes {
+		producer, err := parseConsumesRef(ref)
+		if err != nil {
+			return err
+		}
+		producerOp, ok := d.Codebase.Lookup(producer)
+		if !ok {
+			return fmt.Errorf("consumes unknown operation '%s'", producer)
+		}
+		if len(producerOp.Artifacts) == 0 {
+			return fmt.Errorf("operation '%s' does not declare any artifacts", producer)
+		}
+
+		matches, err := producerOp.MatchArtifacts()
+		if err != nil {
+			return err
+		}
+		if len(matches) == 0 {
+			logger.Infof("Artifacts from %s not found, running it first", producer)
+			if err := d.run(ctx, producer, shellExecutor, visiting); err != nil {
+				return fmt.Errorf("failed to produce artifacts from %s: %w", producer, err)
+			}
+			if matches, err = producerOp.MatchArtifacts(); err != nil {
+				return err
+			}
+			if len(matches) == 0 {
+				return fmt.Errorf("operation '%s' completed but produced no artifacts matching %v", producer, producerOp.Artifacts)
+			}
+		}
+
+		stageDir := filepath.Join(ArtifactsDir, producer)
+		if err := stageArtifacts(matches, stageDir); err != nil {
+			return fmt.Errorf("failed to stage artifacts from %s: %w", producer, err)
+		}
+		if err := fileutils.ApplyPermissions(stageDir, producerOp.ArtifactPermissions.modes()); err != nil {
+			return fmt.Errorf("failed to stage artifacts from %s: %w", producer, err)
+		}
+		logger.WithFields(logrus.Fields{
+			"count": len(matches),
+			"path":  stageDir,
+		}).Infof("Staged artifacts from %s", producer)
+	}
+	return nil
+}
+
+// stageArtifacts replaces the contents of dir with copies of the given
+// files and directories, keeping their paths relative to the project root.
+func stageArtifacts(paths []string, dir string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	root := os.DirFS(".")
+	for _, path := range paths {
+		rel := filepath.ToSlash(filepath.Clean(path))
+		dst := filepath.Join(dir, rel)
+		if fileutils.IsDir(path) {
+			if err := fileutils.CopyDirectory(root, rel, dst, nil); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fileutils.CopyFile(root, rel, dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectFailureArtifacts copies the files matching the operation's
+// on_failure_artifacts patterns into the failures directory, returning the
+// directory and the number of paths collected.
+func (op *Operation) collectFailureArtifacts(name string) (string, int, error) {
+	seen := map[string]bool{}
+	matches := []string{}
+	for _, pattern := range op.OnFailureArtifacts {
+		found, err := fileutils.Glob(os.DirFS("."), pattern, ".git", WorkDir)
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid failure artifact pattern '%s': %w", pattern, err)
+		}
+		for _, path := range found {
+			if !seen[path] {
+				seen[path] = true
+				matches = append(matches, path)
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return "", 0, nil
+	}
+	dir := workPath(filepath.Join(FailureArtifactsDir, name))
+	if err := stageArtifacts(matches, dir); err != nil {
+		return "", 0, err
+	}
+	return dir, len(matches), nil
+}
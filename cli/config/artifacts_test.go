@@ -0,0 +1,189 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConsumesRef(t *testing.T) {
+	producer, err := parseConsumesRef("build.artifacts")
+	assert.NoError(t, err)
+	assert.Equal(t, "build", producer)
+
+	for _, ref := range []string{"build", ".artifacts", "build.outputs"} {
+		_, err := parseConsumesRef(ref)
+		assert.Error(t, err, ref)
+	}
+}
+
+func TestOperation_MatchArtifacts(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.MkdirAll("bin", 0755))
+	require.NoError(t, os.WriteFile("bin/app", []byte("app"), 0755))
+	require.NoError(t, os.WriteFile("bin/tool", []byte("tool"), 0755))
+
+	op := Operation{Artifacts: []string{"bin/*", `bin\app`, "missing/*"}}
+	matches, err := op.MatchArtifacts()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bin/app", "bin/tool"}, matches)
+
+	require.NoError(t, os.MkdirAll("dist/py/linux", 0755))
+	require.NoError(t, os.WriteFile("dist/app.whl", []byte("wheel"), 0644))
+	require.NoError(t, os.WriteFile("dist/py/linux/lib.whl", []byte("wheel"), 0644))
+	require.NoError(t, os.WriteFile("dist/py/notes.txt", []byte("notes"), 0644))
+	op = Operation{Artifacts: []string{"dist/**/*.whl"}}
+	matches, err = op.MatchArtifacts()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dist/app.whl", "dist/py/linux/lib.whl"}, matches)
+
+	op = Operation{Artifacts: []string{"bin/[a"}}
+	_, err = op.MatchArtifacts()
+	assert.ErrorContains(t, err, "invalid artifact pattern")
+}
+
+func TestProjectDefinition_Run_Consumes(t *testing.T) {
+	newProject := func() ProjectDefinition {
+		return ProjectDefinition{
+			ID: "test-project",
+			Codebase: Codebase{
+				Build: Operation{
+					Artifacts: []string{"dist/*"},
+					Steps:     StepsFromCommands("make dist"),
+				},
+				Operations: map[string]Operation{
+					"deploy": {
+						Consumes: []string{"build.artifacts"},
+						Steps:    StepsFromCommands("./deploy.sh"),
+					},
+				},
+			},
+		}
+	}
+	writeDist := func(mock.Arguments) {
+		_ = os.MkdirAll("dist", 0755)
+		_ = os.WriteFile("dist/app.tar.gz", []byte("archive"), 0644)
+	}
+
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+
+	t.Run("existing artifacts are staged without rebuilding", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		writeDist(nil)
+
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, "./deploy.sh").Return(executor.Result{}, nil)
+
+		project := newProject()
+		require.NoError(t, project.Run(ctx, "deploy", m))
+		m.AssertNotCalled(t, "Exec", mock.Anything, "make dist")
+		assert.FileExists(t, filepath.Join(ArtifactsDir, "build", "dist", "app.tar.gz"))
+	})
+
+	t.Run("missing artifacts trigger the producer", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, "make dist").Run(writeDist).Return(executor.Result{}, nil).Once()
+		m.On("Exec", mock.Anything, "./deploy.sh").Return(executor.Result{}, nil).Once()
+
+		project := newProject()
+		require.NoError(t, project.Run(ctx, "deploy", m))
+		m.AssertExpectations(t)
+		assert.FileExists(t, filepath.Join(ArtifactsDir, "build", "dist", "app.tar.gz"))
+	})
+
+	t.Run("producer without output fails", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, "make dist").Return(executor.Result{}, nil).Once()
+
+		project := newProject()
+		err := project.Run(ctx, "deploy", m)
+		assert.ErrorContains(t, err, "produced no artifacts")
+		m.AssertNotCalled(t, "Exec", mock.Anything, "./deploy.sh")
+	})
+
+	t.Run("producer without declared artifacts fails", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+
+		project := newProject()
+		project.Codebase.Build.Artifacts = nil
+		err := project.Run(ctx, "deploy", &MockShellExecutor{})
+		assert.ErrorContains(t, err, "operation 'build' does not declare any artifacts")
+	})
+
+	t.Run("cycles are rejected", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+
+		project := newProject()
+		project.Codebase.Build.Consumes = []string{"deploy.artifacts"}
+		deploy := project.Codebase.Operations["deploy"]
+		deploy.Artifacts = []string{"release/*"}
+		project.Codebase.Operations["deploy"] = deploy
+
+		m := &MockShellExecutor{}
+		err := project.Run(ctx, "deploy", m)
+		assert.ErrorContains(t, err, "through a cycle")
+	})
+}
+
+func TestProjectDefinition_Run_FailureArtifacts(t *testing.T) {
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	project := ProjectDefinition{
+		Codebase: Codebase{
+			Test: Operation{
+				OnFailureArtifacts: []string{"**/testlogs/*", "core.*"},
+				Steps:              StepsFromCommands("go test ./..."),
+			},
+		},
+	}
+
+	t.Run("collects diagnostics on failure", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		require.NoError(t, os.MkdirAll("pkg/testlogs", 0755))
+		require.NoError(t, os.WriteFile("pkg/testlogs/unit.log", []byte("panic"), 0644))
+		require.NoError(t, os.WriteFile("core.42", []byte("dump"), 0644))
+
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, "go test ./...").Return(executor.Result{ExitCode: 1}, errors.New("exit status 1"))
+
+		err := project.Test(ctx, m)
+		assert.ErrorContains(t, err, "collected 2 failure artifact(s) in .devops/artifacts/failures/test")
+		assert.FileExists(t, filepath.Join(FailureArtifactsDir, "test", "pkg/testlogs/unit.log"))
+		assert.FileExists(t, filepath.Join(FailureArtifactsDir, "test", "core.42"))
+	})
+
+	t.Run("nothing collected on success", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		require.NoError(t, os.WriteFile("core.42", []byte("dump"), 0644))
+
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, "go test ./...").Return(executor.Result{}, nil)
+
+		require.NoError(t, project.Test(ctx, m))
+		assert.NoDirExists(t, FailureArtifactsDir)
+	})
+
+	t.Run("no matches leaves error unchanged", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, "go test ./...").Return(executor.Result{ExitCode: 1}, errors.New("exit status 1"))
+
+		err := project.Test(ctx, m)
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "failure artifact")
+	})
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"context"
+)
+
+// Variables devops sets for every step.
+const (
+	EnvProjectID = "DEVOPS_PROJECT_ID"
+	EnvVersion   = "DEVOPS_VERSION"
+	EnvOperation = "DEVOPS_OPERATION"
+	EnvStepIndex = "DEVOPS_STEP_INDEX"
+	EnvRunID     = "DEVOPS_RUN_ID"
+
+	// EnvOperationStatus tells cleanup steps whether the steps succeeded.
+	EnvOperationStatus = "DEVOPS_OPERATION_STATUS"
+)
+
+const runInfoKey contextKey = "run-info"
+
+// RunInfo identifies the operation run that steps belong to. It is exposed
+// to steps as DEVOPS_* variables.
+type RunInfo struct {
+	ProjectID string
+	Version   string
+	Operation string
+	RunID     string
+}
+
+// withRunInfo attaches the run info for the steps of an operation.
+func withRunInfo(ctx context.Context, info RunInfo) context.Context {
+	return context.WithValue(ctx, runInfoKey, info)
+}
+
+// runInfoFromContext returns the run info on the context, if any.
+func runInfoFromContext(ctx context.Context) (RunInfo, bool) {
+	info, ok := ctx.Value(runInfoKey).(RunInfo)
+	return info, ok
+}
+
+// env returns the DEVOPS_* variables of the run, leaving out empty ones.
+func (r RunInfo) env() []string {
+	env := []string{}
+	for _, kv := range [][2]string{
+		{EnvProjectID, r.ProjectID},
+		{EnvVersion, r.Version},
+		{EnvOperation, r.Operation},
+		{EnvRunID, r.RunID},
+	} {
+		if kv[1] != "" {
+			env = append(env, kv[0]+"="+kv[1])
+		}
+	}
+	return env
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunInfo_Env(t *testing.T) {
+	info := RunInfo{ProjectID: "api", Operation: "build", RunID: "20260501T120000Z-build"}
+	assert.Equal(t, []string{
+		"DEVOPS_PROJECT_ID=api",
+		"DEVOPS_OPERATION=build",
+		"DEVOPS_RUN_ID=20260501T120000Z-build",
+	}, info.env())
+	assert.Empty(t, RunInfo{}.env())
+}
+
+func TestProjectDefinition_Run_BuiltinEnv(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	project := ProjectDefinition{
+		ID:      "api",
+		Version: "1.4.0",
+		Codebase: Codebase{Operations: map[string]Operation{
+			"package": {Steps: StepsFromCommands("make dist", "make sign")},
+		}},
+	}
+	builtins := func(index string) func(env []string) bool {
+		return func(env []string) bool {
+			return envValue(env, EnvProjectID) == "api" &&
+				envValue(env, EnvVersion) == "1.4.0" &&
+				envValue(env, EnvOperation) == "package" &&
+				strings.HasSuffix(envValue(env, EnvRunID), "-package") &&
+				envValue(env, EnvStepIndex) == index
+		}
+	}
+	m := &MockShellExecutor{}
+	m.On("Exec", withEnv(builtins("1")), "make dist").Return(executor.Result{}, nil).Once()
+	m.On("Exec", withEnv(builtins("2")), "make sign").Return(executor.Result{}, nil).Once()
+
+	require.NoError(t, project.Run(ctx, "package", m))
+	m.AssertExpectations(t)
+}
+
+func TestProjectDefinition_Run_BuiltinEnvInSandbox(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	project := ProjectDefinition{
+		ID: "api",
+		Codebase: Codebase{Operations: map[string]Operation{
+			"check": {Sandbox: true, Steps: StepsFromCommands("make check")},
+		}},
+	}
+	m := &MockShellExecutor{}
+	m.On("Exec", withEnv(func(env []string) bool {
+		return envValue(env, EnvProjectID) == "api" &&
+			envValue(env, EnvOperation) == "check" &&
+			envValue(env, EnvStepIndex) == "1"
+	}), "bash --noprofile --norc -c 'make check'").Return(executor.Result{}, nil)
+
+	require.NoError(t, project.Run(ctx, "check", m))
+	m.AssertExpectations(t)
+}
+
+func TestProjectDefinition_Exec_BuiltinEnv(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	project := ProjectDefinition{ID: "api"}
+	m := &MockShellExecutor{}
+	m.On("Exec", withEnv(func(env []string) bool {
+		return envValue(env, EnvProjectID) == "api" &&
+			envValue(env, EnvOperation) == "" &&
+			strings.HasSuffix(envValue(env, EnvRunID), "-exec")
+	}), "env").Return(executor.Result{}, nil)
+
+	require.NoError(t, project.Exec(ctx, []string{"env"}, "", m))
+	m.AssertExpectations(t)
+}
+
+func TestNewRunID(t *testing.T) {
+	start := time.Date(2026, 5, 1, 14, 30, 5, 0, time.FixedZone("CEST", 2*60*60))
+	assert.Equal(t, "20260501T123005Z-build", newRunID("", "build", start))
+	assert.Equal(t, "20260501T123005Z-api-build", newRunID("api", "build", start))
+}
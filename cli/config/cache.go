@@ -0,0 +1,165 @@
+package config
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/internal/fileutils"
+)
+
+const noCacheKey contextKey = "no-cache"
+
+// WithoutCache makes cached operations run every step, ignoring and not
+// recording cache entries.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey, true)
+}
+
+// CacheDisabled reports whether the step cache is turned off.
+func CacheDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noCacheKey).(bool)
+	return disabled
+}
+
+// cacheable reports whether the steps of the operation can be skipped
+// when their inputs are unchanged. Without declared input files there is
+// nothing to tell a changed tree from an unchanged one.
+func (op *Operation) cacheable(ctx context.Context) bool {
+	return op.Cache && len(op.Inputs.Files) > 0 && !CacheDisabled(ctx)
+}
+
+// stepFingerprint returns the cache key of a step: a hash of the project
+// and operation, the step's position and command, the env it runs with
+// and the content of the operation's input files.
+func (op *Operation) stepFingerprint(info RunInfo, index int, step Step) (string, error) {
+	files, err := inputFiles(op.Inputs.Files)
+	if err != nil {
+		return "", err
+	}
+	filesHash, err := fileutils.HashFiles(os.DirFS("."), files)
+	if err != nil {
+		return "", err
+	}
+	env := map[string]string{}
+	for _, key := range op.Inputs.Env {
+		env[key] = os.Getenv(key)
+	}
+	for key, value := range op.Env {
+		env[key] = value
+	}
+	for key, value := range step.Env {
+		env[key] = value
+	}
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%d\x00%s\x00", info.ProjectID, info.Operation, index, step.Run)
+	if shell := cmp.Or(step.Shell, op.Shell); shell != "" {
+		fmt.Fprintf(h, "shell=%s\x00", shell)
+	}
+	for _, key := range keys {
+		fmt.Fprintf(h, "%s=%s\x00", key, env[key])
+	}
+	fmt.Fprintf(h, "%s\x00", filesHash)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// inputFiles expands input patterns into the files they match, descending
+// into matched directories.
+func inputFiles(patterns []string) ([]string, error) {
+	seen := map[string]bool{}
+	files := []string{}
+	root := os.DirFS(".")
+	for _, pattern := range patterns {
+		matches, err := fileutils.Glob(root, pattern, ".git", WorkDir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input pattern '%s': %w", pattern, err)
+		}
+		for _, match := range matches {
+			err := fs.WalkDir(root, match, func(p string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() && (p == ".git" || p == WorkDir) {
+					return fs.SkipDir
+				}
+				if d.Type().IsRegular() && !seen[p] {
+					seen[p] = true
+					files = append(files, p)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+// cachePath returns where the cache entry for a fingerprint is stored.
+func cachePath(fingerprint string) string {
+	return workPath(filepath.Join(CacheDir, fingerprint))
+}
+
+// cacheHit reports whether a step with the fingerprint already succeeded,
+// here or, with a remote cache, on another machine. A local hit refreshes
+// the entry so pruning keeps entries still in use; a remote hit is kept
+// locally. An unreachable remote cache counts as a miss.
+func cacheHit(ctx context.Context, fingerprint string) bool {
+	path := cachePath(fingerprint)
+	if _, err := os.Stat(path); err == nil {
+		now := time.Now()
+		_ = os.Chtimes(path, now, now)
+		return true
+	}
+	remote, ok := remoteCacheFromContext(ctx)
+	if !ok {
+		return false
+	}
+	found, err := remote.store.Has(ctx, fingerprint)
+	if err != nil {
+		logging.FromContext(ctx).Warnf("Remote cache lookup failed: %v", err)
+		return false
+	}
+	if found {
+		_ = writeCacheEntry(fingerprint)
+	}
+	return found
+}
+
+// storeCacheEntry records that a step with the fingerprint succeeded, and
+// uploads the entry to the remote cache unless it is read-only.
+func storeCacheEntry(ctx context.Context, fingerprint string) error {
+	if err := writeCacheEntry(fingerprint); err != nil {
+		return err
+	}
+	remote, ok := remoteCacheFromContext(ctx)
+	if !ok || remote.readOnly {
+		return nil
+	}
+	if err := remote.store.Put(ctx, fingerprint, nil); err != nil {
+		return fmt.Errorf("remote cache: %w", err)
+	}
+	return nil
+}
+
+func writeCacheEntry(fingerprint string) error {
+	if err := os.MkdirAll(workPath(CacheDir), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(cachePath(fingerprint), nil, 0644)
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectDefinition_Run_Cache(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	newProject := func() ProjectDefinition {
+		return ProjectDefinition{
+			ID: "cache-project",
+			Codebase: Codebase{
+				Build: Operation{
+					Cache:  true,
+					Inputs: Inputs{Files: []string{"src"}, Env: []string{"DEVOPS_TEST_CACHE_MODE"}},
+					Steps:  StepsFromCommands("make lint", "make build"),
+				},
+			},
+		}
+	}
+	setup := func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		require.NoError(t, os.MkdirAll("src", 0755))
+		require.NoError(t, os.WriteFile("src/main.go", []byte("package main"), 0644))
+		t.Setenv("DEVOPS_TEST_CACHE_MODE", "release")
+	}
+	run := func(t *testing.T, ctx context.Context, project ProjectDefinition) []string {
+		m := &MockShellExecutor{}
+		calls := []string{}
+		recordCalls(m, &calls, "make lint", "make build")
+		require.NoError(t, project.Run(ctx, "build", m))
+		return calls
+	}
+
+	t.Run("unchanged inputs skip steps", func(t *testing.T) {
+		setup(t)
+		assert.Equal(t, []string{"make lint", "make build"}, run(t, ctx, newProject()))
+		assert.Empty(t, run(t, ctx, newProject()))
+	})
+
+	t.Run("changed inputs re-run steps", func(t *testing.T) {
+		setup(t)
+		run(t, ctx, newProject())
+		require.NoError(t, os.WriteFile("src/main.go", []byte("package main\n\nfunc main() {}"), 0644))
+		assert.Equal(t, []string{"make lint", "make build"}, run(t, ctx, newProject()))
+
+		t.Setenv("DEVOPS_TEST_CACHE_MODE", "debug")
+		assert.Equal(t, []string{"make lint", "make build"}, run(t, ctx, newProject()))
+
+		project := newProject()
+		project.Codebase.Build.Steps[1].Run = "make build-all"
+		m := &MockShellExecutor{}
+		calls := []string{}
+		recordCalls(m, &calls, "make build-all")
+		require.NoError(t, project.Run(ctx, "build", m))
+		assert.Equal(t, []string{"make build-all"}, calls)
+	})
+
+	t.Run("failed steps are not cached", func(t *testing.T) {
+		setup(t)
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, "make lint").Return(executor.Result{}, nil)
+		m.On("Exec", mock.Anything, "make build").Return(executor.Result{ExitCode: 2}, nil)
+		project := newProject()
+		require.Error(t, project.Run(ctx, "build", m))
+		assert.Equal(t, []string{"make build"}, run(t, ctx, newProject()))
+	})
+
+	t.Run("missing outputs invalidate the cache", func(t *testing.T) {
+		setup(t)
+		project := newProject()
+		project.Codebase.Build.Outputs = []string{"bin/app"}
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			require.NoError(t, os.MkdirAll("bin", 0755))
+			require.NoError(t, os.WriteFile("bin/app", nil, 0755))
+		}).Return(executor.Result{}, nil)
+		require.NoError(t, project.Run(ctx, "build", m))
+		require.NoError(t, os.Remove("bin/app"))
+
+		m = &MockShellExecutor{}
+		m.On("Exec", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			require.NoError(t, os.WriteFile("bin/app", nil, 0755))
+		}).Return(executor.Result{}, nil)
+		require.NoError(t, project.Run(ctx, "build", m))
+		m.AssertNumberOfCalls(t, "Exec", 2)
+	})
+
+	t.Run("disabled cache runs every step", func(t *testing.T) {
+		setup(t)
+		run(t, ctx, newProject())
+		assert.Equal(t, []string{"make lint", "make build"}, run(t, WithoutCache(ctx), newProject()))
+	})
+
+	t.Run("no input files, no cache", func(t *testing.T) {
+		setup(t)
+		project := newProject()
+		project.Codebase.Build.Inputs.Files = nil
+		run(t, ctx, project)
+		assert.Equal(t, []string{"make lint", "make build"}, run(t, ctx, project))
+		_, err := os.Stat(CacheDir)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
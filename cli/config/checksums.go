@@ -0,0 +1,176 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ChecksumsFile is the name of the checksums file written next to the
+// manifest.
+const ChecksumsFile = "SHA256SUMS"
+
+// Verification results of a single artifact.
+const (
+	ChecksumOK      = "OK"
+	ChecksumFailed  = "FAILED"
+	ChecksumMissing = "MISSING"
+)
+
+// ArtifactDigest is the SHA-256 of a produced artifact.
+type ArtifactDigest struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+// Verification is the outcome of re-checking one artifact digest.
+type Verification struct {
+	Path   string
+	Status string
+}
+
+// ArtifactDigests returns the digests of the build artifacts of every
+// codebase that exist, of any SBOM generated by devops sbom and of the
+// chart packages built by devops deploy, with paths relative to the
+// project root. Directory artifacts contribute each
+// file they contain.
+func (d *ProjectDefinition) ArtifactDigests() ([]ArtifactDigest, error) {
+	paths := []string{}
+	for _, view := range d.codebaseViews() {
+		err := inDir(view.Codebase.Path, func() error {
+			matches, err := view.buildArtifacts()
+			for _, match := range matches {
+				if _, statErr := os.Stat(match); statErr == nil {
+					paths = append(paths, filepath.ToSlash(filepath.Join(view.Codebase.Path, match)))
+				}
+			}
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return digestFiles(os.DirFS("."), append(append(paths, sbomFiles()...), chartPackages()...))
+}
+
+// digestFiles hashes the given files of fsys, and the files under the
+// given directories, sorted by path.
+func digestFiles(fsys fs.FS, paths []string) ([]ArtifactDigest, error) {
+	digests := []ArtifactDigest{}
+	seen := map[string]bool{}
+	for _, root := range paths {
+		err := fs.WalkDir(fsys, root, func(p string, entry fs.DirEntry, err error) error {
+			if err != nil || !entry.Type().IsRegular() || seen[p] {
+				return err
+			}
+			seen[p] = true
+			sum, err := hashFile(fsys, p)
+			if err != nil {
+				return err
+			}
+			digests = append(digests, ArtifactDigest{Path: p, SHA256: sum})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(digests, func(i, j int) bool { return digests[i].Path < digests[j].Path })
+	return digests, nil
+}
+
+func hashFile(fsys fs.FS, path string) (string, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// FormatChecksums renders digests in the format of sha256sum, so the file
+// can also be checked with sha256sum -c.
+func FormatChecksums(digests []ArtifactDigest) []byte {
+	var buf bytes.Buffer
+	for _, digest := range digests {
+		fmt.Fprintf(&buf, "%s  %s\n", digest.SHA256, digest.Path)
+	}
+	return buf.Bytes()
+}
+
+// ParseChecksums reads digests in the format of sha256sum.
+func ParseChecksums(data []byte) ([]ArtifactDigest, error) {
+	digests := []ArtifactDigest{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		sum, path, ok := strings.Cut(text, " ")
+		path = strings.TrimPrefix(path, " ")
+		// sha256sum marks files hashed in binary mode with a leading '*'.
+		path = strings.TrimPrefix(path, "*")
+		if _, err := hex.DecodeString(sum); !ok || err != nil || len(sum) != sha256.Size*2 || path == "" {
+			return nil, fmt.Errorf("invalid checksum on line %d", line)
+		}
+		digests = append(digests, ArtifactDigest{Path: path, SHA256: strings.ToLower(sum)})
+	}
+	return digests, scanner.Err()
+}
+
+// LoadChecksums reads the digests of a checksums file, or of the artifacts
+// of a manifest when the file is JSON.
+func LoadChecksums(path string) ([]ArtifactDigest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if filepath.Ext(path) != ".json" {
+		digests, err := ParseChecksums(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return digests, nil
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	return manifest.Artifacts, nil
+}
+
+// VerifyChecksums re-hashes each artifact, relative to dir, and compares
+// it to its recorded digest.
+func VerifyChecksums(dir string, digests []ArtifactDigest) ([]Verification, error) {
+	fsys := os.DirFS(dir)
+	results := make([]Verification, 0, len(digests))
+	for _, digest := range digests {
+		result := Verification{Path: digest.Path, Status: ChecksumOK}
+		sum, err := hashFile(fsys, filepath.ToSlash(digest.Path))
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			result.Status = ChecksumMissing
+		case err != nil:
+			return results, err
+		case sum != digest.SHA256:
+			result.Status = ChecksumFailed
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
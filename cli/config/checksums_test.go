@@ -0,0 +1,84 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const helloSHA256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+func TestProjectDefinition_ArtifactDigests(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.MkdirAll("dist/docs", 0755))
+	require.NoError(t, os.WriteFile("dist/app", []byte("hello"), 0755))
+	require.NoError(t, os.WriteFile("dist/docs/README", []byte("hello"), 0644))
+	project := ProjectDefinition{
+		ID:       "app",
+		Codebase: Codebase{Build: Operation{Artifacts: []string{"dist/*", "missing"}}},
+	}
+
+	digests, err := project.ArtifactDigests()
+	require.NoError(t, err)
+	assert.Equal(t, []ArtifactDigest{
+		{Path: "dist/app", SHA256: helloSHA256},
+		{Path: "dist/docs/README", SHA256: helloSHA256},
+	}, digests)
+
+	data, err := project.GenerateManifest(nil)
+	require.NoError(t, err)
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	assert.Equal(t, digests, manifest.Artifacts)
+}
+
+func TestChecksums_RoundTrip(t *testing.T) {
+	digests := []ArtifactDigest{{Path: "dist/app", SHA256: helloSHA256}}
+	data := FormatChecksums(digests)
+	assert.Equal(t, helloSHA256+"  dist/app\n", string(data))
+
+	parsed, err := ParseChecksums(data)
+	require.NoError(t, err)
+	assert.Equal(t, digests, parsed)
+
+	parsed, err = ParseChecksums([]byte("\n" + helloSHA256 + " *dist/app\n"))
+	require.NoError(t, err)
+	assert.Equal(t, digests, parsed)
+
+	_, err = ParseChecksums([]byte(helloSHA256 + "  dist/app\nnot-a-sum  dist/lib\n"))
+	assert.EqualError(t, err, "invalid checksum on line 2")
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(dir+"/ok", []byte("hello"), 0644))
+	require.NoError(t, os.WriteFile(dir+"/changed", []byte("hello!"), 0644))
+
+	results, err := VerifyChecksums(dir, []ArtifactDigest{
+		{Path: "ok", SHA256: helloSHA256},
+		{Path: "changed", SHA256: helloSHA256},
+		{Path: "gone", SHA256: helloSHA256},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []Verification{
+		{Path: "ok", Status: ChecksumOK},
+		{Path: "changed", Status: ChecksumFailed},
+		{Path: "gone", Status: ChecksumMissing},
+	}, results)
+}
+
+func TestLoadChecksums_Manifest(t *testing.T) {
+	dir := t.TempDir()
+	manifest := `{"id": "app", "version": "1.0.0", "artifacts": [{"path": "dist/app", "sha256": "` + helloSHA256 + `"}]}`
+	require.NoError(t, os.WriteFile(dir+"/manifest.json", []byte(manifest), 0644))
+
+	digests, err := LoadChecksums(dir + "/manifest.json")
+	require.NoError(t, err)
+	assert.Equal(t, []ArtifactDigest{{Path: "dist/app", SHA256: helloSHA256}}, digests)
+
+	_, err = LoadChecksums(dir + "/missing")
+	assert.Error(t, err)
+}
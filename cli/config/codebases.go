@@ -0,0 +1,159 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+)
+
+const codebaseKey contextKey = "codebase"
+
+// WithCodebase limits operations to the named codebase of a definition
+// with several codebases.
+func WithCodebase(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, codebaseKey, name)
+}
+
+// CodebaseFromContext returns the selected codebase, or an empty string
+// when every codebase runs.
+func CodebaseFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(codebaseKey).(string)
+	return name
+}
+
+// codebaseViews returns one definition per codebase, with Codebase set to
+// it, so code written against a single codebase works for each of them. A
+// definition without codebases is its own only view.
+func (d *ProjectDefinition) codebaseViews() []*ProjectDefinition {
+	if len(d.Codebases) == 0 {
+		return []*ProjectDefinition{d}
+	}
+	views := make([]*ProjectDefinition, len(d.Codebases))
+	for i, codebase := range d.Codebases {
+		view := *d
+		view.Codebase = codebase
+		view.Codebases = nil
+		view.codebaseKey = "codebases." + codebase.Name
+		views[i] = &view
+	}
+	return views
+}
+
+// codebasePath returns the key path of the view's codebase.
+func (d *ProjectDefinition) codebasePath() string {
+	if d.codebaseKey == "" {
+		return "codebase"
+	}
+	return d.codebaseKey
+}
+
+// operationPath returns the key path of an operation of the view's
+// codebase, used to scope validation findings about it.
+func (d *ProjectDefinition) operationPath(name string) string {
+	if slices.Contains(builtinOperations, name) {
+		return d.codebasePath() + "." + name
+	}
+	return d.codebasePath() + ".operations." + name
+}
+
+// SelectCodebases returns the views of the named codebase, or of every
+// codebase when name is empty.
+func (d *ProjectDefinition) SelectCodebases(name string) ([]*ProjectDefinition, error) {
+	if name == "" {
+		return d.codebaseViews(), nil
+	}
+	if len(d.Codebases) == 0 {
+		return nil, fmt.Errorf("cannot select codebase '%s', the definition has a single codebase", name)
+	}
+	names := make([]string, len(d.Codebases))
+	for i, view := range d.codebaseViews() {
+		if view.Codebase.Name == name {
+			return []*ProjectDefinition{view}, nil
+		}
+		names[i] = view.Codebase.Name
+	}
+	msg := fmt.Sprintf("unknown codebase '%s'", name)
+	if suggestion := closestMatch(name, names); suggestion != "" {
+		msg += fmt.Sprintf(" (did you mean '%s'?)", suggestion)
+	}
+	return nil, errors.New(msg)
+}
+
+// checkCodebases makes sure codebases are named uniquely and not mixed
+// with a top-level codebase.
+func (d *ProjectDefinition) checkCodebases() error {
+	if len(d.Codebases) == 0 {
+		return nil
+	}
+	if !isZeroCodebase(d.Codebase) {
+		return errors.New("use either codebase or codebases, not both")
+	}
+	seen := map[string]bool{}
+	for i, codebase := range d.Codebases {
+		if codebase.Name == "" {
+			return fmt.Errorf("codebases[%d] has no name", i)
+		}
+		if seen[codebase.Name] {
+			return fmt.Errorf("codebase '%s' is defined more than once", codebase.Name)
+		}
+		seen[codebase.Name] = true
+	}
+	return nil
+}
+
+func isZeroCodebase(c Codebase) bool {
+	return c.Name == "" && c.Path == "" && c.Language == "" && c.Dependencies == nil &&
+		c.Install.Steps == nil && c.Test.Steps == nil && c.Build.Steps == nil && len(c.Operations) == 0
+}
+
+// operationNames returns the operations defined by any codebase, built-in
+// ones first.
+func (d *ProjectDefinition) operationNames() []string {
+	custom := []string{}
+	for _, view := range d.codebaseViews() {
+		for _, name := range view.Codebase.OperationNames()[len(builtinOperations):] {
+			if !slices.Contains(custom, name) {
+				custom = append(custom, name)
+			}
+		}
+	}
+	sort.Strings(custom)
+	return append(slices.Clone(builtinOperations), custom...)
+}
+
+// hasOperation reports whether any codebase defines the operation.
+func (d *ProjectDefinition) hasOperation(name string) bool {
+	return slices.Contains(d.operationNames(), name)
+}
+
+// runInCodebases calls fn for each selected codebase that defines the
+// operation, from the codebase's directory.
+func (d *ProjectDefinition) runInCodebases(ctx context.Context, operation string, fn func(view *ProjectDefinition) error) error {
+	if len(d.Codebases) == 0 {
+		return fn(d)
+	}
+	views, err := d.SelectCodebases(CodebaseFromContext(ctx))
+	if err != nil {
+		return err
+	}
+	ran := false
+	for _, view := range views {
+		if _, ok := view.Codebase.Lookup(operation); !ok {
+			continue
+		}
+		ran = true
+		logging.FromContext(ctx).Infof("Running %s in codebase %s", operation, view.Codebase.Name)
+		err := inCodebase(view.Codebase.Path, func() error { return fn(view) })
+		if err != nil {
+			return fmt.Errorf("codebase %s: %w", view.Codebase.Name, err)
+		}
+	}
+	if !ran {
+		return d.unknownOperation(operation)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const polyglotDefinition = `id: polyglot
+codebases:
+  - name: backend
+    path: backend
+    language: go
+    dependencies: [go]
+    test:
+      steps: [go test ./...]
+    build:
+      steps: [go build ./...]
+  - name: frontend
+    path: frontend
+    language: typescript
+    dependencies: [node]
+    test:
+      steps: [npm test]
+    operations:
+      lint:
+        steps: [npm run lint]
+`
+
+func TestLoad_Codebases(t *testing.T) {
+	cfg, err := Load(strings.NewReader(polyglotDefinition))
+	require.NoError(t, err)
+	require.Len(t, cfg.Codebases, 2)
+	assert.Equal(t, "frontend", cfg.Codebases[1].Name)
+	assert.Equal(t, "typescript", cfg.Codebases[1].Language)
+	assert.Equal(t, []string{"install", "test", "build", "lint"}, cfg.operationNames())
+
+	views, err := cfg.SelectCodebases("frontend")
+	require.NoError(t, err)
+	require.Len(t, views, 1)
+	assert.Equal(t, "npm test", views[0].Codebase.Test.Steps[0].Run)
+
+	_, err = cfg.SelectCodebases("fronted")
+	assert.ErrorContains(t, err, "unknown codebase 'fronted' (did you mean 'frontend'?)")
+}
+
+func TestLoad_CodebasesInvalid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"unnamed", "id: x\ncodebases:\n  - language: go\n", "codebases[0] has no name"},
+		{"duplicate", "id: x\ncodebases:\n  - name: a\n  - name: a\n", "codebase 'a' is defined more than once"},
+		{"mixed", "id: x\ncodebase:\n  language: go\ncodebases:\n  - name: a\n", "use either codebase or codebases, not both"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Load(strings.NewReader(tc.content))
+			assert.ErrorContains(t, err, tc.expected)
+		})
+	}
+
+	_, err := (&ProjectDefinition{}).SelectCodebases("api")
+	assert.ErrorContains(t, err, "cannot select codebase 'api', the definition has a single codebase")
+}
+
+func TestProjectDefinition_Run_Codebases(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "backend"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "frontend"), 0o755))
+	t.Chdir(dir)
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	cfg, err := Load(strings.NewReader(polyglotDefinition))
+	require.NoError(t, err)
+
+	newExecutor := func(ran *[]string) *MockShellExecutor {
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+			cwd, err := os.Getwd()
+			require.NoError(t, err)
+			*ran = append(*ran, filepath.Base(cwd)+": "+args.String(1))
+		}).Return(executor.Result{}, nil)
+		return m
+	}
+
+	t.Run("all codebases", func(t *testing.T) {
+		ran := []string{}
+		require.NoError(t, cfg.Test(ctx, newExecutor(&ran)))
+		assert.Equal(t, []string{"backend: go test ./...", "frontend: npm test"}, ran)
+	})
+
+	t.Run("only codebases defining the operation", func(t *testing.T) {
+		ran := []string{}
+		require.NoError(t, cfg.Run(ctx, "lint", newExecutor(&ran)))
+		assert.Equal(t, []string{"frontend: npm run lint"}, ran)
+		assert.ErrorContains(t, cfg.Run(ctx, "lnt", newExecutor(&ran)), "unknown operation 'lnt' (did you mean 'lint'?)")
+	})
+
+	t.Run("selected codebase", func(t *testing.T) {
+		ran := []string{}
+		require.NoError(t, cfg.Test(WithCodebase(ctx, "backend"), newExecutor(&ran)))
+		assert.Equal(t, []string{"backend: go test ./..."}, ran)
+	})
+
+	t.Run("exec needs a single codebase", func(t *testing.T) {
+		ran := []string{}
+		err := cfg.Exec(ctx, []string{"ls"}, "", newExecutor(&ran))
+		assert.ErrorContains(t, err, "select one with --codebase")
+		require.NoError(t, cfg.Exec(WithCodebase(ctx, "frontend"), []string{"ls"}, "", newExecutor(&ran)))
+		assert.Equal(t, []string{"frontend: ls"}, ran)
+	})
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Base(dir), filepath.Base(cwd))
+}
+
+func TestProjectDefinition_Report_Codebases(t *testing.T) {
+	cfg, err := Load(strings.NewReader(polyglotDefinition))
+	require.NoError(t, err)
+
+	report := cfg.Report()
+	messages := []string{}
+	for _, finding := range report.Findings {
+		messages = append(messages, finding.Message)
+	}
+	assert.Contains(t, messages, "Codebase: backend")
+	assert.Contains(t, messages, "Language: go")
+	assert.Contains(t, messages, "Codebase: frontend")
+	assert.Contains(t, messages, "Language: typescript")
+	assert.Contains(t, messages, "No build steps defined")
+}
+
+func TestProjectDefinition_Run_CodebasesKeepWorkDirAtRoot(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "backend"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "frontend"), 0o755))
+	t.Chdir(dir)
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	cfg, err := Load(strings.NewReader(`id: polyglot
+codebases:
+  - name: backend
+    path: backend
+    build:
+      spill_output: true
+      artifacts: [out.txt]
+      steps: [echo backend > out.txt]
+  - name: frontend
+    path: frontend
+    build:
+      spill_output: true
+      artifacts: [out.txt]
+      steps: [echo frontend > out.txt]
+`))
+	require.NoError(t, err)
+
+	require.NoError(t, cfg.Build(ctx, &executor.DefaultExecutor{}))
+
+	logs, err := filepath.Glob(filepath.Join(LogsDir, "*"))
+	require.NoError(t, err)
+	assert.Len(t, logs, 2)
+	runs, err := filepath.Glob(filepath.Join(RunsDir, "*-build"))
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Contains(t, runs[0], "-backend-build")
+	assert.Contains(t, runs[1], "-frontend-build")
+	assert.NoDirExists(t, filepath.Join("backend", WorkDir))
+	assert.NoDirExists(t, filepath.Join("frontend", WorkDir))
+	assert.FileExists(t, filepath.Join("backend", "out.txt"))
+}
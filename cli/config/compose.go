@@ -0,0 +1,106 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+)
+
+// composeTeardownTimeout bounds the teardown of a compose stack, which
+// runs even after the operation is cancelled.
+const composeTeardownTimeout = 2 * time.Minute
+
+// Compose is a docker compose stack brought up before the steps of an
+// operation and torn down after them, whatever their outcome. Services
+// defaults to every service of the file, and Project to a name unique to
+// the run.
+type Compose struct {
+	File        string        `yaml:"file"`
+	Project     string        `yaml:"project,omitempty"`
+	Services    []string      `yaml:"services,omitempty"`
+	WaitTimeout time.Duration `yaml:"wait_timeout,omitempty"`
+}
+
+// project returns the compose project name of a run.
+func (c Compose) project(info RunInfo) string {
+	if c.Project != "" {
+		return c.Project
+	}
+	name := "devops"
+	if info.RunID != "" {
+		name += "-" + info.RunID
+	}
+	return safeName(name)
+}
+
+func (c Compose) command(project string, args ...string) string {
+	return strings.Join(append([]string{"docker", "compose", "--file", shellQuote(c.File), "--project-name", shellQuote(project)}, args...), " ")
+}
+
+// env exposes the stack to the steps, so they can run docker compose
+// commands against it.
+func (c Compose) env(project string) []string {
+	return []string{"COMPOSE_FILE=" + c.File, "COMPOSE_PROJECT_NAME=" + project}
+}
+
+// up starts the stack and waits for its services to be running and
+// healthy. It returns a teardown function to defer, which runs even if up
+// fails so a partly started stack is removed.
+func (c Compose) up(ctx context.Context, project string, shellExecutor ShellExecutor) (func(), error) {
+	logger := logging.FromContext(ctx)
+	down := func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), composeTeardownTimeout)
+		defer cancel()
+		logger.Infof("Tearing down compose stack %s", project)
+		result, err := shellExecutor.Exec(ctx, c.command(project, "down", "--volumes", "--remove-orphans"))
+		if err != nil || result.ExitCode != 0 {
+			logger.Warnf("Failed to tear down compose stack %s (exit code %d): %v", project, result.ExitCode, err)
+		}
+	}
+
+	args := []string{"up", "--detach", "--wait"}
+	if c.WaitTimeout > 0 {
+		args = append(args, "--wait-timeout", strconv.Itoa(int(c.WaitTimeout.Round(time.Second).Seconds())))
+	}
+	for _, service := range c.Services {
+		args = append(args, shellQuote(service))
+	}
+	logger.Infof("Starting compose stack %s from %s", project, c.File)
+	result, err := shellExecutor.Exec(ctx, c.command(project, args...))
+	if err == nil && result.ExitCode != 0 {
+		err = fmt.Errorf("exit code %d", result.ExitCode)
+	}
+	if err != nil {
+		if stderr := strings.TrimSpace(result.Stderr); stderr != "" {
+			err = fmt.Errorf("%w: %s", err, stderr)
+		}
+		return down, fmt.Errorf("compose stack %s did not become healthy: %w", project, err)
+	}
+	return down, nil
+}
+
+// checkCompose reports compose files that do not exist.
+func (d *ProjectDefinition) checkCompose(b *reportBuilder) {
+	if b.files == nil {
+		return
+	}
+	root := path.Clean(strings.TrimPrefix(d.Codebase.Path, "./"))
+	for _, name := range d.Codebase.OperationNames() {
+		op, _ := d.Codebase.Lookup(name)
+		if op.Compose.File == "" {
+			continue
+		}
+		if _, err := fs.Stat(b.files, path.Join(root, op.Compose.File)); err != nil {
+			b.failAt(d.operationPath(name), RuleComposeFile, fmt.Sprintf("Fix compose.file in %s or restore the file", name),
+				"Compose file %s of %s does not exist", op.Compose.File, name)
+			continue
+		}
+		b.pass(RuleComposeFile, "Operation '%s' runs with compose stack %s", name, op.Compose.File)
+	}
+}
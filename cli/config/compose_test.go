@@ -0,0 +1,96 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	composeUp   = "docker compose --file 'compose.test.yaml' --project-name 'it' up --detach --wait --wait-timeout 30 'db'"
+	composeDown = "docker compose --file 'compose.test.yaml' --project-name 'it' down --volumes --remove-orphans"
+)
+
+func newComposeOperation() Operation {
+	return Operation{
+		FailFast: true,
+		Compose:  Compose{File: "compose.test.yaml", Project: "it", Services: []string{"db"}, WaitTimeout: 30 * time.Second},
+		Steps:    StepsFromCommands("go test ./..."),
+	}
+}
+
+func TestCompose_Project(t *testing.T) {
+	assert.Equal(t, "devops-20260301t100000z-test", Compose{}.project(RunInfo{RunID: "20260301T100000Z-test"}))
+	assert.Equal(t, "devops", Compose{}.project(RunInfo{}))
+	assert.Equal(t, "it", Compose{Project: "it"}.project(RunInfo{RunID: "x"}))
+}
+
+func TestOperation_Run_Compose(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+
+	t.Run("tears down after the steps", func(t *testing.T) {
+		m := &MockShellExecutor{}
+		calls := []string{}
+		recordCalls(m, &calls, composeUp, composeDown)
+		m.On("Exec", mock.Anything, "go test ./...").Run(func(args mock.Arguments) {
+			calls = append(calls, args.String(1))
+			assert.Contains(t, executor.EnvFromContext(args.Get(0).(context.Context)), "COMPOSE_PROJECT_NAME=it")
+		}).Return(executor.Result{ExitCode: 1}, errors.New("exit status 1"))
+
+		op := newComposeOperation()
+		assert.Error(t, op.Run(ctx, m))
+		assert.Equal(t, []string{composeUp, "go test ./...", composeDown}, calls)
+	})
+
+	t.Run("skips the steps when the stack is unhealthy", func(t *testing.T) {
+		m := &MockShellExecutor{}
+		calls := []string{}
+		recordCalls(m, &calls, composeDown)
+		m.On("Exec", mock.Anything, composeUp).Return(executor.Result{ExitCode: 1, Stderr: "container it-db-1 is unhealthy\n"}, nil)
+
+		op := newComposeOperation()
+		err := op.Run(ctx, m)
+		assert.EqualError(t, err, "compose stack it did not become healthy: exit code 1: container it-db-1 is unhealthy")
+		assert.Equal(t, []string{composeDown}, calls)
+		m.AssertNotCalled(t, "Exec", mock.Anything, "go test ./...")
+	})
+
+	t.Run("tears down when cancelled", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, composeUp).Return(executor.Result{}, nil)
+		m.On("Exec", mock.Anything, composeDown).Run(func(args mock.Arguments) {
+			assert.NoError(t, args.Get(0).(context.Context).Err())
+		}).Return(executor.Result{}, nil)
+
+		op := newComposeOperation()
+		assert.ErrorIs(t, op.Run(cancelled, m), context.Canceled)
+		m.AssertCalled(t, "Exec", mock.Anything, composeDown)
+	})
+}
+
+func TestProjectDefinition_Report_Compose(t *testing.T) {
+	project := ProjectDefinition{ID: "app", Codebase: Codebase{Test: newComposeOperation()}}
+	files := fstest.MapFS{"compose.test.yaml": {Data: []byte("services: {}\n")}}
+
+	finding, ok := findingFor(project.ReportFiles(files), RuleComposeFile)
+	require.True(t, ok)
+	assert.True(t, finding.Passed)
+
+	delete(files, "compose.test.yaml")
+	finding, ok = findingFor(project.ReportFiles(files), RuleComposeFile)
+	require.True(t, ok)
+	assert.False(t, finding.Passed)
+	assert.Equal(t, "Compose file compose.test.yaml of test does not exist", finding.Message)
+}
@@ -0,0 +1,224 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jgfranco17/devops/cli/executor"
+)
+
+// defaultImageTags are the tags given to images when the container section
+// lists none.
+var defaultImageTags = []string{"{{.Version}}", "{{.ShortSHA}}"}
+
+// imageTagPattern matches a valid image tag.
+var imageTagPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+
+// Container configures the image built from the project with docker.
+type Container struct {
+	Dockerfile string `yaml:"dockerfile,omitempty"`
+	Context    string `yaml:"context,omitempty"`
+
+	// Registry is the host, and optionally namespace, images are pushed
+	// to, e.g. ghcr.io/acme. Image defaults to the project ID.
+	Registry string `yaml:"registry,omitempty"`
+	Image    string `yaml:"image,omitempty"`
+
+	// Tags are templates rendered with .ID, .Version, .SHA and .ShortSHA.
+	Tags      []string          `yaml:"tags,omitempty"`
+	BuildArgs map[string]string `yaml:"build_args,omitempty"`
+
+	// UsernameEnv and PasswordEnv name the secrets or environment
+	// variables holding the registry credentials.
+	UsernameEnv string `yaml:"username_env,omitempty"`
+	PasswordEnv string `yaml:"password_env,omitempty"`
+}
+
+// imageTagInfo is the data image tags are rendered with.
+type imageTagInfo struct {
+	ID       string
+	Version  string
+	SHA      string
+	ShortSHA string
+}
+
+// Defined reports whether the definition has a container section.
+func (c Container) Defined() bool {
+	return c.Dockerfile != "" || c.Context != "" || c.Registry != "" || c.Image != "" || len(c.Tags) > 0 || len(c.BuildArgs) > 0
+}
+
+// dockerfile returns the Dockerfile path, relative to the project root
+// like docker build --file; it defaults to the Dockerfile in the context.
+func (c Container) dockerfile() string {
+	if c.Dockerfile == "" {
+		return path.Join(c.context(), "Dockerfile")
+	}
+	return c.Dockerfile
+}
+
+func (c Container) context() string {
+	if c.Context == "" {
+		return "."
+	}
+	return c.Context
+}
+
+// imageRefs renders the image tags into full references, such as
+// ghcr.io/acme/app:1.2.0. Tags rendering to the same value are listed once.
+func (d *ProjectDefinition) imageRefs(info imageTagInfo) ([]string, error) {
+	c := d.Container
+	repository := c.Image
+	if repository == "" {
+		repository = d.ID
+	}
+	if c.Registry != "" {
+		repository = strings.TrimSuffix(c.Registry, "/") + "/" + repository
+	}
+	templates := c.Tags
+	if len(templates) == 0 {
+		templates = defaultImageTags
+	}
+	refs := []string{}
+	seen := map[string]bool{}
+	for _, text := range templates {
+		tmpl, err := template.New("tag").Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag template '%s': %w", text, err)
+		}
+		var tag strings.Builder
+		if err := tmpl.Execute(&tag, info); err != nil {
+			return nil, fmt.Errorf("invalid tag template '%s': %w", text, err)
+		}
+		if !imageTagPattern.MatchString(tag.String()) {
+			return nil, fmt.Errorf("tag template '%s' renders '%s', which is not a valid tag", text, tag.String())
+		}
+		ref := repository + ":" + tag.String()
+		if !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// imageTagInfo returns the tag data for the current commit.
+func (d *ProjectDefinition) imageTagInfo(ctx context.Context) (imageTagInfo, error) {
+	sha, err := runGit(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		return imageTagInfo{}, fmt.Errorf("failed to resolve the commit to tag images with: %w", err)
+	}
+	return imageTagInfo{ID: d.ID, Version: d.Version, SHA: sha, ShortSHA: sha[:min(len(sha), 7)]}, nil
+}
+
+// BuildImage builds the container image with docker, tagged with every
+// rendered tag, and returns the image references.
+func (d *ProjectDefinition) BuildImage(ctx context.Context, shellExecutor ShellExecutor) ([]string, error) {
+	if !d.Container.Defined() {
+		return nil, errors.New("no container section is defined")
+	}
+	info, err := d.imageTagInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := d.imageRefs(info)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"docker", "build", "--file", shellQuote(d.Container.dockerfile())}
+	for _, ref := range refs {
+		args = append(args, "--tag", shellQuote(ref))
+	}
+	keys := make([]string, 0, len(d.Container.BuildArgs))
+	for key := range d.Container.BuildArgs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "--build-arg", shellQuote(key+"="+d.Container.BuildArgs[key]))
+	}
+	args = append(args, shellQuote(d.Container.context()))
+	if err := d.Preflight.withDocker(true).Check(ctx); err != nil {
+		return nil, err
+	}
+	return refs, d.runCommands(ctx, "image-build", shellExecutor, nil, strings.Join(args, " "))
+}
+
+// PushImage pushes the images tagged by BuildImage, logging in to the
+// registry first when credentials for it are set. Pushing is refused in
+// read-only mode.
+func (d *ProjectDefinition) PushImage(ctx context.Context, shellExecutor ShellExecutor) ([]string, error) {
+	if ReadOnlyFromContext(ctx) {
+		return nil, errors.New("pushing images is not allowed in read-only mode")
+	}
+	if !d.Container.Defined() {
+		return nil, errors.New("no container section is defined")
+	}
+	if d.Container.Registry == "" {
+		return nil, errors.New("container.registry must be set to push images")
+	}
+	info, err := d.imageTagInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := d.imageRefs(info)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.Preflight.withDocker(true).Check(ctx); err != nil {
+		return nil, err
+	}
+	commands := []string{}
+	var env map[string]string
+	if login, password, ok := d.pushLogin(ctx, registryHost(d.Container.Registry)); ok {
+		commands = append(commands, login)
+		env = map[string]string{registryPasswordEnv: password}
+		ctx = executor.WithSecrets(ctx, password)
+	}
+	for _, ref := range refs {
+		commands = append(commands, "docker push "+shellQuote(ref))
+	}
+	return refs, d.runCommands(ctx, "image-push", shellExecutor, env, commands...)
+}
+
+// runCommands runs tool commands as the steps of an operation, with
+// env, the project env files and secrets.
+func (d *ProjectDefinition) runCommands(ctx context.Context, name string, shellExecutor ShellExecutor, env map[string]string, commands ...string) error {
+	op := Operation{FailFast: true, Env: env, Steps: StepsFromCommands(commands...)}
+	ctx = withRunInfo(ctx, RunInfo{ProjectID: d.ID, Version: d.Version, Operation: name, RunID: newRunID(d.Codebase.Name, name, time.Now())})
+	op, err := d.withEnvFiles(ctx, op)
+	if err != nil {
+		return err
+	}
+	ctx, op, err = d.withSecrets(ctx, op)
+	if err != nil {
+		return err
+	}
+	return op.Run(ctx, shellExecutor)
+}
+
+// checkContainer reports an unusable container section.
+func (d *ProjectDefinition) checkContainer(b *reportBuilder) {
+	if !d.Container.Defined() {
+		return
+	}
+	_, err := d.imageRefs(imageTagInfo{ID: d.ID, Version: d.Version, SHA: strings.Repeat("0", 40), ShortSHA: "0000000"})
+	if err == nil && b.files != nil {
+		dockerfile := path.Clean(d.Container.dockerfile())
+		if _, statErr := fs.Stat(b.files, dockerfile); statErr != nil {
+			err = fmt.Errorf("dockerfile %s not found", dockerfile)
+		}
+	}
+	if err != nil {
+		b.fail(RuleContainerConfig, "Fix the container section or remove it", "container: %s", err.Error())
+		return
+	}
+	b.pass(RuleContainerConfig, "Container image: %s", d.Container.dockerfile())
+}
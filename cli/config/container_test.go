@@ -0,0 +1,112 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubGitSHA makes git report sha as the current commit.
+func stubGitSHA(t *testing.T, sha string) {
+	original := runGit
+	runGit = func(ctx context.Context, args ...string) (string, error) {
+		return sha, nil
+	}
+	t.Cleanup(func() { runGit = original })
+}
+
+// stubDockerPing makes the pre-flight docker check report err.
+func stubDockerPing(t *testing.T, err error) {
+	original := dockerPing
+	dockerPing = func(ctx context.Context) error { return err }
+	t.Cleanup(func() { dockerPing = original })
+}
+
+func TestProjectDefinition_ImageRefs(t *testing.T) {
+	info := imageTagInfo{ID: "app", Version: "1.2.0", SHA: "0123456789abcdef0123456789abcdef01234567", ShortSHA: "0123456"}
+	project := ProjectDefinition{ID: "app", Container: Container{Registry: "ghcr.io/acme/"}}
+
+	refs, err := project.imageRefs(info)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ghcr.io/acme/app:1.2.0", "ghcr.io/acme/app:0123456"}, refs)
+
+	project.Container = Container{Image: "web", Tags: []string{"v{{.Version}}", "latest", "latest"}}
+	refs, err = project.imageRefs(info)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web:v1.2.0", "web:latest"}, refs)
+
+	project.Container.Tags = []string{"{{.Branch}}"}
+	_, err = project.imageRefs(info)
+	assert.ErrorContains(t, err, "invalid tag template '{{.Branch}}'")
+
+	project.Container.Tags = []string{"{{.Version}}+build"}
+	_, err = project.imageRefs(info)
+	assert.EqualError(t, err, "tag template '{{.Version}}+build' renders '1.2.0+build', which is not a valid tag")
+}
+
+func TestProjectDefinition_BuildImage(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	stubGitSHA(t, "0123456789abcdef0123456789abcdef01234567")
+	stubDockerPing(t, nil)
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("REGISTRY_PASSWORD", "")
+	t.Setenv("GHCR_IO_PASSWORD", "")
+	project := ProjectDefinition{
+		ID:      "app",
+		Version: "1.2.0",
+		Container: Container{
+			Context:   "services/web",
+			Registry:  "ghcr.io/acme",
+			BuildArgs: map[string]string{"VERSION": "1.2.0", "GO_VERSION": "1.24"},
+		},
+	}
+	m := &MockShellExecutor{}
+	calls := []string{}
+	build := "docker build --file 'services/web/Dockerfile' --tag 'ghcr.io/acme/app:1.2.0' --tag 'ghcr.io/acme/app:0123456'" +
+		" --build-arg 'GO_VERSION=1.24' --build-arg 'VERSION=1.2.0' 'services/web'"
+	recordCalls(m, &calls, build, "docker push 'ghcr.io/acme/app:1.2.0'", "docker push 'ghcr.io/acme/app:0123456'")
+
+	refs, err := project.BuildImage(ctx, m)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ghcr.io/acme/app:1.2.0", "ghcr.io/acme/app:0123456"}, refs)
+
+	_, err = project.PushImage(ctx, m)
+	require.NoError(t, err)
+	assert.Equal(t, []string{build, "docker push 'ghcr.io/acme/app:1.2.0'", "docker push 'ghcr.io/acme/app:0123456'"}, calls)
+
+	_, err = project.PushImage(WithReadOnly(ctx), m)
+	assert.EqualError(t, err, "pushing images is not allowed in read-only mode")
+
+	project.Container.Registry = ""
+	_, err = project.PushImage(ctx, m)
+	assert.EqualError(t, err, "container.registry must be set to push images")
+
+	_, err = (&ProjectDefinition{ID: "app"}).BuildImage(ctx, m)
+	assert.EqualError(t, err, "no container section is defined")
+
+	stubDockerPing(t, errors.New("Cannot connect to the Docker daemon"))
+	_, err = project.BuildImage(ctx, m)
+	assert.ErrorContains(t, err, "docker daemon is not reachable: Cannot connect to the Docker daemon")
+}
+
+func TestProjectDefinition_Report_Container(t *testing.T) {
+	project := ProjectDefinition{ID: "app", Version: "1.0.0", Container: Container{Registry: "ghcr.io/acme"}}
+	files := fstest.MapFS{"Dockerfile": {Data: []byte("FROM scratch\n")}}
+
+	finding, ok := findingFor(project.ReportFiles(files), RuleContainerConfig)
+	require.True(t, ok)
+	assert.True(t, finding.Passed)
+
+	project.Container.Dockerfile = "build/Dockerfile"
+	finding, ok = findingFor(project.ReportFiles(files), RuleContainerConfig)
+	require.True(t, ok)
+	assert.False(t, finding.Passed)
+	assert.Equal(t, "container: dockerfile build/Dockerfile not found", finding.Message)
+}
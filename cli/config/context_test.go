@@ -27,10 +27,10 @@ func TestWithContext(t *testing.T) {
 					Language:     "go",
 					Dependencies: []string{"go.mod"},
 					Install: Operation{
-						Steps: []string{"go mod download"},
+						Steps: StepsFromCommands("go mod download"),
 					},
 					Build: Operation{
-						Steps: []string{"go build ./..."},
+						Steps: StepsFromCommands("go build ./..."),
 					},
 				},
 			},
@@ -46,14 +46,14 @@ func TestWithContext(t *testing.T) {
 						Env: map[string]string{
 							"PYTHONPATH": "/custom/path",
 						},
-						Steps: []string{"pip install -r requirements.txt"},
+						Steps: StepsFromCommands("pip install -r requirements.txt"),
 					},
 					Build: Operation{
 						FailFast: false,
 						Env: map[string]string{
 							"BUILD_ENV": "production",
 						},
-						Steps: []string{"python setup.py build", "python -m pytest"},
+						Steps: StepsFromCommands("python setup.py build", "python -m pytest"),
 					},
 				},
 			},
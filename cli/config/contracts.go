@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Inputs declares what an operation expects to exist before it runs.
+type Inputs struct {
+	Files []string `yaml:"files,omitempty"`
+	Env   []string `yaml:"env,omitempty"`
+}
+
+// missingInputs returns the declared input files (or patterns) that match
+// nothing and the declared env vars that are not set.
+func (op *Operation) missingInputs() (files []string, env []string) {
+	files = missingPaths(op.Inputs.Files)
+	for _, key := range op.Inputs.Env {
+		if _, ok := os.LookupEnv(key); !ok {
+			env = append(env, key)
+		}
+	}
+	return files, env
+}
+
+// checkOutputs verifies that every declared output exists after a run.
+func (op *Operation) checkOutputs() error {
+	if missing := missingPaths(op.Outputs); len(missing) > 0 {
+		return fmt.Errorf("declared outputs not produced: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// missingPaths returns the paths or glob patterns that match no files.
+func missingPaths(patterns []string) []string {
+	missing := []string{}
+	for _, pattern := range patterns {
+		if matches, err := filepath.Glob(pattern); err != nil || len(matches) == 0 {
+			missing = append(missing, pattern)
+		}
+	}
+	return missing
+}
+
+// checkContracts reports the declared inputs of every operation that are
+// missing from the working tree or environment.
+func (d *ProjectDefinition) checkContracts(b *reportBuilder) {
+	for _, name := range d.Codebase.OperationNames() {
+		op, _ := d.Codebase.Lookup(name)
+		if len(op.Inputs.Files) == 0 && len(op.Inputs.Env) == 0 {
+			continue
+		}
+		files, env := op.missingInputs()
+		if len(files) > 0 {
+			b.failAt(d.operationPath(name), RuleOperationInputs, fmt.Sprintf("Create the files %s needs or update its declared inputs", name),
+				"Operation '%s' is missing input files: %s", name, strings.Join(files, ", "))
+		}
+		if len(env) > 0 {
+			b.failAt(d.operationPath(name), RuleOperationInputs, fmt.Sprintf("Export the variables %s needs or update its declared inputs", name),
+				"Operation '%s' is missing input env: %s", name, strings.Join(env, ", "))
+		}
+		if len(files) == 0 && len(env) == 0 {
+			b.pass(RuleOperationInputs, "Operation '%s' inputs present", name)
+		}
+	}
+}
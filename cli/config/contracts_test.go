@@ -0,0 +1,82 @@
+package config
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectDefinition_Report_OperationInputs(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile("go.mod", []byte("module example"), 0644))
+	t.Setenv("DEVOPS_PRESENT_VAR", "1")
+
+	project := ProjectDefinition{
+		Codebase: Codebase{
+			Test: Operation{
+				Inputs: Inputs{Files: []string{"go.mod", "*.go"}, Env: []string{"DEVOPS_PRESENT_VAR", "DEVOPS_MISSING_VAR"}},
+				Steps:  StepsFromCommands("go test ./..."),
+			},
+			Build: Operation{
+				Inputs: Inputs{Files: []string{"go.mod"}},
+				Steps:  StepsFromCommands("go build ./..."),
+			},
+		},
+	}
+
+	report := project.Report()
+	messages := []string{}
+	for _, finding := range report.Findings {
+		if finding.RuleID == RuleOperationInputs {
+			messages = append(messages, finding.Message)
+		}
+	}
+	assert.Equal(t, []string{
+		"Operation 'test' is missing input files: *.go",
+		"Operation 'test' is missing input env: DEVOPS_MISSING_VAR",
+		"Operation 'build' inputs present",
+	}, messages)
+	finding, ok := findingFor(report, RuleOperationInputs)
+	require.True(t, ok)
+	assert.Equal(t, SeverityError, finding.Severity)
+}
+
+func TestProjectDefinition_Run_Outputs(t *testing.T) {
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	project := ProjectDefinition{
+		Codebase: Codebase{
+			Build: Operation{
+				Outputs: []string{"bin/app"},
+				Steps:   StepsFromCommands("make"),
+			},
+		},
+	}
+
+	t.Run("missing outputs fail the operation", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, "make").Return(executor.Result{}, nil)
+
+		err := project.Build(ctx, m)
+		assert.ErrorContains(t, err, "operation build: declared outputs not produced: bin/app")
+	})
+
+	t.Run("produced outputs pass", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, "make").Run(func(mock.Arguments) {
+			_ = os.MkdirAll("bin", 0755)
+			_ = os.WriteFile("bin/app", []byte("app"), 0755)
+		}).Return(executor.Result{}, nil)
+
+		assert.NoError(t, project.Build(ctx, m))
+	})
+}
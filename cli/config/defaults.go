@@ -0,0 +1,29 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FlagValue holds the default for a single flag. It is written as a scalar
+// or, for flags that accept several values, as a list.
+type FlagValue []string
+
+func (f *FlagValue) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.ScalarNode:
+		*f = FlagValue{value.Value}
+		return nil
+	case yaml.SequenceNode:
+		var values []string
+		if err := value.Decode(&values); err != nil {
+			return err
+		}
+		*f = values
+		return nil
+	}
+	return &yaml.TypeError{Errors: []string{
+		fmt.Sprintf("line %d: flag default must be a scalar or a list", value.Line),
+	}}
+}
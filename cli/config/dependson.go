@@ -0,0 +1,119 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+)
+
+const (
+	skipDependenciesKey   contextKey = "skip-dependencies"
+	excludedOperationsKey contextKey = "excluded-operations"
+)
+
+// WithoutDependencies makes Run execute only the named operation, leaving
+// out the operations it depends on.
+func WithoutDependencies(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipDependenciesKey, true)
+}
+
+// DependenciesSkipped reports whether dependency resolution is turned off.
+func DependenciesSkipped(ctx context.Context) bool {
+	skipped, _ := ctx.Value(skipDependenciesKey).(bool)
+	return skipped
+}
+
+// withExcludedOperations keeps the named operations from running as
+// prerequisites of others.
+func withExcludedOperations(ctx context.Context, names []string) context.Context {
+	return context.WithValue(ctx, excludedOperationsKey, names)
+}
+
+func excludedOperationsFromContext(ctx context.Context) []string {
+	names, _ := ctx.Value(excludedOperationsKey).([]string)
+	return names
+}
+
+// DependencyOrder returns the operations name depends on, directly or
+// through other operations, in the order they must run, followed by name.
+func (d *ProjectDefinition) DependencyOrder(name string) ([]string, error) {
+	if _, ok := d.Codebase.Lookup(name); !ok {
+		return nil, d.unknownOperation(name)
+	}
+	needs := map[string][]string{}
+	pending := []string{name}
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = pending[1:]
+		if _, seen := needs[current]; seen {
+			continue
+		}
+		op, _ := d.Codebase.Lookup(current)
+		for _, dep := range op.DependsOn {
+			if _, ok := d.Codebase.Lookup(dep); !ok {
+				return nil, fmt.Errorf("operation '%s' depends on %w", current, d.unknownOperation(dep))
+			}
+		}
+		needs[current] = op.DependsOn
+		pending = append(pending, op.DependsOn...)
+	}
+
+	order, cyclic := dependencyOrder(needs)
+	if len(cyclic) > 0 {
+		return nil, fmt.Errorf("operations have a dependency cycle between: %s", strings.Join(cyclic, ", "))
+	}
+	return order, nil
+}
+
+// runWithDependencies runs the operations name depends on in order, then
+// name itself. Dependencies are left out when a single step of name is
+// selected. Prerequisites that already succeeded during this invocation
+// are not run again.
+func (d *ProjectDefinition) runWithDependencies(ctx context.Context, name string, shellExecutor ShellExecutor) error {
+	if _, ok := selectedStep(ctx, name); ok || DependenciesSkipped(ctx) {
+		return d.run(ctx, name, shellExecutor, map[string]bool{})
+	}
+	logger := logging.FromContext(ctx)
+	order, err := d.DependencyOrder(name)
+	if err != nil {
+		return err
+	}
+	prerequisites := order[:len(order)-1]
+	if len(prerequisites) > 0 {
+		logger.Infof("Running %s after %s", name, strings.Join(prerequisites, " -> "))
+	}
+	for _, prerequisite := range prerequisites {
+		if slices.Contains(excludedOperationsFromContext(ctx), prerequisite) {
+			logger.Infof("Prerequisite %s was left out, skipping", prerequisite)
+			continue
+		}
+		if RunSummaryFromContext(ctx).succeeded(d.Codebase.Name, prerequisite) {
+			logger.Infof("Prerequisite %s already succeeded, skipping", prerequisite)
+			continue
+		}
+		if err := d.run(ctx, prerequisite, shellExecutor, map[string]bool{}); err != nil {
+			return fmt.Errorf("prerequisite %s of %s failed: %w", prerequisite, name, err)
+		}
+	}
+	return d.run(ctx, name, shellExecutor, map[string]bool{})
+}
+
+// checkDependencies reports depends_on entries that name unknown
+// operations or form a cycle.
+func (d *ProjectDefinition) checkDependencies(b *reportBuilder) {
+	for _, name := range d.Codebase.OperationNames() {
+		op, _ := d.Codebase.Lookup(name)
+		if len(op.DependsOn) == 0 {
+			continue
+		}
+		order, err := d.DependencyOrder(name)
+		if err != nil {
+			b.failAt(d.operationPath(name), RuleOperationDependencies, "Fix the depends_on list of the operation", "Invalid dependencies of '%s': %s", name, err.Error())
+			continue
+		}
+		b.pass(RuleOperationDependencies, "Operation '%s' runs after: %s", name, strings.Join(order[:len(order)-1], " -> "))
+	}
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newDependencyProject() ProjectDefinition {
+	return ProjectDefinition{
+		ID: "dependency-project",
+		Codebase: Codebase{
+			Install: Operation{Steps: StepsFromCommands("go mod download")},
+			Test:    Operation{DependsOn: []string{"install"}, Steps: StepsFromCommands("go test ./...")},
+			Build:   Operation{Steps: StepsFromCommands("go build ./...")},
+			Operations: map[string]Operation{
+				"lint": {DependsOn: []string{"install"}, Steps: StepsFromCommands("golangci-lint run")},
+				"e2e":  {DependsOn: []string{"test", "lint"}, Steps: StepsFromCommands("./e2e.sh")},
+			},
+		},
+	}
+}
+
+func recordCalls(m *MockShellExecutor, calls *[]string, commands ...string) {
+	for _, command := range commands {
+		m.On("Exec", mock.Anything, command).Run(func(args mock.Arguments) {
+			*calls = append(*calls, args.String(1))
+		}).Return(executor.Result{}, nil)
+	}
+}
+
+func TestProjectDefinition_DependencyOrder(t *testing.T) {
+	project := newDependencyProject()
+	order, err := project.DependencyOrder("e2e")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"install", "lint", "test", "e2e"}, order)
+
+	order, err = project.DependencyOrder("build")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"build"}, order)
+
+	project.Codebase.Operations["lint"] = Operation{DependsOn: []string{"instal"}}
+	_, err = project.DependencyOrder("e2e")
+	assert.EqualError(t, err, "operation 'lint' depends on unknown operation 'instal' (did you mean 'install'?)")
+
+	project.Codebase.Install.DependsOn = []string{"e2e"}
+	project.Codebase.Operations["lint"] = Operation{}
+	_, err = project.DependencyOrder("test")
+	assert.EqualError(t, err, "operations have a dependency cycle between: e2e, install, test")
+}
+
+func TestProjectDefinition_Run_DependsOn(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	project := newDependencyProject()
+	commands := []string{"go mod download", "golangci-lint run", "go test ./...", "./e2e.sh"}
+
+	t.Run("runs prerequisites in order", func(t *testing.T) {
+		m := &MockShellExecutor{}
+		calls := []string{}
+		recordCalls(m, &calls, commands...)
+
+		require.NoError(t, project.Run(ctx, "e2e", m))
+		assert.Equal(t, commands, calls)
+	})
+
+	t.Run("skips prerequisites that already succeeded", func(t *testing.T) {
+		m := &MockShellExecutor{}
+		calls := []string{}
+		recordCalls(m, &calls, commands...)
+		summaryCtx := WithRunSummary(ctx, &RunSummary{})
+
+		require.NoError(t, project.Run(summaryCtx, "test", m))
+		require.NoError(t, project.Run(summaryCtx, "e2e", m))
+		assert.Equal(t, []string{"go mod download", "go test ./...", "golangci-lint run", "./e2e.sh"}, calls)
+	})
+
+	t.Run("only runs the operation itself", func(t *testing.T) {
+		m := &MockShellExecutor{}
+		calls := []string{}
+		recordCalls(m, &calls, commands...)
+
+		require.NoError(t, project.Run(WithoutDependencies(ctx), "e2e", m))
+		assert.Equal(t, []string{"./e2e.sh"}, calls)
+	})
+
+	t.Run("stops when a prerequisite fails", func(t *testing.T) {
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, "go mod download").Return(executor.Result{ExitCode: 1}, errors.New("exit status 1"))
+
+		err := project.Run(ctx, "e2e", m)
+		assert.ErrorContains(t, err, "prerequisite install of e2e failed")
+		m.AssertNotCalled(t, "Exec", mock.Anything, "./e2e.sh")
+	})
+}
+
+func TestProjectDefinition_Report_Dependencies(t *testing.T) {
+	project := newDependencyProject()
+	finding, ok := findingFor(project.Report(), RuleOperationDependencies)
+	require.True(t, ok)
+	assert.True(t, finding.Passed)
+
+	project.Codebase.Operations["e2e"] = Operation{DependsOn: []string{"deploy"}}
+	report := project.Report()
+	failed := []Finding{}
+	for _, finding := range report.Findings {
+		if finding.RuleID == RuleOperationDependencies && !finding.Passed {
+			failed = append(failed, finding)
+		}
+	}
+	require.Len(t, failed, 1)
+	assert.Equal(t, SeverityError, failed[0].Severity)
+	assert.Contains(t, failed[0].Message, "Invalid dependencies of 'e2e'")
+}
@@ -0,0 +1,255 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Deploy configures how the project is deployed.
+type Deploy struct {
+	Helm     HelmConfig     `yaml:"helm,omitempty"`
+	Strategy DeployStrategy `yaml:"strategy,omitempty"`
+
+	// Profiles override the release settings per environment, such as
+	// staging or prod. Deployments are tracked per profile for rollback.
+	Profiles map[string]DeployProfile `yaml:"profiles,omitempty"`
+}
+
+// HelmConfig is the chart packaged and installed by devops deploy --helm.
+// Release defaults to the project ID.
+type HelmConfig struct {
+	Chart     string            `yaml:"chart,omitempty"`
+	Release   string            `yaml:"release,omitempty"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Values    []string          `yaml:"values,omitempty"`
+	Set       map[string]string `yaml:"set,omitempty"`
+}
+
+// DeployProfile overrides the namespace and values of a deployment. Its
+// values files are applied after those of the helm section, and its set
+// values replace those with the same key.
+type DeployProfile struct {
+	Namespace string            `yaml:"namespace,omitempty"`
+	Values    []string          `yaml:"values,omitempty"`
+	Set       map[string]string `yaml:"set,omitempty"`
+}
+
+// Defined reports whether the definition has a deploy section.
+func (d Deploy) Defined() bool {
+	return d.Helm.Chart != "" || len(d.Profiles) > 0 || d.Strategy.Type != "" || d.Strategy.HealthCheck.Run != ""
+}
+
+// profile returns the named deploy profile, or an empty one for no name.
+func (d Deploy) profile(name string) (DeployProfile, error) {
+	if name == "" {
+		return DeployProfile{}, nil
+	}
+	profile, ok := d.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(d.Profiles))
+		for profileName := range d.Profiles {
+			names = append(names, profileName)
+		}
+		msg := fmt.Sprintf("unknown deploy profile '%s'", name)
+		if suggestion := closestMatch(name, names); suggestion != "" {
+			msg += fmt.Sprintf(" (did you mean '%s'?)", suggestion)
+		}
+		return DeployProfile{}, errors.New(msg)
+	}
+	return profile, nil
+}
+
+// chartName reads the name of the chart from its Chart.yaml.
+func chartName(fsys fs.FS, chart string) (string, error) {
+	file := path.Join(path.Clean(chart), "Chart.yaml")
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return "", fmt.Errorf("chart %s not found: %w", chart, err)
+	}
+	var meta struct {
+		Name string `yaml:"name"`
+	}
+	if err := yaml.Unmarshal(data, &meta); err != nil {
+		return "", fmt.Errorf("invalid %s: %w", file, err)
+	}
+	if meta.Name == "" {
+		return "", fmt.Errorf("%s has no name", file)
+	}
+	return meta.Name, nil
+}
+
+// helmPackage returns the helm package command for the chart and the
+// package it writes into ChartsDir. The chart is versioned with the
+// project when the definition has a version.
+func (d *ProjectDefinition) helmPackage() (string, string, error) {
+	name, err := chartName(os.DirFS("."), d.Deploy.Helm.Chart)
+	if err != nil {
+		return "", "", err
+	}
+	args := []string{"helm", "package", shellQuote(d.Deploy.Helm.Chart), "--destination", shellQuote(ChartsDir)}
+	pkg := ChartsDir + "/" + name + ".tgz"
+	if d.Version != "" {
+		args = append(args, "--version", shellQuote(d.Version), "--app-version", shellQuote(d.Version))
+		pkg = fmt.Sprintf("%s/%s-%s.tgz", ChartsDir, name, d.Version)
+	}
+	return strings.Join(args, " "), pkg, nil
+}
+
+// PackageChart packages the Helm chart into ChartsDir, where it is listed
+// with the build artifacts, and returns the package path.
+func (d *ProjectDefinition) PackageChart(ctx context.Context, shellExecutor ShellExecutor) (string, error) {
+	if d.Deploy.Helm.Chart == "" {
+		return "", errors.New("deploy.helm.chart is not set")
+	}
+	command, pkg, err := d.helmPackage()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(ChartsDir, 0755); err != nil {
+		return "", err
+	}
+	return pkg, d.runCommands(ctx, "helm-package", shellExecutor, nil, command)
+}
+
+// DeployHelm packages the chart and installs or upgrades its release with
+// the values of the helm section and of the given profile, in the phases
+// of the deploy strategy, then records the deployment in the history of
+// the profile's environment. Deploying is
+// refused in read-only mode.
+func (d *ProjectDefinition) DeployHelm(ctx context.Context, profileName string, shellExecutor ShellExecutor, now time.Time) (Deployment, error) {
+	if ReadOnlyFromContext(ctx) {
+		return Deployment{}, errors.New("deploying is not allowed in read-only mode")
+	}
+	helm := d.Deploy.Helm
+	if helm.Chart == "" {
+		return Deployment{}, errors.New("deploy.helm.chart is not set")
+	}
+	if err := d.Deploy.Strategy.validate(); err != nil {
+		return Deployment{}, err
+	}
+	profile, err := d.Deploy.profile(profileName)
+	if err != nil {
+		return Deployment{}, err
+	}
+	packageCommand, pkg, err := d.helmPackage()
+	if err != nil {
+		return Deployment{}, err
+	}
+	environment := profileName
+	if environment == "" {
+		environment = DefaultDeployEnvironment
+	}
+	deployment := Deployment{
+		ID:          newRunID("", environment, now),
+		Environment: environment,
+		Release:     helm.Release,
+		Namespace:   helm.Namespace,
+		Version:     d.Version,
+		Package:     pkg,
+		Values:      append(append([]string{}, helm.Values...), profile.Values...),
+		Set:         map[string]string{},
+		User:        os.Getenv("USER"),
+		DeployedAt:  now.UTC(),
+	}
+	if deployment.Release == "" {
+		deployment.Release = d.ID
+	}
+	if profile.Namespace != "" {
+		deployment.Namespace = profile.Namespace
+	}
+	for key, value := range helm.Set {
+		deployment.Set[key] = value
+	}
+	for key, value := range profile.Set {
+		deployment.Set[key] = value
+	}
+
+	if err := os.MkdirAll(ChartsDir, 0755); err != nil {
+		return deployment, err
+	}
+	history, err := LoadDeployments()
+	if err != nil {
+		return deployment, err
+	}
+	live := ""
+	if previous := liveDeployments(history, environment); len(previous) > 0 {
+		live = previous[len(previous)-1].Color
+	}
+	phases := d.Deploy.Strategy.phases(live)
+	deployment.Strategy = d.Deploy.Strategy.kind()
+	if deployment.Strategy == StrategyBlueGreen {
+		deployment.Color = phases[0].Env["DEVOPS_DEPLOY_COLOR"]
+	}
+
+	if err := os.MkdirAll(ChartsDir, 0755); err != nil {
+		return deployment, err
+	}
+	err = d.runCommands(ctx, "deploy", shellExecutor, nil, packageCommand)
+	if err == nil {
+		err = d.runPhases(ctx, &deployment, phases, shellExecutor)
+	}
+	return deployment, recordDeployment(&deployment, err)
+}
+
+// chartPackages returns the chart packages built into ChartsDir.
+func chartPackages() []string {
+	matches, _ := filepath.Glob(filepath.Join(ChartsDir, "*.tgz"))
+	packages := make([]string, 0, len(matches))
+	for _, match := range matches {
+		packages = append(packages, filepath.ToSlash(match))
+	}
+	return packages
+}
+
+// checkDeploy reports an unusable deploy section.
+func (d *ProjectDefinition) checkDeploy(b *reportBuilder) {
+	if !d.Deploy.Defined() {
+		return
+	}
+	err := d.deployError(b.files)
+	if err != nil {
+		b.fail(RuleDeployConfig, "Fix the deploy section or remove it", "deploy: %s", err.Error())
+		return
+	}
+	b.pass(RuleDeployConfig, "Helm chart: %s", d.Deploy.Helm.Chart)
+}
+
+func (d *ProjectDefinition) deployError(files fs.FS) error {
+	if d.Deploy.Helm.Chart == "" {
+		return errors.New("deploy.helm.chart is not set")
+	}
+	if err := d.Deploy.Strategy.validate(); err != nil {
+		return err
+	}
+	if files == nil {
+		return nil
+	}
+	if _, err := chartName(files, d.Deploy.Helm.Chart); err != nil {
+		return err
+	}
+	values := append([]string{}, d.Deploy.Helm.Values...)
+	names := make([]string, 0, len(d.Deploy.Profiles))
+	for name := range d.Deploy.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values = append(values, d.Deploy.Profiles[name].Values...)
+	}
+	for _, file := range values {
+		if _, err := fs.Stat(files, path.Clean(file)); err != nil {
+			return fmt.Errorf("values file %s not found", file)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeDeployFiles creates the given files with placeholder content.
+func writeDeployFiles(t *testing.T, paths ...string) {
+	for _, path := range paths {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(path), 0644))
+	}
+}
+
+// writeChart creates a chart named app in deploy/chart.
+func writeChart(t *testing.T) {
+	require.NoError(t, os.MkdirAll(filepath.Join("deploy", "chart"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join("deploy", "chart", "Chart.yaml"), []byte("apiVersion: v2\nname: app\nversion: 0.1.0\n"), 0644))
+}
+
+func newHelmProject() ProjectDefinition {
+	return ProjectDefinition{
+		ID:      "app",
+		Version: "1.2.0",
+		Deploy: Deploy{
+			Helm: HelmConfig{
+				Chart:     "deploy/chart",
+				Namespace: "apps",
+				Values:    []string{"deploy/values.yaml"},
+				Set:       map[string]string{"replicaCount": "1", "image.tag": "1.2.0"},
+			},
+			Profiles: map[string]DeployProfile{
+				"prod": {Namespace: "prod", Values: []string{"deploy/values-prod.yaml"}, Set: map[string]string{"replicaCount": "3"}},
+			},
+		},
+	}
+}
+
+func TestProjectDefinition_DeployHelm(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeChart(t)
+	writeDeployFiles(t, ".devops/artifacts/charts/app-1.2.0.tgz", "deploy/values.yaml", "deploy/values-prod.yaml")
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	t.Setenv("USER", "ci")
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	project := newHelmProject()
+	m := &MockShellExecutor{}
+	calls := []string{}
+	pkg := "helm package 'deploy/chart' --destination '.devops/artifacts/charts' --version '1.2.0' --app-version '1.2.0'"
+	upgrade := "helm upgrade --install 'app' '.devops/artifacts/charts/app-1.2.0.tgz' --namespace 'prod'" +
+		" --values 'deploy/values.yaml' --values 'deploy/values-prod.yaml' --set 'image.tag=1.2.0' --set 'replicaCount=3'"
+	recordCalls(m, &calls, pkg, upgrade)
+
+	deployment, err := project.DeployHelm(ctx, "prod", m, now)
+	require.NoError(t, err)
+	assert.Equal(t, Deployment{
+		ID:          "20260301T120000Z-prod",
+		Environment: "prod",
+		Release:     "app",
+		Namespace:   "prod",
+		Version:     "1.2.0",
+		Package:     ".devops/artifacts/charts/app-1.2.0.tgz",
+		Values:      []string{"deploy/values.yaml", "deploy/values-prod.yaml"},
+		Set:         map[string]string{"image.tag": "1.2.0", "replicaCount": "3"},
+		Status:      DeploySucceeded,
+		User:        "ci",
+		DeployedAt:  now,
+		Dir:         ".devops/deployments/20260301T120000Z-prod",
+		Strategy:    StrategyRolling,
+	}, deployment)
+	assert.Equal(t, []string{pkg, upgrade}, calls)
+	assert.FileExists(t, ".devops/deployments/20260301T120000Z-prod/deploy/values-prod.yaml")
+
+	history, err := LoadDeployments()
+	require.NoError(t, err)
+	assert.Equal(t, []Deployment{deployment}, history)
+
+	_, err = project.DeployHelm(ctx, "prd", m, now)
+	assert.EqualError(t, err, "unknown deploy profile 'prd' (did you mean 'prod'?)")
+
+	_, err = project.DeployHelm(WithReadOnly(ctx), "", m, now)
+	assert.EqualError(t, err, "deploying is not allowed in read-only mode")
+
+	_, err = (&ProjectDefinition{ID: "app"}).DeployHelm(ctx, "", m, now)
+	assert.EqualError(t, err, "deploy.helm.chart is not set")
+}
+
+func TestProjectDefinition_PackageChart(t *testing.T) {
+	t.Chdir(t.TempDir())
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	project := newHelmProject()
+	project.Version = ""
+	m := &MockShellExecutor{}
+
+	_, err := project.PackageChart(ctx, m)
+	assert.ErrorContains(t, err, "chart deploy/chart not found")
+
+	writeChart(t)
+	calls := []string{}
+	recordCalls(m, &calls, "helm package 'deploy/chart' --destination '.devops/artifacts/charts'")
+	pkg, err := project.PackageChart(ctx, m)
+	require.NoError(t, err)
+	assert.Equal(t, ".devops/artifacts/charts/app.tgz", pkg)
+	assert.Len(t, calls, 1)
+
+	require.NoError(t, os.WriteFile(pkg, []byte("chart"), 0644))
+	assert.Equal(t, []string{pkg}, chartPackages())
+}
+
+func TestProjectDefinition_Report_Deploy(t *testing.T) {
+	project := newHelmProject()
+	files := fstest.MapFS{
+		"deploy/chart/Chart.yaml": {Data: []byte("name: app\n")},
+		"deploy/values.yaml":      {Data: []byte("{}\n")},
+		"deploy/values-prod.yaml": {Data: []byte("{}\n")},
+	}
+
+	finding, ok := findingFor(project.ReportFiles(files), RuleDeployConfig)
+	require.True(t, ok)
+	assert.True(t, finding.Passed)
+
+	delete(files, "deploy/values-prod.yaml")
+	finding, ok = findingFor(project.ReportFiles(files), RuleDeployConfig)
+	require.True(t, ok)
+	assert.False(t, finding.Passed)
+	assert.Equal(t, "deploy: values file deploy/values-prod.yaml not found", finding.Message)
+
+	project.Deploy.Helm.Chart = ""
+	finding, _ = findingFor(project.ReportFiles(files), RuleDeployConfig)
+	assert.Equal(t, "deploy: deploy.helm.chart is not set", finding.Message)
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DuplicateStep is a step string written out more than once across the
+// codebase operations.
+type DuplicateStep struct {
+	Step      string
+	Locations []string
+}
+
+// stepLocation identifies a step as "<operation>[<1-based index>]".
+func stepLocation(operation string, idx int) string {
+	return fmt.Sprintf("%s[%d]", operation, idx+1)
+}
+
+// duplicateSteps finds identical step strings repeated across operations.
+// Steps that came from YAML aliases are already deduplicated and ignored.
+func (d *ProjectDefinition) duplicateSteps() []DuplicateStep {
+	order := []string{}
+	locations := map[string][]string{}
+	for _, name := range d.Codebase.OperationNames() {
+		op, _ := d.Codebase.Lookup(name)
+		for idx, step := range op.Steps {
+			location := stepLocation(name, idx)
+			if d.aliasedSteps[location] {
+				continue
+			}
+			key := strings.TrimSpace(step.Run)
+			if _, seen := locations[key]; !seen {
+				order = append(order, key)
+			}
+			locations[key] = append(locations[key], location)
+		}
+	}
+
+	duplicates := []DuplicateStep{}
+	for _, step := range order {
+		if len(locations[step]) > 1 {
+			duplicates = append(duplicates, DuplicateStep{Step: step, Locations: locations[step]})
+		}
+	}
+	return duplicates
+}
+
+// findAliasedSteps records the locations of codebase steps that were
+// written as YAML aliases or inherited through merge keys.
+func findAliasedSteps(root *yaml.Node) map[string]bool {
+	aliased := map[string]bool{}
+	codebase := mappingValue(documentMapping(root), "codebase")
+	for _, name := range builtinOperations {
+		if opNode := mappingValue(codebase, name); opNode != nil {
+			markAliasedOperation(aliased, name, opNode)
+		}
+	}
+	if custom := mappingValue(codebase, "operations"); custom != nil && custom.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(custom.Content); i += 2 {
+			markAliasedOperation(aliased, custom.Content[i].Value, custom.Content[i+1])
+		}
+	}
+	return aliased
+}
+
+// markAliasedOperation records the aliased steps of a single operation node.
+func markAliasedOperation(aliased map[string]bool, name string, opNode *yaml.Node) {
+	if opNode.Kind == yaml.AliasNode {
+		markAllAliased(aliased, name, opNode.Alias)
+		return
+	}
+	if opNode.Kind != yaml.MappingNode {
+		return
+	}
+	steps := mappingValue(opNode, "steps")
+	switch {
+	case steps == nil:
+		if merged := mappingValue(opNode, "<<"); merged != nil {
+			markAllAliased(aliased, name, merged)
+		}
+	case steps.Kind == yaml.AliasNode:
+		markAllAliased(aliased, name, steps)
+	case steps.Kind == yaml.SequenceNode:
+		for idx, step := range steps.Content {
+			if step.Kind == yaml.AliasNode {
+				aliased[stepLocation(name, idx)] = true
+			}
+		}
+	}
+}
+
+// markAllAliased marks every step reachable from an aliased operation or
+// steps node as aliased.
+func markAllAliased(aliased map[string]bool, operation string, node *yaml.Node) {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.MappingNode {
+		node = mappingValue(node, "steps")
+		if node == nil {
+			return
+		}
+		for node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+	}
+	if node.Kind != yaml.SequenceNode {
+		return
+	}
+	for idx := range node.Content {
+		aliased[stepLocation(operation, idx)] = true
+	}
+}
+
+// documentMapping returns the root mapping node of a parsed document.
+func documentMapping(root *yaml.Node) *yaml.Node {
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		return root.Content[0]
+	}
+	return root
+}
+
+// mappingValue returns the value node for key in a mapping node.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
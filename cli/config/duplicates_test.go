@@ -0,0 +1,109 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectDefinition_DuplicateSteps(t *testing.T) {
+	project := ProjectDefinition{
+		Codebase: Codebase{
+			Install: Operation{Steps: StepsFromCommands("go mod download", "go mod tidy")},
+			Test:    Operation{Steps: StepsFromCommands("go mod tidy", "go test ./...")},
+			Build:   Operation{Steps: StepsFromCommands("go mod tidy ", "go build ./...", "go test ./...")},
+		},
+	}
+
+	duplicates := project.duplicateSteps()
+	require.Len(t, duplicates, 2)
+	assert.Equal(t, DuplicateStep{
+		Step:      "go mod tidy",
+		Locations: []string{"install[2]", "test[1]", "build[1]"},
+	}, duplicates[0])
+	assert.Equal(t, DuplicateStep{
+		Step:      "go test ./...",
+		Locations: []string{"test[2]", "build[3]"},
+	}, duplicates[1])
+}
+
+func TestProjectDefinition_Report_DuplicateSteps(t *testing.T) {
+	project := ProjectDefinition{
+		Codebase: Codebase{
+			Test:  Operation{Steps: StepsFromCommands("make deps", "make test")},
+			Build: Operation{Steps: StepsFromCommands("make deps", "make build")},
+		},
+	}
+
+	report := project.Report()
+	finding, ok := findingFor(report, RuleDuplicateSteps)
+	require.True(t, ok)
+	assert.Equal(t, SeverityWarning, finding.Severity)
+	assert.Equal(t, "Step 'make deps' repeated 2 times: test[1], build[1]", finding.Message)
+	assert.Contains(t, finding.Remedy, "x- key anchor")
+}
+
+func TestLoad_AliasedStepsAreNotDuplicates(t *testing.T) {
+	tests := []struct {
+		name               string
+		yamlContent        string
+		expectedDuplicates int
+	}{
+		{
+			name: "aliased step scalars",
+			yamlContent: `id: test-project
+x-deps: &deps make deps
+codebase:
+  test:
+    steps: [*deps, make test]
+  build:
+    steps: [*deps, make build]
+`,
+			expectedDuplicates: 0,
+		},
+		{
+			name: "aliased operation",
+			yamlContent: `id: test-project
+codebase:
+  install: &install
+    steps: [make deps]
+  test: *install
+`,
+			expectedDuplicates: 0,
+		},
+		{
+			name: "merged operation without own steps",
+			yamlContent: `id: test-project
+codebase:
+  install: &install
+    steps: [make deps]
+  test:
+    <<: *install
+    fail_fast: true
+`,
+			expectedDuplicates: 0,
+		},
+		{
+			name: "literal repetition is still reported",
+			yamlContent: `id: test-project
+x-deps: &deps make deps
+codebase:
+  test:
+    steps: [*deps, make test]
+  build:
+    steps: [make deps, make test]
+`,
+			expectedDuplicates: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(strings.NewReader(tt.yamlContent))
+			require.NoError(t, err)
+			assert.Len(t, cfg.duplicateSteps(), tt.expectedDuplicates)
+		})
+	}
+}
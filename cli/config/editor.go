@@ -0,0 +1,126 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultSchemaPath is where the editor schema is written by default.
+	DefaultSchemaPath = ".schemas/devops-definition.schema.json"
+
+	vscodeSettingsFile = ".vscode/settings.json"
+	modelinePrefix     = "# yaml-language-server: $schema="
+)
+
+// EditorConfig describes the files written to integrate editors with the
+// definition schema.
+type EditorConfig struct {
+	Schema         []byte
+	SchemaPath     string
+	DefinitionPath string
+}
+
+// Write writes the JSON schema, adds a yaml-language-server modeline to
+// the definition file and associates the schema in the VS Code settings.
+// It returns the paths it changed.
+func (e EditorConfig) Write() ([]string, error) {
+	changed := []string{}
+	if err := e.writeSchema(); err != nil {
+		return changed, err
+	}
+	changed = append(changed, e.SchemaPath)
+
+	added, err := e.addModeline()
+	if err != nil {
+		return changed, err
+	}
+	if added {
+		changed = append(changed, e.DefinitionPath)
+	}
+
+	if err := e.associateVSCode(); err != nil {
+		return changed, err
+	}
+	return append(changed, vscodeSettingsFile), nil
+}
+
+// writeSchema converts the YAML schema to JSON, which every editor accepts.
+func (e EditorConfig) writeSchema() error {
+	var schema any
+	if err := yaml.Unmarshal(e.Schema, &schema); err != nil {
+		return fmt.Errorf("failed to parse schema: %w", err)
+	}
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to render schema: %w", err)
+	}
+	return writeFileWithDirs(e.SchemaPath, append(data, '\n'))
+}
+
+// addModeline prepends the schema modeline to the definition file unless
+// it already has one.
+func (e EditorConfig) addModeline() (bool, error) {
+	content, err := os.ReadFile(e.DefinitionPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", e.DefinitionPath, err)
+	}
+	if bytes.Contains(content, []byte(modelinePrefix)) {
+		return false, nil
+	}
+	rel, err := filepath.Rel(filepath.Dir(e.DefinitionPath), e.SchemaPath)
+	if err != nil {
+		return false, err
+	}
+	modeline := modelinePrefix + filepath.ToSlash(rel) + "\n"
+	if err := os.WriteFile(e.DefinitionPath, append([]byte(modeline), content...), 0644); err != nil {
+		return false, fmt.Errorf("failed to update %s: %w", e.DefinitionPath, err)
+	}
+	return true, nil
+}
+
+// associateVSCode maps the schema to the definition file in the workspace
+// settings, keeping any other settings in place.
+func (e EditorConfig) associateVSCode() error {
+	settings := map[string]any{}
+	content, err := os.ReadFile(vscodeSettingsFile)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("failed to read %s: %w", vscodeSettingsFile, err)
+	default:
+		if err := json.Unmarshal(content, &settings); err != nil {
+			return fmt.Errorf("failed to parse %s (comments are not supported, add the yaml.schemas entry manually): %w", vscodeSettingsFile, err)
+		}
+	}
+
+	schemas, _ := settings["yaml.schemas"].(map[string]any)
+	if schemas == nil {
+		schemas = map[string]any{}
+	}
+	schemas["./"+strings.TrimPrefix(filepath.ToSlash(e.SchemaPath), "./")] = filepath.Base(e.DefinitionPath)
+	settings["yaml.schemas"] = schemas
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileWithDirs(vscodeSettingsFile, append(data, '\n'))
+}
+
+func writeFileWithDirs(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
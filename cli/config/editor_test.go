@@ -0,0 +1,74 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `$schema: "http://json-schema.org/draft-07/schema#"
+type: object
+properties:
+  id:
+    type: string
+`
+
+func TestEditorConfig_Write(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile(DefinitionFile, []byte("id: editor\n"), 0644))
+	require.NoError(t, os.MkdirAll(".vscode", 0755))
+	require.NoError(t, os.WriteFile(vscodeSettingsFile, []byte(`{"editor.tabSize": 2}`), 0644))
+
+	editor := EditorConfig{Schema: []byte(testSchema), SchemaPath: DefaultSchemaPath, DefinitionPath: DefinitionFile}
+	changed, err := editor.Write()
+	require.NoError(t, err)
+	assert.Equal(t, []string{DefaultSchemaPath, DefinitionFile, vscodeSettingsFile}, changed)
+
+	var schema map[string]any
+	data, err := os.ReadFile(DefaultSchemaPath)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &schema))
+	assert.Equal(t, "object", schema["type"])
+
+	definition, err := os.ReadFile(DefinitionFile)
+	require.NoError(t, err)
+	assert.Equal(t, "# yaml-language-server: $schema=.schemas/devops-definition.schema.json\nid: editor\n", string(definition))
+
+	var settings map[string]any
+	data, err = os.ReadFile(vscodeSettingsFile)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &settings))
+	assert.Equal(t, float64(2), settings["editor.tabSize"])
+	assert.Equal(t, map[string]any{"./.schemas/devops-definition.schema.json": DefinitionFile}, settings["yaml.schemas"])
+
+	// Running again keeps a single modeline.
+	changed, err = editor.Write()
+	require.NoError(t, err)
+	assert.NotContains(t, changed, DefinitionFile)
+	definition, err = os.ReadFile(DefinitionFile)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(definition), modelinePrefix))
+}
+
+func TestEditorConfig_Write_Errors(t *testing.T) {
+	t.Run("missing definition", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		editor := EditorConfig{Schema: []byte(testSchema), SchemaPath: DefaultSchemaPath, DefinitionPath: DefinitionFile}
+		_, err := editor.Write()
+		assert.ErrorContains(t, err, "failed to read devops-definition.yaml")
+	})
+
+	t.Run("settings with comments", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		require.NoError(t, os.WriteFile(DefinitionFile, []byte("id: editor\n"), 0644))
+		require.NoError(t, os.MkdirAll(".vscode", 0755))
+		require.NoError(t, os.WriteFile(vscodeSettingsFile, []byte("// comment\n{}"), 0644))
+		editor := EditorConfig{Schema: []byte(testSchema), SchemaPath: DefaultSchemaPath, DefinitionPath: DefinitionFile}
+		_, err := editor.Write()
+		assert.ErrorContains(t, err, "add the yaml.schemas entry manually")
+	})
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/jgfranco17/devops/cli/executor"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// encryptedTag marks a scalar holding an age-encrypted, ASCII-armored value.
+	encryptedTag = "!encrypted"
+
+	// AgeIdentityEnv names the variable pointing at the age identity file
+	// used to decrypt encrypted values.
+	AgeIdentityEnv = "DEVOPS_AGE_IDENTITY"
+)
+
+// ageDecrypt decrypts an armored age ciphertext with the given identity
+// file. A single trailing newline, as left by `echo secret | age`, is dropped.
+var ageDecrypt = func(identity string, ciphertext string) (string, error) {
+	ctx := context.Background()
+	cmd := exec.CommandContext(ctx, "age", "--decrypt", "--identity", identity)
+	cmd.Stdin = strings.NewReader(ciphertext)
+	var stdout, stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := executor.RunCommand(ctx, cmd); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimSuffix(stdout.String(), "\n"), nil
+}
+
+// decryptValues replaces every !encrypted scalar in the document with its
+// plaintext and reports whether there were any, along with the plaintexts
+// to mask. Without an identity the values are blanked instead and their
+// paths returned, so the rest of the definition remains usable.
+func decryptValues(root *yaml.Node) (found bool, plaintexts []string, undecrypted []string, err error) {
+	identity := os.Getenv(AgeIdentityEnv)
+	var walk func(node *yaml.Node, path string) error
+	walk = func(node *yaml.Node, path string) error {
+		switch node.Kind {
+		case yaml.DocumentNode:
+			for _, child := range node.Content {
+				if err := walk(child, path); err != nil {
+					return err
+				}
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if err := walk(node.Content[i+1], joinPath(path, node.Content[i].Value)); err != nil {
+					return err
+				}
+			}
+		case yaml.SequenceNode:
+			for idx, child := range node.Content {
+				if err := walk(child, itemPath(path, child, idx)); err != nil {
+					return err
+				}
+			}
+		case yaml.ScalarNode:
+			if node.Tag != encryptedTag {
+				return nil
+			}
+			found = true
+			node.Tag = "!!str"
+			if identity == "" {
+				node.Value = ""
+				undecrypted = append(undecrypted, path)
+				return nil
+			}
+			plaintext, err := ageDecrypt(identity, node.Value)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt %s at line %d: %w", path, node.Line, err)
+			}
+			node.Value = plaintext
+			plaintexts = append(plaintexts, plaintext)
+		}
+		return nil
+	}
+	err = walk(root, "")
+	return found, plaintexts, undecrypted, err
+}
+
+// checkEncryptedValues reports values that could not be decrypted.
+func (d *ProjectDefinition) checkEncryptedValues(b *reportBuilder) {
+	if len(d.undecrypted) == 0 {
+		return
+	}
+	b.fail(RuleEncryptedValues, fmt.Sprintf("Set %s to the path of your age identity file", AgeIdentityEnv),
+		"%d encrypted value(s) not decrypted: %s", len(d.undecrypted), strings.Join(d.undecrypted, ", "))
+}
+
+// checkDecrypted fails if the named operation uses a value that could not
+// be decrypted.
+func (d *ProjectDefinition) checkDecrypted(name string) error {
+	missing := []string{}
+	for _, path := range d.undecrypted {
+		if withinKey(path, d.operationPath(name)) {
+			missing = append(missing, path)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("operation %s uses encrypted values that could not be decrypted (%s); set %s",
+			name, strings.Join(missing, ", "), AgeIdentityEnv)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const encryptedDefinition = `id: secrets
+x-shared: &token !encrypted |
+  -----BEGIN AGE ENCRYPTED FILE-----
+  c2hhcmVk
+  -----END AGE ENCRYPTED FILE-----
+codebase:
+  build:
+    env:
+      PLAIN: visible
+      TOKEN: !encrypted |
+        -----BEGIN AGE ENCRYPTED FILE-----
+        dG9rZW4=
+        -----END AGE ENCRYPTED FILE-----
+      SHARED: *token
+    steps:
+      - make
+  test:
+    steps:
+      - make test
+`
+
+func TestLoad_EncryptedValues(t *testing.T) {
+	original := ageDecrypt
+	t.Cleanup(func() { ageDecrypt = original })
+
+	t.Run("decrypts with identity", func(t *testing.T) {
+		t.Setenv(AgeIdentityEnv, "/keys/age.txt")
+		ageDecrypt = func(identity string, ciphertext string) (string, error) {
+			assert.Equal(t, "/keys/age.txt", identity)
+			assert.True(t, strings.HasPrefix(ciphertext, "-----BEGIN AGE ENCRYPTED FILE-----"))
+			if strings.Contains(ciphertext, "c2hhcmVk") {
+				return "shared-secret", nil
+			}
+			return "token-secret", nil
+		}
+
+		cfg, err := Load(strings.NewReader(encryptedDefinition))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"PLAIN":  "visible",
+			"TOKEN":  "token-secret",
+			"SHARED": "shared-secret",
+		}, cfg.Codebase.Build.Env)
+		assert.Empty(t, cfg.undecrypted)
+
+		ctx, _, err := cfg.withSecrets(context.Background(), cfg.Codebase.Build)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"shared-secret", "token-secret"}, executor.SecretsFromContext(ctx))
+	})
+
+	t.Run("decryption failure is reported", func(t *testing.T) {
+		t.Setenv(AgeIdentityEnv, "/keys/age.txt")
+		ageDecrypt = func(identity string, ciphertext string) (string, error) {
+			return "", errors.New("no identity matched any of the recipients")
+		}
+
+		_, err := Load(strings.NewReader(encryptedDefinition))
+		assert.ErrorContains(t, err, "failed to decrypt x-shared at line 2")
+	})
+
+	t.Run("without identity values are blanked", func(t *testing.T) {
+		t.Setenv(AgeIdentityEnv, "")
+		ageDecrypt = func(identity string, ciphertext string) (string, error) {
+			t.Fatal("decrypt must not be called without an identity")
+			return "", nil
+		}
+
+		cfg, err := Load(strings.NewReader(encryptedDefinition))
+		require.NoError(t, err)
+		assert.Equal(t, "", cfg.Codebase.Build.Env["TOKEN"])
+		assert.Equal(t, []string{"x-shared", "codebase.build.env.TOKEN"}, cfg.undecrypted)
+
+		finding, ok := findingFor(cfg.Report(), RuleEncryptedValues)
+		require.True(t, ok)
+		assert.Equal(t, SeverityWarning, finding.Severity)
+		assert.Contains(t, finding.Remedy, AgeIdentityEnv)
+
+		ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+		err = cfg.Build(ctx, &MockShellExecutor{})
+		assert.ErrorContains(t, err, "operation build uses encrypted values that could not be decrypted (codebase.build.env.TOKEN)")
+	})
+
+	t.Run("without identity values of named codebases are checked", func(t *testing.T) {
+		t.Setenv(AgeIdentityEnv, "")
+		t.Chdir(t.TempDir())
+		require.NoError(t, os.MkdirAll("api", 0o755))
+		require.NoError(t, os.MkdirAll("web", 0o755))
+
+		cfg, err := Load(strings.NewReader(`id: secrets
+codebases:
+  - name: api
+    path: api
+    operations:
+      deploy:
+        env:
+          TOKEN: !encrypted |
+            -----BEGIN AGE ENCRYPTED FILE-----
+            dG9rZW4=
+            -----END AGE ENCRYPTED FILE-----
+        steps: [make deploy]
+  - name: web
+    path: web
+    operations:
+      deploy:
+        steps: [npm run deploy]
+`))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"codebases.api.operations.deploy.env.TOKEN"}, cfg.undecrypted)
+
+		ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, "npm run deploy").Return(executor.Result{}, nil)
+		err = cfg.Run(WithCodebase(ctx, "api"), "deploy", m)
+		assert.ErrorContains(t, err, "operation deploy uses encrypted values that could not be decrypted (codebases.api.operations.deploy.env.TOKEN)")
+		require.NoError(t, cfg.Run(WithCodebase(ctx, "web"), "deploy", m))
+		m.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/internal/environment"
+)
+
+const envOverridesKey contextKey = "env-overrides"
+
+// envFiles lists the env files loaded for an operation, project files
+// first so the operation's own files override them.
+func (d *ProjectDefinition) envFiles(op Operation) []string {
+	return append(append([]string{}, d.EnvFiles...), op.EnvFiles...)
+}
+
+// envFilePath returns the key path of the env_files list that names
+// path, the project's or that of the operation.
+func (d *ProjectDefinition) envFilePath(name string, path string) string {
+	if slices.Contains(d.EnvFiles, path) {
+		return "env_files"
+	}
+	return d.operationPath(name) + ".env_files"
+}
+
+// withEnvFiles merges the project and operation env files into the
+// operation env. Later files override earlier ones, the operation's env
+// overrides them all, and --env overrides on the context win over
+// everything. Missing files are skipped, as doctor reports them.
+func (d *ProjectDefinition) withEnvFiles(ctx context.Context, op Operation) (Operation, error) {
+	overrides := EnvOverridesFromContext(ctx)
+	files := d.envFiles(op)
+	if len(files) == 0 && len(overrides) == 0 {
+		return op, nil
+	}
+	logger := logging.FromContext(ctx)
+	env := map[string]string{}
+	for _, path := range files {
+		f, err := os.Open(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			logger.Debugf("Env file %s not found, skipping it", path)
+			continue
+		}
+		if err != nil {
+			return op, err
+		}
+		values, err := environment.ParseDotenv(f)
+		_ = f.Close()
+		if err != nil {
+			return op, fmt.Errorf("invalid env file %s: %w", path, err)
+		}
+		maps.Copy(env, values)
+	}
+	maps.Copy(env, op.Env)
+	maps.Copy(env, overrides)
+	op.Env = env
+	return op, nil
+}
+
+// WithEnvOverrides adds variables that override the env of every
+// operation run with the context.
+func WithEnvOverrides(ctx context.Context, overrides map[string]string) context.Context {
+	return context.WithValue(ctx, envOverridesKey, overrides)
+}
+
+// EnvOverridesFromContext returns the env overrides on the context, or nil.
+func EnvOverridesFromContext(ctx context.Context) map[string]string {
+	overrides, _ := ctx.Value(envOverridesKey).(map[string]string)
+	return overrides
+}
+
+// ParseEnvOverrides parses KEY=VALUE pairs given on the command line.
+func ParseEnvOverrides(pairs []string) (map[string]string, error) {
+	overrides := map[string]string{}
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid env override '%s', expected KEY=VALUE", pair)
+		}
+		overrides[key] = value
+	}
+	return overrides, nil
+}
+
+// checkEnvFiles warns about env files that do not exist. Paths are
+// relative to the codebase the operations run in.
+func (d *ProjectDefinition) checkEnvFiles(b *reportBuilder) {
+	seen := map[string]bool{}
+	for _, name := range d.Codebase.OperationNames() {
+		op, _ := d.Codebase.Lookup(name)
+		for _, path := range d.envFiles(op) {
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			if _, err := os.Stat(filepath.Join(d.Codebase.Path, path)); err != nil {
+				b.failAt(d.envFilePath(name, path), RuleEnvFiles, "Create the file or remove it from env_files", "Env file %s not found", path)
+			} else {
+				b.pass(RuleEnvFiles, "Env file: %s", path)
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectDefinition_WithEnvFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	require.NoError(t, os.WriteFile(".env", []byte("A=project\nB=project\nC=project\n"), 0644))
+	require.NoError(t, os.WriteFile(".env.ci", []byte("B=operation\nC=operation\n"), 0644))
+	project := ProjectDefinition{EnvFiles: []string{".env", ".env.missing"}}
+	op := Operation{EnvFiles: []string{".env.ci"}, Env: map[string]string{"C": "env"}}
+
+	merged, err := project.withEnvFiles(ctx, op)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"A": "project", "B": "operation", "C": "env"}, merged.Env)
+	assert.Equal(t, map[string]string{"C": "env"}, op.Env)
+}
+
+func TestProjectDefinition_WithEnvFiles_Overrides(t *testing.T) {
+	t.Chdir(t.TempDir())
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	require.NoError(t, os.WriteFile(".env", []byte("A=file\nB=file\n"), 0644))
+	project := ProjectDefinition{EnvFiles: []string{".env"}}
+	op := Operation{Env: map[string]string{"B": "env", "C": "env"}}
+	ctx = WithEnvOverrides(ctx, map[string]string{"C": "cli", "D": "cli"})
+
+	merged, err := project.withEnvFiles(ctx, op)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"A": "file", "B": "env", "C": "cli", "D": "cli"}, merged.Env)
+}
+
+func TestParseEnvOverrides(t *testing.T) {
+	overrides, err := ParseEnvOverrides([]string{"A=1", "B=x=y", "EMPTY="})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"A": "1", "B": "x=y", "EMPTY": ""}, overrides)
+
+	_, err = ParseEnvOverrides([]string{"=1"})
+	assert.ErrorContains(t, err, "invalid env override '=1', expected KEY=VALUE")
+}
+
+func TestProjectDefinition_WithEnvFiles_Invalid(t *testing.T) {
+	t.Chdir(t.TempDir())
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	require.NoError(t, os.WriteFile(".env", []byte("NOT VALID\n"), 0644))
+	project := ProjectDefinition{EnvFiles: []string{".env"}}
+
+	_, err := project.withEnvFiles(ctx, Operation{})
+
+	assert.ErrorContains(t, err, "invalid env file .env: line 1: expected KEY=VALUE")
+}
+
+func TestProjectDefinition_Run_EnvFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	require.NoError(t, os.WriteFile(".env", []byte("API_URL=http://localhost\n"), 0644))
+	project := ProjectDefinition{
+		EnvFiles: []string{".env"},
+		Codebase: Codebase{Operations: map[string]Operation{
+			"serve": {When: `env.API_URL == "http://localhost"`, Steps: StepsFromCommands("serve")},
+		}},
+	}
+	m := &MockShellExecutor{}
+	m.On("Exec", withEnv(func(env []string) bool {
+		return slices.Contains(env, "API_URL=http://localhost")
+	}), "serve").Return(executor.Result{}, nil)
+
+	require.NoError(t, project.Run(ctx, "serve", m))
+	m.AssertExpectations(t)
+}
+
+func TestProjectDefinition_Report_EnvFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile(".env", []byte("A=1\n"), 0644))
+	project := ProjectDefinition{
+		EnvFiles: []string{".env"},
+		Codebase: Codebase{Test: Operation{EnvFiles: []string{".env.test"}, Steps: StepsFromCommands("go test ./...")}},
+	}
+
+	report := project.Report()
+
+	found := []Finding{}
+	for _, finding := range report.Findings {
+		if finding.RuleID == RuleEnvFiles {
+			found = append(found, finding)
+		}
+	}
+	require.Len(t, found, 2)
+	assert.True(t, found[0].Passed)
+	assert.Equal(t, "Env file: .env", found[0].Message)
+	assert.False(t, found[1].Passed)
+	assert.Equal(t, SeverityWarning, found[1].Severity)
+	assert.Equal(t, "Env file .env.test not found", found[1].Message)
+}
+
+func TestLoad_EnvFiles(t *testing.T) {
+	yamlContent := `id: shared
+env_files: [.env]
+codebase:
+  test:
+    env_files: [.env.test]
+    steps:
+      - go test ./...
+`
+	cfg, err := Load(strings.NewReader(yamlContent))
+	require.NoError(t, err)
+	assert.Equal(t, []string{".env"}, cfg.EnvFiles)
+	assert.Equal(t, []string{".env.test"}, cfg.Codebase.Test.EnvFiles)
+}
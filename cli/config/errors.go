@@ -0,0 +1,182 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxSuggestionDistance is the largest edit distance at which a known
+// field name is still offered as a suggestion for an unknown key.
+const maxSuggestionDistance = 3
+
+// extensionPrefix marks top-level keys that are ignored by the loader,
+// typically used to hold YAML anchors.
+const extensionPrefix = "x-"
+
+var unknownFieldPattern = regexp.MustCompile(`^line (\d+): field (\S+) not found in type (\S+)$`)
+
+// UnknownFieldError reports a key in the definition file that does not
+// map to any known field, along with the closest valid name if any.
+type UnknownFieldError struct {
+	Field      string
+	Line       int
+	Suggestion string
+}
+
+func (e *UnknownFieldError) Error() string {
+	msg := fmt.Sprintf("unknown field '%s'", e.Field)
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf(" (did you mean '%s'?)", e.Suggestion)
+	}
+	if e.Line > 0 {
+		msg += fmt.Sprintf(" at line %d", e.Line)
+	}
+	return msg
+}
+
+// explainDecodeError rewrites strict-decoding errors from the YAML decoder
+// into UnknownFieldErrors carrying "did you mean" suggestions. Errors it
+// does not recognise are returned unchanged.
+func explainDecodeError(err error, root reflect.Type) error {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return err
+	}
+	fieldsByType := collectYAMLFields(root, map[string][]string{})
+	explained := make([]error, 0, len(typeErr.Errors))
+	for _, msg := range typeErr.Errors {
+		match := unknownFieldPattern.FindStringSubmatch(msg)
+		if match == nil {
+			explained = append(explained, errors.New(msg))
+			continue
+		}
+		line, _ := strconv.Atoi(match[1])
+		explained = append(explained, &UnknownFieldError{
+			Field:      match[2],
+			Line:       line,
+			Suggestion: closestMatch(match[2], fieldsByType[match[3]]),
+		})
+	}
+	return errors.Join(explained...)
+}
+
+// checkExtensionKeys rejects top-level keys captured by the inline
+// extensions map that do not use the "x-" prefix.
+func checkExtensionKeys(root *yaml.Node, extensions map[string]yaml.Node, rootType reflect.Type) error {
+	if len(extensions) == 0 {
+		return nil
+	}
+	known := collectYAMLFields(rootType, map[string][]string{})[rootType.String()]
+	unknown := []error{}
+	for _, key := range topLevelKeys(root) {
+		if _, ok := extensions[key.Value]; !ok || strings.HasPrefix(key.Value, extensionPrefix) {
+			continue
+		}
+		unknown = append(unknown, &UnknownFieldError{
+			Field:      key.Value,
+			Line:       key.Line,
+			Suggestion: closestMatch(key.Value, known),
+		})
+	}
+	return errors.Join(unknown...)
+}
+
+// topLevelKeys returns the key nodes of the document's root mapping in
+// the order they appear.
+func topLevelKeys(root *yaml.Node) []*yaml.Node {
+	doc := documentMapping(root)
+	if doc.Kind != yaml.MappingNode {
+		return nil
+	}
+	keys := []*yaml.Node{}
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		keys = append(keys, doc.Content[i])
+	}
+	return keys
+}
+
+// collectYAMLFields walks a struct type and records the YAML keys
+// accepted by it and every struct type nested within it, keyed by the
+// type name used in decoder error messages.
+func collectYAMLFields(t reflect.Type, known map[string][]string) map[string][]string {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return known
+	}
+	if _, seen := known[t.String()]; seen {
+		return known
+	}
+	names := []string{}
+	known[t.String()] = names
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("yaml")
+		name := strings.Split(tag, ",")[0]
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(tag, ",inline") {
+			if field.Type.Kind() == reflect.Map {
+				continue
+			}
+			collectYAMLFields(field.Type, known)
+			names = append(names, known[field.Type.String()]...)
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		names = append(names, name)
+		collectYAMLFields(field.Type, known)
+	}
+	known[t.String()] = names
+	return known
+}
+
+// closestMatch returns the candidate nearest to input by edit distance,
+// or an empty string if none are close enough to be a plausible typo.
+func closestMatch(input string, candidates []string) string {
+	best := ""
+	bestDistance := maxSuggestionDistance + 1
+	for _, candidate := range candidates {
+		distance := levenshtein(strings.ToLower(input), strings.ToLower(candidate))
+		if distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+	return best
+}
+
+// levenshtein computes the edit distance between two strings.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
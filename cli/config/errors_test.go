@@ -0,0 +1,99 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_UnknownFieldSuggestions(t *testing.T) {
+	tests := []struct {
+		name        string
+		yamlContent string
+		expected    []string
+	}{
+		{
+			name: "typo in operation field",
+			yamlContent: `id: test-project
+codebase:
+  language: go
+  build:
+    stpes:
+      - go build ./...
+`,
+			expected: []string{"unknown field 'stpes' (did you mean 'steps'?) at line 5"},
+		},
+		{
+			name: "typo in top-level field",
+			yamlContent: `id: test-project
+verison: 1.0.0
+`,
+			expected: []string{"unknown field 'verison' (did you mean 'version'?) at line 2"},
+		},
+		{
+			name: "unknown field with no close match",
+			yamlContent: `id: test-project
+completely_unrelated: true
+`,
+			expected: []string{"unknown field 'completely_unrelated' at line 2"},
+		},
+		{
+			name: "multiple unknown fields",
+			yamlContent: `id: test-project
+codebase:
+  langauge: go
+  tset:
+    steps: []
+`,
+			expected: []string{
+				"unknown field 'langauge' (did you mean 'language'?) at line 3",
+				"unknown field 'tset' (did you mean 'test'?) at line 4",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(strings.NewReader(tt.yamlContent))
+			require.Error(t, err)
+			assert.Nil(t, cfg)
+			for _, msg := range tt.expected {
+				assert.Contains(t, err.Error(), msg)
+			}
+
+			var unknown *UnknownFieldError
+			assert.True(t, errors.As(err, &unknown))
+		})
+	}
+}
+
+func TestClosestMatch(t *testing.T) {
+	candidates := []string{"steps", "env", "fail_fast"}
+
+	assert.Equal(t, "steps", closestMatch("stpes", candidates))
+	assert.Equal(t, "fail_fast", closestMatch("failfast", candidates))
+	assert.Equal(t, "env", closestMatch("ENV", candidates))
+	assert.Equal(t, "", closestMatch("something_else", candidates))
+	assert.Equal(t, "", closestMatch("steps", nil))
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"steps", "steps", 0},
+		{"stpes", "steps", 2},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, levenshtein(tt.a, tt.b), "levenshtein(%q, %q)", tt.a, tt.b)
+	}
+}
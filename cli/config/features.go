@@ -0,0 +1,95 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ExperimentalEnv enables experiments as a comma-separated list, on top of
+// those enabled in the user config.
+const ExperimentalEnv = "DEVOPS_EXPERIMENTAL"
+
+// Experiment names.
+const (
+	FeaturePipelineDAG = "pipeline-dag"
+)
+
+const featuresKey contextKey = "features"
+
+// Experiment is an unstable subsystem that must be enabled explicitly.
+type Experiment struct {
+	Name        string
+	Description string
+}
+
+// Experiments lists every experiment devops knows about.
+var Experiments = []Experiment{
+	{Name: FeaturePipelineDAG, Description: "Run operations in dependency order with 'devops pipeline'"},
+}
+
+// Features is the set of enabled experiments.
+type Features map[string]bool
+
+// LoadFeatures combines the experiments enabled in the user config and in
+// DEVOPS_EXPERIMENTAL. It also returns any names that are not known
+// experiments, which are ignored.
+func LoadFeatures(user UserConfig) (Features, []string) {
+	requested := append([]string{}, user.Experimental...)
+	for _, name := range strings.Split(os.Getenv(ExperimentalEnv), ",") {
+		requested = append(requested, name)
+	}
+
+	features := Features{}
+	unknown := []string{}
+	for _, name := range requested {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !isExperiment(name) {
+			unknown = append(unknown, name)
+			continue
+		}
+		features[name] = true
+	}
+	sort.Strings(unknown)
+	return features, unknown
+}
+
+func isExperiment(name string) bool {
+	for _, experiment := range Experiments {
+		if experiment.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Enabled reports whether the named experiment is enabled.
+func (f Features) Enabled(name string) bool {
+	return f[name]
+}
+
+// WithFeatures attaches the enabled experiments to the context.
+func WithFeatures(ctx context.Context, features Features) context.Context {
+	return context.WithValue(ctx, featuresKey, features)
+}
+
+// FeaturesFromContext returns the enabled experiments, or none if the
+// context has no feature set.
+func FeaturesFromContext(ctx context.Context) Features {
+	features, _ := ctx.Value(featuresKey).(Features)
+	return features
+}
+
+// RequireFeature fails unless the named experiment is enabled, explaining
+// how to enable it.
+func RequireFeature(ctx context.Context, name string) error {
+	if FeaturesFromContext(ctx).Enabled(name) {
+		return nil
+	}
+	return fmt.Errorf("'%s' is experimental, enable it with %s=%s or under 'experimental' in the user config", name, ExperimentalEnv, name)
+}
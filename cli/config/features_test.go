@@ -0,0 +1,35 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadFeatures(t *testing.T) {
+	t.Setenv(ExperimentalEnv, " pipeline-dag ,warp-drive")
+
+	features, unknown := LoadFeatures(UserConfig{Experimental: []string{"time-travel"}})
+	assert.True(t, features.Enabled(FeaturePipelineDAG))
+	assert.Equal(t, []string{"time-travel", "warp-drive"}, unknown)
+}
+
+func TestLoadFeatures_UserConfig(t *testing.T) {
+	t.Setenv(ExperimentalEnv, "")
+
+	features, unknown := LoadFeatures(UserConfig{})
+	assert.False(t, features.Enabled(FeaturePipelineDAG))
+	assert.Empty(t, unknown)
+
+	features, _ = LoadFeatures(UserConfig{Experimental: []string{FeaturePipelineDAG}})
+	assert.True(t, features.Enabled(FeaturePipelineDAG))
+}
+
+func TestRequireFeature(t *testing.T) {
+	ctx := context.Background()
+	assert.ErrorContains(t, RequireFeature(ctx, FeaturePipelineDAG), "DEVOPS_EXPERIMENTAL=pipeline-dag")
+
+	ctx = WithFeatures(ctx, Features{FeaturePipelineDAG: true})
+	assert.NoError(t, RequireFeature(ctx, FeaturePipelineDAG))
+}
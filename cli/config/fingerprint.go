@@ -0,0 +1,111 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/jgfranco17/devops/internal/fileutils"
+	"github.com/jgfranco17/devops/internal/hostinfo"
+)
+
+// Fingerprint describes the environment that produced a manifest, so two
+// builds can be compared.
+type Fingerprint struct {
+	DevopsVersion string            `json:"devops_version"`
+	OS            string            `json:"os"`
+	Arch          string            `json:"arch"`
+	OSRelease     string            `json:"os_release,omitempty"`
+	Container     string            `json:"container,omitempty"`
+	CIProvider    string            `json:"ci_provider,omitempty"`
+	ConfigHash    string            `json:"config_hash"`
+	Tools         map[string]string `json:"tools,omitempty"`
+}
+
+// fingerprintTools lists the version commands recorded for every project,
+// and languageTools those recorded for a project's language.
+var (
+	fingerprintTools = [][]string{{"git", "--version"}}
+	languageTools    = map[string][][]string{
+		"go":         {{"go", "version"}},
+		"python":     {{"python3", "--version"}},
+		"javascript": {{"node", "--version"}, {"npm", "--version"}},
+		"rust":       {{"rustc", "--version"}, {"cargo", "--version"}},
+	}
+)
+
+// ciProviders maps the variable each CI system sets to its name.
+var ciProviders = []struct {
+	env  string
+	name string
+}{
+	{"GITHUB_ACTIONS", "github-actions"},
+	{"GITLAB_CI", "gitlab-ci"},
+	{"JENKINS_URL", "jenkins"},
+	{"CIRCLECI", "circleci"},
+	{"BUILDKITE", "buildkite"},
+	{"TF_BUILD", "azure-pipelines"},
+	{"TRAVIS", "travis-ci"},
+	{"CI", "unknown"},
+}
+
+// toolVersion returns the first line printed by a version command.
+var toolVersion = func(ctx context.Context, args []string) (string, error) {
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = &stdout
+	if err := executor.RunCommand(ctx, cmd); err != nil {
+		return "", err
+	}
+	line, _, _ := strings.Cut(stdout.String(), "\n")
+	return strings.TrimSpace(line), nil
+}
+
+// DetectCIProvider returns the CI system devops is running in, or an empty
+// string when run locally.
+func DetectCIProvider() string {
+	for _, provider := range ciProviders {
+		if os.Getenv(provider.env) != "" {
+			return provider.name
+		}
+	}
+	return ""
+}
+
+// ConfigHash returns the SHA-256 of a definition file. It ignores line
+// endings so checkouts on Windows and Linux agree.
+func ConfigHash(definition []byte) string {
+	hash := sha256.Sum256(fileutils.NormalizeNewlines(definition))
+	return hex.EncodeToString(hash[:])
+}
+
+// CollectFingerprint gathers the environment fingerprint for a manifest.
+// Tools that are not installed are left out.
+func (d *ProjectDefinition) CollectFingerprint(ctx context.Context, devopsVersion string, definition []byte) Fingerprint {
+	fingerprint := Fingerprint{
+		DevopsVersion: devopsVersion,
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		OSRelease:     hostinfo.OSRelease(),
+		Container:     hostinfo.Container(),
+		CIProvider:    DetectCIProvider(),
+		ConfigHash:    ConfigHash(definition),
+		Tools:         map[string]string{},
+	}
+	tools := append([][]string{}, fingerprintTools...)
+	for _, view := range d.codebaseViews() {
+		tools = append(tools, languageTools[view.Codebase.Language]...)
+	}
+	for _, args := range tools {
+		if version, err := toolVersion(ctx, args); err == nil && version != "" {
+			fingerprint.Tools[args[0]] = version
+		}
+	}
+	return fingerprint
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mockToolVersion(t *testing.T, versions map[string]string) {
+	t.Helper()
+	original := toolVersion
+	toolVersion = func(ctx context.Context, args []string) (string, error) {
+		if version, ok := versions[args[0]]; ok {
+			return version, nil
+		}
+		return "", errors.New("executable file not found")
+	}
+	t.Cleanup(func() { toolVersion = original })
+}
+
+func clearCIEnv(t *testing.T) {
+	t.Helper()
+	for _, provider := range ciProviders {
+		t.Setenv(provider.env, "")
+	}
+}
+
+func TestCollectFingerprint(t *testing.T) {
+	mockToolVersion(t, map[string]string{"git": "git version 2.45.0", "go": "go version go1.24.3 linux/amd64"})
+	clearCIEnv(t)
+	t.Setenv("GITLAB_CI", "true")
+
+	d := ProjectDefinition{Codebase: Codebase{Language: "go"}}
+	fingerprint := d.CollectFingerprint(context.Background(), "1.4.0", []byte("id: demo\n"))
+
+	assert.Equal(t, "1.4.0", fingerprint.DevopsVersion)
+	assert.Equal(t, runtime.GOOS, fingerprint.OS)
+	assert.Equal(t, runtime.GOARCH, fingerprint.Arch)
+	assert.Equal(t, "gitlab-ci", fingerprint.CIProvider)
+	assert.Equal(t, map[string]string{"git": "git version 2.45.0", "go": "go version go1.24.3 linux/amd64"}, fingerprint.Tools)
+
+	crlf := d.CollectFingerprint(context.Background(), "1.4.0", []byte("id: demo\r\n"))
+	assert.Equal(t, fingerprint.ConfigHash, crlf.ConfigHash)
+	changed := d.CollectFingerprint(context.Background(), "1.4.0", []byte("id: other\n"))
+	assert.NotEqual(t, fingerprint.ConfigHash, changed.ConfigHash)
+}
+
+func TestDetectCIProvider(t *testing.T) {
+	clearCIEnv(t)
+	assert.Equal(t, "", DetectCIProvider())
+
+	t.Setenv("CI", "true")
+	assert.Equal(t, "unknown", DetectCIProvider())
+
+	t.Setenv("GITHUB_ACTIONS", "true")
+	assert.Equal(t, "github-actions", DetectCIProvider())
+}
+
+func TestGenerateManifest_Environment(t *testing.T) {
+	d := ProjectDefinition{ID: "demo", Version: "1.0.0"}
+
+	data, err := d.GenerateManifest(nil)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "environment")
+
+	data, err = d.GenerateManifest(&Fingerprint{DevopsVersion: "1.4.0", OS: "linux", Arch: "amd64", ConfigHash: "abc"})
+	require.NoError(t, err)
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	require.NotNil(t, manifest.Environment)
+	assert.Equal(t, "abc", manifest.Environment.ConfigHash)
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/jgfranco17/devops/internal/i18n"
+	"gopkg.in/yaml.v3"
+)
+
+// Fix is a change the doctor can make to the definition file to resolve a
+// failed finding.
+type Fix struct {
+	RuleID string
+	Prompt string
+	key    string
+	value  any
+}
+
+// Fixes returns the changes the preset offers for the failed findings in the
+// report, in the order the findings were reported.
+func (p *Preset) Fixes(report *ValidationReport, codebase Codebase) []Fix {
+	additions := p.Additions(codebase)
+	label := strings.ToUpper(p.Name[:1]) + p.Name[1:]
+	fixes := []Fix{}
+	for _, finding := range report.Findings {
+		if finding.Passed || finding.Suppressed {
+			continue
+		}
+		switch finding.RuleID {
+		case RuleLanguageRequired:
+			if additions.Language != "" {
+				fixes = append(fixes, Fix{
+					RuleID: finding.RuleID,
+					Prompt: i18n.T("Set the language to %s?", additions.Language),
+					key:    "language",
+					value:  additions.Language,
+				})
+			}
+		case RuleDependenciesDefined:
+			if additions.Dependencies != nil {
+				fixes = append(fixes, Fix{
+					RuleID: finding.RuleID,
+					Prompt: i18n.T("Add %s as dependencies?", strings.Join(additions.Dependencies, ", ")),
+					key:    "dependencies",
+					value:  additions.Dependencies,
+				})
+			}
+		case RuleTestStepsDefined:
+			if additions.Test.Steps != nil {
+				fixes = append(fixes, Fix{
+					RuleID: finding.RuleID,
+					Prompt: i18n.T("Add default test steps for %s?", label),
+					key:    "test",
+					value:  additions.Test,
+				})
+			}
+		case RuleBuildStepsDefined:
+			if additions.Build.Steps != nil {
+				fixes = append(fixes, Fix{
+					RuleID: finding.RuleID,
+					Prompt: i18n.T("Add default build steps for %s?", label),
+					key:    "build",
+					value:  additions.Build,
+				})
+			}
+		}
+	}
+	return fixes
+}
+
+// Apply sets the fix in the codebase section of a definition document and
+// returns the updated document. Comments in the document are kept.
+func (f Fix) Apply(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse definition: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("definition is not a mapping")
+	}
+	codebase := mappingValue(root, "codebase")
+	if codebase == nil || codebase.Kind != yaml.MappingNode {
+		codebase = &yaml.Node{Kind: yaml.MappingNode}
+		setMappingValue(root, "codebase", codebase)
+	}
+	value := &yaml.Node{}
+	if err := value.Encode(f.value); err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", f.key, err)
+	}
+	setMappingValue(codebase, f.key, value)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// setMappingValue replaces the value for key in a mapping node, appending
+// the key if it is not there yet.
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreset_Fixes(t *testing.T) {
+	preset := Presets[0]
+	report := &ValidationReport{Findings: []Finding{
+		{RuleID: RuleIDRequired},
+		{RuleID: RuleLanguageRequired},
+		{RuleID: RuleDependenciesDefined, Suppressed: true},
+		{RuleID: RuleTestStepsDefined},
+		{RuleID: RuleBuildStepsDefined, Passed: true},
+	}}
+
+	fixes := preset.Fixes(report, Codebase{})
+
+	require.Len(t, fixes, 2)
+	assert.Equal(t, RuleLanguageRequired, fixes[0].RuleID)
+	assert.Equal(t, "Set the language to go?", fixes[0].Prompt)
+	assert.Equal(t, RuleTestStepsDefined, fixes[1].RuleID)
+	assert.Equal(t, "Add default test steps for Go?", fixes[1].Prompt)
+}
+
+func TestPreset_Fixes_SkipsCoveredFields(t *testing.T) {
+	preset := Presets[0]
+	report := &ValidationReport{Findings: []Finding{{RuleID: RuleLanguageRequired}}}
+
+	fixes := preset.Fixes(report, Codebase{Language: "go"})
+
+	assert.Empty(t, fixes)
+}
+
+func TestFix_Apply(t *testing.T) {
+	preset := Presets[0]
+	report := &ValidationReport{Findings: []Finding{
+		{RuleID: RuleLanguageRequired},
+		{RuleID: RuleTestStepsDefined},
+	}}
+	fixes := preset.Fixes(report, Codebase{})
+	require.Len(t, fixes, 2)
+
+	data := []byte("# Project definition\nname: demo\ncodebase:\n  install:\n    steps:\n      - go mod download\n")
+	for _, fix := range fixes {
+		updated, err := fix.Apply(data)
+		require.NoError(t, err)
+		data = updated
+	}
+
+	assert.Contains(t, string(data), "# Project definition")
+	def, err := decodeDefinition(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "go", def.Codebase.Language)
+	require.Len(t, def.Codebase.Test.Steps, 1)
+	assert.Equal(t, "go test ./...", def.Codebase.Test.Steps[0].Run)
+	assert.True(t, def.Codebase.Test.FailFast)
+	require.Len(t, def.Codebase.Install.Steps, 1)
+	assert.Equal(t, "go mod download", def.Codebase.Install.Steps[0].Run)
+}
+
+func TestFix_Apply_AddsCodebase(t *testing.T) {
+	fixes := Presets[0].Fixes(&ValidationReport{Findings: []Finding{{RuleID: RuleLanguageRequired}}}, Codebase{})
+	require.Len(t, fixes, 1)
+
+	updated, err := fixes[0].Apply([]byte("name: demo\n"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "name: demo\ncodebase:\n  language: go\n", string(updated))
+}
+
+func TestFix_Apply_InvalidDocument(t *testing.T) {
+	fixes := Presets[0].Fixes(&ValidationReport{Findings: []Finding{{RuleID: RuleLanguageRequired}}}, Codebase{})
+	require.Len(t, fixes, 1)
+
+	_, err := fixes[0].Apply([]byte("- a\n- b\n"))
+
+	assert.ErrorContains(t, err, "definition is not a mapping")
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/jgfranco17/devops/cli/executor"
+)
+
+const gitignoreFile = ".gitignore"
+
+// gitIgnored reports whether git ignores the given path. It returns an
+// error when git is unavailable or the directory is not a repository.
+var gitIgnored = func(ctx context.Context, path string) (bool, error) {
+	err := executor.RunCommand(ctx, exec.CommandContext(ctx, "git", "check-ignore", "-q", path))
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// gitTrackedFiles lists the files under path that are tracked by git.
+var gitTrackedFiles = func(ctx context.Context, path string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "ls-files", "--", path)
+	var out strings.Builder
+	cmd.Stdout = &out
+	if err := executor.RunCommand(ctx, cmd); err != nil {
+		return nil, err
+	}
+	return strings.Fields(out.String()), nil
+}
+
+// WorkDirNeedsIgnore reports whether the devops working directory exists
+// inside a git repository without being ignored.
+func WorkDirNeedsIgnore(ctx context.Context) bool {
+	if _, err := os.Stat(WorkDir); err != nil {
+		return false
+	}
+	ignored, err := gitIgnored(ctx, WorkDir)
+	return err == nil && !ignored
+}
+
+// IgnoreWorkDir appends the devops working directory to .gitignore,
+// creating the file if needed.
+func IgnoreWorkDir() error {
+	existing, err := os.ReadFile(gitignoreFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", gitignoreFile, err)
+	}
+	entry := "/" + WorkDir + "/\n"
+	if len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+		entry = "\n" + entry
+	}
+	f, err := os.OpenFile(gitignoreFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", gitignoreFile, err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(entry); err != nil {
+		return fmt.Errorf("failed to update %s: %w", gitignoreFile, err)
+	}
+	return nil
+}
+
+// checkWorkDirTracked warns when files in the working directory are
+// committed to git.
+func checkWorkDirTracked(b *reportBuilder) {
+	tracked, err := gitTrackedFiles(context.Background(), WorkDir)
+	if err != nil || len(tracked) == 0 {
+		return
+	}
+	b.fail(RuleWorkDirTracked, fmt.Sprintf("Run 'git rm -r --cached %s' and add /%s/ to %s", WorkDir, WorkDir, gitignoreFile),
+		"%d file(s) under %s are tracked by git", len(tracked), WorkDir)
+}
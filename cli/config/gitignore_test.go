@@ -0,0 +1,67 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreWorkDir(t *testing.T) {
+	t.Run("creates gitignore", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		require.NoError(t, IgnoreWorkDir())
+		content, err := os.ReadFile(".gitignore")
+		require.NoError(t, err)
+		assert.Equal(t, "/.devops/\n", string(content))
+	})
+
+	t.Run("appends to existing gitignore without trailing newline", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		require.NoError(t, os.WriteFile(".gitignore", []byte("bin/"), 0644))
+		require.NoError(t, IgnoreWorkDir())
+		content, err := os.ReadFile(".gitignore")
+		require.NoError(t, err)
+		assert.Equal(t, "bin/\n/.devops/\n", string(content))
+	})
+}
+
+func TestWorkDirNeedsIgnore(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	ctx := context.Background()
+	t.Chdir(t.TempDir())
+	require.NoError(t, exec.Command("git", "init", "-q").Run())
+
+	assert.False(t, WorkDirNeedsIgnore(ctx), "missing work dir needs nothing")
+
+	require.NoError(t, os.MkdirAll(ArtifactsDir, 0755))
+	assert.True(t, WorkDirNeedsIgnore(ctx))
+
+	require.NoError(t, IgnoreWorkDir())
+	assert.False(t, WorkDirNeedsIgnore(ctx))
+}
+
+func TestProjectDefinition_Report_WorkDirTracked(t *testing.T) {
+	original := gitTrackedFiles
+	t.Cleanup(func() { gitTrackedFiles = original })
+
+	gitTrackedFiles = func(ctx context.Context, path string) ([]string, error) {
+		return []string{".devops/manifest.json", ".devops/artifacts/build/app"}, nil
+	}
+	finding, ok := findingFor((&ProjectDefinition{}).Report(), RuleWorkDirTracked)
+	require.True(t, ok)
+	assert.Equal(t, SeverityWarning, finding.Severity)
+	assert.Equal(t, "2 file(s) under .devops are tracked by git", finding.Message)
+
+	gitTrackedFiles = func(ctx context.Context, path string) ([]string, error) {
+		return nil, errors.New("not a git repository")
+	}
+	_, ok = findingFor((&ProjectDefinition{}).Report(), RuleWorkDirTracked)
+	assert.False(t, ok)
+}
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jgfranco17/devops/cli/executor"
+)
+
+// runsFile is the file of the history directory runs are appended to.
+const runsFile = "runs.jsonl"
+
+// HistoryEntry is a devops invocation that ran operations, as recorded in
+// the run history.
+type HistoryEntry struct {
+	Command    string             `json:"command"`
+	Status     string             `json:"status"`
+	ExitCode   int                `json:"exit_code"`
+	StartedAt  time.Time          `json:"started_at"`
+	DurationMs int64              `json:"duration_ms"`
+	GitSHA     string             `json:"git_sha,omitempty"`
+	ConfigHash string             `json:"config_hash,omitempty"`
+	Operations []HistoryOperation `json:"operations"`
+	Error      string             `json:"error,omitempty"`
+}
+
+// HistoryOperation is an operation run by a recorded invocation.
+type HistoryOperation struct {
+	Name       string        `json:"name"`
+	Codebase   string        `json:"codebase,omitempty"`
+	RunID      string        `json:"run_id"`
+	Status     string        `json:"status"`
+	DurationMs int64         `json:"duration_ms"`
+	Steps      []HistoryStep `json:"steps"`
+
+	// Attempts records each attempt of an operation re-run after
+	// infrastructure failures, so they are not mistaken for step failures.
+	Attempts []HistoryAttempt `json:"attempts,omitempty"`
+}
+
+// HistoryAttempt is an attempt of a recorded operation that was re-run.
+type HistoryAttempt struct {
+	Attempt    int           `json:"attempt"`
+	Status     string        `json:"status"`
+	InfraError string        `json:"infra_error,omitempty"`
+	Steps      []HistoryStep `json:"steps"`
+}
+
+// HistoryStep is a step of a recorded operation.
+type HistoryStep struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	ExitCode   int    `json:"exit_code"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Duration returns how long the invocation ran.
+func (e HistoryEntry) Duration() time.Duration {
+	return time.Duration(e.DurationMs) * time.Millisecond
+}
+
+// Label names the operation, prefixed with its codebase if it has one.
+func (o HistoryOperation) Label() string {
+	if o.Codebase == "" {
+		return o.Name
+	}
+	return o.Codebase + "/" + o.Name
+}
+
+// NewHistoryEntry describes an invocation from its run summary and the
+// error it ended with. It returns false if no operation ran.
+func NewHistoryEntry(summary *RunSummary, err error, finished time.Time) (HistoryEntry, bool) {
+	if summary == nil {
+		return HistoryEntry{}, false
+	}
+	summary.mu.Lock()
+	defer summary.mu.Unlock()
+	if len(summary.Operations) == 0 {
+		return HistoryEntry{}, false
+	}
+	started := summary.Operations[0].Start
+	entry := HistoryEntry{
+		Command:    summary.Command,
+		Status:     LastRunSuccess,
+		StartedAt:  started.UTC(),
+		DurationMs: finished.Sub(started).Milliseconds(),
+		ConfigHash: summary.ConfigHash,
+		Operations: []HistoryOperation{},
+	}
+	for _, op := range summary.Operations {
+		recorded := HistoryOperation{
+			Name:       op.Name,
+			Codebase:   op.Codebase,
+			RunID:      op.RunID,
+			Status:     LastRunSuccess,
+			DurationMs: op.Duration.Milliseconds(),
+			Steps:      historySteps(op.Steps),
+		}
+		if op.Err != nil {
+			recorded.Status = LastRunFailure
+		}
+		for _, attempt := range op.Attempts {
+			recordedAttempt := HistoryAttempt{Attempt: attempt.Attempt, Status: LastRunSuccess, Steps: historySteps(attempt.Steps)}
+			if attempt.Err != nil {
+				recordedAttempt.Status = LastRunFailure
+			}
+			if executor.IsInfraError(attempt.Err) {
+				recordedAttempt.InfraError = attempt.Err.Error()
+			}
+			recorded.Attempts = append(recorded.Attempts, recordedAttempt)
+		}
+		entry.Operations = append(entry.Operations, recorded)
+	}
+	if err != nil {
+		entry.Status = LastRunFailure
+		entry.ExitCode = 1
+		entry.Error = err.Error()
+	}
+	return entry, true
+}
+
+// historySteps describes the steps of an operation or attempt.
+func historySteps(steps []StepResult) []HistoryStep {
+	recorded := []HistoryStep{}
+	for _, step := range steps {
+		recorded = append(recorded, HistoryStep{
+			Name: step.Name, Status: step.Status, ExitCode: step.ExitCode, DurationMs: step.Duration.Milliseconds(),
+		})
+	}
+	return recorded
+}
+
+// RecordHistory appends the invocation to the run history, with the
+// commit it ran on. Invocations that ran no operation are not recorded.
+func RecordHistory(ctx context.Context, summary *RunSummary, err error, finished time.Time) error {
+	entry, ok := NewHistoryEntry(summary, err, finished)
+	if !ok {
+		return nil
+	}
+	// Outside a git checkout the run is recorded without a commit.
+	entry.GitSHA, _ = runGit(ctx, "rev-parse", "HEAD")
+	if err := appendHistory(runsFile, entry); err != nil {
+		return fmt.Errorf("failed to record run history: %w", err)
+	}
+	return nil
+}
+
+// LoadHistory reads the run history, oldest first.
+func LoadHistory() ([]HistoryEntry, error) {
+	f, err := os.Open(filepath.Join(HistoryDir, runsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return []HistoryEntry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries := []HistoryEntry{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid run on line %d of %s: %w", line, runsFile, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// FilterHistory returns the entries that ran the operation, given by name
+// or by codebase/name.
+func FilterHistory(entries []HistoryEntry, operation string) []HistoryEntry {
+	filtered := []HistoryEntry{}
+	for _, entry := range entries {
+		for _, op := range entry.Operations {
+			if op.Name == operation || op.Label() == operation {
+				filtered = append(filtered, entry)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// OperationTrend sums up the recorded runs of an operation.
+type OperationTrend struct {
+	Operation    string
+	Runs         int
+	Failures     int
+	MeanDuration time.Duration
+	Last         HistoryOperation
+	LastRun      HistoryEntry
+}
+
+// PassRate returns the share of runs that succeeded, from 0 to 1.
+func (t OperationTrend) PassRate() float64 {
+	if t.Runs == 0 {
+		return 0
+	}
+	return float64(t.Runs-t.Failures) / float64(t.Runs)
+}
+
+// HistoryTrends sums up the runs of every operation in the history, in
+// the order the operations first ran.
+func HistoryTrends(entries []HistoryEntry) []OperationTrend {
+	trends := []OperationTrend{}
+	index := map[string]int{}
+	var total []time.Duration
+	for _, entry := range entries {
+		for _, op := range entry.Operations {
+			i, ok := index[op.Label()]
+			if !ok {
+				i = len(trends)
+				index[op.Label()] = i
+				trends = append(trends, OperationTrend{Operation: op.Label()})
+				total = append(total, 0)
+			}
+			trend := &trends[i]
+			trend.Runs++
+			if op.Status != LastRunSuccess {
+				trend.Failures++
+			}
+			total[i] += time.Duration(op.DurationMs) * time.Millisecond
+			trend.MeanDuration = total[i] / time.Duration(trend.Runs)
+			trend.Last = op
+			trend.LastRun = entry
+		}
+	}
+	return trends
+}
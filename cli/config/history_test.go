@@ -0,0 +1,121 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHistoryEntry(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	summary := &RunSummary{Command: "devops test", ConfigHash: "abc123"}
+	summary.setSteps([]StepResult{{Name: "go vet ./...", Status: "passed", Duration: 2 * time.Second}})
+	summary.record("", "install", start, nil)
+	summary.setSteps([]StepResult{{Name: "go test ./...", Status: "failed", ExitCode: 1}})
+	summary.record("api", "test", start.Add(time.Minute), errors.New("exit status 1"))
+
+	entry, ok := NewHistoryEntry(summary, errors.New("tests failed: exit status 1"), start.Add(2*time.Minute))
+	require.True(t, ok)
+	assert.Equal(t, "devops test", entry.Command)
+	assert.Equal(t, LastRunFailure, entry.Status)
+	assert.Equal(t, 1, entry.ExitCode)
+	assert.Equal(t, start, entry.StartedAt)
+	assert.Equal(t, 2*time.Minute, entry.Duration())
+	assert.Equal(t, "abc123", entry.ConfigHash)
+	require.Len(t, entry.Operations, 2)
+	assert.Equal(t, HistoryStep{Name: "go vet ./...", Status: "passed", DurationMs: 2000}, entry.Operations[0].Steps[0])
+	assert.Equal(t, "api/test", entry.Operations[1].Label())
+	assert.Equal(t, LastRunFailure, entry.Operations[1].Status)
+	assert.Equal(t, "20240501T120100Z-api-test", entry.Operations[1].RunID)
+
+	_, ok = NewHistoryEntry(&RunSummary{Command: "devops doctor"}, nil, start)
+	assert.False(t, ok)
+	_, ok = NewHistoryEntry(nil, nil, start)
+	assert.False(t, ok)
+}
+
+func TestNewHistoryEntry_RetriedOperation(t *testing.T) {
+	t.Chdir(t.TempDir())
+	summary := &RunSummary{Command: "devops build"}
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	ctx = WithRunSummary(ctx, summary)
+	project := ProjectDefinition{ID: "app", Codebase: Codebase{
+		Build: Operation{InfraRetries: 1, Steps: StepsFromCommands("make")},
+	}}
+	infraErr := &executor.InfraError{Reason: "failed to start shell", Err: errors.New("resource temporarily unavailable")}
+	m := &MockShellExecutor{}
+	m.On("Exec", mock.Anything, "make").Return(executor.Result{ExitCode: -1}, infraErr).Once()
+	m.On("Exec", mock.Anything, "make").Return(executor.Result{}, nil).Once()
+	require.NoError(t, project.Build(ctx, m))
+
+	entry, ok := NewHistoryEntry(summary, nil, time.Now())
+	require.True(t, ok)
+	require.Len(t, entry.Operations, 1)
+	op := entry.Operations[0]
+	assert.Equal(t, LastRunSuccess, op.Status)
+	require.Len(t, op.Attempts, 2)
+	assert.Equal(t, 1, op.Attempts[0].Attempt)
+	assert.Equal(t, LastRunFailure, op.Attempts[0].Status)
+	assert.Contains(t, op.Attempts[0].InfraError, "failed to start shell")
+	require.Len(t, op.Attempts[0].Steps, 1)
+	assert.Equal(t, "make", op.Attempts[0].Steps[0].Name)
+	assert.Equal(t, HistoryAttempt{Attempt: 2, Status: LastRunSuccess, Steps: op.Steps}, op.Attempts[1])
+}
+
+func TestRecordHistory(t *testing.T) {
+	t.Chdir(t.TempDir())
+	stubGitSHA(t, "0123456789abcdef0123456789abcdef01234567")
+	entries, err := LoadHistory()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, runErr := range []error{nil, errors.New("exit status 1")} {
+		summary := &RunSummary{Command: "devops build"}
+		summary.record("", "build", start.Add(time.Duration(i)*time.Hour), runErr)
+		require.NoError(t, RecordHistory(context.Background(), summary, runErr, start.Add(time.Duration(i)*time.Hour+time.Minute)))
+	}
+	require.NoError(t, RecordHistory(context.Background(), &RunSummary{Command: "devops doctor"}, nil, start))
+
+	entries, err = LoadHistory()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "0123456789abcdef0123456789abcdef01234567", entries[0].GitSHA)
+	assert.Equal(t, LastRunSuccess, entries[0].Status)
+	assert.Equal(t, LastRunFailure, entries[1].Status)
+	assert.Equal(t, "exit status 1", entries[1].Error)
+}
+
+func TestHistoryTrends(t *testing.T) {
+	op := func(name string, status string, ms int64) HistoryOperation {
+		return HistoryOperation{Name: name, Status: status, DurationMs: ms}
+	}
+	entries := []HistoryEntry{
+		{Command: "devops build", Operations: []HistoryOperation{op("build", LastRunSuccess, 1000)}},
+		{Command: "devops test", Operations: []HistoryOperation{op("build", LastRunSuccess, 3000), op("test", LastRunFailure, 500)}},
+		{Command: "devops build", Operations: []HistoryOperation{op("build", LastRunFailure, 2000)}},
+	}
+
+	trends := HistoryTrends(entries)
+	require.Len(t, trends, 2)
+	assert.Equal(t, "build", trends[0].Operation)
+	assert.Equal(t, 3, trends[0].Runs)
+	assert.Equal(t, 1, trends[0].Failures)
+	assert.InDelta(t, 2.0/3, trends[0].PassRate(), 0.001)
+	assert.Equal(t, 2*time.Second, trends[0].MeanDuration)
+	assert.Equal(t, LastRunFailure, trends[0].Last.Status)
+	assert.Equal(t, 0.0, trends[1].PassRate())
+
+	assert.Len(t, FilterHistory(entries, "test"), 1)
+	assert.Len(t, FilterHistory(entries, "build"), 3)
+	assert.Empty(t, FilterHistory(entries, "deploy"))
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// LoadFile reads the definition at path, resolving its includes relative
+// to the file's directory.
+func LoadFile(path string) (*ProjectDefinition, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open definition: %w", err)
+	}
+	defer file.Close()
+
+	cfg, err := decodeDefinition(file)
+	if err != nil {
+		return nil, err
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	return finishLoad(cfg, filepath.Dir(abs), []string{abs})
+}
+
+// finishLoad merges the definition's includes and interpolates the result.
+func finishLoad(cfg *ProjectDefinition, dir string, chain []string) (*ProjectDefinition, error) {
+	if err := cfg.resolveIncludes(dir, chain); err != nil {
+		return nil, err
+	}
+	if err := cfg.checkCodebases(); err != nil {
+		return nil, err
+	}
+	if err := cfg.interpolate(); err != nil {
+		return nil, fmt.Errorf("failed to interpolate definition: %w", err)
+	}
+	return cfg, nil
+}
+
+// resolveIncludes merges the fragments listed under include into d, in
+// order. Values set in d win over included ones, and a later include wins
+// over an earlier one. chain holds the files being loaded, to detect
+// cycles.
+func (d *ProjectDefinition) resolveIncludes(dir string, chain []string) error {
+	for i := len(d.Include) - 1; i >= 0; i-- {
+		path := d.Include[i]
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		path = filepath.Clean(path)
+		for _, loading := range chain {
+			if loading == path {
+				return fmt.Errorf("include cycle: %s", strings.Join(append(chain, path), " -> "))
+			}
+		}
+
+		fragment, err := loadFragment(path, append(chain[:len(chain):len(chain)], path))
+		if err != nil {
+			return fmt.Errorf("failed to include %s: %w", d.Include[i], err)
+		}
+		d.merge(fragment)
+	}
+	return nil
+}
+
+func loadFragment(path string, chain []string) (*ProjectDefinition, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	fragment, err := decodeDefinition(file)
+	if err != nil {
+		return nil, err
+	}
+	if err := fragment.resolveIncludes(filepath.Dir(path), chain); err != nil {
+		return nil, err
+	}
+	return fragment, nil
+}
+
+// merge fills the fields d leaves unset from fragment. Maps such as
+// codebase.operations and vars are merged key by key.
+func (d *ProjectDefinition) merge(fragment *ProjectDefinition) {
+	fillUnset(reflect.ValueOf(d).Elem(), reflect.ValueOf(fragment).Elem())
+	d.Suppressions = append(d.Suppressions, fragment.Suppressions...)
+	d.undecrypted = append(d.undecrypted, fragment.undecrypted...)
+	d.decrypted = append(d.decrypted, fragment.decrypted...)
+	for location := range fragment.aliasedSteps {
+		if d.aliasedSteps == nil {
+			d.aliasedSteps = map[string]bool{}
+		}
+		d.aliasedSteps[location] = true
+	}
+}
+
+func fillUnset(dst, src reflect.Value) {
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Type().Field(i)
+		if !field.IsExported() || field.Name == "Suppressions" || field.Name == "Include" {
+			continue
+		}
+		to, from := dst.Field(i), src.Field(i)
+		switch {
+		case field.Type == reflect.TypeOf(Codebase{}):
+			fillUnset(to, from)
+		case to.Kind() == reflect.Map && !from.IsNil():
+			if to.IsNil() {
+				to.Set(reflect.MakeMap(to.Type()))
+			}
+			iter := from.MapRange()
+			for iter.Next() {
+				if !to.MapIndex(iter.Key()).IsValid() {
+					to.SetMapIndex(iter.Key(), iter.Value())
+				}
+			}
+		case to.IsZero():
+			to.Set(from)
+		}
+	}
+}
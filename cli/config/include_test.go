@@ -0,0 +1,118 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeDefinitionFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestLoadFile_Include(t *testing.T) {
+	dir := writeDefinitionFiles(t, map[string]string{
+		".devops.yaml": `id: composed
+version: 1.0.0
+include:
+  - ci/test.yaml
+  - ci/build.yaml
+vars:
+  TARGET: main
+codebase:
+  language: go
+  operations:
+    lint:
+      steps:
+        - golangci-lint run
+`,
+		"ci/test.yaml": `codebase:
+  test:
+    steps:
+      - go test ./...
+  operations:
+    lint:
+      steps:
+        - go vet ./...
+`,
+		"ci/build.yaml": `include:
+  - ../shared/vars.yaml
+codebase:
+  build:
+    steps:
+      - go build -o ${{ vars.OUT }} ./cmd/${{ vars.TARGET }}
+`,
+		"shared/vars.yaml": `vars:
+  OUT: bin/app
+  TARGET: other
+`,
+	})
+
+	cfg, err := LoadFile(filepath.Join(dir, ".devops.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "composed", cfg.ID)
+	assert.Equal(t, "go", cfg.Codebase.Language)
+	assert.Equal(t, "go test ./...", cfg.Codebase.Test.Steps[0].Run)
+	assert.Equal(t, "go build -o bin/app ./cmd/main", cfg.Codebase.Build.Steps[0].Run)
+	assert.Equal(t, "golangci-lint run", cfg.Codebase.Operations["lint"].Steps[0].Run)
+}
+
+func TestLoadFile_LaterIncludeWins(t *testing.T) {
+	dir := writeDefinitionFiles(t, map[string]string{
+		".devops.yaml": "id: ordered\ninclude: [a.yaml, b.yaml]\n",
+		"a.yaml":       "codebase:\n  test:\n    steps: [make test-a]\n",
+		"b.yaml":       "codebase:\n  test:\n    steps: [make test-b]\n",
+	})
+
+	cfg, err := LoadFile(filepath.Join(dir, ".devops.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "make test-b", cfg.Codebase.Test.Steps[0].Run)
+}
+
+func TestLoadFile_IncludeCycle(t *testing.T) {
+	dir := writeDefinitionFiles(t, map[string]string{
+		".devops.yaml": "id: cyclic\ninclude: [a.yaml]\n",
+		"a.yaml":       "include: [b.yaml]\n",
+		"b.yaml":       "include: [.devops.yaml]\n",
+	})
+
+	_, err := LoadFile(filepath.Join(dir, ".devops.yaml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "include cycle: ")
+	assert.Contains(t, err.Error(), filepath.Join(dir, "b.yaml")+" -> "+filepath.Join(dir, ".devops.yaml"))
+}
+
+func TestLoadFile_IncludeErrors(t *testing.T) {
+	dir := writeDefinitionFiles(t, map[string]string{
+		".devops.yaml": "id: broken\ninclude: [missing.yaml]\n",
+		"typo.yaml":    "id: typo\ninclude: [bad.yaml]\n",
+		"bad.yaml":     "codebase:\n  tset: {}\n",
+	})
+
+	_, err := LoadFile(filepath.Join(dir, ".devops.yaml"))
+	assert.ErrorContains(t, err, "failed to include missing.yaml")
+
+	_, err = LoadFile(filepath.Join(dir, "typo.yaml"))
+	assert.ErrorContains(t, err, "failed to include bad.yaml: failed to decode YAML")
+}
+
+func TestLoad_IncludeRelativeToWorkingDirectory(t *testing.T) {
+	t.Chdir(writeDefinitionFiles(t, map[string]string{
+		"fragment.yaml": "codebase:\n  build:\n    steps: [make]\n",
+	}))
+
+	cfg, err := Load(strings.NewReader("id: reader\ninclude: [fragment.yaml]\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "make", cfg.Codebase.Build.Steps[0].Run)
+}
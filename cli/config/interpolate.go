@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// interpolationPattern matches references such as ${{ project.version }}.
+var interpolationPattern = regexp.MustCompile(`\$\{\{\s*([^}\s]*)\s*\}\}`)
+
+// interpolator expands ${{ ... }} references in definition values.
+type interpolator struct {
+	project map[string]string
+	vars    map[string]string
+}
+
+// expand replaces every reference in s. project.* and vars.* references
+// must exist; env.* references to unset variables expand to nothing.
+func (in *interpolator) expand(s string) (string, error) {
+	var expandErr error
+	result := interpolationPattern.ReplaceAllStringFunc(s, func(match string) string {
+		ref := interpolationPattern.FindStringSubmatch(match)[1]
+		namespace, key, _ := strings.Cut(ref, ".")
+		var value string
+		var ok bool
+		switch namespace {
+		case "env":
+			value, ok = os.Getenv(key), key != ""
+		case "project":
+			value, ok = in.project[key]
+		case "vars":
+			if in.vars != nil {
+				value, ok = in.vars[key]
+			}
+		}
+		if !ok && expandErr == nil {
+			expandErr = fmt.Errorf("unknown reference '%s'", ref)
+		}
+		return value
+	})
+	return result, expandErr
+}
+
+func (in *interpolator) expandMap(values map[string]string) (map[string]string, error) {
+	if values == nil {
+		return nil, nil
+	}
+	expanded := make(map[string]string, len(values))
+	for key, value := range values {
+		result, err := in.expand(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		expanded[key] = result
+	}
+	return expanded, nil
+}
+
+// interpolate expands ${{ project.* }}, ${{ env.* }} and ${{ vars.* }} in
+// step commands, step names and env values. Vars may reference project
+// fields and env, but not other vars.
+func (d *ProjectDefinition) interpolate() error {
+	in := &interpolator{project: map[string]string{
+		"id":          d.ID,
+		"name":        d.Name,
+		"version":     d.Version,
+		"description": d.Description,
+		"repo_url":    d.RepoUrl,
+		"language":    d.Codebase.Language,
+	}}
+	vars, err := in.expandMap(d.Vars)
+	if err != nil {
+		return fmt.Errorf("vars.%w", err)
+	}
+	in.vars = vars
+	if in.vars == nil {
+		in.vars = map[string]string{}
+	}
+	d.Vars = vars
+
+	if err := in.interpolateCodebase(&d.Codebase); err != nil {
+		return err
+	}
+	for i := range d.Codebases {
+		if err := in.interpolateCodebase(&d.Codebases[i]); err != nil {
+			return fmt.Errorf("codebase %s: %w", d.Codebases[i].Name, err)
+		}
+	}
+	return nil
+}
+
+// interpolateCodebase expands references in the operations of c.
+func (in *interpolator) interpolateCodebase(c *Codebase) error {
+	var err error
+	for _, name := range c.OperationNames() {
+		op, _ := c.Lookup(name)
+		if op.Env, err = in.expandMap(op.Env); err != nil {
+			return fmt.Errorf("operation %s env %w", name, err)
+		}
+		if op.Steps, err = in.expandSteps(op.Steps); err != nil {
+			return fmt.Errorf("operation %s %w", name, err)
+		}
+		if op.Cleanup, err = in.expandSteps(op.Cleanup); err != nil {
+			return fmt.Errorf("operation %s cleanup %w", name, err)
+		}
+		c.set(name, op)
+	}
+	return nil
+}
+
+// expandSteps expands references in the commands, names and env of steps.
+func (in *interpolator) expandSteps(steps []Step) ([]Step, error) {
+	if steps == nil {
+		return nil, nil
+	}
+	var err error
+	expanded := make([]Step, len(steps))
+	for i, step := range steps {
+		if step.Run, err = in.expand(step.Run); err != nil {
+			return nil, fmt.Errorf("step %d: %w", i+1, err)
+		}
+		if step.Name, err = in.expand(step.Name); err != nil {
+			return nil, fmt.Errorf("step %d name: %w", i+1, err)
+		}
+		if step.Env, err = in.expandMap(step.Env); err != nil {
+			return nil, fmt.Errorf("step %d env %w", i+1, err)
+		}
+		expanded[i] = step
+	}
+	return expanded, nil
+}
+
+// set replaces the built-in or custom operation with the given name.
+func (c *Codebase) set(name string, op Operation) {
+	switch name {
+	case "install":
+		c.Install = op
+	case "test":
+		c.Test = op
+	case "build":
+		c.Build = op
+	default:
+		operations := maps.Clone(c.Operations)
+		if operations == nil {
+			operations = map[string]Operation{}
+		}
+		operations[name] = op
+		c.Operations = operations
+	}
+}
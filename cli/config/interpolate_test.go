@@ -0,0 +1,90 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_Interpolation(t *testing.T) {
+	t.Setenv("REGISTRY", "ghcr.io/acme")
+	yamlContent := `id: interpolated
+version: 1.4.0
+vars:
+  image: ${{ env.REGISTRY }}/${{ project.id }}
+codebase:
+  language: go
+  build:
+    env:
+      LDFLAGS: -X main.version=${{ project.version }}
+    steps:
+      - go build -ldflags "$LDFLAGS" ./...
+      - name: Push ${{vars.image}}
+        run: docker push ${{ vars.image }}:${{ project.version }}
+        env:
+          TAG: v${{ project.version }}
+  operations:
+    lint:
+      steps:
+        - echo ${{ env.DEVOPS_UNSET_VARIABLE }}done
+      cleanup:
+        - name: Untag ${{ project.version }}
+          run: docker rmi ${{ vars.image }}:${{ project.version }}
+`
+	cfg, err := Load(strings.NewReader(yamlContent))
+	require.NoError(t, err)
+
+	assert.Equal(t, "ghcr.io/acme/interpolated", cfg.Vars["image"])
+	build := cfg.Codebase.Build
+	assert.Equal(t, "-X main.version=1.4.0", build.Env["LDFLAGS"])
+	assert.Equal(t, `go build -ldflags "$LDFLAGS" ./...`, build.Steps[0].Run)
+	assert.Equal(t, "Push ghcr.io/acme/interpolated", build.Steps[1].Name)
+	assert.Equal(t, "docker push ghcr.io/acme/interpolated:1.4.0", build.Steps[1].Run)
+	assert.Equal(t, "v1.4.0", build.Steps[1].Env["TAG"])
+	assert.Equal(t, "echo done", cfg.Codebase.Operations["lint"].Steps[0].Run)
+	cleanup := cfg.Codebase.Operations["lint"].Cleanup[0]
+	assert.Equal(t, "Untag 1.4.0", cleanup.Name)
+	assert.Equal(t, "docker rmi ghcr.io/acme/interpolated:1.4.0", cleanup.Run)
+}
+
+func TestLoad_InterpolationErrors(t *testing.T) {
+	tests := map[string]string{
+		"unknown var": `id: bad
+codebase:
+  build:
+    steps:
+      - echo ${{ vars.missing }}
+`,
+		"unknown project field": `id: bad
+codebase:
+  test:
+    env:
+      X: ${{ project.owner }}
+`,
+		"unknown var in cleanup": `id: bad
+codebase:
+  build:
+    cleanup:
+      - echo ${{ vars.missing }}
+`,
+		"vars referencing vars": `id: bad
+vars:
+  a: one
+  b: ${{ vars.a }}
+`,
+	}
+	expected := map[string]string{
+		"unknown var":            "operation build step 1: unknown reference 'vars.missing'",
+		"unknown var in cleanup": "operation build cleanup step 1: unknown reference 'vars.missing'",
+		"unknown project field":  "operation test env X: unknown reference 'project.owner'",
+		"vars referencing vars":  "vars.b: unknown reference 'vars.a'",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(strings.NewReader(content))
+			assert.ErrorContains(t, err, "failed to interpolate definition: "+expected[name])
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+	SystemErr string          `xml:"system-err,omitempty"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// WriteJUnit renders the summary as JUnit XML, with a test suite per
+// operation and a test case per step.
+func (s *RunSummary) WriteJUnit(w io.Writer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc := junitTestSuites{Name: s.Command, Suites: []junitTestSuite{}}
+	var total time.Duration
+	for _, op := range s.Operations {
+		suite := junitTestSuite{
+			Name:      op.Name,
+			Tests:     len(op.Steps),
+			Time:      junitSeconds(op.Duration),
+			Timestamp: op.Start.UTC().Format("2006-01-02T15:04:05"),
+			Cases:     []junitTestCase{},
+		}
+		for _, step := range op.Steps {
+			testCase := junitTestCase{Name: step.Name, ClassName: op.Name, Time: junitSeconds(step.Duration)}
+			switch step.Status {
+			case "ok":
+			case "skipped":
+				testCase.Skipped = &junitMessage{Message: "condition not met"}
+				suite.Skipped++
+			case "failed (allowed)":
+				testCase.Skipped = &junitMessage{Message: fmt.Sprintf("failed with exit code %d, failure allowed", step.ExitCode)}
+				suite.Skipped++
+			default:
+				testCase.Failure = &junitMessage{Message: fmt.Sprintf("%s with exit code %d", step.Status, step.ExitCode), Body: step.Stderr}
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, testCase)
+		}
+		if op.Err != nil {
+			suite.SystemErr = op.Err.Error()
+		}
+		doc.Tests += suite.Tests
+		doc.Failures += suite.Failures
+		doc.Skipped += suite.Skipped
+		total += op.Duration
+		doc.Suites = append(doc.Suites, suite)
+	}
+	doc.Time = junitSeconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteJUnitFile writes the JUnit XML report to path.
+func (s *RunSummary) WriteJUnitFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	defer f.Close()
+	if err := s.WriteJUnit(f); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunSummary_WriteJUnit(t *testing.T) {
+	summary := &RunSummary{Command: "devops test", Operations: []OperationResult{{
+		Name:     "test",
+		Start:    time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC),
+		Duration: 3 * time.Second,
+		Err:      errors.New("failed to run steps: [go test ./...]"),
+		Steps: []StepResult{
+			{Name: "go vet ./...", Duration: 500 * time.Millisecond, Status: "ok"},
+			{Name: "lint", ExitCode: 1, Duration: time.Second, Status: "failed (allowed)"},
+			{Name: "go test ./...", ExitCode: 2, Duration: 1500 * time.Millisecond, Status: "failed", Stderr: "FAIL pkg <x>"},
+		},
+	}}}
+
+	var buf bytes.Buffer
+	require.NoError(t, summary.WriteJUnit(&buf))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="devops test" tests="3" failures="1" skipped="1" time="3.000">
+  <testsuite name="test" tests="3" failures="1" skipped="1" time="3.000" timestamp="2026-05-04T12:00:00">
+    <testcase name="go vet ./..." classname="test" time="0.500"></testcase>
+    <testcase name="lint" classname="test" time="1.000">
+      <skipped message="failed with exit code 1, failure allowed"></skipped>
+    </testcase>
+    <testcase name="go test ./..." classname="test" time="1.500">
+      <failure message="failed with exit code 2">FAIL pkg &lt;x&gt;</failure>
+    </testcase>
+    <system-err>failed to run steps: [go test ./...]</system-err>
+  </testsuite>
+</testsuites>
+`, buf.String())
+}
+
+func TestRunSummary_WriteJUnitFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports", "junit.xml")
+	summary := &RunSummary{Command: "devops test"}
+
+	require.NoError(t, summary.WriteJUnitFile(path))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `<testsuites name="devops test" tests="0" failures="0" skipped="0" time="0.000"></testsuites>`)
+}
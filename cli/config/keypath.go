@@ -0,0 +1,25 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// joinPath appends a mapping key to the dotted key path of a node in the
+// definition, e.g. codebase.operations.lint.
+func joinPath(parent string, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+// itemPath returns the key path of a sequence item. Items with a name, as
+// codebases have, are keyed by it; others by their index, e.g. steps[0].
+func itemPath(parent string, item *yaml.Node, index int) string {
+	if name := mappingValue(item, "name"); name != nil && name.Kind == yaml.ScalarNode && name.Value != "" {
+		return joinPath(parent, name.Value)
+	}
+	return fmt.Sprintf("%s[%d]", parent, index)
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LastRunFile records the outcome of the latest invocation, so wrapper
+// scripts can read it instead of parsing the logs.
+const LastRunFile = WorkDir + "/last-run.json"
+
+// LastRun statuses.
+const (
+	LastRunSuccess = "success"
+	LastRunFailure = "failure"
+)
+
+// LastRun is the outcome of a devops invocation as written to LastRunFile.
+type LastRun struct {
+	Command    string `json:"command"`
+	Status     string `json:"status"`
+	ExitCode   int    `json:"exit_code"`
+	RunID      string `json:"run_id,omitempty"`
+	ReportPath string `json:"report_path,omitempty"`
+	Error      string `json:"error,omitempty"`
+	FinishedAt string `json:"finished_at"`
+}
+
+// NewLastRun describes an invocation from its run summary and the error it
+// ended with. The run ID is that of the last operation run.
+func NewLastRun(summary *RunSummary, err error, finished time.Time) LastRun {
+	run := LastRun{
+		Status:     LastRunSuccess,
+		FinishedAt: finished.UTC().Format(time.RFC3339),
+	}
+	if summary != nil {
+		summary.mu.Lock()
+		run.Command = summary.Command
+		run.ReportPath = summary.ReportPath
+		if count := len(summary.Operations); count > 0 {
+			run.RunID = summary.Operations[count-1].RunID
+		}
+		summary.mu.Unlock()
+	}
+	if err != nil {
+		run.Status = LastRunFailure
+		run.ExitCode = 1
+		run.Error = err.Error()
+	}
+	return run
+}
+
+// WriteLastRun replaces LastRunFile with the run. The file is written
+// under a temporary name first, so readers never see a partial file.
+func WriteLastRun(run LastRun) error {
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(LastRunFile), 0755); err != nil {
+		return fmt.Errorf("failed to write %s: %w", LastRunFile, err)
+	}
+	tmp := LastRunFile + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", LastRunFile, err)
+	}
+	if err := os.Rename(tmp, LastRunFile); err != nil {
+		return fmt.Errorf("failed to write %s: %w", LastRunFile, err)
+	}
+	return nil
+}
+
+// LoadLastRun reads LastRunFile.
+func LoadLastRun() (LastRun, error) {
+	var run LastRun
+	data, err := os.ReadFile(LastRunFile)
+	if err != nil {
+		return run, err
+	}
+	if err := json.Unmarshal(data, &run); err != nil {
+		return run, fmt.Errorf("failed to parse %s: %w", LastRunFile, err)
+	}
+	return run, nil
+}
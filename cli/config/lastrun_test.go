@@ -0,0 +1,45 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLastRun(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	summary := &RunSummary{Command: "devops test", ReportPath: "reports/junit.xml"}
+	summary.record("", "install", start, nil)
+	summary.record("", "test", start.Add(time.Minute), errors.New("exit status 1"))
+	finished := start.Add(2 * time.Minute)
+
+	run := NewLastRun(summary, errors.New("tests failed: exit status 1"), finished)
+	assert.Equal(t, LastRun{
+		Command:    "devops test",
+		Status:     LastRunFailure,
+		ExitCode:   1,
+		RunID:      "20240501T120100Z-test",
+		ReportPath: "reports/junit.xml",
+		Error:      "tests failed: exit status 1",
+		FinishedAt: "2024-05-01T12:02:00Z",
+	}, run)
+
+	run = NewLastRun(nil, nil, finished)
+	assert.Equal(t, LastRun{Status: LastRunSuccess, FinishedAt: "2024-05-01T12:02:00Z"}, run)
+}
+
+func TestWriteLastRun(t *testing.T) {
+	t.Chdir(t.TempDir())
+	_, err := LoadLastRun()
+	assert.Error(t, err)
+
+	run := LastRun{Command: "devops build", Status: LastRunSuccess, RunID: "20240501T120000Z-build", FinishedAt: "2024-05-01T12:00:05Z"}
+	require.NoError(t, WriteLastRun(run))
+	loaded, err := LoadLastRun()
+	require.NoError(t, err)
+	assert.Equal(t, run, loaded)
+	assert.NoFileExists(t, LastRunFile+".tmp")
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/internal/fileutils"
+)
+
+// LockFile is the lock taken by commands that run operations, so two
+// invocations in the same workspace do not run at once.
+const LockFile = WorkDir + "/lock"
+
+// remoteLockDir is created on the remote host to lock its workspace.
+const remoteLockDir = WorkDir + "/lock.d"
+
+// lockPollInterval is how often a held lock is retried.
+var lockPollInterval = 500 * time.Millisecond
+
+// remoteLockExecutor returns the executor the remote lock is taken with.
+var remoteLockExecutor = func(r *Remote) ShellExecutor {
+	return r.executor()
+}
+
+// LockConfig configures the workspace lock. With Remote, the workspace of
+// the remote host is locked too, so invocations on different machines
+// sharing it do not overlap. Timeout bounds the wait for a held lock; it
+// is waited for indefinitely by default.
+type LockConfig struct {
+	Remote  bool          `yaml:"remote,omitempty"`
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// LockHolder describes the invocation holding a lock.
+type LockHolder struct {
+	PID     int       `json:"pid"`
+	Host    string    `json:"host,omitempty"`
+	Command string    `json:"command"`
+	Started time.Time `json:"started"`
+}
+
+func (h LockHolder) String() string {
+	return fmt.Sprintf("%s (pid %d on %s) since %s", h.Command, h.PID, h.Host, h.Started.Format(time.RFC3339))
+}
+
+// Lock is a held workspace lock.
+type Lock struct {
+	file   *os.File
+	remote ShellExecutor
+}
+
+// AcquireLock takes the workspace lock for the command, waiting while
+// another invocation holds it, then the remote lock if configured.
+func (d *ProjectDefinition) AcquireLock(ctx context.Context, command string) (*Lock, error) {
+	if d.Lock.Remote && d.Remote.Host == "" {
+		return nil, errors.New("lock.remote is set but no remote host is configured")
+	}
+	if d.Lock.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.Lock.Timeout)
+		defer cancel()
+	}
+	hostname, _ := os.Hostname()
+	holder := LockHolder{PID: os.Getpid(), Host: hostname, Command: command, Started: time.Now().UTC()}
+
+	lock := &Lock{}
+	file, err := lockLocal(ctx, holder)
+	if err != nil {
+		return nil, err
+	}
+	lock.file = file
+	if d.Lock.Remote {
+		remote := remoteLockExecutor(&d.Remote)
+		if err := lockRemote(ctx, remote, d.Remote.Host, holder); err != nil {
+			return nil, errors.Join(err, lock.Release())
+		}
+		lock.remote = remote
+	}
+	return lock, nil
+}
+
+// lockLocal takes the lock of LockFile and records the holder in it.
+func lockLocal(ctx context.Context, holder LockHolder) (*os.File, error) {
+	if err := os.MkdirAll(WorkDir, 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(LockFile, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	err = waitForLock(ctx, func() (bool, error) {
+		ok, err := fileutils.TryLock(file)
+		if errors.Is(err, fileutils.ErrLockUnsupported) {
+			logging.FromContext(ctx).Warnf("Running without the workspace lock: %v", err)
+			return true, nil
+		}
+		return ok, err
+	}, func() string {
+		var current LockHolder
+		if data, err := os.ReadFile(LockFile); err == nil && json.Unmarshal(data, &current) == nil {
+			return current.String()
+		}
+		return "another invocation"
+	})
+	if err != nil {
+		return nil, errors.Join(err, file.Close())
+	}
+	data, _ := json.Marshal(holder)
+	if err := file.Truncate(0); err != nil {
+		return nil, errors.Join(err, file.Close())
+	}
+	if _, err := file.WriteAt(data, 0); err != nil {
+		return nil, errors.Join(err, file.Close())
+	}
+	return file, nil
+}
+
+// lockRemote creates remoteLockDir on the remote host, which fails while
+// another invocation holds it, and records the holder in it.
+func lockRemote(ctx context.Context, remote ShellExecutor, host string, holder LockHolder) error {
+	data, _ := json.Marshal(holder)
+	command := fmt.Sprintf("mkdir -p %s && mkdir %s 2>/dev/null && printf '%%s' %s > %s/holder",
+		shellQuote(WorkDir), shellQuote(remoteLockDir), shellQuote(string(data)), shellQuote(remoteLockDir))
+	err := waitForLock(ctx, func() (bool, error) {
+		result, err := remote.Exec(ctx, command)
+		if err != nil && result.ExitCode <= 0 {
+			return false, fmt.Errorf("failed to lock %s: %w", host, err)
+		}
+		return result.ExitCode == 0, nil
+	}, func() string {
+		result, err := remote.Exec(context.WithoutCancel(ctx), "cat "+shellQuote(remoteLockDir+"/holder"))
+		var current LockHolder
+		if err == nil && json.Unmarshal([]byte(result.Stdout), &current) == nil {
+			return current.String()
+		}
+		return "another invocation"
+	})
+	if err != nil {
+		return fmt.Errorf("remote %s: %w (remove %s there if it is stale)", host, err, remoteLockDir)
+	}
+	return nil
+}
+
+// waitForLock retries try until it succeeds, logging who holds the lock
+// once, and fails when ctx ends.
+func waitForLock(ctx context.Context, try func() (bool, error), holder func() string) error {
+	logged := false
+	for {
+		ok, err := try()
+		if ok || err != nil {
+			return err
+		}
+		if !logged {
+			logging.FromContext(ctx).Warnf("Waiting for the workspace lock held by %s", holder())
+			logged = true
+		}
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("timed out waiting for the workspace lock held by %s, use --no-lock to run anyway", holder())
+			}
+			return ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// Release releases the lock. It is a no-op on a nil lock.
+func (l *Lock) Release() error {
+	if l == nil {
+		return nil
+	}
+	var errs []error
+	if l.remote != nil {
+		if _, err := l.remote.Exec(context.Background(), "rm -rf "+shellQuote(remoteLockDir)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to release the remote lock: %w", err))
+		}
+		l.remote = nil
+	}
+	if l.file != nil {
+		errs = append(errs, l.file.Close())
+		l.file = nil
+	}
+	return errors.Join(errs...)
+}
+
+// checkLock reports a remote lock without a remote host.
+func (d *ProjectDefinition) checkLock(b *reportBuilder) {
+	if d.Lock.Remote && d.Remote.Host == "" {
+		b.failAt("lock.remote", RuleRemoteConfig, "Set remote.host or drop lock.remote", "lock.remote is set but no remote host is configured")
+	}
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func stubLockPolling(t *testing.T) {
+	original := lockPollInterval
+	lockPollInterval = time.Millisecond
+	t.Cleanup(func() { lockPollInterval = original })
+}
+
+func TestProjectDefinition_AcquireLock(t *testing.T) {
+	t.Chdir(t.TempDir())
+	stubLockPolling(t)
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	project := ProjectDefinition{ID: "app", Lock: LockConfig{Timeout: 20 * time.Millisecond}}
+
+	lock, err := project.AcquireLock(ctx, "devops build")
+	require.NoError(t, err)
+
+	_, err = project.AcquireLock(ctx, "devops test")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out waiting for the workspace lock held by devops build (pid")
+	assert.Contains(t, err.Error(), "use --no-lock to run anyway")
+
+	require.NoError(t, lock.Release())
+	require.NoError(t, lock.Release())
+	lock, err = project.AcquireLock(ctx, "devops test")
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+
+	var none *Lock
+	assert.NoError(t, none.Release())
+}
+
+func TestProjectDefinition_AcquireLockRemote(t *testing.T) {
+	t.Chdir(t.TempDir())
+	stubLockPolling(t)
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	m := &MockShellExecutor{}
+	original := remoteLockExecutor
+	remoteLockExecutor = func(r *Remote) ShellExecutor { return m }
+	t.Cleanup(func() { remoteLockExecutor = original })
+
+	project := ProjectDefinition{ID: "app", Lock: LockConfig{Remote: true}}
+	_, err := project.AcquireLock(ctx, "devops build")
+	assert.EqualError(t, err, "lock.remote is set but no remote host is configured")
+
+	project.Remote = Remote{Host: "builder"}
+	mkdir := mock.MatchedBy(func(command string) bool { return len(command) > 5 && command[:5] == "mkdir" })
+	m.On("Exec", mock.Anything, mkdir).Return(executor.Result{ExitCode: 1}, nil).Once()
+	m.On("Exec", mock.Anything, mkdir).Return(executor.Result{}, nil).Once()
+	m.On("Exec", mock.Anything, "cat '.devops/lock.d/holder'").
+		Return(executor.Result{Stdout: `{"pid":7,"host":"ci-1","command":"devops build","started":"2026-03-01T10:00:00Z"}`}, nil)
+	m.On("Exec", mock.Anything, "rm -rf '.devops/lock.d'").Return(executor.Result{}, nil)
+
+	lock, err := project.AcquireLock(ctx, "devops build")
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+	m.AssertCalled(t, "Exec", mock.Anything, "cat '.devops/lock.d/holder'")
+	m.AssertCalled(t, "Exec", mock.Anything, "rm -rf '.devops/lock.d'")
+}
+
+func TestProjectDefinition_Report_Lock(t *testing.T) {
+	project := ProjectDefinition{ID: "app", Lock: LockConfig{Remote: true}}
+	finding, ok := findingFor(project.Report(), RuleRemoteConfig)
+	require.True(t, ok)
+	assert.False(t, finding.Passed)
+	assert.Equal(t, "lock.remote is set but no remote host is configured", finding.Message)
+}
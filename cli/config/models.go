@@ -1,17 +1,28 @@
 package config
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"maps"
 	"os"
+	"reflect"
 	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 	"unicode"
 
 	"github.com/jgfranco17/dev-tooling-go/logging"
 	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/jgfranco17/devops/internal/fileutils"
 	"github.com/jgfranco17/devops/internal/outputs"
 	"github.com/sirupsen/logrus"
 
@@ -20,23 +31,90 @@ import (
 
 type ShellExecutor interface {
 	Exec(ctx context.Context, command string) (executor.Result, error)
-	AddEnv(env []string)
 }
 
 type Manifest struct {
-	ID           string   `json:"id"`
-	Version      string   `json:"version"`
-	RepoUrl      string   `json:"repo_url,omitempty"`
-	Dependencies []string `json:"dependencies,omitempty"`
+	ID           string       `json:"id"`
+	Version      string       `json:"version"`
+	RepoUrl      string       `json:"repo_url,omitempty"`
+	Dependencies []string     `json:"dependencies,omitempty"`
+	Environment  *Fingerprint `json:"environment,omitempty"`
+
+	// Artifacts are the digests of the build artifacts present when the
+	// manifest was generated.
+	Artifacts []ArtifactDigest `json:"artifacts,omitempty"`
 }
 
 type ProjectDefinition struct {
-	ID          string   `yaml:"id"`
-	Name        string   `yaml:"name,omitempty"`
-	Version     string   `yaml:"version"`
-	Description string   `yaml:"description,omitempty"`
-	RepoUrl     string   `yaml:"repo_url"`
-	Codebase    Codebase `yaml:"codebase"`
+	ID          string           `yaml:"id"`
+	Name        string           `yaml:"name,omitempty"`
+	Version     string           `yaml:"version"`
+	Description string           `yaml:"description,omitempty"`
+	RepoUrl     string           `yaml:"repo_url"`
+	Codebase    Codebase         `yaml:"codebase"`
+	Codebases   []Codebase       `yaml:"codebases,omitempty"`
+	Validation  ValidationConfig `yaml:"validation,omitempty"`
+	Preflight   Preflight        `yaml:"preflight,omitempty"`
+	Remote      Remote           `yaml:"remote,omitempty"`
+	Lock        LockConfig       `yaml:"lock,omitempty"`
+	Cache       CacheConfig      `yaml:"cache,omitempty"`
+	Signing     Signing          `yaml:"signing,omitempty"`
+	Container   Container        `yaml:"container,omitempty"`
+	Deploy      Deploy           `yaml:"deploy,omitempty"`
+
+	// Include lists definition fragments, relative to this file, merged
+	// into the definition.
+	Include []string `yaml:"include,omitempty"`
+
+	// Vars are user-defined values referenced as ${{ vars.NAME }}.
+	Vars map[string]string `yaml:"vars,omitempty"`
+
+	// Environments are the destinations run artifacts are promoted to.
+	Environments map[string]Environment `yaml:"environments,omitempty"`
+
+	// ArtifactStores are the remote stores run artifacts are pushed to.
+	ArtifactStores map[string]StoreConfig `yaml:"artifact_stores,omitempty"`
+
+	// EnvFiles are .env files loaded into the env of every operation.
+	EnvFiles []string `yaml:"env_files,omitempty"`
+
+	// LogDir, if set, is where the output of every step is written, in a
+	// directory per run. The --log-dir flag takes precedence.
+	LogDir string `yaml:"log_dir,omitempty"`
+
+	// Secrets are exposed to steps as environment variables and masked
+	// from their output.
+	Secrets map[string]Secret `yaml:"secrets,omitempty"`
+
+	// Pipeline orders operations by the operations they need.
+	Pipeline map[string]PipelineStage `yaml:"pipeline,omitempty"`
+
+	// Defaults maps command names to flag values used when the flag is
+	// not given on the command line.
+	Defaults map[string]map[string]FlagValue `yaml:"defaults,omitempty"`
+
+	// Suppressions are parsed from devops:disable comments in the file.
+	Suppressions []Suppression `yaml:"-"`
+
+	// Extensions holds top-level "x-" keys. They are otherwise ignored,
+	// but can define YAML anchors reused elsewhere in the file.
+	Extensions map[string]yaml.Node `yaml:",inline"`
+
+	// aliasedSteps marks step locations written as YAML aliases, which
+	// are intentional reuse rather than duplication.
+	aliasedSteps map[string]bool
+
+	// codebaseKey is the key path of the codebase in a view of one of
+	// several codebases, e.g. codebases.api.
+	codebaseKey string
+
+	// undecrypted lists the paths of encrypted values left blank because
+	// no age identity was available.
+	undecrypted []string
+
+	// decrypted holds the plaintext of encrypted values, masked from the
+	// output of steps like secrets.
+	decrypted []string
 }
 
 func (d *ProjectDefinition) Validate(ctx context.Context) error {
@@ -45,129 +123,406 @@ func (d *ProjectDefinition) Validate(ctx context.Context) error {
 
 func (d *ProjectDefinition) ValidateTo(ctx context.Context, w io.Writer) error {
 	logger := logging.FromContext(ctx)
-	fixes := []string{}
-	suggestions := []string{}
+
+	files, _ := fileutils.LookupRootDir(ctx)
+	report := d.ReportFiles(files)
+	report.Render(w)
+	if errs := report.Errors(); len(errs) > 0 {
+		return fmt.Errorf("found %d required fixes", len(errs))
+	}
+
+	logger.Info("Project definition validated successfully")
+	return nil
+}
+
+// Report runs every validation rule against the definition and returns
+// the findings, with severities adjusted by the validation config.
+// Files referenced by steps are not checked; see ReportFiles.
+func (d *ProjectDefinition) Report() *ValidationReport {
+	return d.ReportFiles(nil)
+}
+
+// ReportFiles is Report with the files referenced by steps checked against
+// the workspace in files. A nil files skips those checks.
+func (d *ProjectDefinition) ReportFiles(files fs.FS) *ValidationReport {
+	b := &reportBuilder{
+		overrides:    d.Validation.Rules,
+		suppressions: d.Suppressions,
+		files:        files,
+	}
 
 	if d.ID == "" {
-		outputs.PrintColoredMessageTo(w, "red", "[✘] ID is required")
-		fixes = append(fixes, "Set an ID for the project")
+		b.fail(RuleIDRequired, "Set an ID for the project", "ID is required")
 	} else if err := validateProjectName(d.ID); err != nil {
-		outputs.PrintColoredMessageTo(w, "red", "[✘] Invalid ID: %s", err.Error())
-		fixes = append(fixes, "Use a valid project ID (alphanumeric/dashes/underscores, starts with letter, no whitespace, under 30 chars)")
+		b.fail(RuleIDFormat, "Use a valid project ID (alphanumeric/dashes/underscores, starts with letter, no whitespace, under 30 chars)", "Invalid ID: %s", err.Error())
 	} else {
-		outputs.PrintColoredMessageTo(w, "green", "[✔] ID: %s", d.ID)
+		b.pass(RuleIDFormat, "ID: %s", d.ID)
 	}
 
 	if d.Name != "" {
-		outputs.PrintColoredMessageTo(w, "green", "[✔] Name: %s", d.Name)
+		b.pass("", "Name: %s", d.Name)
 	}
 
 	if d.RepoUrl == "" {
-		outputs.PrintColoredMessageTo(w, "red", "[✘] Repository URL is required")
-		fixes = append(fixes, "Set a repository URL for the project")
+		b.fail(RuleRepoURLRequired, "Set a repository URL for the project", "Repository URL is required")
 	} else {
-		outputs.PrintColoredMessageTo(w, "green", "[✔] Repository URL: %s", d.RepoUrl)
+		b.pass(RuleRepoURLRequired, "Repository URL: %s", d.RepoUrl)
 	}
 
+	for _, view := range d.codebaseViews() {
+		if view.Codebase.Name != "" {
+			b.pass("", "Codebase: %s", view.Codebase.Name)
+		}
+		view.checkCodebase(b)
+	}
+	d.checkEncryptedValues(b)
+	d.checkPipeline(b)
+	d.checkCache(b)
+	d.checkArtifactStores(b)
+	d.checkSigning(b)
+	d.checkContainer(b)
+	d.checkDeploy(b)
+	d.checkLock(b)
+	checkWorkDirTracked(b)
+
+	b.checkOverrides()
+	return &b.report
+}
+
+// checkCodebase runs the rules that apply to each codebase.
+func (d *ProjectDefinition) checkCodebase(b *reportBuilder) {
 	if d.Codebase.Language == "" {
-		outputs.PrintColoredMessageTo(w, "red", "[✘] Language is required")
-		fixes = append(fixes, "Set a language in the codebase")
+		b.fail(RuleLanguageRequired, "Set a language in the codebase", "Language is required")
 	} else {
-		outputs.PrintColoredMessageTo(w, "green", "[✔] Language: %s", d.Codebase.Language)
+		b.pass(RuleLanguageRequired, "Language: %s", d.Codebase.Language)
 	}
 
 	if d.Codebase.Dependencies != nil {
-		outputs.PrintColoredMessageTo(w, "green", "[✔] Dependencies: %s", d.Codebase.Dependencies)
+		b.pass(RuleDependenciesDefined, "Dependencies: %s", d.Codebase.Dependencies)
 	} else {
-		outputs.PrintColoredMessageTo(w, "yellow", "[~] No dependencies defined")
+		b.fail(RuleDependenciesDefined, "", "No dependencies defined")
 	}
 
 	if d.Codebase.Install.Steps != nil {
-		outputs.PrintColoredMessageTo(w, "green", "[✔] Install steps (%d)", len(d.Codebase.Install.Steps))
+		b.pass("", "Install steps (%d)", len(d.Codebase.Install.Steps))
 	}
 
 	if d.Codebase.Test.Steps != nil {
-		outputs.PrintColoredMessageTo(w, "green", "[✔] Test steps (%d)", len(d.Codebase.Test.Steps))
+		b.pass(RuleTestStepsDefined, "Test steps (%d)", len(d.Codebase.Test.Steps))
 	} else {
-		outputs.PrintColoredMessageTo(w, "yellow", "[~] No test steps defined")
-		suggestions = append(suggestions, "Set test steps in the codebase")
+		b.fail(RuleTestStepsDefined, "Set test steps in the codebase", "No test steps defined")
 	}
 
 	if d.Codebase.Build.Steps != nil {
-		outputs.PrintColoredMessageTo(w, "green", "[✔] Build steps (%d)", len(d.Codebase.Build.Steps))
+		b.pass(RuleBuildStepsDefined, "Build steps (%d)", len(d.Codebase.Build.Steps))
 	} else {
-		outputs.PrintColoredMessageTo(w, "yellow", "[~] No build steps defined")
-		suggestions = append(suggestions, "Set build steps in the codebase")
+		b.fail(RuleBuildStepsDefined, "Set build steps in the codebase", "No build steps defined")
 	}
 
-	outputs.PrintTerminalWideLineTo(w, "=")
-	if len(suggestions) > 0 {
-		outputs.PrintColoredMessageTo(w, "yellow", "Suggestions:")
-		for _, suggestion := range suggestions {
-			outputs.PrintColoredMessageTo(w, "yellow", "  - %s", suggestion)
+	if len(d.Codebase.Operations) > 0 {
+		custom := d.Codebase.OperationNames()[len(builtinOperations):]
+		for _, name := range builtinOperations {
+			if _, ok := d.Codebase.Operations[name]; ok {
+				b.failAt(d.codebasePath()+".operations."+name, RuleOperationNames, fmt.Sprintf("Rename the custom '%s' operation or define it directly under codebase", name),
+					"Custom operation '%s' shadows the built-in operation", name)
+			}
 		}
-	}
-	if len(fixes) > 0 {
-		outputs.PrintColoredMessageTo(w, "red", "Fixes:")
-		for _, fix := range fixes {
-			outputs.PrintColoredMessageTo(w, "red", "  - %s", fix)
+		if len(custom) > 0 {
+			b.pass(RuleOperationNames, "Custom operations (%d): %s", len(custom), strings.Join(custom, ", "))
 		}
-		return fmt.Errorf("found %d required fixes", len(fixes))
 	}
 
-	logger.Info("Project definition validated successfully")
-	return nil
+	for _, duplicate := range d.duplicateSteps() {
+		b.fail(RuleDuplicateSteps,
+			fmt.Sprintf("Define '%s' once under an x- key anchor and reuse it with an alias", duplicate.Step),
+			"Step '%s' repeated %d times: %s", duplicate.Step, len(duplicate.Locations), strings.Join(duplicate.Locations, ", "))
+	}
+
+	d.checkContracts(b)
+	d.checkRemote(b)
+	d.checkConditions(b)
+	d.checkEnvFiles(b)
+	d.checkDependencies(b)
+	d.checkStepFiles(b)
+	d.checkTargets(b)
+	d.checkCompose(b)
 }
 
 func (d *ProjectDefinition) Test(ctx context.Context, shellExecutor ShellExecutor) error {
-	logger := logging.FromContext(ctx)
-	if len(d.Codebase.Test.Steps) == 0 {
-		logger.Warn("No test steps defined in the configuration.")
-		return nil
-	}
-	if err := d.Codebase.Test.Run(ctx, shellExecutor); err != nil {
-		return fmt.Errorf("failed to run test steps: %w", err)
-	}
-	logger.Info("Tests completed successfully")
-	return nil
+	return d.Run(ctx, "test", shellExecutor)
 }
 
 func (d *ProjectDefinition) Build(ctx context.Context, shellExecutor ShellExecutor) error {
+	return d.Run(ctx, "build", shellExecutor)
+}
+
+// Run executes the named built-in or custom operation, first running the
+// operations it depends on and staging the artifacts of any operations it
+// consumes. With several codebases it runs
+// in each selected codebase that defines the operation.
+func (d *ProjectDefinition) Run(ctx context.Context, name string, shellExecutor ShellExecutor) error {
+	return d.runInCodebases(ctx, name, func(view *ProjectDefinition) error {
+		return view.runWithDependencies(ctx, name, shellExecutor)
+	})
+}
+
+func (d *ProjectDefinition) run(ctx context.Context, name string, shellExecutor ShellExecutor, visiting map[string]bool) (err error) {
 	logger := logging.FromContext(ctx)
 	startTime := time.Now()
 
-	if len(d.Codebase.Build.Steps) == 0 {
-		logger.Warn("No build steps defined in the configuration.")
+	op, ok := d.Codebase.Lookup(name)
+	if !ok {
+		return d.unknownOperation(name)
+	}
+	defer func() {
+		RunSummaryFromContext(ctx).record(d.Codebase.Name, name, startTime, err)
+	}()
+	if visiting[name] {
+		return fmt.Errorf("operation '%s' consumes its own artifacts through a cycle", name)
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	if len(op.Steps) == 0 {
+		logger.Warnf("No %s steps defined in the configuration.", name)
+		return nil
+	}
+	if selector, ok := selectedStep(ctx, name); ok {
+		if _, err := op.stepIndex(selector); err != nil {
+			return fmt.Errorf("operation %s: %w", name, err)
+		}
+	}
+	if err := op.checkReadOnly(ctx, name); err != nil {
+		return err
+	}
+	op, err = d.withEnvFiles(ctx, op)
+	if err != nil {
+		return fmt.Errorf("operation %s: %w", name, err)
+	}
+	shouldRun, err := evaluateWhen(op.When, op.Env)
+	if err != nil {
+		return fmt.Errorf("operation %s: %w", name, err)
+	}
+	if !shouldRun {
+		logger.Infof("Skipping %s, condition '%s' is not met", name, op.When)
 		return nil
 	}
-	if err := d.Codebase.Build.Run(ctx, shellExecutor); err != nil {
-		return fmt.Errorf("failed to run build steps: %w", err)
+	if err := d.checkDecrypted(name); err != nil {
+		return err
+	}
+	ctx, op, err = d.withSecrets(ctx, op)
+	if err != nil {
+		return err
+	}
+	runID := newRunID(d.Codebase.Name, name, startTime)
+	ctx = withRunInfo(ctx, RunInfo{ProjectID: d.ID, Version: d.Version, Operation: name, RunID: runID})
+	if err := d.Preflight.withDocker(op.Compose.File != "").Check(ctx); err != nil {
+		return err
+	}
+	ctx, err = d.withRemoteCache(ctx, op)
+	if err != nil {
+		return fmt.Errorf("operation %s: %w", name, err)
+	}
+	if err := d.stageConsumed(ctx, op, shellExecutor, visiting); err != nil {
+		return err
+	}
+	opExecutor, err := d.remoteExecutor(name, op, shellExecutor)
+	if err != nil {
+		return err
+	}
+	packages, err := d.runTargets(ctx, op, opExecutor)
+	if err != nil {
+		err = fmt.Errorf("failed to run %s steps: %w", name, err)
+		dir, count, collectErr := op.collectFailureArtifacts(name)
+		switch {
+		case collectErr != nil:
+			logger.Warnf("Failed to collect failure artifacts: %v", collectErr)
+		case count > 0:
+			err = fmt.Errorf("%w (collected %d failure artifact(s) in %s)", err, count, dir)
+		}
+		return err
+	}
+	if err := op.checkOutputs(); err != nil {
+		return fmt.Errorf("operation %s: %w", name, err)
+	}
+	if len(op.Targets) > 0 {
+		// The packages are what a run for several targets produces.
+		op.Artifacts = packages
+	}
+	if err := op.applyArtifactPermissions(); err != nil {
+		return fmt.Errorf("operation %s: %w", name, err)
+	}
+	if len(op.Artifacts) > 0 {
+		retained, err := d.retainArtifacts(runID, name, op, startTime)
+		if err != nil {
+			return fmt.Errorf("operation %s: failed to retain artifacts: %w", name, err)
+		}
+		if retained {
+			logger.Infof("Retained artifacts of %s as run %s", name, runID)
+		}
 	}
-	duration := time.Since(startTime)
 	logger.WithFields(logrus.Fields{
-		"duration": duration,
-	}).Info("Build completed successfully")
+		"duration": time.Since(startTime),
+	}).Infof("Operation %s completed successfully", name)
 	return nil
 }
 
+// Exec runs an ad-hoc command the way a step of the named operation would
+// run, with its shell, env, env files, sandbox, tool paths, path_prepend,
+// umask and remote host. The args are quoted for that shell, so they reach
+// the command exactly as given.
+// With no operation the command runs with the process environment, the
+// project env files and the DEVOPS_* variables.
+// Since the command is arbitrary, it is refused in read-only mode.
+func (d *ProjectDefinition) Exec(ctx context.Context, args []string, operation string, shellExecutor ShellExecutor) error {
+	if ReadOnlyFromContext(ctx) {
+		return errors.New("ad-hoc commands are not allowed in read-only mode")
+	}
+	if len(d.Codebases) > 0 {
+		views, err := d.SelectCodebases(CodebaseFromContext(ctx))
+		if err != nil {
+			return err
+		}
+		if len(views) > 1 {
+			return errors.New("the definition has several codebases, select one with --codebase")
+		}
+		return inCodebase(views[0].Codebase.Path, func() error {
+			return views[0].Exec(ctx, args, operation, shellExecutor)
+		})
+	}
+	op := Operation{FailFast: true}
+	if operation != "" {
+		base, ok := d.Codebase.Lookup(operation)
+		if !ok {
+			return d.unknownOperation(operation)
+		}
+		op.Env, op.Sandbox, op.ToolPaths, op.Remote = base.Env, base.Sandbox, base.ToolPaths, base.Remote
+		op.PathPrepend, op.Umask, op.EnvFiles = base.PathPrepend, base.Umask, base.EnvFiles
+		op.Shell = base.Shell
+	}
+	op.Steps = []Step{{Run: executor.QuoteArgs(cmp.Or(op.Shell, executor.ShellFromContext(ctx)), args)}}
+	name := operation
+	if name == "" {
+		name = "exec"
+	}
+	ctx = withRunInfo(ctx, RunInfo{ProjectID: d.ID, Version: d.Version, Operation: operation, RunID: newRunID(d.Codebase.Name, name, time.Now())})
+	op, err := d.withEnvFiles(ctx, op)
+	if err != nil {
+		return err
+	}
+	ctx, op, err = d.withSecrets(ctx, op)
+	if err != nil {
+		return err
+	}
+	opExecutor, err := d.remoteExecutor(operation, op, shellExecutor)
+	if err != nil {
+		return err
+	}
+	return op.Run(ctx, opExecutor)
+}
+
+func (d *ProjectDefinition) unknownOperation(name string) error {
+	msg := fmt.Sprintf("unknown operation '%s'", name)
+	if suggestion := closestMatch(name, d.operationNames()); suggestion != "" {
+		msg += fmt.Sprintf(" (did you mean '%s'?)", suggestion)
+	}
+	return errors.New(msg)
+}
+
 // Load reads a YAML configuration from the provided reader and unmarshals
-// it into a struct instance.
+// it into a struct instance. Unknown keys are rejected, with the closest
+// valid field name suggested where one exists. Anchors and aliases are
+// expanded into independent copies, so each use can be modified on its own.
+// Includes are resolved relative to the working directory; use LoadFile
+// to resolve them relative to the definition file.
 func Load(r io.Reader) (*ProjectDefinition, error) {
+	cfg, err := decodeDefinition(r)
+	if err != nil {
+		return nil, err
+	}
+	return finishLoad(cfg, ".", nil)
+}
+
+// decodeDefinition parses a single definition file without resolving its
+// includes or interpolating it.
+func decodeDefinition(r io.Reader) (*ProjectDefinition, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read YAML: %w", err)
+	}
+
 	var cfg ProjectDefinition
-	decoder := yaml.NewDecoder(r)
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
 	if err := decoder.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode YAML: %w", explainDecodeError(err, reflect.TypeOf(cfg)))
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to decode YAML: %w", err)
+	}
+	if err := checkExtensionKeys(&root, cfg.Extensions, reflect.TypeOf(cfg)); err != nil {
 		return nil, fmt.Errorf("failed to decode YAML: %w", err)
 	}
+	cfg.Suppressions = parseSuppressions(&root)
+	cfg.aliasedSteps = findAliasedSteps(&root)
+
+	found, plaintexts, undecrypted, err := decryptValues(&root)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		// Keys were validated by the strict decode above; decode again from
+		// the tree now that encrypted values hold their plaintext.
+		decrypted := ProjectDefinition{Suppressions: cfg.Suppressions, aliasedSteps: cfg.aliasedSteps}
+		if err := root.Decode(&decrypted); err != nil {
+			return nil, fmt.Errorf("failed to decode YAML: %w", err)
+		}
+		decrypted.undecrypted = undecrypted
+		decrypted.decrypted = plaintexts
+		cfg = decrypted
+	}
 	return &cfg, nil
 }
 
-func (d *ProjectDefinition) GenerateManifest() ([]byte, error) {
-	manifest := Manifest{
+// dependencies returns the dependencies of every codebase.
+func (d *ProjectDefinition) dependencies() []string {
+	var deps []string
+	for _, view := range d.codebaseViews() {
+		deps = append(deps, view.Codebase.Dependencies...)
+	}
+	return deps
+}
+
+// BuildManifest collects the manifest, including the environment
+// fingerprint when one is given and the digests of the build artifacts.
+func (d *ProjectDefinition) BuildManifest(environment *Fingerprint) (Manifest, error) {
+	digests, err := d.ArtifactDigests()
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to hash artifacts: %w", err)
+	}
+	return Manifest{
 		ID:           d.ID,
 		Version:      d.Version,
-		Dependencies: d.Codebase.Dependencies,
+		Dependencies: d.dependencies(),
+		Environment:  environment,
+		Artifacts:    digests,
+	}, nil
+}
+
+// GenerateManifest renders the manifest built by BuildManifest.
+func (d *ProjectDefinition) GenerateManifest(environment *Fingerprint) ([]byte, error) {
+	manifest, err := d.BuildManifest(environment)
+	if err != nil {
+		return nil, err
 	}
-	data, err := json.MarshalIndent(&manifest, "", "  ")
+	return manifest.Render()
+}
+
+// Render renders the manifest as indented JSON.
+func (m *Manifest) Render() ([]byte, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
 	if err != nil {
 		return nil, fmt.Errorf("failed to write manifest: %w", err)
 	}
@@ -175,22 +530,102 @@ func (d *ProjectDefinition) GenerateManifest() ([]byte, error) {
 }
 
 type Codebase struct {
-	Language     string    `yaml:"language"`
-	Dependencies []string  `yaml:"dependencies,omitempty"`
-	Install      Operation `yaml:"install,omitempty"`
-	Test         Operation `yaml:"test,omitempty"`
-	Build        Operation `yaml:"build,omitempty"`
+	// Name and Path identify a codebase under codebases; its operations
+	// run from Path, relative to the project root.
+	Name         string               `yaml:"name,omitempty"`
+	Path         string               `yaml:"path,omitempty"`
+	Language     string               `yaml:"language,omitempty"`
+	Dependencies []string             `yaml:"dependencies,omitempty"`
+	Install      Operation            `yaml:"install,omitempty"`
+	Test         Operation            `yaml:"test,omitempty"`
+	Build        Operation            `yaml:"build,omitempty"`
+	Operations   map[string]Operation `yaml:"operations,omitempty"`
+}
+
+// builtinOperations lists the operations defined directly on a Codebase.
+var builtinOperations = []string{"install", "test", "build"}
+
+// Lookup returns the built-in or custom operation with the given name.
+func (c *Codebase) Lookup(name string) (Operation, bool) {
+	switch name {
+	case "install":
+		return c.Install, true
+	case "test":
+		return c.Test, true
+	case "build":
+		return c.Build, true
+	}
+	op, ok := c.Operations[name]
+	return op, ok
+}
+
+// OperationNames returns the built-in operation names followed by the
+// custom operation names in alphabetical order.
+func (c *Codebase) OperationNames() []string {
+	custom := make([]string, 0, len(c.Operations))
+	for name := range c.Operations {
+		if !slices.Contains(builtinOperations, name) {
+			custom = append(custom, name)
+		}
+	}
+	sort.Strings(custom)
+	return append(slices.Clone(builtinOperations), custom...)
 }
 
 type Operation struct {
-	FailFast bool              `yaml:"fail_fast,omitempty"`
-	Env      map[string]string `yaml:"env,omitempty"`
-	Steps    []string          `yaml:"steps"`
+	FailFast            bool                `yaml:"fail_fast,omitempty"`
+	InfraRetries        int                 `yaml:"infra_retries,omitempty"`
+	Timeout             time.Duration       `yaml:"timeout,omitempty"`
+	GracePeriod         time.Duration       `yaml:"grace_period,omitempty"`
+	Shell               string              `yaml:"shell,omitempty"`
+	MaxOutput           ByteSize            `yaml:"max_output,omitempty"`
+	SpillOutput         bool                `yaml:"spill_output,omitempty"`
+	Sandbox             bool                `yaml:"sandbox,omitempty"`
+	Remote              bool                `yaml:"remote,omitempty"`
+	ToolPaths           []string            `yaml:"tool_paths,omitempty"`
+	PathPrepend         []string            `yaml:"path_prepend,omitempty"`
+	Artifacts           []string            `yaml:"artifacts,omitempty"`
+	Consumes            []string            `yaml:"consumes,omitempty"`
+	Inputs              Inputs              `yaml:"inputs,omitempty"`
+	Outputs             []string            `yaml:"outputs,omitempty"`
+	OnFailureArtifacts  []string            `yaml:"on_failure_artifacts,omitempty"`
+	When                string              `yaml:"when,omitempty"`
+	Mutating            bool                `yaml:"mutating,omitempty"`
+	Cache               bool                `yaml:"cache,omitempty"`
+	Targets             []string            `yaml:"targets,omitempty"`
+	Package             string              `yaml:"package,omitempty"`
+	Umask               *FileMode           `yaml:"umask,omitempty"`
+	ArtifactPermissions ArtifactPermissions `yaml:"artifact_permissions,omitempty"`
+	Env                 map[string]string   `yaml:"env,omitempty"`
+	EnvFiles            []string            `yaml:"env_files,omitempty"`
+	DependsOn           []string            `yaml:"depends_on,omitempty"`
+	Compose             Compose             `yaml:"compose,omitempty"`
+	Steps               []Step              `yaml:"steps"`
+
+	// Cleanup steps run after the steps whatever their outcome, even when
+	// the operation is cancelled or times out.
+	Cleanup []Step `yaml:"cleanup,omitempty"`
 }
 
 // Run executes the defined steps in the Operation using the provided envs.
-func (op *Operation) Run(ctx context.Context, executor ShellExecutor) error {
+// If a step fails because of the execution environment rather than the
+// step itself, the operation is re-run from the top up to InfraRetries times.
+// A Timeout bounds the whole operation, retries included, but not its
+// cleanup steps. With a compose stack, the steps run once it is healthy
+// and it is always torn down, after the cleanup steps.
+func (op *Operation) Run(ctx context.Context, shellExecutor ShellExecutor) error {
 	logger := logging.FromContext(ctx)
+	if err := op.checkShells(); err != nil {
+		return err
+	}
+	if op.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, op.Timeout)
+		defer cancel()
+	}
+	if op.GracePeriod > 0 {
+		ctx = executor.WithGracePeriod(ctx, op.GracePeriod)
+	}
 
 	env := os.Environ()
 	if len(op.Env) > 0 {
@@ -201,32 +636,276 @@ func (op *Operation) Run(ctx context.Context, executor ShellExecutor) error {
 		}
 		logger.Infof("Loading additional %d additional environment variable(s): %v", len(op.Env), envsAdded)
 	}
-	executor.AddEnv(env)
+	info, _ := runInfoFromContext(ctx)
+	if info.RunID == "" {
+		// Step logs are kept per run, so ad hoc runs get an ID of their own.
+		info.RunID = newRunID("", cmp.Or(info.Operation, "steps"), time.Now())
+		ctx = withRunInfo(ctx, info)
+	}
+	env = append(env, info.env()...)
+	env, err := op.prependPath(env)
+	if err != nil {
+		return err
+	}
+	if op.Compose.File != "" {
+		env = append(env, op.Compose.env(op.Compose.project(info))...)
+	}
+	ctx = executor.WithEnv(ctx, env)
+
+	if op.Cache && len(op.Inputs.Files) == 0 {
+		logger.Warn("Caching is enabled but no input files are declared, running every step")
+	}
+
+	var sb *sandbox
+	if op.Sandbox {
+		if sb, err = newSandbox(op); err != nil {
+			return err
+		}
+		sb.env = append(sb.env, info.env()...)
+		defer sb.cleanup()
+		logger.Infof("Running steps in sandbox (HOME=%s)", sb.home)
+	}
+
+	if op.Compose.File != "" {
+		var down func()
+		down, err = op.Compose.up(ctx, op.Compose.project(info), shellExecutor)
+		defer down()
+	}
+
+	if err == nil {
+		err = op.runAttempts(ctx, shellExecutor, env, sb)
+	}
+	if len(op.Cleanup) > 0 {
+		err = errors.Join(err, op.runCleanup(ctx, shellExecutor, env, sb, err == nil))
+	}
+	return err
+}
+
+// runAttempts runs the steps, re-running them after infrastructure
+// failures.
+func (op *Operation) runAttempts(ctx context.Context, shellExecutor ShellExecutor, env []string, sb *sandbox) error {
+	logger := logging.FromContext(ctx)
+	for attempt := 0; ; attempt++ {
+		err := op.runSteps(ctx, shellExecutor, env, sb)
+		if op.Timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("operation timed out after %s: %w", op.Timeout, err)
+		}
+		if err == nil || !executor.IsInfraError(err) || attempt >= op.InfraRetries {
+			return err
+		}
+		RunSummaryFromContext(ctx).retry(err)
+		logger.WithFields(logrus.Fields{
+			"attempt": attempt + 1,
+			"retries": op.InfraRetries,
+		}).Warnf("Infrastructure failure, re-running operation: %v", err)
+	}
+}
+
+// cleanupTimeout bounds the cleanup steps of an operation, which run even
+// after the operation is cancelled.
+const cleanupTimeout = 5 * time.Minute
+
+// runCleanup runs every cleanup step, on a context that is not cancelled
+// with the operation, and reports those that failed.
+func (op *Operation) runCleanup(ctx context.Context, shellExecutor ShellExecutor, env []string, sb *sandbox, succeeded bool) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+	defer cancel()
+	w := textOutput(ctx)
+	status := "failure"
+	if succeeded {
+		status = "success"
+	}
+	var failed []string
+	for idx, step := range op.Cleanup {
+		shouldRun, err := evaluateWhen(step.When, op.Env, step.Env, map[string]string{EnvOperationStatus: status})
+		if err != nil {
+			return fmt.Errorf("cleanup step '%s': %w", step.Label(), err)
+		}
+		if !shouldRun {
+			continue
+		}
+		step.Env = maps.Clone(step.Env)
+		if step.Env == nil {
+			step.Env = map[string]string{}
+		}
+		step.Env[EnvOperationStatus] = status
+		start := time.Now()
+		_, _ = fmt.Fprintf(w, "[cleanup %d] %s %s\n", idx+1, outputs.Timestamp(ctx, start), step.Label())
+		result, err := op.runStep(ctx, shellExecutor, idx, step, env, sb)
+		printOutput(w, result)
+		if (err != nil || result.ExitCode != 0) && !step.AllowFailure {
+			failed = append(failed, step.Label())
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to run cleanup steps: %v", failed)
+	}
+	return nil
+}
 
+func (op *Operation) runSteps(ctx context.Context, shellExecutor ShellExecutor, env []string, sb *sandbox) error {
+	logger := logging.FromContext(ctx)
+	w := textOutput(ctx)
 	var failedSteps []string
+	steps := []StepResult{}
+	defer func() {
+		printStepTimings(w, steps)
+		RunSummaryFromContext(ctx).setSteps(steps)
+	}()
+	only := -1
+	info, _ := runInfoFromContext(ctx)
+	if selector, ok := selectedStep(ctx, info.Operation); ok {
+		index, err := op.stepIndex(selector)
+		if err != nil {
+			return err
+		}
+		only = index
+	}
+	// Cached results are only trusted while the outputs they produced exist.
+	outputsPresent := len(missingPaths(op.Outputs)) == 0
 	for idx, step := range op.Steps {
-		fmt.Printf("[%d] %s\n", idx+1, step)
-		result, err := executor.Exec(ctx, step)
-		if err != nil || result.ExitCode != 0 {
-			if op.FailFast {
-				return fmt.Errorf("error while running '%s' (exit code %d): %w", step, result.ExitCode, err)
+		if only >= 0 && idx != only {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		shouldRun, err := evaluateWhen(step.When, op.Env, step.Env)
+		if err != nil {
+			return fmt.Errorf("step '%s': %w", step.Label(), err)
+		}
+		if !shouldRun {
+			_, _ = fmt.Fprintf(w, "[%d] %s skipped, condition '%s' is not met\n", idx+1, step.Label(), step.When)
+			steps = append(steps, StepResult{Name: step.Label(), Status: "skipped"})
+			continue
+		}
+		fingerprint := ""
+		if op.cacheable(ctx) {
+			if fingerprint, err = op.stepFingerprint(info, idx, step); err != nil {
+				logger.Warnf("Failed to fingerprint step '%s', running it uncached: %v", step.Label(), err)
+			} else if outputsPresent && cacheHit(ctx, fingerprint) {
+				_, _ = fmt.Fprintf(w, "[%d] %s cached, inputs unchanged\n", idx+1, step.Label())
+				steps = append(steps, StepResult{Name: step.Label(), Status: "cached"})
+				continue
 			}
-			failedSteps = append(failedSteps, step)
 		}
-		if result.Stdout != "" {
-			_, _ = fmt.Fprintf(os.Stdout, "%s\n", result.Stdout)
+		start := time.Now()
+		_, _ = fmt.Fprintf(w, "[%d] %s %s\n", idx+1, outputs.Timestamp(ctx, start), step.Label())
+		result, err := op.runStep(ctx, shellExecutor, idx, step, env, sb)
+		stepResult := StepResult{Name: step.Label(), ExitCode: result.ExitCode, Duration: result.Duration, Status: "ok"}
+		if stepResult.Duration == 0 {
+			stepResult.Duration = time.Since(start)
+		}
+		if ctx.Err() != nil {
+			steps = append(steps, stepResult.withStatus("interrupted"))
+			printOutput(w, result)
+			logger.Warnf("Step '%s' was interrupted after %s", step.Label(), stepResult.Duration.Round(time.Millisecond))
+			return fmt.Errorf("step '%s' was interrupted: %w", step.Label(), ctx.Err())
 		}
-		if result.Stderr != "" {
-			_, _ = fmt.Fprintf(os.Stderr, "%s\n", result.Stderr)
+		if executor.IsInfraError(err) {
+			steps = append(steps, stepResult.withStatus("infra error"))
+			return fmt.Errorf("infrastructure failure while running '%s': %w", step.Label(), err)
+		}
+		if err != nil || result.ExitCode != 0 {
+			stepResult.Stderr = result.Stderr
+			switch {
+			case step.AllowFailure:
+				stepResult.Status = "failed (allowed)"
+				logger.Warnf("Step '%s' failed (exit code %d), continuing since failure is allowed", step.Label(), result.ExitCode)
+			case op.FailFast:
+				steps = append(steps, stepResult.withStatus("failed"))
+				return fmt.Errorf("error while running '%s' (exit code %d): %w", step.Label(), result.ExitCode, err)
+			default:
+				stepResult.Status = "failed"
+				failedSteps = append(failedSteps, step.Label())
+			}
+		}
+		if fingerprint != "" && stepResult.Status == "ok" {
+			if err := storeCacheEntry(ctx, fingerprint); err != nil {
+				logger.Warnf("Failed to record cache entry for step '%s': %v", step.Label(), err)
+			}
 		}
+		printOutput(w, result)
+		_, _ = fmt.Fprintf(w, "[%d] %s finished in %s\n", idx+1, outputs.Timestamp(ctx, start.Add(stepResult.Duration)), stepResult.Duration.Round(time.Millisecond))
+		steps = append(steps, stepResult)
 	}
-	outputs.PrintTerminalWideLine("=")
+	outputs.PrintTerminalWideLineTo(w, "=")
 	if len(failedSteps) > 0 {
 		return fmt.Errorf("failed to run steps: %v", failedSteps)
 	}
 	return nil
 }
 
+// printOutput writes a step's output, keeping the original order of stdout
+// and stderr lines when it was captured. Stdout lines go to w.
+func printOutput(w io.Writer, result executor.Result) {
+	if result.Terminal {
+		// Already shown as it was written.
+		return
+	}
+	if len(result.Output) > 0 {
+		for _, line := range result.Output {
+			out := w
+			if line.Stream == executor.StreamStderr {
+				out = os.Stderr
+			}
+			_, _ = fmt.Fprintln(out, line.Text)
+		}
+		return
+	}
+	if result.Stdout != "" {
+		_, _ = fmt.Fprintf(w, "%s\n", result.Stdout)
+	}
+	if result.Stderr != "" {
+		_, _ = fmt.Fprintf(os.Stderr, "%s\n", result.Stderr)
+	}
+}
+
+// runStep executes a single step, applying its own env and timeout on top
+// of the operation's, plus DEVOPS_STEP_INDEX. File templates are rendered
+// just before the step runs, so they see files written by earlier steps.
+func (op *Operation) runStep(ctx context.Context, shellExecutor ShellExecutor, index int, step Step, env []string, sb *sandbox) (executor.Result, error) {
+	stepCtx := ctx
+	if step.Timeout > 0 {
+		var cancel context.CancelFunc
+		stepCtx, cancel = context.WithTimeout(ctx, step.Timeout)
+		defer cancel()
+	}
+
+	command, err := renderFiles(step.Run, os.DirFS("."))
+	if err != nil {
+		return executor.Result{}, err
+	}
+	command = op.applyUmask(command)
+	step.Run = command
+	step.Env = maps.Clone(step.Env)
+	if step.Env == nil {
+		step.Env = map[string]string{}
+	}
+	step.Env[EnvStepIndex] = strconv.Itoa(index + 1)
+	step.Shell = cmp.Or(step.Shell, op.Shell)
+	if sb != nil {
+		if command, err = sb.wrap(step); err != nil {
+			return executor.Result{}, err
+		}
+		stepCtx = executor.WithEnv(stepCtx, sb.environ(step))
+	} else {
+		stepCtx = executor.WithEnv(stepCtx, append(slices.Clone(env), envPairs(step.Env)...))
+		stepCtx = executor.WithShell(stepCtx, step.Shell)
+	}
+	if step.PTY {
+		stepCtx = executor.WithTerminal(stepCtx, executor.Terminal{In: os.Stdin, Out: textOutput(ctx)})
+	}
+	stepCtx, finishOutput := op.limitOutput(ctx, stepCtx, index, step)
+
+	result, err := shellExecutor.Exec(stepCtx, command)
+	finishOutput(result)
+	if step.Timeout > 0 && ctx.Err() == nil && errors.Is(stepCtx.Err(), context.DeadlineExceeded) {
+		return result, fmt.Errorf("timed out after %s: %w", step.Timeout, stepCtx.Err())
+	}
+	return result, err
+}
+
 // validateProjectName validates that the project ID meets the specified criteria:
 // - Contains only alphanumeric characters, dashes, and underscores
 // - Starts with a letter
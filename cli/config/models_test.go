@@ -7,12 +7,14 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jgfranco17/dev-tooling-go/logging"
 	"github.com/jgfranco17/devops/cli/executor"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockShellExecutor is a mock implementation of ShellExecutor
@@ -25,8 +27,22 @@ func (m *MockShellExecutor) Exec(ctx context.Context, command string) (executor.
 	return args.Get(0).(executor.Result), args.Error(1)
 }
 
-func (m *MockShellExecutor) AddEnv(env []string) {
-	m.Called(env)
+// withEnv matches a context whose environment satisfies match.
+func withEnv(match func(env []string) bool) any {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		return match(executor.EnvFromContext(ctx))
+	})
+}
+
+// envValue returns the effective value of key in env, the last one set.
+func envValue(env []string, key string) string {
+	value := ""
+	for _, kv := range env {
+		if v, ok := strings.CutPrefix(kv, key+"="); ok {
+			value = v
+		}
+	}
+	return value
 }
 
 func TestProjectDefinition_Test(t *testing.T) {
@@ -43,12 +59,11 @@ func TestProjectDefinition_Test(t *testing.T) {
 				ID: "test-project",
 				Codebase: Codebase{
 					Test: Operation{
-						Steps: []string{"go test ./...", "go test -race ./..."},
+						Steps: StepsFromCommands("go test ./...", "go test -race ./..."),
 					},
 				},
 			},
 			mockSetup: func(m *MockShellExecutor) {
-				m.On("AddEnv", mock.AnythingOfType("[]string")).Return()
 				m.On("Exec", mock.Anything, "go test ./...").Return(executor.Result{ExitCode: 0, Stdout: "PASS"}, nil)
 				m.On("Exec", mock.Anything, "go test -race ./...").Return(executor.Result{ExitCode: 0, Stdout: "PASS"}, nil)
 			},
@@ -59,7 +74,7 @@ func TestProjectDefinition_Test(t *testing.T) {
 				ID: "test-project",
 				Codebase: Codebase{
 					Test: Operation{
-						Steps: []string{},
+						Steps: []Step{},
 					},
 				},
 			},
@@ -74,12 +89,11 @@ func TestProjectDefinition_Test(t *testing.T) {
 				ID: "test-project",
 				Codebase: Codebase{
 					Test: Operation{
-						Steps: []string{"go test ./..."},
+						Steps: StepsFromCommands("go test ./..."),
 					},
 				},
 			},
 			mockSetup: func(m *MockShellExecutor) {
-				m.On("AddEnv", mock.AnythingOfType("[]string")).Return()
 				m.On("Exec", mock.Anything, "go test ./...").Return(executor.Result{ExitCode: 1, Stderr: "test failed"}, nil)
 			},
 			expectedError: "failed to run test steps",
@@ -94,12 +108,12 @@ func TestProjectDefinition_Test(t *testing.T) {
 							"TEST_ENV":    "test_value",
 							"GO111MODULE": "on",
 						},
-						Steps: []string{"go test ./..."},
+						Steps: StepsFromCommands("go test ./..."),
 					},
 				},
 			},
 			mockSetup: func(m *MockShellExecutor) {
-				m.On("AddEnv", mock.MatchedBy(func(env []string) bool {
+				m.On("Exec", withEnv(func(env []string) bool {
 					// Check that our env vars are included
 					envStr := ""
 					for _, e := range env {
@@ -107,8 +121,7 @@ func TestProjectDefinition_Test(t *testing.T) {
 					}
 					return strings.Contains(envStr, "TEST_ENV=test_value") &&
 						strings.Contains(envStr, "GO111MODULE=on")
-				})).Return()
-				m.On("Exec", mock.Anything, "go test ./...").Return(executor.Result{ExitCode: 0, Stdout: "PASS"}, nil)
+				}), "go test ./...").Return(executor.Result{ExitCode: 0, Stdout: "PASS"}, nil)
 			},
 		},
 		{
@@ -118,12 +131,11 @@ func TestProjectDefinition_Test(t *testing.T) {
 				Codebase: Codebase{
 					Test: Operation{
 						FailFast: true,
-						Steps:    []string{"go test ./pkg1", "go test ./pkg2"},
+						Steps:    StepsFromCommands("go test ./pkg1", "go test ./pkg2"),
 					},
 				},
 			},
 			mockSetup: func(m *MockShellExecutor) {
-				m.On("AddEnv", mock.AnythingOfType("[]string")).Return()
 				m.On("Exec", mock.Anything, "go test ./pkg1").Return(executor.Result{ExitCode: 0, Stdout: "PASS"}, nil)
 				m.On("Exec", mock.Anything, "go test ./pkg2").Return(executor.Result{ExitCode: 1, Stderr: "test failed"}, nil)
 			},
@@ -166,12 +178,11 @@ func TestProjectDefinition_Build(t *testing.T) {
 				ID: "test-project",
 				Codebase: Codebase{
 					Build: Operation{
-						Steps: []string{"echo hello", "echo world"},
+						Steps: StepsFromCommands("echo hello", "echo world"),
 					},
 				},
 			},
 			mockSetup: func(m *MockShellExecutor) {
-				m.On("AddEnv", mock.AnythingOfType("[]string")).Return()
 				m.On("Exec", mock.Anything, "echo hello").Return(executor.Result{ExitCode: 0, Stdout: "hello"}, nil)
 				m.On("Exec", mock.Anything, "echo world").Return(executor.Result{ExitCode: 0, Stdout: "world"}, nil)
 			},
@@ -182,7 +193,7 @@ func TestProjectDefinition_Build(t *testing.T) {
 				ID: "test-project",
 				Codebase: Codebase{
 					Build: Operation{
-						Steps: []string{},
+						Steps: []Step{},
 					},
 				},
 			},
@@ -195,12 +206,11 @@ func TestProjectDefinition_Build(t *testing.T) {
 				ID: "test-project",
 				Codebase: Codebase{
 					Build: Operation{
-						Steps: []string{"false"},
+						Steps: StepsFromCommands("false"),
 					},
 				},
 			},
 			mockSetup: func(m *MockShellExecutor) {
-				m.On("AddEnv", mock.AnythingOfType("[]string")).Return()
 				m.On("Exec", mock.Anything, "false").Return(executor.Result{ExitCode: 1, Stderr: "command failed"}, nil)
 			},
 			expectedError: "failed to run steps",
@@ -320,10 +330,9 @@ func TestOperation_Run(t *testing.T) {
 		{
 			name: "successful execution",
 			operation: Operation{
-				Steps: []string{"echo hello", "echo world"},
+				Steps: StepsFromCommands("echo hello", "echo world"),
 			},
 			mockSetup: func(m *MockShellExecutor) {
-				m.On("AddEnv", mock.AnythingOfType("[]string")).Return()
 				m.On("Exec", mock.Anything, "echo hello").Return(executor.Result{ExitCode: 0, Stdout: "hello"}, nil)
 				m.On("Exec", mock.Anything, "echo world").Return(executor.Result{ExitCode: 0, Stdout: "world"}, nil)
 			},
@@ -335,26 +344,24 @@ func TestOperation_Run(t *testing.T) {
 					"TEST_VAR": "test_value",
 					"ANOTHER":  "value",
 				},
-				Steps: []string{"echo $TEST_VAR"},
+				Steps: StepsFromCommands("echo $TEST_VAR"),
 			},
 			mockSetup: func(m *MockShellExecutor) {
-				m.On("AddEnv", mock.MatchedBy(func(env []string) bool {
+				m.On("Exec", withEnv(func(env []string) bool {
 					// Check that our env vars are included
 					envStr := strings.Join(env, " ")
 					return strings.Contains(envStr, "TEST_VAR=test_value") &&
 						strings.Contains(envStr, "ANOTHER=value")
-				})).Return()
-				m.On("Exec", mock.Anything, "echo $TEST_VAR").Return(executor.Result{ExitCode: 0, Stdout: "test_value"}, nil)
+				}), "echo $TEST_VAR").Return(executor.Result{ExitCode: 0, Stdout: "test_value"}, nil)
 			},
 		},
 		{
 			name: "fail fast on error",
 			operation: Operation{
 				FailFast: true,
-				Steps:    []string{"echo hello", "false", "echo world"},
+				Steps:    StepsFromCommands("echo hello", "false", "echo world"),
 			},
 			mockSetup: func(m *MockShellExecutor) {
-				m.On("AddEnv", mock.AnythingOfType("[]string")).Return()
 				m.On("Exec", mock.Anything, "echo hello").Return(executor.Result{ExitCode: 0, Stdout: "hello"}, nil)
 				m.On("Exec", mock.Anything, "false").Return(executor.Result{ExitCode: 1, Stderr: "command failed"}, nil)
 			},
@@ -364,10 +371,9 @@ func TestOperation_Run(t *testing.T) {
 			name: "collect failed steps when not fail fast",
 			operation: Operation{
 				FailFast: false,
-				Steps:    []string{"echo hello", "false", "echo world", "invalid_command"},
+				Steps:    StepsFromCommands("echo hello", "false", "echo world", "invalid_command"),
 			},
 			mockSetup: func(m *MockShellExecutor) {
-				m.On("AddEnv", mock.AnythingOfType("[]string")).Return()
 				m.On("Exec", mock.Anything, "echo hello").Return(executor.Result{ExitCode: 0, Stdout: "hello"}, nil)
 				m.On("Exec", mock.Anything, "false").Return(executor.Result{ExitCode: 1, Stderr: "command failed"}, nil)
 				m.On("Exec", mock.Anything, "echo world").Return(executor.Result{ExitCode: 0, Stdout: "world"}, nil)
@@ -378,10 +384,9 @@ func TestOperation_Run(t *testing.T) {
 		{
 			name: "execution error",
 			operation: Operation{
-				Steps: []string{"echo hello"},
+				Steps: StepsFromCommands("echo hello"),
 			},
 			mockSetup: func(m *MockShellExecutor) {
-				m.On("AddEnv", mock.AnythingOfType("[]string")).Return()
 				m.On("Exec", mock.Anything, "echo hello").Return(executor.Result{}, errors.New("execution failed"))
 			},
 			expectedError: "failed to run steps",
@@ -389,10 +394,9 @@ func TestOperation_Run(t *testing.T) {
 		{
 			name: "empty steps",
 			operation: Operation{
-				Steps: []string{},
+				Steps: []Step{},
 			},
 			mockSetup: func(m *MockShellExecutor) {
-				m.On("AddEnv", mock.AnythingOfType("[]string")).Return()
 			},
 		},
 	}
@@ -420,7 +424,6 @@ func TestOperation_Run(t *testing.T) {
 
 func TestOperation_Run_OutputHandling(t *testing.T) {
 	mockExecutor := &MockShellExecutor{}
-	mockExecutor.On("AddEnv", mock.AnythingOfType("[]string")).Return()
 	mockExecutor.On("Exec", mock.Anything, "test_command").Return(
 		executor.Result{
 			ExitCode: 0,
@@ -429,7 +432,7 @@ func TestOperation_Run_OutputHandling(t *testing.T) {
 		}, nil)
 
 	operation := Operation{
-		Steps: []string{"test_command"},
+		Steps: StepsFromCommands("test_command"),
 	}
 
 	logger := logging.New(os.Stderr, logrus.InfoLevel)
@@ -459,13 +462,13 @@ func TestProjectDefinition_Validate(t *testing.T) {
 					Language:     "go",
 					Dependencies: []string{"github.com/stretchr/testify"},
 					Install: Operation{
-						Steps: []string{"go mod download"},
+						Steps: StepsFromCommands("go mod download"),
 					},
 					Test: Operation{
-						Steps: []string{"go test ./..."},
+						Steps: StepsFromCommands("go test ./..."),
 					},
 					Build: Operation{
-						Steps: []string{"go build ./..."},
+						Steps: StepsFromCommands("go build ./..."),
 					},
 				},
 			},
@@ -484,10 +487,10 @@ func TestProjectDefinition_Validate(t *testing.T) {
 				RepoUrl: "https://github.com/test/project",
 				Codebase: Codebase{
 					Test: Operation{
-						Steps: []string{"go test ./..."},
+						Steps: StepsFromCommands("go test ./..."),
 					},
 					Build: Operation{
-						Steps: []string{"go build ./..."},
+						Steps: StepsFromCommands("go build ./..."),
 					},
 				},
 			},
@@ -506,10 +509,10 @@ func TestProjectDefinition_Validate(t *testing.T) {
 				Codebase: Codebase{
 					Language: "",
 					Test: Operation{
-						Steps: []string{"go test ./..."},
+						Steps: StepsFromCommands("go test ./..."),
 					},
 					Build: Operation{
-						Steps: []string{"go build ./..."},
+						Steps: StepsFromCommands("go build ./..."),
 					},
 				},
 			},
@@ -526,10 +529,10 @@ func TestProjectDefinition_Validate(t *testing.T) {
 				Codebase: Codebase{
 					Language: "go",
 					Test: Operation{
-						Steps: []string{"go test ./..."},
+						Steps: StepsFromCommands("go test ./..."),
 					},
 					Build: Operation{
-						Steps: []string{"go build ./..."},
+						Steps: StepsFromCommands("go build ./..."),
 					},
 				},
 			},
@@ -550,7 +553,7 @@ func TestProjectDefinition_Validate(t *testing.T) {
 					Language:     "go",
 					Dependencies: []string{"github.com/stretchr/testify"},
 					Build: Operation{
-						Steps: []string{"go build ./..."},
+						Steps: StepsFromCommands("go build ./..."),
 					},
 				},
 			},
@@ -573,7 +576,7 @@ func TestProjectDefinition_Validate(t *testing.T) {
 					Language:     "go",
 					Dependencies: []string{"github.com/stretchr/testify"},
 					Test: Operation{
-						Steps: []string{"go test ./..."},
+						Steps: StepsFromCommands("go test ./..."),
 					},
 				},
 			},
@@ -596,10 +599,10 @@ func TestProjectDefinition_Validate(t *testing.T) {
 					Language:     "go",
 					Dependencies: []string{"github.com/stretchr/testify"},
 					Test: Operation{
-						Steps: []string{"go test ./..."},
+						Steps: StepsFromCommands("go test ./..."),
 					},
 					Build: Operation{
-						Steps: []string{"go build ./..."},
+						Steps: StepsFromCommands("go build ./..."),
 					},
 				},
 			},
@@ -657,10 +660,10 @@ func TestProjectDefinition_Validate(t *testing.T) {
 					Language:     "go",
 					Dependencies: nil,
 					Test: Operation{
-						Steps: []string{"go test ./..."},
+						Steps: StepsFromCommands("go test ./..."),
 					},
 					Build: Operation{
-						Steps: []string{"go build ./..."},
+						Steps: StepsFromCommands("go build ./..."),
 					},
 				},
 			},
@@ -1009,3 +1012,486 @@ func TestProjectDefinition_Validate_EdgeCases(t *testing.T) {
 		assert.Contains(t, output, "Dependencies:")
 	})
 }
+
+func TestOperation_Run_InfraRetries(t *testing.T) {
+	infraErr := &executor.InfraError{Reason: "failed to start shell", Err: errors.New("fork/exec: resource temporarily unavailable")}
+
+	tests := []struct {
+		name          string
+		operation     Operation
+		mockSetup     func(*MockShellExecutor)
+		expectedError string
+	}{
+		{
+			name: "infra failure retried then succeeds",
+			operation: Operation{
+				InfraRetries: 2,
+				Steps:        StepsFromCommands("echo hello", "make"),
+			},
+			mockSetup: func(m *MockShellExecutor) {
+				m.On("Exec", mock.Anything, "echo hello").Return(executor.Result{Stdout: "hello"}, nil).Twice()
+				m.On("Exec", mock.Anything, "make").Return(executor.Result{ExitCode: -1}, infraErr).Once()
+				m.On("Exec", mock.Anything, "make").Return(executor.Result{}, nil).Once()
+			},
+		},
+		{
+			name: "infra failure exhausts retries",
+			operation: Operation{
+				InfraRetries: 1,
+				Steps:        StepsFromCommands("make"),
+			},
+			mockSetup: func(m *MockShellExecutor) {
+				m.On("Exec", mock.Anything, "make").Return(executor.Result{ExitCode: -1}, infraErr).Twice()
+			},
+			expectedError: "infrastructure failure while running 'make'",
+		},
+		{
+			name: "infra failure without retries stops immediately",
+			operation: Operation{
+				Steps: StepsFromCommands("make", "echo never"),
+			},
+			mockSetup: func(m *MockShellExecutor) {
+				m.On("Exec", mock.Anything, "make").Return(executor.Result{ExitCode: -1}, infraErr).Once()
+			},
+			expectedError: "infrastructure failure",
+		},
+		{
+			name: "step failures are not retried",
+			operation: Operation{
+				FailFast:     true,
+				InfraRetries: 3,
+				Steps:        StepsFromCommands("false"),
+			},
+			mockSetup: func(m *MockShellExecutor) {
+				m.On("Exec", mock.Anything, "false").Return(executor.Result{ExitCode: 1}, errors.New("exit status 1")).Once()
+			},
+			expectedError: "error while running 'false'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExecutor := &MockShellExecutor{}
+			tt.mockSetup(mockExecutor)
+
+			logger := logging.New(os.Stderr, logrus.InfoLevel)
+			ctx := logging.WithContext(context.Background(), logger)
+			err := tt.operation.Run(ctx, mockExecutor)
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockExecutor.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOperation_Run_Cleanup(t *testing.T) {
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	cleanup := []Step{
+		{Run: "docker rm -f fixture"},
+		{Run: "tar czf logs.tgz logs", When: `env.DEVOPS_OPERATION_STATUS == "failure"`},
+	}
+	statusOf := func(args mock.Arguments) string {
+		for _, entry := range executor.EnvFromContext(args.Get(0).(context.Context)) {
+			if value, ok := strings.CutPrefix(entry, EnvOperationStatus+"="); ok {
+				return value
+			}
+		}
+		return ""
+	}
+
+	t.Run("runs after a failed step", func(t *testing.T) {
+		m := &MockShellExecutor{}
+		calls := []string{}
+		recordCalls(m, &calls, "tar czf logs.tgz logs")
+		m.On("Exec", mock.Anything, "make").Return(executor.Result{ExitCode: 2}, errors.New("exit status 2"))
+		m.On("Exec", mock.Anything, "docker rm -f fixture").Run(func(args mock.Arguments) {
+			calls = append(calls, args.String(1))
+			assert.Equal(t, "failure", statusOf(args))
+		}).Return(executor.Result{}, nil)
+
+		op := Operation{FailFast: true, Steps: StepsFromCommands("make"), Cleanup: cleanup}
+		assert.ErrorContains(t, op.Run(ctx, m), "error while running 'make'")
+		assert.Equal(t, []string{"docker rm -f fixture", "tar czf logs.tgz logs"}, calls)
+	})
+
+	t.Run("reports failed cleanup steps", func(t *testing.T) {
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, "make").Return(executor.Result{}, nil)
+		m.On("Exec", mock.Anything, "docker rm -f fixture").Return(executor.Result{ExitCode: 1}, errors.New("exit status 1"))
+
+		op := Operation{Steps: StepsFromCommands("make"), Cleanup: cleanup}
+		assert.EqualError(t, op.Run(ctx, m), "failed to run cleanup steps: [docker rm -f fixture]")
+		m.AssertNotCalled(t, "Exec", mock.Anything, "tar czf logs.tgz logs")
+	})
+
+	t.Run("runs when cancelled", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, "docker rm -f fixture").Run(func(args mock.Arguments) {
+			assert.NoError(t, args.Get(0).(context.Context).Err())
+		}).Return(executor.Result{}, nil)
+		m.On("Exec", mock.Anything, "tar czf logs.tgz logs").Return(executor.Result{}, nil)
+
+		op := Operation{Steps: StepsFromCommands("make"), Cleanup: cleanup}
+		assert.ErrorIs(t, op.Run(cancelled, m), context.Canceled)
+		m.AssertNotCalled(t, "Exec", mock.Anything, "make")
+		m.AssertCalled(t, "Exec", mock.Anything, "docker rm -f fixture")
+	})
+}
+
+func TestLoad_Anchors(t *testing.T) {
+	yamlContent := `id: anchored
+x-go-env: &go-env
+  CGO_ENABLED: "0"
+  GOOS: linux
+x-steps:
+  - &tidy go mod tidy
+codebase:
+  language: go
+  install: &install
+    fail_fast: true
+    env: *go-env
+    steps:
+      - go mod download
+      - *tidy
+  test: *install
+  build:
+    <<: *install
+    env:
+      <<: *go-env
+      GOARCH: amd64
+    steps:
+      - *tidy
+      - go build ./...
+`
+	cfg, err := Load(strings.NewReader(yamlContent))
+	assert.NoError(t, err)
+	if !assert.NotNil(t, cfg) {
+		return
+	}
+
+	install, test, build := cfg.Codebase.Install, cfg.Codebase.Test, cfg.Codebase.Build
+	assert.Equal(t, install, test)
+	assert.True(t, build.FailFast)
+	assert.Equal(t, []string{"go mod tidy", "go build ./..."}, Commands(build.Steps))
+	assert.Equal(t, map[string]string{"CGO_ENABLED": "0", "GOOS": "linux", "GOARCH": "amd64"}, build.Env)
+	assert.Contains(t, cfg.Extensions, "x-go-env")
+
+	// Expanded aliases must not share backing storage.
+	cfg.Codebase.Install.Steps[0].Run = "changed"
+	cfg.Codebase.Install.Env["GOOS"] = "changed"
+	assert.Equal(t, "go mod download", cfg.Codebase.Test.Steps[0].Run)
+	assert.Equal(t, "linux", cfg.Codebase.Test.Env["GOOS"])
+	assert.Equal(t, "linux", cfg.Codebase.Build.Env["GOOS"])
+}
+
+func TestLoad_ExtensionKeys(t *testing.T) {
+	tests := []struct {
+		name        string
+		yamlContent string
+		expectedErr string
+	}{
+		{
+			name: "x- prefixed keys are allowed",
+			yamlContent: `id: test-project
+x-anything:
+  nested: [1, 2]
+`,
+		},
+		{
+			name: "other unknown top-level keys are rejected",
+			yamlContent: `id: test-project
+x-ok: true
+descripton: typo
+`,
+			expectedErr: "unknown field 'descripton' (did you mean 'description'?) at line 3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(strings.NewReader(tt.yamlContent))
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				assert.Nil(t, cfg)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCodebase_Lookup(t *testing.T) {
+	codebase := Codebase{
+		Test: Operation{Steps: StepsFromCommands("go test ./...")},
+		Operations: map[string]Operation{
+			"lint": {Steps: StepsFromCommands("golangci-lint run")},
+			"docs": {Steps: StepsFromCommands("mkdocs build")},
+		},
+	}
+
+	op, ok := codebase.Lookup("test")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"go test ./..."}, Commands(op.Steps))
+
+	op, ok = codebase.Lookup("lint")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"golangci-lint run"}, Commands(op.Steps))
+
+	_, ok = codebase.Lookup("deploy")
+	assert.False(t, ok)
+
+	assert.Equal(t, []string{"install", "test", "build", "docs", "lint"}, codebase.OperationNames())
+}
+
+func TestProjectDefinition_Run(t *testing.T) {
+	tests := []struct {
+		name          string
+		operation     string
+		mockSetup     func(*MockShellExecutor)
+		expectedError string
+	}{
+		{
+			name:      "custom operation",
+			operation: "lint",
+			mockSetup: func(m *MockShellExecutor) {
+				m.On("Exec", mock.Anything, "golangci-lint run").Return(executor.Result{}, nil)
+			},
+		},
+		{
+			name:      "built-in operation",
+			operation: "build",
+			mockSetup: func(m *MockShellExecutor) {
+				m.On("Exec", mock.Anything, "go build ./...").Return(executor.Result{}, nil)
+			},
+		},
+		{
+			name:      "operation without steps",
+			operation: "install",
+			mockSetup: func(m *MockShellExecutor) {},
+		},
+		{
+			name:      "failing operation",
+			operation: "migrate",
+			mockSetup: func(m *MockShellExecutor) {
+				m.On("Exec", mock.Anything, "./migrate.sh").Return(executor.Result{ExitCode: 1}, errors.New("exit status 1"))
+			},
+			expectedError: "failed to run migrate steps",
+		},
+		{
+			name:          "unknown operation with suggestion",
+			operation:     "lnit",
+			mockSetup:     func(m *MockShellExecutor) {},
+			expectedError: "unknown operation 'lnit' (did you mean 'lint'?)",
+		},
+	}
+
+	project := ProjectDefinition{
+		ID: "test-project",
+		Codebase: Codebase{
+			Build: Operation{Steps: StepsFromCommands("go build ./...")},
+			Operations: map[string]Operation{
+				"lint":    {Steps: StepsFromCommands("golangci-lint run")},
+				"migrate": {FailFast: true, Steps: StepsFromCommands("./migrate.sh")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExecutor := &MockShellExecutor{}
+			tt.mockSetup(mockExecutor)
+
+			logger := logging.New(os.Stderr, logrus.InfoLevel)
+			ctx := logging.WithContext(context.Background(), logger)
+			err := project.Run(ctx, tt.operation, mockExecutor)
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockExecutor.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjectDefinition_Report_OperationNames(t *testing.T) {
+	project := ProjectDefinition{
+		Codebase: Codebase{
+			Operations: map[string]Operation{
+				"test": {Steps: StepsFromCommands("make test")},
+				"lint": {Steps: StepsFromCommands("make lint")},
+			},
+		},
+	}
+
+	report := project.Report()
+	var messages []string
+	for _, finding := range report.Findings {
+		if finding.RuleID == RuleOperationNames {
+			messages = append(messages, finding.Message)
+		}
+	}
+	assert.Equal(t, []string{
+		"Custom operation 'test' shadows the built-in operation",
+		"Custom operations (1): lint",
+	}, messages)
+}
+
+func TestOperation_Run_Timeout(t *testing.T) {
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	blockUntilDone := func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}
+
+	t.Run("operation timeout stops remaining steps", func(t *testing.T) {
+		op := Operation{
+			Timeout: 10 * time.Millisecond,
+			Steps:   StepsFromCommands("sleep 5", "echo never"),
+		}
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, "sleep 5").Run(blockUntilDone).Return(executor.Result{ExitCode: -1}, context.DeadlineExceeded)
+
+		err := op.Run(ctx, m)
+		assert.ErrorContains(t, err, "operation timed out after 10ms")
+		m.AssertNotCalled(t, "Exec", mock.Anything, "echo never")
+	})
+
+	t.Run("step timeout inside operation timeout", func(t *testing.T) {
+		op := Operation{
+			Timeout: time.Minute,
+			Steps:   []Step{{Run: "sleep 5", Timeout: 10 * time.Millisecond}, {Run: "echo next"}},
+		}
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, "sleep 5").Run(blockUntilDone).Return(executor.Result{ExitCode: -1}, context.DeadlineExceeded)
+		m.On("Exec", mock.Anything, "echo next").Return(executor.Result{}, nil)
+
+		err := op.Run(ctx, m)
+		assert.ErrorContains(t, err, "failed to run steps: [sleep 5]")
+		assert.NotContains(t, err.Error(), "operation timed out")
+	})
+
+	t.Run("timeout is decoded from the definition", func(t *testing.T) {
+		cfg, err := Load(strings.NewReader("id: timeouts\ncodebase:\n  build:\n    timeout: 15m\n    steps:\n      - make\n"))
+		require.NoError(t, err)
+		assert.Equal(t, 15*time.Minute, cfg.Codebase.Build.Timeout)
+	})
+}
+
+func TestOperation_Run_Shell(t *testing.T) {
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	op := Operation{
+		Shell: "sh",
+		Steps: []Step{{Run: "echo default"}, {Run: "echo direct", Shell: "none"}},
+	}
+	shells := map[string]string{}
+	m := &MockShellExecutor{}
+	m.On("Exec", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		shells[args.String(1)] = executor.ShellFromContext(args.Get(0).(context.Context))
+	}).Return(executor.Result{}, nil)
+
+	require.NoError(t, op.Run(ctx, m))
+	assert.Equal(t, map[string]string{"echo default": "sh", "echo direct": "none"}, shells)
+
+	op = Operation{Steps: []Step{{Run: "echo hi", Shell: "fish"}}}
+	assert.ErrorContains(t, op.Run(ctx, m), "step 'echo hi': unknown shell 'fish'")
+
+	cfg, err := Load(strings.NewReader("id: shells\ncodebase:\n  build:\n    shell: pwsh\n    steps:\n      - run: make\n        shell: none\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "pwsh", cfg.Codebase.Build.Shell)
+	assert.Equal(t, "none", cfg.Codebase.Build.Steps[0].Shell)
+}
+
+func TestProjectDefinition_Exec_OperationShell(t *testing.T) {
+	t.Chdir(t.TempDir())
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := executor.WithShell(logging.WithContext(context.Background(), logger), executor.ShellBash)
+	d := ProjectDefinition{Codebase: Codebase{
+		Operations: map[string]Operation{"release": {Shell: executor.ShellPwsh}},
+	}}
+	shells := map[string]string{}
+	m := &MockShellExecutor{}
+	m.On("Exec", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		shells[args.String(1)] = executor.ShellFromContext(args.Get(0).(context.Context))
+	}).Return(executor.Result{}, nil)
+
+	require.NoError(t, d.Exec(ctx, []string{"echo", "it's"}, "release", m))
+	require.NoError(t, d.Exec(ctx, []string{"echo", "it's"}, "", m))
+	assert.Equal(t, map[string]string{
+		`echo 'it''s'`:   executor.ShellPwsh,
+		`echo 'it'\''s'`: executor.ShellBash,
+	}, shells)
+}
+
+func TestOperation_Run_PTY(t *testing.T) {
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	op := Operation{Steps: []Step{{Run: "npm init", PTY: true}, {Run: "make"}}}
+	attached := map[string]bool{}
+	m := &MockShellExecutor{}
+	m.On("Exec", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		term, ok := executor.TerminalFromContext(args.Get(0).(context.Context))
+		attached[args.String(1)] = ok && term.In == os.Stdin
+	}).Return(executor.Result{}, nil)
+
+	require.NoError(t, op.Run(ctx, m))
+	assert.Equal(t, map[string]bool{"npm init": true, "make": false}, attached)
+
+	var out bytes.Buffer
+	printOutput(&out, executor.Result{Stdout: "shown live\n", Terminal: true})
+	assert.Empty(t, out.String())
+
+	cfg, err := Load(strings.NewReader("id: pty\ncodebase:\n  build:\n    steps:\n      - run: npm init\n        pty: true\n"))
+	require.NoError(t, err)
+	assert.True(t, cfg.Codebase.Build.Steps[0].PTY)
+}
+
+func TestOperation_Run_Interrupted(t *testing.T) {
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx, cancel := context.WithCancel(logging.WithContext(context.Background(), logger))
+	op := Operation{
+		GracePeriod: 3 * time.Second,
+		Steps:       StepsFromCommands("make serve", "echo never"),
+	}
+	m := &MockShellExecutor{}
+	m.On("Exec", mock.Anything, "make serve").Run(func(args mock.Arguments) {
+		assert.Equal(t, 3*time.Second, executor.GracePeriodFromContext(args.Get(0).(context.Context)))
+		cancel()
+	}).Return(executor.Result{ExitCode: -1}, errors.New("signal: terminated"))
+
+	err := op.Run(ctx, m)
+	assert.ErrorContains(t, err, "step 'make serve' was interrupted")
+	assert.ErrorIs(t, err, context.Canceled)
+	m.AssertNotCalled(t, "Exec", mock.Anything, "echo never")
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	yamlContent := `id: defaults
+codebase:
+  language: go
+defaults:
+  test:
+    fail-fast: true
+    tags: [unit, fast]
+`
+	cfg, err := Load(strings.NewReader(yamlContent))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]FlagValue{
+		"test": {
+			"fail-fast": {"true"},
+			"tags":      {"unit", "fast"},
+		},
+	}, cfg.Defaults)
+
+	_, err = Load(strings.NewReader("id: defaults\ndefaults:\n  test:\n    tags: {unit: true}\n"))
+	assert.ErrorContains(t, err, "flag default must be a scalar or a list")
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jgfranco17/devops/internal/objectstore"
+)
+
+// Object store backends.
+const (
+	StoreBackendS3   = "s3"
+	StoreBackendGCS  = "gcs"
+	StoreBackendHTTP = "http"
+)
+
+var storeBackends = []string{StoreBackendS3, StoreBackendGCS, StoreBackendHTTP}
+
+// StoreConfig locates a remote object store.
+type StoreConfig struct {
+	Backend string `yaml:"backend,omitempty"`
+
+	// Bucket and Prefix locate the objects in the s3 and gcs backends.
+	Bucket string `yaml:"bucket,omitempty"`
+	Prefix string `yaml:"prefix,omitempty"`
+
+	// Region and Endpoint configure the s3 backend. Endpoint points it
+	// at an S3 compatible service instead of AWS.
+	Region   string `yaml:"region,omitempty"`
+	Endpoint string `yaml:"endpoint,omitempty"`
+
+	// URL is the base URL of the http backend.
+	URL string `yaml:"url,omitempty"`
+
+	// TokenEnv names the env var holding the bearer token of the gcs and
+	// http backends.
+	TokenEnv string `yaml:"token_env,omitempty"`
+}
+
+// store returns the object store of the config, or nil when no backend is
+// configured. Credentials are looked up with getenv: AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN for s3, the TokenEnv var for
+// the others.
+func (c StoreConfig) store(getenv func(string) string) (objectstore.Store, error) {
+	switch c.Backend {
+	case "":
+		return nil, nil
+	case StoreBackendS3:
+		if c.Bucket == "" {
+			return nil, errors.New("backend s3 needs a bucket")
+		}
+		region := c.Region
+		if region == "" {
+			region = getenv("AWS_REGION")
+		}
+		if region == "" {
+			region = "us-east-1"
+		}
+		return &objectstore.S3Store{
+			Bucket:          c.Bucket,
+			Prefix:          c.Prefix,
+			Region:          region,
+			Endpoint:        c.Endpoint,
+			AccessKeyID:     getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    getenv("AWS_SESSION_TOKEN"),
+			Client:          objectstore.NewClient(),
+		}, nil
+	case StoreBackendGCS:
+		if c.Bucket == "" {
+			return nil, errors.New("backend gcs needs a bucket")
+		}
+		tokenEnv := c.TokenEnv
+		if tokenEnv == "" {
+			tokenEnv = "GOOGLE_OAUTH_ACCESS_TOKEN"
+		}
+		url := "https://storage.googleapis.com/" + c.Bucket
+		if prefix := strings.Trim(c.Prefix, "/"); prefix != "" {
+			url += "/" + prefix
+		}
+		return &objectstore.HTTPStore{URL: url, Token: getenv(tokenEnv), Client: objectstore.NewClient()}, nil
+	case StoreBackendHTTP:
+		if c.URL == "" {
+			return nil, errors.New("backend http needs a url")
+		}
+		token := ""
+		if c.TokenEnv != "" {
+			token = getenv(c.TokenEnv)
+		}
+		return &objectstore.HTTPStore{URL: c.URL, Token: token, Client: objectstore.NewClient()}, nil
+	}
+	msg := fmt.Sprintf("unknown backend '%s'", c.Backend)
+	if suggestion := closestMatch(c.Backend, storeBackends); suggestion != "" {
+		msg += fmt.Sprintf(" (did you mean '%s'?)", suggestion)
+	}
+	return nil, errors.New(msg)
+}
+
+// credential returns the value of a store credential: the secret of that
+// name when the definition declares one, otherwise the env var.
+func (d *ProjectDefinition) credential(name string) string {
+	if secret, ok := d.Secrets[name]; ok {
+		if value, ok, err := secret.resolve(); err == nil && ok {
+			return value
+		}
+	}
+	return os.Getenv(name)
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/jgfranco17/devops/internal/objectstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreConfig_Store(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("CACHE_TOKEN", "token")
+	t.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "oauth")
+
+	store, err := StoreConfig{}.store(os.Getenv)
+	require.NoError(t, err)
+	assert.Nil(t, store)
+
+	store, err = StoreConfig{Backend: "s3", Bucket: "ci-cache"}.store(os.Getenv)
+	require.NoError(t, err)
+	s3, ok := store.(*objectstore.S3Store)
+	require.True(t, ok)
+	assert.Equal(t, "us-east-1", s3.Region)
+	assert.Equal(t, "AKID", s3.AccessKeyID)
+
+	store, err = StoreConfig{Backend: "gcs", Bucket: "ci-cache", Prefix: "/devops/"}.store(os.Getenv)
+	require.NoError(t, err)
+	gcs, ok := store.(*objectstore.HTTPStore)
+	require.True(t, ok)
+	assert.Equal(t, "https://storage.googleapis.com/ci-cache/devops", gcs.URL)
+	assert.Equal(t, "oauth", gcs.Token)
+
+	store, err = StoreConfig{Backend: "http", URL: "https://cache.example.com", TokenEnv: "CACHE_TOKEN"}.store(os.Getenv)
+	require.NoError(t, err)
+	assert.Equal(t, "token", store.(*objectstore.HTTPStore).Token)
+
+	_, err = StoreConfig{Backend: "s3"}.store(os.Getenv)
+	assert.EqualError(t, err, "backend s3 needs a bucket")
+	_, err = StoreConfig{Backend: "http"}.store(os.Getenv)
+	assert.EqualError(t, err, "backend http needs a url")
+	_, err = StoreConfig{Backend: "gcp", Bucket: "ci-cache"}.store(os.Getenv)
+	assert.EqualError(t, err, "unknown backend 'gcp' (did you mean 'gcs'?)")
+}
+
+func TestProjectDefinition_Credential(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile("token", []byte("from-file\n"), 0600))
+	t.Setenv("UPLOAD_TOKEN", "from-env")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+
+	project := ProjectDefinition{Secrets: map[string]Secret{"UPLOAD_TOKEN": {File: "token"}}}
+	assert.Equal(t, "from-file", project.credential("UPLOAD_TOKEN"))
+	assert.Equal(t, "AKID", project.credential("AWS_ACCESS_KEY_ID"))
+
+	project.Secrets["UPLOAD_TOKEN"] = Secret{File: "missing"}
+	assert.Equal(t, "from-env", project.credential("UPLOAD_TOKEN"))
+}
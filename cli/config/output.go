@@ -0,0 +1,48 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Output formats accepted by --output.
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+const outputFormatKey contextKey = "output-format"
+
+// ParseOutputFormat checks that format is a supported output format.
+func ParseOutputFormat(format string) (string, error) {
+	switch format {
+	case OutputText, OutputJSON:
+		return format, nil
+	}
+	return "", fmt.Errorf("unsupported output format '%s' (expected %s or %s)", format, OutputText, OutputJSON)
+}
+
+// WithOutputFormat attaches the output format to the context.
+func WithOutputFormat(ctx context.Context, format string) context.Context {
+	return context.WithValue(ctx, outputFormatKey, format)
+}
+
+// OutputFormatFromContext returns the output format in the context,
+// defaulting to text.
+func OutputFormatFromContext(ctx context.Context) string {
+	if format, ok := ctx.Value(outputFormatKey).(string); ok && format != "" {
+		return format
+	}
+	return OutputText
+}
+
+// textOutput is where human-readable progress is written. With JSON
+// output it moves to stderr so stdout carries only the JSON document.
+func textOutput(ctx context.Context) io.Writer {
+	if OutputFormatFromContext(ctx) == OutputJSON {
+		return os.Stderr
+	}
+	return os.Stdout
+}
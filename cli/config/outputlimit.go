@@ -0,0 +1,90 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"gopkg.in/yaml.v3"
+)
+
+// byteUnits are the suffixes a ByteSize accepts, in binary multiples.
+var byteUnits = map[string]int64{
+	"": 1, "b": 1,
+	"k": 1 << 10, "kb": 1 << 10, "kib": 1 << 10,
+	"m": 1 << 20, "mb": 1 << 20, "mib": 1 << 20,
+	"g": 1 << 30, "gb": 1 << 30, "gib": 1 << 30,
+}
+
+// ByteSize is a size in bytes, written as a number with an optional unit
+// such as 512KB, 10MB or 1GiB. Units are binary multiples.
+type ByteSize int64
+
+// ParseByteSize parses a size such as 4096, 512KB or 10MB.
+func ParseByteSize(text string) (ByteSize, error) {
+	trimmed := strings.TrimSpace(text)
+	digits := strings.TrimRightFunc(trimmed, func(r rune) bool { return r < '0' || r > '9' })
+	unit, ok := byteUnits[strings.ToLower(strings.TrimSpace(trimmed[len(digits):]))]
+	size, err := strconv.ParseInt(digits, 10, 64)
+	if !ok || err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size '%s', expected bytes or a size such as 512KB or 10MB", text)
+	}
+	return ByteSize(size * unit), nil
+}
+
+// UnmarshalYAML parses the size from a number or a string with a unit.
+func (s *ByteSize) UnmarshalYAML(value *yaml.Node) error {
+	size, err := ParseByteSize(value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", value.Line, err)
+	}
+	*s = size
+	return nil
+}
+
+// limitOutput applies the operation's output limit to a step, and opens
+// the log its full output is written to, if a log dir is set on the
+// context or the operation spills its output. The returned func closes the
+// log and warns if the output was truncated.
+func (op *Operation) limitOutput(ctx context.Context, stepCtx context.Context, index int, step Step) (context.Context, func(executor.Result)) {
+	logger := logging.FromContext(ctx)
+	limit := executor.DefaultOutputLimit
+	if op.MaxOutput > 0 {
+		limit = int(op.MaxOutput)
+		stepCtx = executor.WithOutputLimit(stepCtx, limit)
+	}
+
+	dir := LogDirFromContext(ctx)
+	if dir == "" && op.SpillOutput {
+		dir = LogsDir
+	}
+	logPath := ""
+	closeLog := func() {}
+	if dir != "" {
+		info, _ := runInfoFromContext(ctx)
+		path := stepLogPath(workPath(dir), info.RunID, index, step)
+		file, err := createLogFile(path)
+		if err != nil {
+			logger.Warnf("Failed to open output log for step '%s': %v", step.Label(), err)
+		} else {
+			logPath = path
+			closeLog = func() { _ = file.Close() }
+			stepCtx = executor.WithOutputSpill(stepCtx, file)
+		}
+	}
+
+	return stepCtx, func(result executor.Result) {
+		closeLog()
+		if !result.Truncated {
+			return
+		}
+		if logPath != "" {
+			logger.Warnf("Output of step '%s' was truncated to %s, full output is in %s", step.Label(), FormatSize(int64(limit)), logPath)
+			return
+		}
+		logger.Warnf("Output of step '%s' was truncated to %s, set spill_output or --log-dir to keep all of it", step.Label(), FormatSize(int64(limit)))
+	}
+}
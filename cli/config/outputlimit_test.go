@@ -0,0 +1,72 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := map[string]ByteSize{
+		"4096":   4096,
+		"512KB":  512 << 10,
+		"10mb":   10 << 20,
+		"1 GiB":  1 << 30,
+		"64k":    64 << 10,
+		" 12B ":  12,
+		"0":      0,
+		"3MiB":   3 << 20,
+		"100000": 100000,
+	}
+	for text, want := range tests {
+		size, err := ParseByteSize(text)
+		require.NoError(t, err, text)
+		assert.Equal(t, want, size, text)
+	}
+
+	for _, text := range []string{"", "MB", "1.5MB", "10TB", "-1", "ten"} {
+		_, err := ParseByteSize(text)
+		assert.ErrorContains(t, err, "invalid size", text)
+	}
+}
+
+func TestLoad_MaxOutput(t *testing.T) {
+	cfg, err := Load(strings.NewReader("id: limits\ncodebase:\n  test:\n    max_output: 2MB\n    spill_output: true\n    steps:\n      - go test ./...\n"))
+	require.NoError(t, err)
+	assert.Equal(t, ByteSize(2<<20), cfg.Codebase.Test.MaxOutput)
+	assert.True(t, cfg.Codebase.Test.SpillOutput)
+
+	_, err = Load(strings.NewReader("id: limits\ncodebase:\n  test:\n    max_output: lots\n    steps:\n      - go test ./...\n"))
+	assert.ErrorContains(t, err, "invalid size 'lots'")
+}
+
+func TestOperation_Run_SpillOutput(t *testing.T) {
+	t.Chdir(t.TempDir())
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	ctx = withRunInfo(ctx, RunInfo{Operation: "test", RunID: "20260301T100000Z-test"})
+
+	var result executor.Result
+	recorder := executor.Chain(&executor.DefaultExecutor{}, func(next executor.ExecFunc) executor.ExecFunc {
+		return func(ctx context.Context, command string) (executor.Result, error) {
+			r, err := next(ctx, command)
+			result = r
+			return r, err
+		}
+	})
+	op := Operation{MaxOutput: 16, SpillOutput: true, Steps: []Step{{Name: "count", Run: "seq 1 100"}}}
+	require.NoError(t, op.Run(ctx, recorder))
+
+	assert.True(t, result.Truncated)
+	assert.Contains(t, result.Stdout, "bytes truncated")
+	data, err := os.ReadFile(filepath.Join(LogsDir, "20260301T100000Z-test", "01-count.log"))
+	require.NoError(t, err)
+	assert.Equal(t, 100, strings.Count(string(data), "\n"))
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// absPaths resolves dirs against the working directory, so steps that
+// change directory still find them.
+func absPaths(dirs []string) ([]string, error) {
+	paths := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve path %s: %w", dir, err)
+		}
+		paths = append(paths, abs)
+	}
+	return paths, nil
+}
+
+// prependPath returns env with the operation's path_prepend directories
+// placed in front of PATH.
+func (op *Operation) prependPath(env []string) ([]string, error) {
+	if len(op.PathPrepend) == 0 {
+		return env, nil
+	}
+	paths, err := absPaths(op.PathPrepend)
+	if err != nil {
+		return nil, err
+	}
+	current := ""
+	for _, kv := range env {
+		if key, value, ok := strings.Cut(kv, "="); ok && isPathVar(key) {
+			current = value
+		}
+	}
+	if current != "" {
+		paths = append(paths, current)
+	}
+	return append(env, "PATH="+strings.Join(paths, string(os.PathListSeparator))), nil
+}
+
+// isPathVar reports whether key names the search path. Windows spells it
+// Path and matches variable names case-insensitively.
+func isPathVar(key string) bool {
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(key, "PATH")
+	}
+	return key == "PATH"
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperation_PrependPath(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	op := Operation{PathPrepend: []string{"./node_modules/.bin", "bin"}}
+	env, err := op.prependPath([]string{"HOME=/home/dev", "PATH=/usr/bin:/bin"})
+	require.NoError(t, err)
+
+	expected := strings.Join([]string{filepath.Join(dir, "node_modules/.bin"), filepath.Join(dir, "bin"), "/usr/bin:/bin"}, string(os.PathListSeparator))
+	assert.Equal(t, []string{"HOME=/home/dev", "PATH=/usr/bin:/bin", "PATH=" + expected}, env)
+
+	unchanged, err := (&Operation{}).prependPath([]string{"PATH=/usr/bin"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"PATH=/usr/bin"}, unchanged)
+}
+
+func TestOperation_Run_PathPrepend(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+
+	op := Operation{
+		Env:         map[string]string{"PATH": "/opt/tools"},
+		PathPrepend: []string{"bin"},
+		Steps:       StepsFromCommands("app --version"),
+	}
+	m := &MockShellExecutor{}
+	m.On("Exec", withEnv(func(env []string) bool {
+		return envValue(env, "PATH") == filepath.Join(dir, "bin")+string(os.PathListSeparator)+"/opt/tools"
+	}), "app --version").Return(executor.Result{}, nil)
+
+	require.NoError(t, op.Run(ctx, m))
+	m.AssertExpectations(t)
+}
+
+func TestProjectDefinition_RemoteExecutor_PathPrepend(t *testing.T) {
+	d := ProjectDefinition{Remote: Remote{Host: "build.example.com"}}
+	_, err := d.remoteExecutor("deploy", Operation{Remote: true, PathPrepend: []string{"bin"}}, nil)
+	assert.ErrorContains(t, err, "operation deploy cannot use path_prepend when remote")
+}
+
+func TestProjectDefinition_Exec_PathPrepend(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+
+	d := ProjectDefinition{Codebase: Codebase{
+		Operations: map[string]Operation{"lint": {PathPrepend: []string{"node_modules/.bin"}}},
+	}}
+	m := &MockShellExecutor{}
+	m.On("Exec", withEnv(func(env []string) bool {
+		return strings.HasPrefix(envValue(env, "PATH"), filepath.Join(dir, "node_modules/.bin"))
+	}), "eslint .").Return(executor.Result{}, nil)
+
+	require.NoError(t, d.Exec(ctx, []string{"eslint", "."}, "lint", m))
+	m.AssertExpectations(t)
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/jgfranco17/devops/internal/fileutils"
+	"gopkg.in/yaml.v3"
+)
+
+// FileMode is a permission mode written in octal, such as "0755" or 022.
+type FileMode uint32
+
+// UnmarshalYAML parses the mode as octal, whether or not it is quoted.
+func (m *FileMode) UnmarshalYAML(value *yaml.Node) error {
+	digits := strings.TrimPrefix(strings.TrimPrefix(value.Value, "0o"), "0O")
+	mode, err := strconv.ParseUint(digits, 8, 32)
+	if err != nil || mode > 0o777 {
+		return fmt.Errorf("line %d: invalid file mode '%s', expected octal such as 0755", value.Line, value.Value)
+	}
+	*m = FileMode(mode)
+	return nil
+}
+
+// MarshalYAML writes the mode as a quoted octal string.
+func (m FileMode) MarshalYAML() (any, error) {
+	return fmt.Sprintf("%04o", uint32(m)), nil
+}
+
+// ArtifactPermissions are the modes applied to an operation's artifacts
+// once it succeeds, so they are the same however the build was run.
+type ArtifactPermissions struct {
+	File       FileMode `yaml:"file,omitempty"`
+	Executable FileMode `yaml:"executable,omitempty"`
+	Dir        FileMode `yaml:"dir,omitempty"`
+}
+
+func (p ArtifactPermissions) modes() fileutils.Permissions {
+	return fileutils.Permissions{
+		File:       fs.FileMode(p.File),
+		Executable: fs.FileMode(p.Executable),
+		Dir:        fs.FileMode(p.Dir),
+	}
+}
+
+// applyUmask makes command run with the operation's umask, if it sets one.
+func (op *Operation) applyUmask(command string) string {
+	if op.Umask == nil {
+		return command
+	}
+	return fmt.Sprintf("umask %04o; %s", uint32(*op.Umask), command)
+}
+
+// applyArtifactPermissions sets the configured modes on the operation's
+// artifacts.
+func (op *Operation) applyArtifactPermissions() error {
+	modes := op.ArtifactPermissions.modes()
+	if modes.IsZero() {
+		return nil
+	}
+	paths, err := op.MatchArtifacts()
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := fileutils.ApplyPermissions(path, modes); err != nil {
+			return fmt.Errorf("failed to set artifact permissions: %w", err)
+		}
+	}
+	return nil
+}
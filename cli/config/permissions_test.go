@@ -0,0 +1,91 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestLoad_Permissions(t *testing.T) {
+	yamlContent := `id: perms
+codebase:
+  build:
+    umask: 022
+    artifacts: [dist/*]
+    artifact_permissions:
+      file: "0644"
+      executable: 0o755
+    steps:
+      - make
+`
+	cfg, err := Load(strings.NewReader(yamlContent))
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Codebase.Build.Umask)
+	assert.Equal(t, FileMode(0o022), *cfg.Codebase.Build.Umask)
+	assert.Equal(t, ArtifactPermissions{File: 0o644, Executable: 0o755}, cfg.Codebase.Build.ArtifactPermissions)
+
+	_, err = Load(strings.NewReader("id: perms\ncodebase:\n  build:\n    umask: 0999\n"))
+	assert.ErrorContains(t, err, "invalid file mode '0999', expected octal such as 0755")
+
+	data, err := yaml.Marshal(FileMode(0o755))
+	require.NoError(t, err)
+	assert.Equal(t, "\"0755\"\n", string(data))
+}
+
+func TestOperation_Run_Umask(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	umask := FileMode(0o027)
+	op := Operation{Umask: &umask, Steps: StepsFromCommands("make")}
+	m := &MockShellExecutor{}
+	m.On("Exec", mock.Anything, "umask 0027; make").Return(executor.Result{}, nil)
+
+	require.NoError(t, op.Run(ctx, m))
+	m.AssertExpectations(t)
+}
+
+func TestOperation_Run_UmaskNeedsPOSIXShell(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	umask := FileMode(0o027)
+	op := Operation{Umask: &umask, Shell: "pwsh", Steps: StepsFromCommands("make")}
+	m := &MockShellExecutor{}
+
+	assert.ErrorContains(t, op.Run(ctx, m), "step 'make': umask needs a POSIX shell, not pwsh")
+	m.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything)
+}
+
+func TestProjectDefinition_Run_ArtifactPermissions(t *testing.T) {
+	t.Chdir(t.TempDir())
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	project := ProjectDefinition{Codebase: Codebase{Build: Operation{
+		Artifacts:           []string{"dist"},
+		ArtifactPermissions: ArtifactPermissions{File: 0o644, Executable: 0o755, Dir: 0o755},
+		Steps:               StepsFromCommands("make"),
+	}}}
+	m := &MockShellExecutor{}
+	m.On("Exec", mock.Anything, "make").Run(func(mock.Arguments) {
+		require.NoError(t, os.MkdirAll("dist", 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join("dist", "app"), nil, 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join("dist", "app.sha256"), nil, 0o600))
+	}).Return(executor.Result{}, nil)
+
+	require.NoError(t, project.Build(ctx, m))
+	for path, want := range map[string]os.FileMode{
+		"dist":                              0o755,
+		filepath.Join("dist", "app"):        0o755,
+		filepath.Join("dist", "app.sha256"): 0o644,
+	} {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, want, info.Mode().Perm(), path)
+	}
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+)
+
+// PipelineStage places an operation in the pipeline after the operations
+// it needs.
+type PipelineStage struct {
+	Needs []string `yaml:"needs,omitempty"`
+}
+
+// PipelineOrder returns the pipeline operations in dependency order. Ties
+// are broken alphabetically so the order is stable between runs.
+func (d *ProjectDefinition) PipelineOrder() ([]string, error) {
+	if len(d.Pipeline) == 0 {
+		return nil, fmt.Errorf("no pipeline defined")
+	}
+
+	needs := map[string][]string{}
+	for name, stage := range d.Pipeline {
+		if !d.hasOperation(name) {
+			return nil, fmt.Errorf("pipeline stage: %w", d.unknownOperation(name))
+		}
+		for _, need := range stage.Needs {
+			if _, ok := d.Pipeline[need]; !ok {
+				return nil, fmt.Errorf("pipeline stage '%s' needs '%s', which is not in the pipeline", name, need)
+			}
+		}
+		needs[name] = stage.Needs
+	}
+
+	order, cyclic := dependencyOrder(needs)
+	if len(cyclic) > 0 {
+		return nil, fmt.Errorf("pipeline has a dependency cycle between: %s", strings.Join(cyclic, ", "))
+	}
+	return order, nil
+}
+
+// dependencyOrder sorts the keys of needs so each comes after the names
+// it needs, breaking ties alphabetically. Names caught in a cycle are
+// returned separately, sorted. Every needed name must be a key.
+func dependencyOrder(needs map[string][]string) ([]string, []string) {
+	pending := map[string]int{}
+	dependents := map[string][]string{}
+	for name, deps := range needs {
+		pending[name] = len(deps)
+		for _, dep := range deps {
+			dependents[dep] = append(dependents[dep], name)
+		}
+	}
+
+	ready := []string{}
+	for name, count := range pending {
+		if count == 0 {
+			ready = append(ready, name)
+		}
+	}
+	order := []string{}
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		name := ready[0]
+		ready = ready[1:]
+		order = append(order, name)
+		for _, dependent := range dependents[name] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	cyclic := []string{}
+	for name, count := range pending {
+		if count > 0 {
+			cyclic = append(cyclic, name)
+		}
+	}
+	sort.Strings(cyclic)
+	return order, cyclic
+}
+
+// RunPipeline executes the pipeline operations in dependency order,
+// stopping at the first failure.
+func (d *ProjectDefinition) RunPipeline(ctx context.Context, shellExecutor ShellExecutor) error {
+	logger := logging.FromContext(ctx)
+	order, err := d.PipelineOrder()
+	if err != nil {
+		return err
+	}
+	logger.Infof("Pipeline order: %s", strings.Join(order, " -> "))
+	for _, name := range order {
+		if err := d.Run(ctx, name, shellExecutor); err != nil {
+			return fmt.Errorf("pipeline stopped at %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// checkPipeline reports pipelines that cannot be ordered.
+func (d *ProjectDefinition) checkPipeline(b *reportBuilder) {
+	if len(d.Pipeline) == 0 {
+		return
+	}
+	order, err := d.PipelineOrder()
+	if err != nil {
+		b.fail(RulePipeline, "Fix the pipeline stages and their needs", "Invalid pipeline: %s", err.Error())
+		return
+	}
+	b.pass(RulePipeline, "Pipeline: %s", strings.Join(order, " -> "))
+}
+
+// CIStages are the operations devops ci runs, in order. Stages the
+// definition does not declare are skipped.
+var CIStages = []string{"install", "lint", "test", "build"}
+
+// SelectCIStages returns the CI stages to run: only the listed ones when
+// only is set, otherwise every stage except the skipped ones. Stages keep
+// their canonical order.
+func SelectCIStages(only []string, skip []string) ([]string, error) {
+	if len(only) > 0 && len(skip) > 0 {
+		return nil, errors.New("--only and --skip cannot be used together")
+	}
+	for _, name := range append(slices.Clone(only), skip...) {
+		if !slices.Contains(CIStages, name) {
+			msg := fmt.Sprintf("unknown ci stage '%s'", name)
+			if suggestion := closestMatch(name, CIStages); suggestion != "" {
+				msg += fmt.Sprintf(" (did you mean '%s'?)", suggestion)
+			}
+			return nil, errors.New(msg)
+		}
+	}
+	stages := []string{}
+	for _, name := range CIStages {
+		if (len(only) == 0 || slices.Contains(only, name)) && !slices.Contains(skip, name) {
+			stages = append(stages, name)
+		}
+	}
+	return stages, nil
+}
+
+// RunCI runs the given CI stages in order. Stages left out are not run as
+// prerequisites of the others either. A failed stage whose operation is
+// fail_fast stops the run; otherwise the remaining stages still run and
+// the failed stages are reported at the end.
+func (d *ProjectDefinition) RunCI(ctx context.Context, stages []string, shellExecutor ShellExecutor) error {
+	logger := logging.FromContext(ctx)
+	excluded := []string{}
+	for _, name := range CIStages {
+		if !slices.Contains(stages, name) {
+			excluded = append(excluded, name)
+		}
+	}
+	ctx = withExcludedOperations(ctx, excluded)
+	failed := []string{}
+	for _, name := range stages {
+		if !d.hasOperation(name) {
+			logger.Infof("Skipping %s, no such operation defined", name)
+			continue
+		}
+		if err := d.Run(ctx, name, shellExecutor); err != nil {
+			if d.failFast(name) {
+				return fmt.Errorf("ci stopped at %s: %w", name, err)
+			}
+			logger.Errorf("Stage %s failed, continuing: %v", name, err)
+			failed = append(failed, name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("ci stages failed: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// failFast reports whether any codebase marks the operation fail_fast.
+func (d *ProjectDefinition) failFast(name string) bool {
+	for _, view := range d.codebaseViews() {
+		if op, ok := view.Codebase.Lookup(name); ok && op.FailFast {
+			return true
+		}
+	}
+	return false
+}
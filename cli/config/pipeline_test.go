@@ -0,0 +1,218 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newPipelineProject(pipeline map[string]PipelineStage) ProjectDefinition {
+	return ProjectDefinition{
+		ID: "pipeline-project",
+		Codebase: Codebase{
+			Install: Operation{Steps: StepsFromCommands("go mod download")},
+			Test:    Operation{Steps: StepsFromCommands("go test ./...")},
+			Build:   Operation{Steps: StepsFromCommands("go build ./...")},
+			Operations: map[string]Operation{
+				"lint": {Steps: StepsFromCommands("golangci-lint run")},
+			},
+		},
+		Pipeline: pipeline,
+	}
+}
+
+func TestProjectDefinition_PipelineOrder(t *testing.T) {
+	tests := []struct {
+		name          string
+		pipeline      map[string]PipelineStage
+		expectedOrder []string
+		expectedError string
+	}{
+		{
+			name: "dependency order with alphabetical ties",
+			pipeline: map[string]PipelineStage{
+				"build":   {Needs: []string{"lint", "test"}},
+				"test":    {Needs: []string{"install"}},
+				"lint":    {Needs: []string{"install"}},
+				"install": {},
+			},
+			expectedOrder: []string{"install", "lint", "test", "build"},
+		},
+		{
+			name:          "empty pipeline",
+			expectedError: "no pipeline defined",
+		},
+		{
+			name:          "unknown operation",
+			pipeline:      map[string]PipelineStage{"tset": {}},
+			expectedError: "pipeline stage: unknown operation 'tset' (did you mean 'test'?)",
+		},
+		{
+			name:          "need outside the pipeline",
+			pipeline:      map[string]PipelineStage{"test": {Needs: []string{"install"}}},
+			expectedError: "pipeline stage 'test' needs 'install', which is not in the pipeline",
+		},
+		{
+			name: "cycle",
+			pipeline: map[string]PipelineStage{
+				"install": {},
+				"test":    {Needs: []string{"install", "build"}},
+				"build":   {Needs: []string{"test"}},
+			},
+			expectedError: "pipeline has a dependency cycle between: build, test",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := newPipelineProject(tt.pipeline)
+			order, err := project.PipelineOrder()
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedOrder, order)
+		})
+	}
+}
+
+func TestProjectDefinition_RunPipeline(t *testing.T) {
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	project := newPipelineProject(map[string]PipelineStage{
+		"install": {},
+		"test":    {Needs: []string{"install"}},
+		"build":   {Needs: []string{"test"}},
+	})
+
+	t.Run("runs every stage in order", func(t *testing.T) {
+		m := &MockShellExecutor{}
+		calls := []string{}
+		for _, command := range []string{"go mod download", "go test ./...", "go build ./..."} {
+			m.On("Exec", mock.Anything, command).Run(func(args mock.Arguments) {
+				calls = append(calls, args.String(1))
+			}).Return(executor.Result{}, nil)
+		}
+
+		require.NoError(t, project.RunPipeline(ctx, m))
+		assert.Equal(t, []string{"go mod download", "go test ./...", "go build ./..."}, calls)
+	})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, "go mod download").Return(executor.Result{}, nil)
+		m.On("Exec", mock.Anything, "go test ./...").Return(executor.Result{ExitCode: 1}, errors.New("exit status 1"))
+
+		err := project.RunPipeline(ctx, m)
+		assert.ErrorContains(t, err, "pipeline stopped at test")
+		m.AssertNotCalled(t, "Exec", mock.Anything, "go build ./...")
+	})
+}
+
+func TestProjectDefinition_Report_Pipeline(t *testing.T) {
+	project := newPipelineProject(map[string]PipelineStage{
+		"install": {},
+		"build":   {Needs: []string{"install"}},
+	})
+	finding, ok := findingFor(project.Report(), RulePipeline)
+	require.True(t, ok)
+	assert.True(t, finding.Passed)
+	assert.Equal(t, "Pipeline: install -> build", finding.Message)
+
+	project.Pipeline["build"] = PipelineStage{Needs: []string{"deploy"}}
+	finding, ok = findingFor(project.Report(), RulePipeline)
+	require.True(t, ok)
+	assert.False(t, finding.Passed)
+	assert.Equal(t, SeverityError, finding.Severity)
+}
+
+func TestProjectDefinition_RunCI(t *testing.T) {
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+
+	t.Run("skips undefined stages", func(t *testing.T) {
+		project := newPipelineProject(nil)
+		delete(project.Codebase.Operations, "lint")
+		m := &MockShellExecutor{}
+		calls := []string{}
+		recordCalls(m, &calls, "go mod download", "go test ./...", "go build ./...")
+
+		require.NoError(t, project.RunCI(ctx, CIStages, m))
+		assert.Equal(t, []string{"go mod download", "go test ./...", "go build ./..."}, calls)
+	})
+
+	t.Run("continues after a failure without fail_fast", func(t *testing.T) {
+		project := newPipelineProject(nil)
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, "go mod download").Return(executor.Result{}, nil)
+		m.On("Exec", mock.Anything, "golangci-lint run").Return(executor.Result{ExitCode: 1}, errors.New("exit status 1"))
+		m.On("Exec", mock.Anything, "go test ./...").Return(executor.Result{ExitCode: 1}, errors.New("exit status 1"))
+		m.On("Exec", mock.Anything, "go build ./...").Return(executor.Result{}, nil)
+
+		err := project.RunCI(ctx, CIStages, m)
+		assert.ErrorContains(t, err, "ci stages failed: lint, test")
+		m.AssertExpectations(t)
+	})
+
+	t.Run("stops at a failed fail_fast stage", func(t *testing.T) {
+		project := newPipelineProject(nil)
+		project.Codebase.Test.FailFast = true
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, "go mod download").Return(executor.Result{}, nil)
+		m.On("Exec", mock.Anything, "golangci-lint run").Return(executor.Result{}, nil)
+		m.On("Exec", mock.Anything, "go test ./...").Return(executor.Result{ExitCode: 1}, errors.New("exit status 1"))
+
+		err := project.RunCI(ctx, CIStages, m)
+		assert.ErrorContains(t, err, "ci stopped at test")
+		m.AssertNotCalled(t, "Exec", mock.Anything, "go build ./...")
+	})
+}
+
+func TestSelectCIStages(t *testing.T) {
+	tests := []struct {
+		name          string
+		only          []string
+		skip          []string
+		expected      []string
+		expectedError string
+	}{
+		{name: "every stage", expected: CIStages},
+		{name: "only keeps canonical order", only: []string{"build", "test"}, expected: []string{"test", "build"}},
+		{name: "skip", skip: []string{"install"}, expected: []string{"lint", "test", "build"}},
+		{name: "unknown stage", skip: []string{"instal"}, expectedError: "unknown ci stage 'instal' (did you mean 'install'?)"},
+		{name: "only and skip", only: []string{"test"}, skip: []string{"lint"}, expectedError: "--only and --skip cannot be used together"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stages, err := SelectCIStages(tt.only, tt.skip)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, stages)
+		})
+	}
+}
+
+func TestProjectDefinition_RunCI_LeftOutPrerequisites(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	project := newPipelineProject(nil)
+	project.Codebase.Test.DependsOn = []string{"install"}
+	m := &MockShellExecutor{}
+	calls := []string{}
+	recordCalls(m, &calls, "go test ./...", "go build ./...")
+
+	require.NoError(t, project.RunCI(ctx, []string{"test", "build"}, m))
+	assert.Equal(t, []string{"go test ./...", "go build ./..."}, calls)
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/jgfranco17/devops/internal/fileutils"
+	"github.com/jgfranco17/devops/internal/hostinfo"
+)
+
+const dockerPingTimeout = 10 * time.Second
+
+// Preflight declares resource checks executed before the first step of an
+// operation, so runs fail early instead of partway through a pipeline. The
+// docker check is always on for operations that need docker.
+type Preflight struct {
+	MinFreeDiskMB uint64   `yaml:"min_free_disk_mb,omitempty"`
+	MinMemoryMB   uint64   `yaml:"min_memory_mb,omitempty"`
+	WritableDirs  []string `yaml:"writable_dirs,omitempty"`
+	Docker        bool     `yaml:"docker,omitempty"`
+}
+
+// PreflightError lists every failed pre-flight check with its remediation.
+type PreflightError struct {
+	Failures []string
+}
+
+func (e *PreflightError) Error() string {
+	return fmt.Sprintf("pre-flight checks failed:\n  - %s", strings.Join(e.Failures, "\n  - "))
+}
+
+// dockerPing checks that the docker daemon is reachable.
+var dockerPing = func(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, dockerPingTimeout)
+	defer cancel()
+	return executor.RunCommand(ctx, exec.CommandContext(ctx, "docker", "info"))
+}
+
+// freeDiskSpace reports the available bytes for the given path.
+var freeDiskSpace = hostinfo.DiskFree
+
+// availableMemory reports the memory of the host available to steps, in
+// bytes.
+var availableMemory = hostinfo.AvailableMemory
+
+// withDocker returns the checks with the docker daemon check turned on if
+// needed is set.
+func (p Preflight) withDocker(needed bool) *Preflight {
+	p.Docker = p.Docker || needed
+	return &p
+}
+
+// Check runs the configured pre-flight checks and returns a PreflightError
+// describing all failures, or nil if every check passed.
+func (p *Preflight) Check(ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+	failures := []string{}
+
+	if p.MinFreeDiskMB > 0 {
+		free, err := freeDiskSpace(".")
+		switch {
+		case err != nil:
+			failures = append(failures, fmt.Sprintf("could not determine free disk space: %s (remedy: lower or remove preflight.min_free_disk_mb)", err))
+		case free/(1024*1024) < p.MinFreeDiskMB:
+			failures = append(failures, fmt.Sprintf("only %d MB of disk space free, %d MB required (remedy: free up disk space or lower preflight.min_free_disk_mb)", free/(1024*1024), p.MinFreeDiskMB))
+		default:
+			logger.Debugf("Pre-flight: %d MB disk space free", free/(1024*1024))
+		}
+	}
+
+	if p.MinMemoryMB > 0 {
+		memory, err := availableMemory()
+		switch {
+		case err != nil:
+			failures = append(failures, fmt.Sprintf("could not determine available memory: %s (remedy: lower or remove preflight.min_memory_mb)", err))
+		case memory/(1024*1024) < p.MinMemoryMB:
+			failures = append(failures, fmt.Sprintf("only %d MB of memory available, %d MB required (remedy: stop other processes, use a larger runner or lower preflight.min_memory_mb)", memory/(1024*1024), p.MinMemoryMB))
+		default:
+			logger.Debugf("Pre-flight: %d MB memory available", memory/(1024*1024))
+		}
+	}
+
+	for _, dir := range p.WritableDirs {
+		if err := fileutils.IsWritableDir(dir); err != nil {
+			failures = append(failures, fmt.Sprintf("directory %s is not writable: %s (remedy: fix its permissions or ownership)", dir, err))
+		}
+	}
+
+	if p.Docker {
+		if err := dockerPing(ctx); err != nil {
+			failures = append(failures, fmt.Sprintf("docker daemon is not reachable: %s (remedy: start docker or check DOCKER_HOST)", err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return &PreflightError{Failures: failures}
+	}
+	return nil
+}
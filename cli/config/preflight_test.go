@@ -0,0 +1,151 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreflight_Check(t *testing.T) {
+	tmpDir := t.TempDir()
+	readOnlyFile := filepath.Join(tmpDir, "not-a-dir")
+	require.NoError(t, os.WriteFile(readOnlyFile, []byte("x"), 0644))
+
+	tests := []struct {
+		name             string
+		preflight        Preflight
+		freeBytes        uint64
+		freeErr          error
+		memoryBytes      uint64
+		memoryErr        error
+		dockerErr        error
+		expectedFailures []string
+	}{
+		{
+			name:      "no checks configured",
+			preflight: Preflight{},
+		},
+		{
+			name:      "enough disk space",
+			preflight: Preflight{MinFreeDiskMB: 100},
+			freeBytes: 200 * 1024 * 1024,
+		},
+		{
+			name:             "not enough disk space",
+			preflight:        Preflight{MinFreeDiskMB: 100},
+			freeBytes:        50 * 1024 * 1024,
+			expectedFailures: []string{"only 50 MB of disk space free, 100 MB required"},
+		},
+		{
+			name:             "disk space lookup fails",
+			preflight:        Preflight{MinFreeDiskMB: 100},
+			freeErr:          errors.New("unsupported"),
+			expectedFailures: []string{"could not determine free disk space"},
+		},
+		{
+			name:        "enough memory",
+			preflight:   Preflight{MinMemoryMB: 4096},
+			memoryBytes: 8192 * 1024 * 1024,
+		},
+		{
+			name:             "not enough memory",
+			preflight:        Preflight{MinMemoryMB: 4096},
+			memoryBytes:      2048 * 1024 * 1024,
+			expectedFailures: []string{"only 2048 MB of memory available, 4096 MB required"},
+		},
+		{
+			name:             "memory lookup fails",
+			preflight:        Preflight{MinMemoryMB: 4096},
+			memoryErr:        errors.New("unsupported"),
+			expectedFailures: []string{"could not determine available memory"},
+		},
+		{
+			name:      "writable directory",
+			preflight: Preflight{WritableDirs: []string{filepath.Join(tmpDir, "cache")}},
+		},
+		{
+			name:             "unwritable directory",
+			preflight:        Preflight{WritableDirs: []string{readOnlyFile}},
+			expectedFailures: []string{"is not writable"},
+		},
+		{
+			name:      "docker reachable",
+			preflight: Preflight{Docker: true},
+		},
+		{
+			name:             "docker unreachable alongside disk failure",
+			preflight:        Preflight{Docker: true, MinFreeDiskMB: 100},
+			dockerErr:        errors.New("connection refused"),
+			expectedFailures: []string{"docker daemon is not reachable", "disk space free"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			originalFree, originalMemory, originalPing := freeDiskSpace, availableMemory, dockerPing
+			defer func() { freeDiskSpace, availableMemory, dockerPing = originalFree, originalMemory, originalPing }()
+			freeDiskSpace = func(string) (uint64, error) { return tt.freeBytes, tt.freeErr }
+			availableMemory = func() (uint64, error) { return tt.memoryBytes, tt.memoryErr }
+			dockerPing = func(context.Context) error { return tt.dockerErr }
+
+			logger := logging.New(os.Stderr, logrus.InfoLevel)
+			ctx := logging.WithContext(context.Background(), logger)
+
+			err := tt.preflight.Check(ctx)
+			if len(tt.expectedFailures) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var preflightErr *PreflightError
+			require.True(t, errors.As(err, &preflightErr))
+			assert.Len(t, preflightErr.Failures, len(tt.expectedFailures))
+			for _, msg := range tt.expectedFailures {
+				assert.Contains(t, err.Error(), msg)
+			}
+			assert.Contains(t, err.Error(), "remedy:")
+		})
+	}
+}
+
+func TestProjectDefinition_Build_PreflightFailure(t *testing.T) {
+	originalFree := freeDiskSpace
+	defer func() { freeDiskSpace = originalFree }()
+	freeDiskSpace = func(string) (uint64, error) { return 0, nil }
+
+	mockExecutor := new(MockShellExecutor)
+	project := ProjectDefinition{
+		ID:        "test-project",
+		Preflight: Preflight{MinFreeDiskMB: 1},
+		Codebase: Codebase{
+			Build: Operation{Steps: StepsFromCommands("go build ./...")},
+		},
+	}
+
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+
+	err := project.Build(ctx, mockExecutor)
+	assert.ErrorContains(t, err, "pre-flight checks failed")
+	mockExecutor.AssertNotCalled(t, "Exec")
+}
+
+func TestProjectDefinition_Run_ComposeChecksDocker(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	stubDockerPing(t, errors.New("Cannot connect to the Docker daemon"))
+	project := ProjectDefinition{ID: "app", Codebase: Codebase{Operations: map[string]Operation{
+		"integration": {Compose: Compose{File: "compose.test.yaml"}, Steps: StepsFromCommands("make it")},
+	}}}
+	m := &MockShellExecutor{}
+
+	err := project.Run(ctx, "integration", m)
+	assert.ErrorContains(t, err, "docker daemon is not reachable: Cannot connect to the Docker daemon")
+	m.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"bytes"
+	"io/fs"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Preset is a ready-made codebase definition for a common project layout.
+type Preset struct {
+	Name     string
+	Markers  []string
+	Codebase Codebase
+}
+
+// Presets lists the built-in presets in detection order. A layout can have
+// several presets, told apart by their markers, e.g. python projects
+// installed from requirements.txt or from their package metadata.
+var Presets = []Preset{
+	{
+		Name:    "go",
+		Markers: []string{"go.mod"},
+		Codebase: Codebase{
+			Language:     "go",
+			Dependencies: []string{"go.mod"},
+			Install:      Operation{Steps: StepsFromCommands("go mod download")},
+			Test:         Operation{FailFast: true, Steps: StepsFromCommands("go test ./...")},
+			Build:        Operation{FailFast: true, Steps: StepsFromCommands("go build ./...")},
+		},
+	},
+	{
+		Name:    "python",
+		Markers: []string{"requirements.txt"},
+		Codebase: Codebase{
+			Language:     "python",
+			Dependencies: []string{"requirements.txt"},
+			Install:      Operation{Steps: StepsFromCommands("pip install -r requirements.txt")},
+			Test:         Operation{FailFast: true, Steps: StepsFromCommands("python -m pytest")},
+			Build:        Operation{FailFast: true, Steps: StepsFromCommands("python -m build")},
+		},
+	},
+	{
+		Name:    "python",
+		Markers: []string{"pyproject.toml"},
+		Codebase: Codebase{
+			Language:     "python",
+			Dependencies: []string{"pyproject.toml"},
+			Install:      Operation{Steps: StepsFromCommands("pip install .")},
+			Test:         Operation{FailFast: true, Steps: StepsFromCommands("python -m pytest")},
+			Build:        Operation{FailFast: true, Steps: StepsFromCommands("python -m build")},
+		},
+	},
+	{
+		Name:    "python",
+		Markers: []string{"setup.py"},
+		Codebase: Codebase{
+			Language:     "python",
+			Dependencies: []string{"setup.py"},
+			Install:      Operation{Steps: StepsFromCommands("pip install .")},
+			Test:         Operation{FailFast: true, Steps: StepsFromCommands("python -m pytest")},
+			Build:        Operation{FailFast: true, Steps: StepsFromCommands("python -m build")},
+		},
+	},
+	{
+		Name:    "node",
+		Markers: []string{"package.json"},
+		Codebase: Codebase{
+			Language:     "javascript",
+			Dependencies: []string{"package.json"},
+			Install:      Operation{Steps: StepsFromCommands("npm ci")},
+			Test:         Operation{FailFast: true, Steps: StepsFromCommands("npm test")},
+			Build:        Operation{FailFast: true, Steps: StepsFromCommands("npm run build")},
+		},
+	},
+	{
+		Name:    "rust",
+		Markers: []string{"Cargo.toml"},
+		Codebase: Codebase{
+			Language:     "rust",
+			Dependencies: []string{"Cargo.toml"},
+			Install:      Operation{Steps: StepsFromCommands("cargo fetch")},
+			Test:         Operation{FailFast: true, Steps: StepsFromCommands("cargo test")},
+			Build:        Operation{FailFast: true, Steps: StepsFromCommands("cargo build --release")},
+		},
+	},
+}
+
+// DetectPreset returns the first preset whose marker files exist in fsys.
+func DetectPreset(fsys fs.FS) (Preset, bool) {
+	for _, preset := range Presets {
+		for _, marker := range preset.Markers {
+			if _, err := fs.Stat(fsys, marker); err == nil {
+				return preset, true
+			}
+		}
+	}
+	return Preset{}, false
+}
+
+// Additions returns the parts of the preset missing from the codebase.
+func (p *Preset) Additions(codebase Codebase) Codebase {
+	additions := Codebase{}
+	if codebase.Language == "" {
+		additions.Language = p.Codebase.Language
+	}
+	if codebase.Dependencies == nil {
+		additions.Dependencies = p.Codebase.Dependencies
+	}
+	if len(codebase.Install.Steps) == 0 {
+		additions.Install = p.Codebase.Install
+	}
+	if len(codebase.Test.Steps) == 0 {
+		additions.Test = p.Codebase.Test
+	}
+	if len(codebase.Build.Steps) == 0 {
+		additions.Build = p.Codebase.Build
+	}
+	return additions
+}
+
+// AdditionsYAML renders the preset additions as a codebase YAML fragment,
+// or returns nil if the codebase already covers everything in the preset.
+func (p *Preset) AdditionsYAML(codebase Codebase) ([]byte, error) {
+	additions := p.Additions(codebase)
+	if additions.Language == "" && additions.Dependencies == nil &&
+		additions.Install.Steps == nil && additions.Test.Steps == nil && additions.Build.Steps == nil {
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(struct {
+		Codebase Codebase `yaml:"codebase"`
+	}{additions}); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectPreset(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		expected string
+		install  string
+		found    bool
+	}{
+		{
+			name:     "go module",
+			files:    fstest.MapFS{"go.mod": {}, "main.go": {}},
+			expected: "go",
+			found:    true,
+		},
+		{
+			name:     "python with pyproject",
+			files:    fstest.MapFS{"pyproject.toml": {}},
+			expected: "python",
+			install:  "pip install .",
+			found:    true,
+		},
+		{
+			name:     "python with setup.py",
+			files:    fstest.MapFS{"setup.py": {}},
+			expected: "python",
+			install:  "pip install .",
+			found:    true,
+		},
+		{
+			name:     "python with requirements",
+			files:    fstest.MapFS{"pyproject.toml": {}, "requirements.txt": {}},
+			expected: "python",
+			install:  "pip install -r requirements.txt",
+			found:    true,
+		},
+		{
+			name:     "node package",
+			files:    fstest.MapFS{"package.json": {}},
+			expected: "node",
+			found:    true,
+		},
+		{
+			name:     "rust crate",
+			files:    fstest.MapFS{"Cargo.toml": {}, "src/main.rs": {}},
+			expected: "rust",
+			found:    true,
+		},
+		{
+			name:  "unknown layout",
+			files: fstest.MapFS{"README.md": {}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preset, found := DetectPreset(tt.files)
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.expected, preset.Name)
+			if tt.install != "" {
+				assert.Equal(t, StepsFromCommands(tt.install), preset.Codebase.Install.Steps)
+			}
+		})
+	}
+}
+
+func TestPreset_Additions(t *testing.T) {
+	preset, found := DetectPreset(fstest.MapFS{"go.mod": {}})
+	require.True(t, found)
+
+	t.Run("empty codebase receives everything", func(t *testing.T) {
+		additions := preset.Additions(Codebase{})
+		assert.Equal(t, preset.Codebase, additions)
+	})
+
+	t.Run("existing fields are kept", func(t *testing.T) {
+		additions := preset.Additions(Codebase{
+			Language: "go",
+			Test:     Operation{Steps: StepsFromCommands("make test")},
+		})
+		assert.Empty(t, additions.Language)
+		assert.Empty(t, additions.Test.Steps)
+		assert.Equal(t, []string{"go mod download"}, Commands(additions.Install.Steps))
+		assert.Equal(t, []string{"go build ./..."}, Commands(additions.Build.Steps))
+	})
+
+	t.Run("yaml fragment", func(t *testing.T) {
+		data, err := preset.AdditionsYAML(Codebase{
+			Language:     "go",
+			Dependencies: []string{"go.mod"},
+			Install:      Operation{Steps: StepsFromCommands("go mod download")},
+			Test:         Operation{Steps: StepsFromCommands("go test ./...")},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "codebase:\n  build:\n    fail_fast: true\n    steps:\n      - go build ./...\n", string(data))
+	})
+
+	t.Run("nothing to add", func(t *testing.T) {
+		data, err := preset.AdditionsYAML(preset.Codebase)
+		require.NoError(t, err)
+		assert.Nil(t, data)
+	})
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/jgfranco17/devops/internal/fileutils"
+)
+
+const (
+	runRecordFile  = "run.json"
+	runFilesDir    = "files"
+	promotionsFile = "promotions.jsonl"
+)
+
+// Environment is a destination that retained run artifacts can be
+// promoted to.
+type Environment struct {
+	Path string `yaml:"path"`
+}
+
+// RunRecord describes the artifacts retained from a successful operation
+// run, so they can be promoted later without rebuilding.
+type RunRecord struct {
+	ID        string    `json:"id"`
+	Operation string    `json:"operation"`
+	Version   string    `json:"version,omitempty"`
+	Created   time.Time `json:"created"`
+	Artifacts []string  `json:"artifacts"`
+}
+
+// Promotion is the audit record of a run promoted to an environment.
+type Promotion struct {
+	RunID       string    `json:"run_id"`
+	Operation   string    `json:"operation"`
+	Version     string    `json:"version,omitempty"`
+	Environment string    `json:"environment"`
+	Destination string    `json:"destination"`
+	User        string    `json:"user,omitempty"`
+	PromotedAt  time.Time `json:"promoted_at"`
+}
+
+// newRunID identifies the run of an operation started at start, in the
+// named codebase if the definition has several.
+func newRunID(codebase string, name string, start time.Time) string {
+	if codebase != "" {
+		name = codebase + "-" + name
+	}
+	return fmt.Sprintf("%s-%s", start.UTC().Format("20060102T150405Z"), name)
+}
+
+// retainArtifacts copies the artifacts of a successful run into the runs
+// directory under its run ID. It reports false if the run produced no
+// artifacts to retain.
+func (d *ProjectDefinition) retainArtifacts(runID string, name string, op Operation, start time.Time) (bool, error) {
+	matches, err := op.MatchArtifacts()
+	if err != nil || len(matches) == 0 {
+		return false, err
+	}
+	record := RunRecord{
+		ID:        runID,
+		Operation: name,
+		Version:   d.Version,
+		Created:   start.UTC(),
+		Artifacts: matches,
+	}
+	runDir := workPath(filepath.Join(RunsDir, record.ID))
+	if err := stageArtifacts(matches, filepath.Join(runDir, runFilesDir)); err != nil {
+		return false, err
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(filepath.Join(runDir, runRecordFile), data, 0644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LoadRun reads the record of a retained run.
+func LoadRun(id string) (RunRecord, error) {
+	var record RunRecord
+	data, err := os.ReadFile(filepath.Join(RunsDir, filepath.Base(id), runRecordFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return record, fmt.Errorf("run %s not found in %s", id, RunsDir)
+	}
+	if err != nil {
+		return record, err
+	}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return record, fmt.Errorf("invalid record for run %s: %w", id, err)
+	}
+	return record, nil
+}
+
+// Promote copies the artifacts of a retained run to the environment's
+// path, under the name of the operation that produced them, and appends
+// the promotion to the history. Earlier promotions of the same operation
+// to the environment are replaced. Promotion is refused in read-only mode.
+func (d *ProjectDefinition) Promote(ctx context.Context, runID string, environment string, now time.Time) (Promotion, error) {
+	if ReadOnlyFromContext(ctx) {
+		return Promotion{}, errors.New("promotion is not allowed in read-only mode")
+	}
+	env, ok := d.Environments[environment]
+	if !ok {
+		names := make([]string, 0, len(d.Environments))
+		for name := range d.Environments {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		msg := fmt.Sprintf("unknown environment '%s'", environment)
+		if suggestion := closestMatch(environment, names); suggestion != "" {
+			msg += fmt.Sprintf(" (did you mean '%s'?)", suggestion)
+		}
+		return Promotion{}, errors.New(msg)
+	}
+	if env.Path == "" {
+		return Promotion{}, fmt.Errorf("environment '%s' has no path", environment)
+	}
+	record, err := LoadRun(runID)
+	if err != nil {
+		return Promotion{}, err
+	}
+
+	destination := filepath.Join(env.Path, record.Operation)
+	if err := os.RemoveAll(destination); err != nil {
+		return Promotion{}, err
+	}
+	source := filepath.Join(RunsDir, record.ID, runFilesDir)
+	if err := fileutils.CopyDirectory(os.DirFS(source), ".", destination, nil); err != nil {
+		return Promotion{}, fmt.Errorf("failed to copy artifacts of run %s: %w", record.ID, err)
+	}
+
+	promotion := Promotion{
+		RunID:       record.ID,
+		Operation:   record.Operation,
+		Version:     record.Version,
+		Environment: environment,
+		Destination: destination,
+		User:        os.Getenv("USER"),
+		PromotedAt:  now.UTC(),
+	}
+	if err := appendPromotion(promotion); err != nil {
+		return promotion, fmt.Errorf("failed to record promotion: %w", err)
+	}
+	return promotion, nil
+}
+
+// appendPromotion adds the promotion to the history as a JSON line.
+func appendPromotion(promotion Promotion) error {
+	return appendHistory(promotionsFile, promotion)
+}
+
+// appendHistory adds a JSON line to a file of the history directory.
+func appendHistory(name string, entry any) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(workPath(HistoryDir), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(workPath(filepath.Join(HistoryDir, name)), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectDefinition_Run_RetainsArtifacts(t *testing.T) {
+	t.Chdir(t.TempDir())
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	require.NoError(t, os.MkdirAll("dist", 0755))
+	require.NoError(t, os.WriteFile("dist/app", []byte("v1"), 0755))
+	project := ProjectDefinition{
+		Version: "1.2.0",
+		Codebase: Codebase{
+			Build: Operation{Artifacts: []string{"dist/*"}, Steps: StepsFromCommands("make dist")},
+		},
+	}
+	m := &MockShellExecutor{}
+	m.On("Exec", mock.Anything, "make dist").Return(executor.Result{}, nil)
+
+	require.NoError(t, project.Build(ctx, m))
+
+	runs, err := os.ReadDir(RunsDir)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	record, err := LoadRun(runs[0].Name())
+	require.NoError(t, err)
+	assert.Equal(t, "build", record.Operation)
+	assert.Equal(t, "1.2.0", record.Version)
+	assert.Equal(t, []string{"dist/app"}, record.Artifacts)
+	assert.FileExists(t, filepath.Join(RunsDir, record.ID, runFilesDir, "dist", "app"))
+}
+
+func TestProjectDefinition_Promote(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("USER", "ci")
+	require.NoError(t, os.MkdirAll("dist", 0755))
+	require.NoError(t, os.WriteFile("dist/app", []byte("v1"), 0644))
+	project := ProjectDefinition{
+		Version:      "1.2.0",
+		Environments: map[string]Environment{"staging": {Path: "envs/staging"}},
+	}
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	runID := newRunID("", "build", start)
+	assert.Equal(t, "20260501T120000Z-build", runID)
+	retained, err := project.retainArtifacts(runID, "build", Operation{Artifacts: []string{"dist/*"}}, start)
+	require.NoError(t, err)
+	assert.True(t, retained)
+	// A stale promotion is replaced.
+	require.NoError(t, os.MkdirAll("envs/staging/build", 0755))
+	require.NoError(t, os.WriteFile("envs/staging/build/old", []byte("old"), 0644))
+
+	now := start.Add(time.Hour)
+	promotion, err := project.Promote(context.Background(), runID, "staging", now)
+	require.NoError(t, err)
+
+	assert.Equal(t, Promotion{
+		RunID:       runID,
+		Operation:   "build",
+		Version:     "1.2.0",
+		Environment: "staging",
+		Destination: filepath.Join("envs", "staging", "build"),
+		User:        "ci",
+		PromotedAt:  now,
+	}, promotion)
+	data, err := os.ReadFile("envs/staging/build/dist/app")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(data))
+	assert.NoFileExists(t, "envs/staging/build/old")
+
+	f, err := os.Open(filepath.Join(HistoryDir, promotionsFile))
+	require.NoError(t, err)
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	require.True(t, scanner.Scan())
+	var recorded Promotion
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &recorded))
+	assert.Equal(t, promotion, recorded)
+}
+
+func TestProjectDefinition_Promote_Errors(t *testing.T) {
+	t.Chdir(t.TempDir())
+	project := ProjectDefinition{Environments: map[string]Environment{
+		"staging": {Path: "envs/staging"},
+		"empty":   {},
+	}}
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		runID         string
+		environment   string
+		expectedError string
+	}{
+		{
+			name:          "unknown environment",
+			ctx:           context.Background(),
+			runID:         "run",
+			environment:   "stagin",
+			expectedError: "unknown environment 'stagin' (did you mean 'staging'?)",
+		},
+		{
+			name:          "environment without path",
+			ctx:           context.Background(),
+			runID:         "run",
+			environment:   "empty",
+			expectedError: "environment 'empty' has no path",
+		},
+		{
+			name:          "missing run",
+			ctx:           context.Background(),
+			runID:         "20260501T120000Z-build",
+			environment:   "staging",
+			expectedError: "run 20260501T120000Z-build not found",
+		},
+		{
+			name:          "read-only mode",
+			ctx:           WithReadOnly(context.Background()),
+			runID:         "run",
+			environment:   "staging",
+			expectedError: "promotion is not allowed in read-only mode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := project.Promote(tt.ctx, tt.runID, tt.environment, time.Now())
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// sandboxHomePattern matches the temporary HOME directories created for
+// sandboxed operations, which are left behind if devops is killed.
+const sandboxHomePattern = "devops-home-*"
+
+// PrunedPath is a file or directory removed by Prune.
+type PrunedPath struct {
+	Path string
+	Size int64
+}
+
+// PruneResult lists what Prune removed and how much space it reclaimed.
+type PruneResult struct {
+	Removed   []PrunedPath
+	Reclaimed int64
+}
+
+// Prune removes generated files older than the retention settings: the
+// entries of the logs, reports, history, cache, failure artifact and
+// retained run directories, and leftover sandbox workspaces. With dryRun
+// set nothing is deleted but the result lists what would be.
+func Prune(retention Retention, now time.Time, dryRun bool) (PruneResult, error) {
+	result := PruneResult{Removed: []PrunedPath{}}
+	targets := map[string]time.Duration{
+		LogsDir:             retention.orMaxAge(retention.Logs),
+		ReportsDir:          retention.orMaxAge(retention.Reports),
+		HistoryDir:          retention.orMaxAge(retention.History),
+		CacheDir:            retention.orMaxAge(retention.Cache),
+		FailureArtifactsDir: retention.orMaxAge(retention.Failures),
+		RunsDir:             retention.orMaxAge(retention.Runs),
+	}
+	candidates := map[string]time.Duration{}
+	for dir, maxAge := range targets {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return result, fmt.Errorf("failed to read %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			candidates[filepath.Join(dir, entry.Name())] = maxAge
+		}
+	}
+	workspaces, err := filepath.Glob(filepath.Join(os.TempDir(), sandboxHomePattern))
+	if err != nil {
+		return result, err
+	}
+	for _, path := range workspaces {
+		candidates[path] = retention.orMaxAge(retention.Workspaces)
+	}
+
+	paths := make([]string, 0, len(candidates))
+	for path := range candidates {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return result, err
+		}
+		if now.Sub(info.ModTime()) < candidates[path] {
+			continue
+		}
+		size, err := diskUsage(path)
+		if err != nil {
+			return result, err
+		}
+		if !dryRun {
+			if err := os.RemoveAll(path); err != nil {
+				return result, fmt.Errorf("failed to remove %s: %w", path, err)
+			}
+		}
+		result.Removed = append(result.Removed, PrunedPath{Path: path, Size: size})
+		result.Reclaimed += size
+	}
+	return result, nil
+}
+
+// diskUsage returns the total size of the files under path.
+func diskUsage(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// FormatSize renders a byte count in human-readable binary units.
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeAged(t *testing.T, path string, size int, age time.Duration) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, make([]byte, size), 0644))
+	mtime := time.Now().Add(-age)
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+}
+
+func TestPrune(t *testing.T) {
+	t.Chdir(t.TempDir())
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	writeAged(t, filepath.Join(LogsDir, "old.log"), 100, 10*24*time.Hour)
+	writeAged(t, filepath.Join(LogsDir, "new.log"), 100, time.Hour)
+	writeAged(t, filepath.Join(CacheDir, "entry"), 50, 3*24*time.Hour)
+	writeAged(t, filepath.Join(tmp, "devops-home-123", ".bashrc"), 10, 0)
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(tmp, "devops-home-123"), old, old))
+
+	retention := Retention{MaxAge: 7 * 24 * time.Hour, Cache: 24 * time.Hour, Workspaces: time.Hour}
+
+	dryRun, err := Prune(retention, time.Now(), true)
+	require.NoError(t, err)
+	assert.Len(t, dryRun.Removed, 3)
+	assert.Equal(t, int64(160), dryRun.Reclaimed)
+	assert.FileExists(t, filepath.Join(LogsDir, "old.log"))
+
+	result, err := Prune(retention, time.Now(), false)
+	require.NoError(t, err)
+	assert.Equal(t, dryRun, result)
+	assert.NoFileExists(t, filepath.Join(LogsDir, "old.log"))
+	assert.NoFileExists(t, filepath.Join(CacheDir, "entry"))
+	assert.NoDirExists(t, filepath.Join(tmp, "devops-home-123"))
+	assert.FileExists(t, filepath.Join(LogsDir, "new.log"))
+}
+
+func TestPrune_NothingToRemove(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("TMPDIR", t.TempDir())
+
+	result, err := Prune(Retention{MaxAge: time.Hour}, time.Now(), false)
+	require.NoError(t, err)
+	assert.Empty(t, result.Removed)
+	assert.Zero(t, result.Reclaimed)
+}
+
+func TestLoadUserConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	cfg, err := LoadUserConfig()
+	require.NoError(t, err)
+	assert.Equal(t, defaultRetention, cfg.Retention.MaxAge)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "devops"), 0755))
+	content := "retention:\n  max_age: 72h\n  cache: 12h\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, userConfigFile), []byte(content), 0644))
+
+	cfg, err = LoadUserConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 72*time.Hour, cfg.Retention.MaxAge)
+	assert.Equal(t, 12*time.Hour, cfg.Retention.Cache)
+	assert.Equal(t, 72*time.Hour, cfg.Retention.orMaxAge(cfg.Retention.Logs))
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "512 B", FormatSize(512))
+	assert.Equal(t, "1.5 KiB", FormatSize(1536))
+	assert.Equal(t, "3.0 MiB", FormatSize(3*1024*1024))
+}
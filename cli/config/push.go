@@ -0,0 +1,126 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+)
+
+// Push is the outcome of pushing the artifacts of a run to a store.
+type Push struct {
+	RunID     string   `json:"run_id"`
+	Operation string   `json:"operation"`
+	Store     string   `json:"store"`
+	Objects   []string `json:"objects"`
+}
+
+// LatestRun returns the record of the most recently retained run.
+func LatestRun() (RunRecord, error) {
+	entries, err := os.ReadDir(RunsDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return RunRecord{}, err
+	}
+	// Run IDs start with their UTC start time, so they sort by age.
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].IsDir() {
+			return LoadRun(entries[i].Name())
+		}
+	}
+	return RunRecord{}, fmt.Errorf("no retained runs in %s", RunsDir)
+}
+
+// PushArtifacts uploads the retained artifacts of a run to an artifact
+// store, as <operation>/<version>/<path> objects, or under the run ID when
+// the project has no version. The store may be left empty when only one
+// is configured. Pushing is refused in read-only mode.
+func (d *ProjectDefinition) PushArtifacts(ctx context.Context, runID string, storeName string) (Push, error) {
+	if ReadOnlyFromContext(ctx) {
+		return Push{}, errors.New("pushing artifacts is not allowed in read-only mode")
+	}
+	storeName, config, err := d.artifactStore(storeName)
+	if err != nil {
+		return Push{}, err
+	}
+	store, err := config.store(d.credential)
+	if err != nil {
+		return Push{}, fmt.Errorf("artifact store '%s': %w", storeName, err)
+	}
+	record, err := LoadRun(runID)
+	if err != nil {
+		return Push{}, err
+	}
+
+	folder := record.Version
+	if folder == "" {
+		folder = record.ID
+	}
+	push := Push{RunID: record.ID, Operation: record.Operation, Store: storeName, Objects: []string{}}
+	source := os.DirFS(filepath.Join(RunsDir, record.ID, runFilesDir))
+	err = fs.WalkDir(source, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		data, err := fs.ReadFile(source, p)
+		if err != nil {
+			return err
+		}
+		key := path.Join(record.Operation, folder, p)
+		if err := store.Put(ctx, key, data); err != nil {
+			return fmt.Errorf("failed to push %s: %w", p, err)
+		}
+		logging.FromContext(ctx).Infof("Pushed %s to %s", p, key)
+		push.Objects = append(push.Objects, key)
+		return nil
+	})
+	return push, err
+}
+
+// checkArtifactStores reports unusable artifact stores.
+func (d *ProjectDefinition) checkArtifactStores(b *reportBuilder) {
+	names := make([]string, 0, len(d.ArtifactStores))
+	for name := range d.ArtifactStores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := d.ArtifactStores[name].store(d.credential); err != nil {
+			b.failAt("artifact_stores."+name, RuleArtifactStores, fmt.Sprintf("Fix artifact_stores.%s or remove it", name), "Artifact store '%s': %s", name, err.Error())
+		} else {
+			b.pass(RuleArtifactStores, "Artifact store '%s': %s", name, d.ArtifactStores[name].Backend)
+		}
+	}
+}
+
+// artifactStore picks the named artifact store, or the only one when no
+// name is given.
+func (d *ProjectDefinition) artifactStore(name string) (string, StoreConfig, error) {
+	names := make([]string, 0, len(d.ArtifactStores))
+	for storeName := range d.ArtifactStores {
+		names = append(names, storeName)
+	}
+	sort.Strings(names)
+	switch {
+	case len(names) == 0:
+		return "", StoreConfig{}, errors.New("no artifact stores are configured")
+	case name == "" && len(names) == 1:
+		name = names[0]
+	case name == "":
+		return "", StoreConfig{}, errors.New("several artifact stores are configured, select one with --to")
+	}
+	config, ok := d.ArtifactStores[name]
+	if !ok {
+		msg := fmt.Sprintf("unknown artifact store '%s'", name)
+		if suggestion := closestMatch(name, names); suggestion != "" {
+			msg += fmt.Sprintf(" (did you mean '%s'?)", suggestion)
+		}
+		return "", StoreConfig{}, errors.New(msg)
+	}
+	return name, config, nil
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectDefinition_PushArtifacts(t *testing.T) {
+	t.Chdir(t.TempDir())
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	var mu sync.Mutex
+	uploads := map[string]string{}
+	auth := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		data, _ := io.ReadAll(r.Body)
+		uploads[r.URL.Path] = string(data)
+		auth = r.Header.Get("Authorization")
+	}))
+	t.Cleanup(server.Close)
+
+	require.NoError(t, os.MkdirAll("dist/docs", 0755))
+	require.NoError(t, os.WriteFile("dist/app", []byte("binary"), 0755))
+	require.NoError(t, os.WriteFile("dist/docs/README", []byte("docs"), 0644))
+	require.NoError(t, os.WriteFile("token", []byte("secret-token"), 0600))
+	project := ProjectDefinition{
+		Version: "1.2.0",
+		ArtifactStores: map[string]StoreConfig{
+			"releases": {Backend: "http", URL: server.URL + "/releases", TokenEnv: "RELEASE_TOKEN"},
+		},
+		Secrets: map[string]Secret{"RELEASE_TOKEN": {File: "token"}},
+	}
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	runID := newRunID("", "build", start)
+	_, err := project.retainArtifacts(runID, "build", Operation{Artifacts: []string{"dist/*"}}, start)
+	require.NoError(t, err)
+
+	push, err := project.PushArtifacts(ctx, runID, "")
+	require.NoError(t, err)
+	assert.Equal(t, Push{
+		RunID:     runID,
+		Operation: "build",
+		Store:     "releases",
+		Objects:   []string{"build/1.2.0/dist/app", "build/1.2.0/dist/docs/README"},
+	}, push)
+	assert.Equal(t, map[string]string{
+		"/releases/build/1.2.0/dist/app":         "binary",
+		"/releases/build/1.2.0/dist/docs/README": "docs",
+	}, uploads)
+	assert.Equal(t, "Bearer secret-token", auth)
+
+	latest, err := LatestRun()
+	require.NoError(t, err)
+	assert.Equal(t, runID, latest.ID)
+
+	_, err = project.PushArtifacts(WithReadOnly(ctx), runID, "")
+	assert.EqualError(t, err, "pushing artifacts is not allowed in read-only mode")
+}
+
+func TestProjectDefinition_ArtifactStore(t *testing.T) {
+	project := ProjectDefinition{}
+	_, _, err := project.artifactStore("")
+	assert.EqualError(t, err, "no artifact stores are configured")
+
+	project.ArtifactStores = map[string]StoreConfig{
+		"releases": {Backend: "s3", Bucket: "releases"},
+		"mirror":   {Backend: "http", URL: "https://mirror.example.com"},
+	}
+	_, _, err = project.artifactStore("")
+	assert.EqualError(t, err, "several artifact stores are configured, select one with --to")
+	_, _, err = project.artifactStore("release")
+	assert.EqualError(t, err, "unknown artifact store 'release' (did you mean 'releases'?)")
+	name, store, err := project.artifactStore("mirror")
+	require.NoError(t, err)
+	assert.Equal(t, "mirror", name)
+	assert.Equal(t, "http", store.Backend)
+
+	project.ArtifactStores["broken"] = StoreConfig{Backend: "gcs"}
+	finding, ok := findingFor(project.Report(), RuleArtifactStores)
+	require.True(t, ok)
+	assert.False(t, finding.Passed)
+	assert.Equal(t, "Artifact store 'broken': backend gcs needs a bucket", finding.Message)
+}
+
+func TestLatestRun_None(t *testing.T) {
+	t.Chdir(t.TempDir())
+	_, err := LatestRun()
+	assert.EqualError(t, err, "no retained runs in "+RunsDir)
+}
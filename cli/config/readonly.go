@@ -0,0 +1,40 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// ReadOnlyEnv lets a shared runner enforce read-only mode regardless of
+// the flags devops is invoked with.
+const ReadOnlyEnv = "DEVOPS_READ_ONLY"
+
+const readOnlyKey contextKey = "read-only"
+
+// ReadOnlyEnforced reports whether DEVOPS_READ_ONLY is set to a true value.
+func ReadOnlyEnforced() bool {
+	enforced, _ := strconv.ParseBool(os.Getenv(ReadOnlyEnv))
+	return enforced
+}
+
+// WithReadOnly blocks mutating operations and ad-hoc commands run with
+// the context.
+func WithReadOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readOnlyKey, true)
+}
+
+// ReadOnlyFromContext reports whether read-only mode is on.
+func ReadOnlyFromContext(ctx context.Context) bool {
+	readOnly, _ := ctx.Value(readOnlyKey).(bool)
+	return readOnly
+}
+
+// checkReadOnly fails for mutating operations in read-only mode.
+func (op *Operation) checkReadOnly(ctx context.Context, name string) error {
+	if op.Mutating && ReadOnlyFromContext(ctx) {
+		return fmt.Errorf("operation %s is marked mutating and devops is in read-only mode", name)
+	}
+	return nil
+}
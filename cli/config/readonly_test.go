@@ -0,0 +1,66 @@
+package config
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnlyEnforced(t *testing.T) {
+	t.Setenv(ReadOnlyEnv, "")
+	assert.False(t, ReadOnlyEnforced())
+	t.Setenv(ReadOnlyEnv, "true")
+	assert.True(t, ReadOnlyEnforced())
+	t.Setenv(ReadOnlyEnv, "yes please")
+	assert.False(t, ReadOnlyEnforced())
+}
+
+func TestProjectDefinition_Run_ReadOnly(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	ctx = WithReadOnly(ctx)
+	project := ProjectDefinition{Codebase: Codebase{
+		Build: Operation{Steps: StepsFromCommands("make")},
+		Operations: map[string]Operation{
+			"deploy": {Mutating: true, Steps: StepsFromCommands("make deploy")},
+		},
+	}}
+	m := &MockShellExecutor{}
+	m.On("Exec", mock.Anything, "make").Return(executor.Result{}, nil)
+
+	require.NoError(t, project.Build(ctx, m))
+	assert.ErrorContains(t, project.Run(ctx, "deploy", m), "operation deploy is marked mutating and devops is in read-only mode")
+	m.AssertNotCalled(t, "Exec", mock.Anything, "make deploy")
+	m.AssertExpectations(t)
+}
+
+func TestProjectDefinition_Exec_ReadOnly(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	m := &MockShellExecutor{}
+
+	err := (&ProjectDefinition{}).Exec(WithReadOnly(ctx), []string{"rm", "-rf", "build"}, "", m)
+	assert.ErrorContains(t, err, "ad-hoc commands are not allowed in read-only mode")
+	m.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything)
+}
+
+func TestLoad_Mutating(t *testing.T) {
+	yamlContent := `id: shared
+codebase:
+  operations:
+    deploy:
+      mutating: true
+      steps:
+        - make deploy
+`
+	cfg, err := Load(strings.NewReader(yamlContent))
+	require.NoError(t, err)
+	assert.True(t, cfg.Codebase.Operations["deploy"].Mutating)
+	assert.False(t, cfg.Codebase.Build.Mutating)
+}
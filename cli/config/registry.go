@@ -0,0 +1,106 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+)
+
+// registryPasswordEnv passes the registry password to docker login on
+// stdin, keeping it off the command line.
+const registryPasswordEnv = "DEVOPS_REGISTRY_PASSWORD"
+
+// registryHost returns the registry host of an image reference or registry
+// such as ghcr.io/acme. References without one are on Docker Hub.
+func registryHost(ref string) string {
+	host, _, _ := strings.Cut(ref, "/")
+	if strings.ContainsAny(host, ".:") || host == "localhost" {
+		return host
+	}
+	return "docker.io"
+}
+
+// registryEnvPrefix returns the prefix of the host-specific credential
+// names of a registry, e.g. GHCR_IO for ghcr.io.
+func registryEnvPrefix(host string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, host)
+}
+
+// registryCredentials returns the username and password for a registry,
+// read from secrets or the environment. It tries, in order, the names set
+// by container.username_env and container.password_env for the container
+// registry, <HOST>_USERNAME and <HOST>_PASSWORD, REGISTRY_USERNAME and
+// REGISTRY_PASSWORD, and on ghcr.io GITHUB_ACTOR and GITHUB_TOKEN. It
+// reports false when no pair is fully set.
+func (d *ProjectDefinition) registryCredentials(host string) (string, string, bool) {
+	pairs := [][2]string{}
+	if d.Container.Registry != "" && registryHost(d.Container.Registry) == host &&
+		(d.Container.UsernameEnv != "" || d.Container.PasswordEnv != "") {
+		pairs = append(pairs, [2]string{d.Container.UsernameEnv, d.Container.PasswordEnv})
+	}
+	prefix := registryEnvPrefix(host)
+	pairs = append(pairs, [2]string{prefix + "_USERNAME", prefix + "_PASSWORD"}, [2]string{"REGISTRY_USERNAME", "REGISTRY_PASSWORD"})
+	if host == "ghcr.io" {
+		pairs = append(pairs, [2]string{"GITHUB_ACTOR", "GITHUB_TOKEN"})
+	}
+	for _, pair := range pairs {
+		if pair[0] == "" || pair[1] == "" {
+			continue
+		}
+		username, password := d.credential(pair[0]), d.credential(pair[1])
+		if username != "" && password != "" {
+			return username, password, true
+		}
+	}
+	return "", "", false
+}
+
+// Login logs docker in to a registry with the credentials found by
+// registryCredentials. The registry defaults to that of the container
+// section. It returns the registry host.
+func (d *ProjectDefinition) Login(ctx context.Context, registry string, shellExecutor ShellExecutor) (string, error) {
+	if registry == "" {
+		if d.Container.Registry == "" {
+			return "", errors.New("no registry given and container.registry is not set")
+		}
+		registry = d.Container.Registry
+	}
+	host := registryHost(registry)
+	username, password, ok := d.registryCredentials(host)
+	if !ok {
+		prefix := registryEnvPrefix(host)
+		return host, fmt.Errorf("no credentials for %s, set %s_USERNAME and %s_PASSWORD or REGISTRY_USERNAME and REGISTRY_PASSWORD as secrets or environment variables",
+			host, prefix, prefix)
+	}
+	return host, d.runCommands(executor.WithSecrets(ctx, password), "login", shellExecutor,
+		map[string]string{registryPasswordEnv: password}, loginCommand(host, username))
+}
+
+// loginCommand returns the docker login command for a registry, reading
+// the password from registryPasswordEnv.
+func loginCommand(host string, username string) string {
+	return fmt.Sprintf(`printf '%%s' "$%s" | docker login %s --username %s --password-stdin`,
+		registryPasswordEnv, shellQuote(host), shellQuote(username))
+}
+
+// pushLogin returns the login command to run before pushing to a
+// registry, if credentials for it are set. Without them, pushing relies on
+// an earlier docker login.
+func (d *ProjectDefinition) pushLogin(ctx context.Context, host string) (string, string, bool) {
+	username, password, ok := d.registryCredentials(host)
+	if !ok {
+		logging.FromContext(ctx).Debugf("No credentials for %s, using the existing docker login", host)
+		return "", "", false
+	}
+	return loginCommand(host, username), password, true
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryHost(t *testing.T) {
+	cases := map[string]string{
+		"ghcr.io/acme":          "ghcr.io",
+		"ghcr.io":               "ghcr.io",
+		"localhost:5000/app":    "localhost:5000",
+		"localhost":             "localhost",
+		"acme":                  "docker.io",
+		"acme/app":              "docker.io",
+		"registry.example.com":  "registry.example.com",
+		"docker.io/library/app": "docker.io",
+	}
+	for ref, host := range cases {
+		assert.Equal(t, host, registryHost(ref), ref)
+	}
+	assert.Equal(t, "LOCALHOST_5000", registryEnvPrefix("localhost:5000"))
+}
+
+func TestProjectDefinition_RegistryCredentials(t *testing.T) {
+	for _, name := range []string{"GHCR_IO_USERNAME", "GHCR_IO_PASSWORD", "REGISTRY_USERNAME", "REGISTRY_PASSWORD", "GITHUB_ACTOR", "GITHUB_TOKEN", "CI_USER"} {
+		t.Setenv(name, "")
+	}
+	project := ProjectDefinition{Container: Container{Registry: "ghcr.io/acme", UsernameEnv: "CI_USER", PasswordEnv: "CI_TOKEN"}}
+
+	_, _, ok := project.registryCredentials("ghcr.io")
+	assert.False(t, ok)
+
+	t.Setenv("GITHUB_ACTOR", "octocat")
+	t.Setenv("GITHUB_TOKEN", "gh-token")
+	username, password, ok := project.registryCredentials("ghcr.io")
+	require.True(t, ok)
+	assert.Equal(t, []string{"octocat", "gh-token"}, []string{username, password})
+
+	t.Setenv("REGISTRY_USERNAME", "shared")
+	t.Setenv("REGISTRY_PASSWORD", "shared-pass")
+	username, _, _ = project.registryCredentials("ghcr.io")
+	assert.Equal(t, "shared", username)
+
+	t.Setenv("GHCR_IO_USERNAME", "host")
+	t.Setenv("GHCR_IO_PASSWORD", "host-pass")
+	username, _, _ = project.registryCredentials("ghcr.io")
+	assert.Equal(t, "host", username)
+
+	t.Setenv("CI_USER", "bot")
+	t.Setenv("VAULT_CI_TOKEN", "secret-pass")
+	project.Secrets = map[string]Secret{"CI_TOKEN": {Env: "VAULT_CI_TOKEN"}}
+	username, password, _ = project.registryCredentials("ghcr.io")
+	assert.Equal(t, []string{"bot", "secret-pass"}, []string{username, password})
+
+	username, _, _ = project.registryCredentials("quay.io")
+	assert.Equal(t, "shared", username)
+}
+
+func TestProjectDefinition_Login(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	t.Setenv("QUAY_IO_USERNAME", "")
+	t.Setenv("QUAY_IO_PASSWORD", "")
+	t.Setenv("REGISTRY_USERNAME", "")
+	t.Setenv("REGISTRY_PASSWORD", "")
+	project := ProjectDefinition{ID: "app"}
+	m := &MockShellExecutor{}
+
+	_, err := project.Login(ctx, "", m)
+	assert.EqualError(t, err, "no registry given and container.registry is not set")
+
+	_, err = project.Login(ctx, "quay.io/acme", m)
+	assert.EqualError(t, err, "no credentials for quay.io, set QUAY_IO_USERNAME and QUAY_IO_PASSWORD or REGISTRY_USERNAME and REGISTRY_PASSWORD as secrets or environment variables")
+
+	t.Setenv("QUAY_IO_USERNAME", "bot")
+	t.Setenv("QUAY_IO_PASSWORD", "hunter2")
+	calls := []string{}
+	login := `printf '%s' "$DEVOPS_REGISTRY_PASSWORD" | docker login 'quay.io' --username 'bot' --password-stdin`
+	recordCalls(m, &calls, login)
+
+	host, err := project.Login(ctx, "quay.io/acme", m)
+	require.NoError(t, err)
+	assert.Equal(t, "quay.io", host)
+	assert.Equal(t, []string{login}, calls)
+}
+
+func TestProjectDefinition_PushImageLogsIn(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	stubGitSHA(t, "0123456789abcdef0123456789abcdef01234567")
+	stubDockerPing(t, nil)
+	t.Setenv("REGISTRY_USERNAME", "bot")
+	t.Setenv("REGISTRY_PASSWORD", "hunter2")
+	project := ProjectDefinition{ID: "app", Version: "1.2.0", Container: Container{Registry: "registry.example.com", Tags: []string{"{{.Version}}"}}}
+	m := &MockShellExecutor{}
+	calls := []string{}
+	login := `printf '%s' "$DEVOPS_REGISTRY_PASSWORD" | docker login 'registry.example.com' --username 'bot' --password-stdin`
+	recordCalls(m, &calls, login, "docker push 'registry.example.com/app:1.2.0'")
+
+	_, err := project.PushImage(ctx, m)
+	require.NoError(t, err)
+	assert.Equal(t, []string{login, "docker push 'registry.example.com/app:1.2.0'"}, calls)
+}
@@ -0,0 +1,328 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/jgfranco17/devops/internal/fileutils"
+	"github.com/jgfranco17/devops/internal/github"
+	"github.com/jgfranco17/devops/internal/outbound"
+	"gopkg.in/yaml.v3"
+)
+
+// Version bumps accepted by Release besides an explicit version.
+const (
+	BumpMajor = "major"
+	BumpMinor = "minor"
+	BumpPatch = "patch"
+)
+
+var semverPattern = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// NextVersion applies a bump (major, minor or patch) to a semantic version,
+// or validates an explicit version given instead. Bumping a pre-release
+// version to the same level releases it, e.g. 1.3.0-rc.1 becomes 1.3.0 with
+// a minor bump. The result has no leading v.
+func NextVersion(current string, bump string) (string, error) {
+	switch bump {
+	case BumpMajor, BumpMinor, BumpPatch:
+	default:
+		match := semverPattern.FindStringSubmatch(bump)
+		if match == nil {
+			return "", fmt.Errorf("invalid version '%s', expected major, minor, patch or a semantic version", bump)
+		}
+		return strings.TrimPrefix(bump, "v"), nil
+	}
+	match := semverPattern.FindStringSubmatch(current)
+	if match == nil {
+		return "", fmt.Errorf("current version '%s' is not a semantic version", current)
+	}
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	patch, _ := strconv.Atoi(match[3])
+	prerelease := match[4] != ""
+	switch {
+	case bump == BumpMajor && !(prerelease && minor == 0 && patch == 0):
+		major, minor, patch = major+1, 0, 0
+	case bump == BumpMinor && !(prerelease && patch == 0):
+		minor, patch = minor+1, 0
+	case bump == BumpPatch && !prerelease:
+		patch++
+	}
+	return fmt.Sprintf("%d.%d.%d", major, minor, patch), nil
+}
+
+// SetDefinitionVersion rewrites the top-level version of a definition file
+// in place, leaving the rest of the file untouched.
+func SetDefinitionVersion(path string, version string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("%s is not a mapping", path)
+	}
+	value := mappingValue(doc.Content[0], "version")
+	if value == nil || value.Kind != yaml.ScalarNode {
+		return fmt.Errorf("%s has no version to update", path)
+	}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	line := lines[value.Line-1]
+	start := value.Column - 1
+	token := value.Value
+	if value.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+		quote := string(line[start])
+		token = quote + token + quote
+		version = quote + version + quote
+	}
+	end := start + len(token)
+	if end > len(line) || string(line[start:end]) != token {
+		return fmt.Errorf("failed to locate the version in %s", path)
+	}
+	lines[value.Line-1] = append(append(append([]byte{}, line[:start]...), version...), line[end:]...)
+	return os.WriteFile(path, bytes.Join(lines, nil), 0644)
+}
+
+// ReleaseOptions configure a release.
+type ReleaseOptions struct {
+	// DefinitionPath is the definition file whose version is bumped.
+	DefinitionPath string
+
+	// Bump is major, minor, patch or an explicit version.
+	Bump string
+
+	// GitHub pushes the tag and publishes a GitHub release with the
+	// packaged artifacts attached.
+	GitHub bool
+}
+
+// ReleaseResult describes a completed release.
+type ReleaseResult struct {
+	Version    string
+	Tag        string
+	Package    string
+	Checksums  string
+	Signatures []string
+	ReleaseURL string
+}
+
+// runGit runs git with the given arguments and returns its trimmed output.
+var runGit = func(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := executor.RunCommand(ctx, cmd); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// Release bumps the definition version, builds with it, packages the
+// build artifacts into ReleasesDir with a checksums file, signed when
+// signing is configured, then commits the version change and
+// tags it v<version>. The definition is reloaded after the bump so values
+// referencing the version see the new one, and restored if the build
+// fails. The working tree must be clean and the tag must not exist yet.
+// Releasing is refused in read-only mode.
+func (d *ProjectDefinition) Release(ctx context.Context, opts ReleaseOptions, shellExecutor ShellExecutor) (ReleaseResult, error) {
+	logger := logging.FromContext(ctx)
+	if ReadOnlyFromContext(ctx) {
+		return ReleaseResult{}, errors.New("releasing is not allowed in read-only mode")
+	}
+	version, err := NextVersion(d.Version, opts.Bump)
+	if err != nil {
+		return ReleaseResult{}, err
+	}
+	result := ReleaseResult{Version: version, Tag: "v" + version}
+	var owner, repo, token string
+	if opts.GitHub {
+		if owner, repo, err = github.ParseRepo(d.RepoUrl); err != nil {
+			return result, err
+		}
+		if token = d.credential("GITHUB_TOKEN"); token == "" {
+			return result, errors.New("GITHUB_TOKEN is required to publish a GitHub release")
+		}
+	}
+	status, err := runGit(ctx, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return result, err
+	}
+	if status != "" {
+		return result, errors.New("the working tree has uncommitted changes, commit or stash them first")
+	}
+	if _, err := runGit(ctx, "rev-parse", "--verify", "--quiet", "refs/tags/"+result.Tag); err == nil {
+		return result, fmt.Errorf("tag %s already exists", result.Tag)
+	}
+
+	original, err := os.ReadFile(opts.DefinitionPath)
+	if err != nil {
+		return result, err
+	}
+	result.Package, result.Checksums, err = d.buildRelease(ctx, opts.DefinitionPath, version, shellExecutor)
+	if err == nil && result.Package != "" && d.Signing.Enabled() {
+		result.Signatures, err = d.Signing.SignFiles(ctx, result.Package, result.Checksums)
+	}
+	if err != nil {
+		if restoreErr := os.WriteFile(opts.DefinitionPath, original, 0644); restoreErr != nil {
+			logger.Warnf("Failed to restore %s: %v", opts.DefinitionPath, restoreErr)
+		}
+		return result, err
+	}
+
+	if _, err := runGit(ctx, "commit", "--quiet", "-m", "Release "+result.Tag, "--", opts.DefinitionPath); err != nil {
+		return result, err
+	}
+	if _, err := runGit(ctx, "tag", "-a", result.Tag, "-m", "Release "+result.Tag); err != nil {
+		return result, err
+	}
+	logger.Infof("Tagged %s", result.Tag)
+
+	if opts.GitHub {
+		if _, err := runGit(ctx, "push", "origin", result.Tag); err != nil {
+			return result, err
+		}
+		client := &github.Client{APIURL: githubAPIURL(), Token: token, HTTP: outbound.New()}
+		release, err := client.CreateRelease(ctx, owner, repo, result.Tag)
+		if err != nil {
+			return result, err
+		}
+		for _, asset := range append([]string{result.Package, result.Checksums}, result.Signatures...) {
+			if asset == "" {
+				continue
+			}
+			if err := client.UploadAsset(ctx, release, asset); err != nil {
+				return result, err
+			}
+		}
+		result.ReleaseURL = release.HTMLURL
+	}
+	return result, nil
+}
+
+// buildRelease bumps the definition file to the version, then builds and
+// packages the release from the reloaded definition. It returns the
+// package and its checksums file.
+func (d *ProjectDefinition) buildRelease(ctx context.Context, path string, version string, shellExecutor ShellExecutor) (string, string, error) {
+	if err := SetDefinitionVersion(path, version); err != nil {
+		return "", "", err
+	}
+	released, err := LoadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	logging.FromContext(ctx).Infof("Building version %s", version)
+	if err := released.Build(ctx, shellExecutor); err != nil {
+		return "", "", fmt.Errorf("build failed: %w", err)
+	}
+	pkg, err := released.packageRelease(version)
+	if err != nil || pkg == "" {
+		return pkg, "", err
+	}
+	checksums, err := writeReleaseChecksums(pkg)
+	return pkg, checksums, err
+}
+
+// writeReleaseChecksums writes the checksums of a release package next to
+// it, as <package name>-SHA256SUMS, listing the package by base name so
+// it can be checked where both files are downloaded.
+func writeReleaseChecksums(pkg string) (string, error) {
+	digests, err := digestFiles(os.DirFS(filepath.Dir(pkg)), []string{filepath.Base(pkg)})
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSuffix(pkg, ".tar.gz") + "-" + ChecksumsFile
+	if err := os.WriteFile(path, FormatChecksums(digests), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// buildArtifacts returns the artifacts of the build operation, or its
+// packages when it runs for several targets.
+func (d *ProjectDefinition) buildArtifacts() ([]string, error) {
+	build := d.Codebase.Build
+	if len(build.Targets) == 0 {
+		return build.MatchArtifacts()
+	}
+	targets, err := build.targets()
+	if err != nil {
+		return nil, err
+	}
+	packages := []string{}
+	for _, t := range targets {
+		name, err := build.packageName(d.packageInfo(t))
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, PackagesDir+"/"+name)
+	}
+	return packages, nil
+}
+
+// githubAPIURL honours GITHUB_API_URL, set by GitHub Actions on GitHub
+// Enterprise Server.
+func githubAPIURL() string {
+	if apiURL := os.Getenv("GITHUB_API_URL"); apiURL != "" {
+		return apiURL
+	}
+	return github.DefaultAPIURL
+}
+
+// packageRelease archives the build artifacts of every codebase into
+// ReleasesDir as <id>-<version>.tar.gz. It returns an empty path when the
+// build declares no artifacts.
+func (d *ProjectDefinition) packageRelease(version string) (string, error) {
+	paths := []string{}
+	for _, view := range d.codebaseViews() {
+		err := inDir(view.Codebase.Path, func() error {
+			matches, err := view.buildArtifacts()
+			for _, match := range matches {
+				paths = append(paths, filepath.ToSlash(filepath.Join(view.Codebase.Path, match)))
+			}
+			return err
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	if len(paths) == 0 {
+		return "", nil
+	}
+	staging, err := os.MkdirTemp("", "devops-release-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(staging)
+	if err := stageArtifacts(paths, filepath.Join(staging, "files")); err != nil {
+		return "", fmt.Errorf("failed to package release: %w", err)
+	}
+	if err := os.MkdirAll(ReleasesDir, 0755); err != nil {
+		return "", err
+	}
+	archive, err := filepath.Abs(filepath.Join(ReleasesDir, fmt.Sprintf("%s-%s.tar.gz", d.ID, version)))
+	if err != nil {
+		return "", err
+	}
+	if err := fileutils.CreateTarGz(filepath.Join(staging, "files"), archive); err != nil {
+		return "", fmt.Errorf("failed to package release: %w", err)
+	}
+	return filepath.Join(ReleasesDir, filepath.Base(archive)), nil
+}
@@ -0,0 +1,223 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextVersion(t *testing.T) {
+	tests := []struct {
+		current  string
+		bump     string
+		expected string
+		err      string
+	}{
+		{current: "1.2.3", bump: "patch", expected: "1.2.4"},
+		{current: "1.2.3", bump: "minor", expected: "1.3.0"},
+		{current: "v1.2.3", bump: "major", expected: "2.0.0"},
+		{current: "1.3.0-rc.1", bump: "minor", expected: "1.3.0"},
+		{current: "1.3.1-rc.1", bump: "minor", expected: "1.4.0"},
+		{current: "2.0.0-beta", bump: "major", expected: "2.0.0"},
+		{current: "1.2.3-rc.1", bump: "patch", expected: "1.2.3"},
+		{current: "1.2.3", bump: "v2.0.0-rc.1", expected: "2.0.0-rc.1"},
+		{current: "1.2", bump: "patch", err: "current version '1.2' is not a semantic version"},
+		{current: "1.2.3", bump: "next", err: "invalid version 'next', expected major, minor, patch or a semantic version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.current+" "+tt.bump, func(t *testing.T) {
+			version, err := NextVersion(tt.current, tt.bump)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, version)
+		})
+	}
+}
+
+func TestSetDefinitionVersion(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for _, tt := range []struct{ before, after string }{
+		{before: "id: app # the app\nversion: 1.2.3 # current\ncodebase:\n  version: 9.9.9\n", after: "id: app # the app\nversion: 1.3.0 # current\ncodebase:\n  version: 9.9.9\n"},
+		{before: "version: \"1.2.3\"\r\nid: app\r\n", after: "version: \"1.3.0\"\r\nid: app\r\n"},
+		{before: "version: '1.2.3'", after: "version: '1.3.0'"},
+	} {
+		require.NoError(t, os.WriteFile(DefinitionFile, []byte(tt.before), 0644))
+		require.NoError(t, SetDefinitionVersion(DefinitionFile, "1.3.0"))
+		data, err := os.ReadFile(DefinitionFile)
+		require.NoError(t, err)
+		assert.Equal(t, tt.after, string(data))
+	}
+
+	require.NoError(t, os.WriteFile(DefinitionFile, []byte("id: app\n"), 0644))
+	assert.EqualError(t, SetDefinitionVersion(DefinitionFile, "1.3.0"), DefinitionFile+" has no version to update")
+}
+
+// newReleaseRepo creates a git repository with a committed definition and
+// returns the loaded definition.
+func newReleaseRepo(t *testing.T, definition string) *ProjectDefinition {
+	t.Chdir(t.TempDir())
+	for key, value := range map[string]string{
+		"GIT_AUTHOR_NAME": "ci", "GIT_AUTHOR_EMAIL": "ci@example.com",
+		"GIT_COMMITTER_NAME": "ci", "GIT_COMMITTER_EMAIL": "ci@example.com",
+		"GIT_CONFIG_GLOBAL": os.DevNull, "GIT_CONFIG_NOSYSTEM": "1",
+	} {
+		t.Setenv(key, value)
+	}
+	require.NoError(t, os.WriteFile(DefinitionFile, []byte(definition), 0644))
+	require.NoError(t, os.WriteFile(".gitignore", []byte("/.devops/\n/dist/\n"), 0644))
+	git(t, "init", "--quiet")
+	git(t, "add", ".")
+	git(t, "commit", "--quiet", "-m", "init")
+	project, err := LoadFile(DefinitionFile)
+	require.NoError(t, err)
+	return project
+}
+
+func git(t *testing.T, args ...string) string {
+	out, err := exec.Command("git", args...).CombinedOutput()
+	require.NoError(t, err, string(out))
+	return string(out)
+}
+
+const releaseDefinition = `id: app
+version: 1.2.3
+repo_url: https://github.com/owner/app
+codebase:
+  language: go
+  build:
+    artifacts: [dist/*]
+    steps:
+      - run: make dist VERSION=${{ project.version }}
+`
+
+func TestProjectDefinition_Release(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	project := newReleaseRepo(t, releaseDefinition)
+	m := &MockShellExecutor{}
+	m.On("Exec", mock.Anything, "make dist VERSION=1.3.0").Run(func(args mock.Arguments) {
+		require.NoError(t, os.MkdirAll("dist", 0755))
+		require.NoError(t, os.WriteFile("dist/app", []byte("binary"), 0755))
+	}).Return(executor.Result{}, nil)
+
+	result, err := project.Release(ctx, ReleaseOptions{DefinitionPath: DefinitionFile, Bump: "minor"}, m)
+	require.NoError(t, err)
+	assert.Equal(t, ReleaseResult{
+		Version:   "1.3.0",
+		Tag:       "v1.3.0",
+		Package:   ReleasesDir + "/app-1.3.0.tar.gz",
+		Checksums: ReleasesDir + "/app-1.3.0-SHA256SUMS",
+	}, result)
+	assert.FileExists(t, result.Package)
+	digests, err := LoadChecksums(result.Checksums)
+	require.NoError(t, err)
+	require.Len(t, digests, 1)
+	assert.Equal(t, "app-1.3.0.tar.gz", digests[0].Path)
+	assert.Equal(t, "v1.3.0\n", git(t, "tag", "--points-at", "HEAD"))
+	assert.Equal(t, "Release v1.3.0\n", git(t, "log", "-1", "--format=%s"))
+	assert.Contains(t, git(t, "show", "HEAD:"+DefinitionFile), "version: 1.3.0\n")
+	m.AssertExpectations(t)
+
+	project.Version = "1.2.3"
+	_, err = project.Release(ctx, ReleaseOptions{DefinitionPath: DefinitionFile, Bump: "minor"}, m)
+	assert.EqualError(t, err, "tag v1.3.0 already exists")
+
+	_, err = project.Release(WithReadOnly(ctx), ReleaseOptions{DefinitionPath: DefinitionFile, Bump: "patch"}, m)
+	assert.EqualError(t, err, "releasing is not allowed in read-only mode")
+}
+
+func TestProjectDefinition_Release_Refused(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+
+	t.Run("uncommitted changes", func(t *testing.T) {
+		project := newReleaseRepo(t, releaseDefinition)
+		require.NoError(t, os.WriteFile(DefinitionFile, []byte(releaseDefinition+"description: wip\n"), 0644))
+		_, err := project.Release(ctx, ReleaseOptions{DefinitionPath: DefinitionFile, Bump: "patch"}, &MockShellExecutor{})
+		assert.EqualError(t, err, "the working tree has uncommitted changes, commit or stash them first")
+	})
+
+	t.Run("failed build restores the version", func(t *testing.T) {
+		project := newReleaseRepo(t, releaseDefinition)
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, mock.Anything).Return(executor.Result{ExitCode: 2}, nil)
+		_, err := project.Release(ctx, ReleaseOptions{DefinitionPath: DefinitionFile, Bump: "patch"}, m)
+		assert.ErrorContains(t, err, "build failed: ")
+		assert.Empty(t, git(t, "status", "--porcelain"))
+		assert.Empty(t, git(t, "tag"))
+	})
+
+	t.Run("github release without a token", func(t *testing.T) {
+		t.Setenv("GITHUB_TOKEN", "")
+		project := newReleaseRepo(t, releaseDefinition)
+		_, err := project.Release(ctx, ReleaseOptions{DefinitionPath: DefinitionFile, Bump: "patch", GitHub: true}, &MockShellExecutor{})
+		assert.EqualError(t, err, "GITHUB_TOKEN is required to publish a GitHub release")
+	})
+}
+
+func TestProjectDefinition_Release_GitHub(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	var created map[string]any
+	uploaded := []string{}
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/app/releases":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			_, _ = io.WriteString(w, `{"id": 1, "html_url": "https://github.com/owner/app/releases/tag/v1.2.4", "upload_url": "`+server.URL+`/assets{?name,label}"}`)
+		case "/assets":
+			uploaded = append(uploaded, r.URL.Query().Get("name"))
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("GITHUB_API_URL", server.URL)
+	t.Setenv("GITHUB_TOKEN", "token")
+
+	origin := t.TempDir()
+	project := newReleaseRepo(t, releaseDefinition)
+	git(t, "init", "--quiet", "--bare", origin)
+	git(t, "remote", "add", "origin", origin)
+	m := &MockShellExecutor{}
+	m.On("Exec", mock.Anything, "make dist VERSION=1.2.4").Run(func(args mock.Arguments) {
+		require.NoError(t, os.MkdirAll("dist", 0755))
+		require.NoError(t, os.WriteFile("dist/app", []byte("binary"), 0755))
+	}).Return(executor.Result{}, nil)
+
+	result, err := project.Release(ctx, ReleaseOptions{DefinitionPath: DefinitionFile, Bump: "patch", GitHub: true}, m)
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/owner/app/releases/tag/v1.2.4", result.ReleaseURL)
+	assert.Equal(t, "v1.2.4", created["tag_name"])
+	assert.Equal(t, []string{"app-1.2.4.tar.gz", "app-1.2.4-SHA256SUMS"}, uploaded)
+	assert.Contains(t, git(t, "--git-dir", origin, "tag"), "v1.2.4")
+}
+
+func TestProjectDefinition_Release_Signed(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	project := newReleaseRepo(t, releaseDefinition)
+	project.Signing = Signing{Method: SigningGPG}
+	calls := stubSigner(t, nil)
+	m := &MockShellExecutor{}
+	m.On("Exec", mock.Anything, "make dist VERSION=1.2.4").Run(func(args mock.Arguments) {
+		require.NoError(t, os.MkdirAll("dist", 0755))
+		require.NoError(t, os.WriteFile("dist/app", []byte("binary"), 0755))
+	}).Return(executor.Result{}, nil)
+
+	result, err := project.Release(ctx, ReleaseOptions{DefinitionPath: DefinitionFile, Bump: "patch"}, m)
+	require.NoError(t, err)
+	assert.Equal(t, []string{result.Package + ".asc", result.Checksums + ".asc"}, result.Signatures)
+	assert.Len(t, *calls, 2)
+	assert.FileExists(t, result.Checksums+".asc")
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/jgfranco17/devops/cli/executor"
+)
+
+// Remote describes a host that operations marked `remote: true` run on.
+type Remote struct {
+	Host         string `yaml:"host"`
+	User         string `yaml:"user,omitempty"`
+	Port         int    `yaml:"port,omitempty"`
+	IdentityFile string `yaml:"identity_file,omitempty"`
+	Dir          string `yaml:"dir,omitempty"`
+}
+
+// executor returns an executor running commands on the remote host.
+func (r *Remote) executor() *executor.SSHExecutor {
+	return &executor.SSHExecutor{
+		Host:         r.Host,
+		User:         r.User,
+		Port:         r.Port,
+		IdentityFile: r.IdentityFile,
+		Dir:          r.Dir,
+	}
+}
+
+// remoteExecutor picks the executor for an operation: the SSH executor
+// for remote operations, otherwise the given local one. Middlewares around
+// the local executor are kept around the remote one.
+func (d *ProjectDefinition) remoteExecutor(name string, op Operation, local ShellExecutor) (ShellExecutor, error) {
+	if !op.Remote {
+		return local, nil
+	}
+	if d.Remote.Host == "" {
+		return nil, fmt.Errorf("operation %s is remote but no remote host is configured", name)
+	}
+	if op.Sandbox {
+		return nil, fmt.Errorf("operation %s cannot use both remote and sandbox", name)
+	}
+	if len(op.PathPrepend) > 0 {
+		return nil, fmt.Errorf("operation %s cannot use path_prepend when remote", name)
+	}
+	if chained, ok := local.(*executor.Chained); ok {
+		return chained.WithBase(d.Remote.executor()), nil
+	}
+	return d.Remote.executor(), nil
+}
+
+// checkRemote reports remote operations without a usable remote host.
+func (d *ProjectDefinition) checkRemote(b *reportBuilder) {
+	for _, name := range d.Codebase.OperationNames() {
+		op, _ := d.Codebase.Lookup(name)
+		if _, err := d.remoteExecutor(name, op, nil); err != nil {
+			b.failAt(d.operationPath(name), RuleRemoteConfig, "Set remote.host or drop remote from the operation", "%s", err.Error())
+		} else if op.Remote {
+			b.pass(RuleRemoteConfig, "Operation '%s' runs on %s", name, d.Remote.Host)
+		}
+	}
+}
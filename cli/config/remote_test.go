@@ -0,0 +1,57 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectDefinition_RemoteExecutor(t *testing.T) {
+	local := &MockShellExecutor{}
+	project := ProjectDefinition{
+		Remote: Remote{Host: "builder", User: "ci", Port: 2222, Dir: "/srv/app"},
+	}
+
+	selected, err := project.remoteExecutor("test", Operation{}, local)
+	require.NoError(t, err)
+	assert.Same(t, local, selected)
+
+	selected, err = project.remoteExecutor("build", Operation{Remote: true}, local)
+	require.NoError(t, err)
+	assert.Equal(t, &executor.SSHExecutor{Host: "builder", User: "ci", Port: 2222, Dir: "/srv/app"}, selected)
+
+	_, err = project.remoteExecutor("build", Operation{Remote: true, Sandbox: true}, local)
+	assert.EqualError(t, err, "operation build cannot use both remote and sandbox")
+
+	_, err = (&ProjectDefinition{}).remoteExecutor("build", Operation{Remote: true}, local)
+	assert.EqualError(t, err, "operation build is remote but no remote host is configured")
+}
+
+func TestProjectDefinition_Report_RemoteConfig(t *testing.T) {
+	project := ProjectDefinition{
+		Codebase: Codebase{Build: Operation{Remote: true, Steps: StepsFromCommands("make")}},
+	}
+	finding, ok := findingFor(project.Report(), RuleRemoteConfig)
+	require.True(t, ok)
+	assert.False(t, finding.Passed)
+	assert.Equal(t, SeverityError, finding.Severity)
+
+	project.Remote.Host = "builder"
+	finding, ok = findingFor(project.Report(), RuleRemoteConfig)
+	require.True(t, ok)
+	assert.True(t, finding.Passed)
+	assert.Equal(t, "Operation 'build' runs on builder", finding.Message)
+}
+
+func TestProjectDefinition_RemoteExecutor_KeepsMiddlewares(t *testing.T) {
+	recorder := &executor.Recorder{}
+	local := executor.Chain(&MockShellExecutor{}, recorder.Middleware())
+	project := ProjectDefinition{Remote: Remote{Host: "builder"}}
+
+	selected, err := project.remoteExecutor("build", Operation{Remote: true}, local)
+	require.NoError(t, err)
+	assert.IsType(t, &executor.Chained{}, selected)
+	assert.NotSame(t, local, selected)
+}
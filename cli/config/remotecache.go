@@ -0,0 +1,60 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jgfranco17/devops/internal/objectstore"
+)
+
+// CacheConfig configures a remote store the step cache is shared through,
+// so CI runners skip steps another machine already ran with the same
+// inputs.
+type CacheConfig struct {
+	StoreConfig `yaml:",inline"`
+
+	// ReadOnly looks entries up without uploading new ones, e.g. on
+	// developer machines sharing the cache of CI.
+	ReadOnly bool `yaml:"read_only,omitempty"`
+}
+
+const remoteCacheKey contextKey = "remote-cache"
+
+// remoteCache is the store of a run and whether entries are uploaded.
+type remoteCache struct {
+	store    objectstore.Store
+	readOnly bool
+}
+
+// withRemoteCache attaches the configured remote store for the steps of a
+// cached operation.
+func (d *ProjectDefinition) withRemoteCache(ctx context.Context, op Operation) (context.Context, error) {
+	if !op.cacheable(ctx) {
+		return ctx, nil
+	}
+	store, err := d.Cache.store(d.credential)
+	if err != nil {
+		return ctx, fmt.Errorf("cache: %w", err)
+	}
+	if store == nil {
+		return ctx, nil
+	}
+	return context.WithValue(ctx, remoteCacheKey, remoteCache{store: store, readOnly: d.Cache.ReadOnly}), nil
+}
+
+func remoteCacheFromContext(ctx context.Context) (remoteCache, bool) {
+	cache, ok := ctx.Value(remoteCacheKey).(remoteCache)
+	return cache, ok
+}
+
+// checkCache reports an unusable cache section.
+func (d *ProjectDefinition) checkCache(b *reportBuilder) {
+	if d.Cache.Backend == "" {
+		return
+	}
+	if _, err := d.Cache.store(d.credential); err != nil {
+		b.fail(RuleCacheConfig, "Fix the cache section or remove it", "cache: %s", err.Error())
+		return
+	}
+	b.pass(RuleCacheConfig, "Remote cache: %s", d.Cache.Backend)
+}
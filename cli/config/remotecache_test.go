@@ -0,0 +1,77 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectDefinition_Run_RemoteCache(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	var mu sync.Mutex
+	objects := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodPut {
+			objects[r.URL.Path] = true
+		} else if !objects[r.URL.Path] {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	newProject := func(readOnly bool) ProjectDefinition {
+		return ProjectDefinition{
+			ID:    "cache-project",
+			Cache: CacheConfig{StoreConfig: StoreConfig{Backend: "http", URL: server.URL}, ReadOnly: readOnly},
+			Codebase: Codebase{
+				Operations: map[string]Operation{
+					"lint": {
+						Cache:  true,
+						Inputs: Inputs{Files: []string{"*.go"}},
+						Steps:  StepsFromCommands("golangci-lint run"),
+					},
+				},
+			},
+		}
+	}
+	// Each run is on a fresh machine: same sources, empty local cache.
+	run := func(project ProjectDefinition) []string {
+		t.Chdir(t.TempDir())
+		require.NoError(t, os.WriteFile("main.go", []byte("package main"), 0644))
+		m := &MockShellExecutor{}
+		calls := []string{}
+		recordCalls(m, &calls, "golangci-lint run")
+		require.NoError(t, project.Run(ctx, "lint", m))
+		return calls
+	}
+
+	assert.Equal(t, []string{"golangci-lint run"}, run(newProject(true)))
+	assert.Empty(t, objects, "read-only caches do not upload")
+	assert.Equal(t, []string{"golangci-lint run"}, run(newProject(false)))
+	assert.Len(t, objects, 1)
+	assert.Empty(t, run(newProject(true)))
+}
+
+func TestProjectDefinition_Report_CacheConfig(t *testing.T) {
+	project := ProjectDefinition{Cache: CacheConfig{StoreConfig: StoreConfig{Backend: "s3"}}}
+	finding, ok := findingFor(project.Report(), RuleCacheConfig)
+	require.True(t, ok)
+	assert.False(t, finding.Passed)
+	assert.Equal(t, SeverityError, finding.Severity)
+	assert.Equal(t, "cache: backend s3 needs a bucket", finding.Message)
+
+	project.Cache.Bucket = "ci-cache"
+	finding, ok = findingFor(project.Report(), RuleCacheConfig)
+	require.True(t, ok)
+	assert.True(t, finding.Passed)
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	deploymentsFile = "deployments.jsonl"
+
+	// DefaultDeployEnvironment is the environment of deployments made
+	// without a profile.
+	DefaultDeployEnvironment = "default"
+)
+
+// Deployment statuses.
+const (
+	DeploySucceeded = "succeeded"
+	DeployFailed    = "failed"
+)
+
+// Deployment is the history record of a Helm release deployed to an
+// environment.
+type Deployment struct {
+	ID          string            `json:"id"`
+	Environment string            `json:"environment"`
+	Release     string            `json:"release"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Version     string            `json:"version,omitempty"`
+	Package     string            `json:"package"`
+	Values      []string          `json:"values,omitempty"`
+	Set         map[string]string `json:"set,omitempty"`
+	Status      string            `json:"status"`
+	User        string            `json:"user,omitempty"`
+	DeployedAt  time.Time         `json:"deployed_at"`
+
+	// Strategy is the deploy strategy used, and Color the color that
+	// received traffic after a blue-green deployment.
+	Strategy string `json:"strategy,omitempty"`
+	Color    string `json:"color,omitempty"`
+
+	// Dir holds the copies of the package and values files deployed.
+	Dir string `json:"dir,omitempty"`
+
+	// RollbackOf is the ID of the deployment a rollback reverted.
+	RollbackOf string `json:"rollback_of,omitempty"`
+}
+
+// upgradeCommand returns the helm command installing the deployment, with
+// the package and values files read from under root.
+func (dep Deployment) upgradeCommand(root string) string {
+	args := []string{"helm", "upgrade", "--install", shellQuote(dep.Release), shellQuote(filepath.ToSlash(filepath.Join(root, dep.Package)))}
+	if dep.Namespace != "" {
+		args = append(args, "--namespace", shellQuote(dep.Namespace))
+	}
+	for _, file := range dep.Values {
+		args = append(args, "--values", shellQuote(filepath.ToSlash(filepath.Join(root, file))))
+	}
+	keys := make([]string, 0, len(dep.Set))
+	for key := range dep.Set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "--set", shellQuote(key+"="+dep.Set[key]))
+	}
+	return strings.Join(args, " ")
+}
+
+// recordDeployment sets the status of a deployment from the error of its
+// helm commands and appends it to the history. Successful deployments
+// keep a copy of their package and values files in DeploymentsDir.
+func recordDeployment(dep *Deployment, runErr error) error {
+	dep.Status = DeploySucceeded
+	if runErr != nil {
+		dep.Status = DeployFailed
+	}
+	var stageErr error
+	if runErr == nil && dep.Dir == "" {
+		dep.Dir = filepath.ToSlash(filepath.Join(DeploymentsDir, dep.ID))
+		if stageErr = stageArtifacts(append([]string{dep.Package}, dep.Values...), dep.Dir); stageErr != nil {
+			stageErr = fmt.Errorf("failed to keep the files of deployment %s: %w", dep.ID, stageErr)
+		}
+	}
+	if err := appendHistory(deploymentsFile, dep); err != nil {
+		return errors.Join(runErr, stageErr, fmt.Errorf("failed to record deployment: %w", err))
+	}
+	return errors.Join(runErr, stageErr)
+}
+
+// LoadDeployments reads the deployment history, oldest first.
+func LoadDeployments() ([]Deployment, error) {
+	data, err := os.ReadFile(filepath.Join(HistoryDir, deploymentsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return []Deployment{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	deployments := []Deployment{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var dep Deployment
+		if err := json.Unmarshal(scanner.Bytes(), &dep); err != nil {
+			return nil, fmt.Errorf("invalid deployment on line %d of %s: %w", line, deploymentsFile, err)
+		}
+		deployments = append(deployments, dep)
+	}
+	return deployments, scanner.Err()
+}
+
+// liveDeployments returns the successful deployments of an environment
+// that have not been rolled back, the one currently live last. Each
+// rollback removes the deployment it reverted.
+func liveDeployments(history []Deployment, environment string) []Deployment {
+	live := []Deployment{}
+	for _, dep := range history {
+		if dep.Environment != environment || dep.Status != DeploySucceeded {
+			continue
+		}
+		if dep.RollbackOf != "" {
+			if len(live) > 0 {
+				live = live[:len(live)-1]
+			}
+			continue
+		}
+		live = append(live, dep)
+	}
+	return live
+}
+
+// Rollback redeploys the previous successful deployment of an environment,
+// from the package and values files kept when it was deployed, and
+// records the rollback in the history. Rolling back again goes one
+// deployment further back. Rolling back is refused in read-only mode.
+func (d *ProjectDefinition) Rollback(ctx context.Context, environment string, shellExecutor ShellExecutor, now time.Time) (Deployment, Deployment, error) {
+	if ReadOnlyFromContext(ctx) {
+		return Deployment{}, Deployment{}, errors.New("rolling back is not allowed in read-only mode")
+	}
+	if environment == "" {
+		environment = DefaultDeployEnvironment
+	}
+	history, err := LoadDeployments()
+	if err != nil {
+		return Deployment{}, Deployment{}, err
+	}
+	live := liveDeployments(history, environment)
+	if len(live) < 2 {
+		return Deployment{}, Deployment{}, fmt.Errorf("no earlier successful deployment to roll back to in environment '%s'", environment)
+	}
+	current, target := live[len(live)-1], live[len(live)-2]
+	if _, err := os.Stat(filepath.Join(target.Dir, target.Package)); err != nil {
+		return current, target, fmt.Errorf("the files of deployment %s are missing: %w", target.ID, err)
+	}
+
+	rollback := target
+	rollback.ID = newRunID("", environment, now)
+	rollback.RollbackOf = current.ID
+	rollback.User = os.Getenv("USER")
+	rollback.DeployedAt = now.UTC()
+	err = d.runCommands(ctx, "rollback", shellExecutor, nil, rollback.upgradeCommand(target.Dir))
+	return current, rollback, recordDeployment(&rollback, err)
+}
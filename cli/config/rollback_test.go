@@ -0,0 +1,70 @@
+package config
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiveDeployments(t *testing.T) {
+	history := []Deployment{
+		{ID: "1", Environment: "prod", Status: DeploySucceeded},
+		{ID: "2", Environment: "staging", Status: DeploySucceeded},
+		{ID: "3", Environment: "prod", Status: DeploySucceeded},
+		{ID: "4", Environment: "prod", Status: DeployFailed},
+		{ID: "5", Environment: "prod", Status: DeploySucceeded},
+		{ID: "6", Environment: "prod", Status: DeploySucceeded, RollbackOf: "5"},
+	}
+	ids := []string{}
+	for _, dep := range liveDeployments(history, "prod") {
+		ids = append(ids, dep.ID)
+	}
+	assert.Equal(t, []string{"1", "3"}, ids)
+}
+
+func TestProjectDefinition_Rollback(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeChart(t)
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	project := ProjectDefinition{ID: "app", Deploy: Deploy{Helm: HelmConfig{Chart: "deploy/chart"}}}
+	m := &MockShellExecutor{}
+	calls := []string{}
+	recordCalls(m, &calls,
+		"helm package 'deploy/chart' --destination '.devops/artifacts/charts' --version '1.0.0' --app-version '1.0.0'",
+		"helm package 'deploy/chart' --destination '.devops/artifacts/charts' --version '1.1.0' --app-version '1.1.0'",
+		"helm upgrade --install 'app' '.devops/artifacts/charts/app-1.0.0.tgz'",
+		"helm upgrade --install 'app' '.devops/artifacts/charts/app-1.1.0.tgz'",
+		"helm upgrade --install 'app' '.devops/deployments/20260301T100000Z-default/.devops/artifacts/charts/app-1.0.0.tgz'",
+	)
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	_, _, err := project.Rollback(ctx, "", m, now)
+	assert.EqualError(t, err, "no earlier successful deployment to roll back to in environment 'default'")
+
+	for i, version := range []string{"1.0.0", "1.1.0"} {
+		project.Version = version
+		writeDeployFiles(t, ".devops/artifacts/charts/app-"+version+".tgz")
+		_, err := project.DeployHelm(ctx, "", m, now.Add(time.Duration(i)*time.Hour))
+		require.NoError(t, err)
+	}
+
+	current, rollback, err := project.Rollback(ctx, "", m, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "20260301T110000Z-default", current.ID)
+	assert.Equal(t, "20260301T120000Z-default", rollback.ID)
+	assert.Equal(t, "20260301T110000Z-default", rollback.RollbackOf)
+	assert.Equal(t, "1.0.0", rollback.Version)
+	assert.Equal(t, "helm upgrade --install 'app' '.devops/deployments/20260301T100000Z-default/.devops/artifacts/charts/app-1.0.0.tgz'", calls[len(calls)-1])
+
+	_, _, err = project.Rollback(ctx, "", m, now.Add(3*time.Hour))
+	assert.EqualError(t, err, "no earlier successful deployment to roll back to in environment 'default'")
+
+	_, _, err = project.Rollback(WithReadOnly(ctx), "", m, now)
+	assert.EqualError(t, err, "rolling back is not allowed in read-only mode")
+}
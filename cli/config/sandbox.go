@@ -0,0 +1,93 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/jgfranco17/devops/cli/executor"
+)
+
+// sandboxSystemPaths are always available inside a sandbox so that basic
+// utilities keep working alongside the declared toolchain directories.
+var sandboxSystemPaths = []string{"/usr/bin", "/bin"}
+
+// sandboxPassthroughEnv lists the host variables a sandbox still inherits.
+var sandboxPassthroughEnv = []string{"USER", "LOGNAME", "LANG", "LC_ALL", "TERM", "TZ"}
+
+// sandbox runs steps with a throwaway HOME, a curated PATH and no shell
+// startup files, so results do not depend on the developer's machine.
+type sandbox struct {
+	home string
+	env  []string
+}
+
+func newSandbox(op *Operation) (*sandbox, error) {
+	if runtime.GOOS == "windows" {
+		return nil, errors.New("sandbox is not supported on windows")
+	}
+	home, err := os.MkdirTemp("", "devops-home-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sandbox home: %w", err)
+	}
+
+	paths, err := absPaths(append(slices.Clone(op.PathPrepend), op.ToolPaths...))
+	if err != nil {
+		_ = os.RemoveAll(home)
+		return nil, err
+	}
+	paths = append(paths, sandboxSystemPaths...)
+
+	env := []string{
+		"HOME=" + home,
+		"PATH=" + strings.Join(paths, string(os.PathListSeparator)),
+	}
+	for _, key := range sandboxPassthroughEnv {
+		if val, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+val)
+		}
+	}
+	env = append(env, envPairs(op.Env)...)
+
+	return &sandbox{home: home, env: env}, nil
+}
+
+// wrap rewrites a step so its shell is kept away from startup files. The
+// step runs with the clean environment from environ, which is passed as
+// the process env rather than on the command line, where ps and traces
+// would show secrets.
+func (s *sandbox) wrap(step Step) (string, error) {
+	var parts []string
+	switch step.Shell {
+	case "", executor.ShellBash:
+		parts = []string{"bash", "--noprofile", "--norc", "-c", shellQuote(step.Run)}
+	case executor.ShellZsh:
+		parts = []string{"zsh", "--no-rcs", "-c", shellQuote(step.Run)}
+	default:
+		argv, err := executor.ShellArgs(step.Shell, step.Run)
+		if err != nil {
+			return "", err
+		}
+		for _, arg := range argv {
+			parts = append(parts, shellQuote(arg))
+		}
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// environ returns the complete environment of a step in the sandbox.
+func (s *sandbox) environ(step Step) []string {
+	return append(slices.Clone(s.env), envPairs(step.Env)...)
+}
+
+func (s *sandbox) cleanup() {
+	_ = os.RemoveAll(s.home)
+}
+
+// shellQuote quotes a string for safe use as a single shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
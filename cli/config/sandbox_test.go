@@ -0,0 +1,94 @@
+package config
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, `'echo hello'`, shellQuote("echo hello"))
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+	assert.Equal(t, `''`, shellQuote(""))
+}
+
+func TestNewSandbox(t *testing.T) {
+	op := &Operation{
+		ToolPaths: []string{"/opt/go/bin"},
+		Env:       map[string]string{"B_VAR": "2", "A_VAR": "1"},
+	}
+
+	sb, err := newSandbox(op)
+	require.NoError(t, err)
+	defer sb.cleanup()
+
+	assert.DirExists(t, sb.home)
+	assert.Contains(t, sb.env, "HOME="+sb.home)
+	assert.Contains(t, sb.env, "PATH=/opt/go/bin:/usr/bin:/bin")
+
+	joined := strings.Join(sb.env, " ")
+	assert.Less(t, strings.Index(joined, "A_VAR=1"), strings.Index(joined, "B_VAR=2"))
+
+	wrapped, err := sb.wrap(Step{Run: "echo $HOME"})
+	require.NoError(t, err)
+	assert.Equal(t, "bash --noprofile --norc -c 'echo $HOME'", wrapped)
+	assert.Equal(t, append(slices.Clone(sb.env), "STEP_VAR=3"), sb.environ(Step{Env: map[string]string{"STEP_VAR": "3"}}))
+
+	wrapped, err = sb.wrap(Step{Run: "echo $HOME", Shell: "sh"})
+	require.NoError(t, err)
+	assert.Equal(t, "'sh' '-c' 'echo $HOME'", wrapped)
+
+	wrapped, err = sb.wrap(Step{Run: `echo "$HOME"`, Shell: "none"})
+	require.NoError(t, err)
+	assert.Equal(t, "'echo' '$HOME'", wrapped)
+
+	sb.cleanup()
+	assert.NoDirExists(t, sb.home)
+}
+
+func TestNewSandbox_PathPrepend(t *testing.T) {
+	sb, err := newSandbox(&Operation{PathPrepend: []string{"/work/bin"}, ToolPaths: []string{"/opt/go/bin"}})
+	require.NoError(t, err)
+	defer sb.cleanup()
+
+	assert.Contains(t, sb.env, "PATH=/work/bin:/opt/go/bin:/usr/bin:/bin")
+}
+
+func TestOperation_Run_Sandbox(t *testing.T) {
+	t.Setenv("DEVOPS_SANDBOX_LEAK", "leaked")
+
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+
+	t.Run("wraps steps", func(t *testing.T) {
+		mockExecutor := &MockShellExecutor{}
+		mockExecutor.On("Exec", withEnv(func(env []string) bool {
+			return strings.HasPrefix(envValue(env, "HOME"), os.TempDir()) && envValue(env, "DEVOPS_SANDBOX_LEAK") == ""
+		}), "bash --noprofile --norc -c 'make'").Return(executor.Result{}, nil)
+
+		op := Operation{Sandbox: true, Steps: StepsFromCommands("make")}
+		assert.NoError(t, op.Run(ctx, mockExecutor))
+		mockExecutor.AssertExpectations(t)
+	})
+
+	t.Run("isolates environment with real executor", func(t *testing.T) {
+		op := &Operation{Sandbox: true, Env: map[string]string{"FOO": "bar"}}
+		sb, err := newSandbox(op)
+		require.NoError(t, err)
+		defer sb.cleanup()
+
+		command, err := sb.wrap(Step{Run: `echo "$HOME|$PATH|$FOO|${DEVOPS_SANDBOX_LEAK:-unset}"`})
+		require.NoError(t, err)
+		result, err := (&executor.DefaultExecutor{}).Exec(executor.WithEnv(ctx, sb.environ(Step{})), command)
+		require.NoError(t, err)
+		assert.Equal(t, sb.home+"|/usr/bin:/bin|bar|unset\n", result.Stdout)
+	})
+}
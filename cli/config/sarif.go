@@ -0,0 +1,125 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/jgfranco17/devops/internal/fileutils"
+)
+
+const (
+	sarifSchema   = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion  = "2.1.0"
+	sarifToolName = "devops-doctor"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version,omitempty"`
+	Rules   []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// WriteSARIF writes the failed findings as a SARIF 2.1.0 log for code
+// scanning, reported against the definition file at definitionPath.
+func (r *ValidationReport) WriteSARIF(w io.Writer, definitionPath string, toolVersion string) error {
+	ruleIDs := make([]string, 0, len(defaultSeverities))
+	for ruleID := range defaultSeverities {
+		ruleIDs = append(ruleIDs, ruleID)
+	}
+	sort.Strings(ruleIDs)
+	rules := make([]sarifRule, 0, len(ruleIDs))
+	for _, ruleID := range ruleIDs {
+		rules = append(rules, sarifRule{ID: ruleID, DefaultConfiguration: sarifConfiguration{Level: string(defaultSeverities[ruleID])}})
+	}
+
+	location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: fileutils.ToSlash(definitionPath)},
+	}}
+	results := []sarifResult{}
+	for _, finding := range r.Findings {
+		if finding.Passed {
+			continue
+		}
+		text := finding.Message
+		if finding.Remedy != "" {
+			text = fmt.Sprintf("%s. Remedy: %s", finding.Message, finding.Remedy)
+		}
+		result := sarifResult{
+			RuleID:    finding.RuleID,
+			Level:     string(finding.Severity),
+			Message:   sarifMessage{Text: text},
+			Locations: []sarifLocation{location},
+		}
+		if finding.Suppressed {
+			result.Suppressions = []sarifSuppression{{Kind: "inSource", Justification: finding.SuppressionReason}}
+		}
+		results = append(results, result)
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: sarifToolName, Version: toolVersion, Rules: rules}},
+			Results: results,
+		}},
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
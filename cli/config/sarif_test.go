@@ -0,0 +1,41 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationReport_WriteSARIF(t *testing.T) {
+	report := &ValidationReport{Findings: []Finding{
+		{RuleID: RuleIDFormat, Passed: true, Message: "ID: demo"},
+		{RuleID: RuleRepoURLRequired, Severity: SeverityError, Message: "Repo URL is required", Remedy: "Set repo_url"},
+		{RuleID: RuleDuplicateSteps, Severity: SeverityWarning, Message: "Duplicate steps", Suppressed: true, SuppressionReason: "intentional"},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteSARIF(&buf, `configs\devops-definition.yaml`, "1.2.3"))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	assert.Equal(t, "devops-doctor", run.Tool.Driver.Name)
+	assert.Equal(t, "1.2.3", run.Tool.Driver.Version)
+	assert.Len(t, run.Tool.Driver.Rules, len(defaultSeverities))
+
+	require.Len(t, run.Results, 2)
+	assert.Equal(t, sarifResult{
+		RuleID:  RuleRepoURLRequired,
+		Level:   "error",
+		Message: sarifMessage{Text: "Repo URL is required. Remedy: Set repo_url"},
+		Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: "configs/devops-definition.yaml"},
+		}}},
+	}, run.Results[0])
+	assert.Equal(t, []sarifSuppression{{Kind: "inSource", Justification: "intentional"}}, run.Results[1].Suppressions)
+}
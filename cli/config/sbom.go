@@ -0,0 +1,115 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/jgfranco17/devops/internal/sbom"
+)
+
+// SBOM formats.
+const (
+	SBOMCycloneDX = "cyclonedx"
+	SBOMSPDX      = "spdx"
+)
+
+var sbomFormats = []string{SBOMCycloneDX, SBOMSPDX}
+
+// errSyftNotFound is returned by runSyft when syft is not installed.
+var errSyftNotFound = errors.New("syft is not installed")
+
+// SBOMFile returns where the SBOM of a format is written by default.
+func SBOMFile(format string) string {
+	if format == SBOMSPDX {
+		return WorkDir + "/sbom.spdx.json"
+	}
+	return WorkDir + "/sbom.cdx.json"
+}
+
+// runSyft scans the current directory with syft and returns the SBOM.
+var runSyft = func(ctx context.Context, format string) ([]byte, error) {
+	if _, err := exec.LookPath("syft"); err != nil {
+		return nil, errSyftNotFound
+	}
+	cmd := exec.CommandContext(ctx, "syft", "scan", "dir:.", "--quiet", "--output", format+"-json")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := executor.RunCommand(ctx, cmd); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("syft: %s", msg)
+		}
+		return nil, fmt.Errorf("syft: %w", err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// GenerateSBOM produces a CycloneDX or SPDX bill of materials for the
+// project. It scans with syft when installed, and otherwise lists the
+// modules required by the go.mod of each codebase, which only works for
+// Go codebases. It returns the SBOM and the generator used.
+func (d *ProjectDefinition) GenerateSBOM(ctx context.Context, format string, devopsVersion string, now time.Time) ([]byte, string, error) {
+	if !slices.Contains(sbomFormats, format) {
+		msg := fmt.Sprintf("unknown SBOM format '%s'", format)
+		if suggestion := closestMatch(format, sbomFormats); suggestion != "" {
+			msg += fmt.Sprintf(" (did you mean '%s'?)", suggestion)
+		}
+		return nil, "", errors.New(msg)
+	}
+	data, err := runSyft(ctx, format)
+	if err == nil {
+		return data, "syft", nil
+	}
+	if !errors.Is(err, errSyftNotFound) {
+		return nil, "", err
+	}
+
+	doc := sbom.Document{Name: d.ID, Version: d.Version, Tool: "devops", ToolVersion: devopsVersion, Created: now}
+	seen := map[string]bool{}
+	for _, view := range d.codebaseViews() {
+		if view.Codebase.Language != "go" {
+			return nil, "", fmt.Errorf("syft is not installed and the built-in generator only supports go codebases, not %s", view.Codebase.Language)
+		}
+		path := filepath.Join(view.Codebase.Path, "go.mod")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, "", err
+		}
+		_, components, err := sbom.ParseGoMod(data)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", path, err)
+		}
+		for _, component := range components {
+			if !seen[component.PURL] {
+				seen[component.PURL] = true
+				doc.Components = append(doc.Components, component)
+			}
+		}
+	}
+	if format == SBOMSPDX {
+		data, err = sbom.SPDX(doc)
+	} else {
+		data, err = sbom.CycloneDX(doc)
+	}
+	return data, "devops", err
+}
+
+// sbomFiles returns the SBOMs present at their default locations.
+func sbomFiles() []string {
+	files := []string{}
+	for _, format := range sbomFormats {
+		if _, err := os.Stat(SBOMFile(format)); err == nil {
+			files = append(files, SBOMFile(format))
+		}
+	}
+	return files
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubSyft replaces syft with fn for the duration of the test.
+func stubSyft(t *testing.T, fn func(ctx context.Context, format string) ([]byte, error)) {
+	original := runSyft
+	runSyft = fn
+	t.Cleanup(func() { runSyft = original })
+}
+
+func TestProjectDefinition_GenerateSBOM(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile("go.mod", []byte("module example.com/app\n\nrequire github.com/spf13/cobra v1.9.1\n"), 0644))
+	project := ProjectDefinition{ID: "app", Version: "1.0.0", Codebase: Codebase{Language: "go"}}
+
+	t.Run("native without syft", func(t *testing.T) {
+		stubSyft(t, func(ctx context.Context, format string) ([]byte, error) { return nil, errSyftNotFound })
+		data, generator, err := project.GenerateSBOM(ctx, SBOMCycloneDX, "0.1.0", now)
+		require.NoError(t, err)
+		assert.Equal(t, "devops", generator)
+		var bom map[string]any
+		require.NoError(t, json.Unmarshal(data, &bom))
+		assert.Equal(t, "CycloneDX", bom["bomFormat"])
+		assert.Len(t, bom["components"], 1)
+
+		data, _, err = project.GenerateSBOM(ctx, SBOMSPDX, "0.1.0", now)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"spdxVersion": "SPDX-2.3"`)
+
+		python := ProjectDefinition{ID: "app", Codebase: Codebase{Language: "python"}}
+		_, _, err = python.GenerateSBOM(ctx, SBOMSPDX, "0.1.0", now)
+		assert.EqualError(t, err, "syft is not installed and the built-in generator only supports go codebases, not python")
+	})
+
+	t.Run("syft when installed", func(t *testing.T) {
+		stubSyft(t, func(ctx context.Context, format string) ([]byte, error) {
+			return []byte(`{"scanned": "` + format + `"}`), nil
+		})
+		data, generator, err := project.GenerateSBOM(ctx, SBOMSPDX, "0.1.0", now)
+		require.NoError(t, err)
+		assert.Equal(t, "syft", generator)
+		assert.Equal(t, `{"scanned": "spdx"}`, string(data))
+
+		stubSyft(t, func(ctx context.Context, format string) ([]byte, error) { return nil, errors.New("syft: scan failed") })
+		_, _, err = project.GenerateSBOM(ctx, SBOMSPDX, "0.1.0", now)
+		assert.EqualError(t, err, "syft: scan failed")
+	})
+
+	_, _, err := project.GenerateSBOM(ctx, "cyclone", "0.1.0", now)
+	assert.EqualError(t, err, "unknown SBOM format 'cyclone' (did you mean 'cyclonedx'?)")
+}
+
+func TestProjectDefinition_ArtifactDigests_SBOM(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.MkdirAll(WorkDir, 0755))
+	require.NoError(t, os.WriteFile(SBOMFile(SBOMCycloneDX), []byte("hello"), 0644))
+
+	digests, err := (&ProjectDefinition{ID: "app"}).ArtifactDigests()
+	require.NoError(t, err)
+	assert.Equal(t, []ArtifactDigest{{Path: ".devops/sbom.cdx.json", SHA256: helloSHA256}}, digests)
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+)
+
+// Secret is a sensitive value resolved when an operation runs, from either
+// a host environment variable or a file. Steps see it as an environment
+// variable named after the secret, and its value is masked from their
+// output.
+type Secret struct {
+	Env  string `yaml:"env,omitempty"`
+	File string `yaml:"file,omitempty"`
+}
+
+// resolve reads the secret value. It reports false if the source is not
+// available on this machine.
+func (s Secret) resolve() (string, bool, error) {
+	switch {
+	case s.Env != "" && s.File != "":
+		return "", false, errors.New("set only one of env or file")
+	case s.Env != "":
+		value, ok := os.LookupEnv(s.Env)
+		return value, ok, nil
+	case s.File != "":
+		data, err := os.ReadFile(s.File)
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", false, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+		return strings.TrimRight(string(data), "\r\n"), true, nil
+	default:
+		return "", false, errors.New("set env or file")
+	}
+}
+
+// withSecrets resolves the definition secrets into the operation env and
+// registers their values, and those of decrypted values, for masking.
+// Secrets that are not available are skipped with a warning, so
+// operations that do not need them still run.
+func (d *ProjectDefinition) withSecrets(ctx context.Context, op Operation) (context.Context, Operation, error) {
+	if len(d.decrypted) > 0 {
+		ctx = executor.WithSecrets(ctx, d.decrypted...)
+	}
+	if len(d.Secrets) == 0 {
+		return ctx, op, nil
+	}
+	logger := logging.FromContext(ctx)
+	names := make([]string, 0, len(d.Secrets))
+	for name := range d.Secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	env := maps.Clone(op.Env)
+	if env == nil {
+		env = map[string]string{}
+	}
+	values := []string{}
+	for _, name := range names {
+		value, ok, err := d.Secrets[name].resolve()
+		if err != nil {
+			return ctx, op, fmt.Errorf("secret %s: %w", name, err)
+		}
+		if !ok {
+			logger.Warnf("Secret %s is not available, skipping it", name)
+			continue
+		}
+		env[name] = value
+		values = append(values, value)
+	}
+	op.Env = env
+	return executor.WithSecrets(ctx, values...), op, nil
+}
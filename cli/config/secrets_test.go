@@ -0,0 +1,106 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecret_Resolve(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("from-file\n"), 0600))
+	t.Setenv("DEVOPS_TEST_TOKEN", "from-env")
+
+	tests := []struct {
+		name          string
+		secret        Secret
+		expected      string
+		expectedOK    bool
+		expectedError string
+	}{
+		{name: "from env", secret: Secret{Env: "DEVOPS_TEST_TOKEN"}, expected: "from-env", expectedOK: true},
+		{name: "from file", secret: Secret{File: tokenFile}, expected: "from-file", expectedOK: true},
+		{name: "unset env", secret: Secret{Env: "DEVOPS_TEST_UNSET"}},
+		{name: "missing file", secret: Secret{File: filepath.Join(dir, "missing")}},
+		{name: "both sources", secret: Secret{Env: "A", File: "b"}, expectedError: "set only one of env or file"},
+		{name: "no source", secret: Secret{}, expectedError: "set env or file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, ok, err := tt.secret.resolve()
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedOK, ok)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestProjectDefinition_Run_Secrets(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	t.Setenv("DEVOPS_TEST_TOKEN", "s3cr3t")
+	project := ProjectDefinition{
+		Secrets: map[string]Secret{
+			"TOKEN":   {Env: "DEVOPS_TEST_TOKEN"},
+			"MISSING": {Env: "DEVOPS_TEST_UNSET"},
+		},
+		Codebase: Codebase{Operations: map[string]Operation{
+			"deploy": {Steps: StepsFromCommands("deploy --token $TOKEN")},
+		}},
+	}
+	m := &MockShellExecutor{}
+	m.On("Exec", mock.MatchedBy(func(ctx context.Context) bool {
+		env := executor.EnvFromContext(ctx)
+		return slices.Contains(env, "TOKEN=s3cr3t") &&
+			!slices.ContainsFunc(env, func(e string) bool { return strings.HasPrefix(e, "MISSING=") }) &&
+			slices.Equal(executor.SecretsFromContext(ctx), []string{"s3cr3t"})
+	}), "deploy --token $TOKEN").Return(executor.Result{}, nil)
+
+	require.NoError(t, project.Run(ctx, "deploy", m))
+	m.AssertExpectations(t)
+}
+
+func TestProjectDefinition_Run_InvalidSecret(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	project := ProjectDefinition{
+		Secrets: map[string]Secret{"TOKEN": {}},
+		Codebase: Codebase{Operations: map[string]Operation{
+			"deploy": {Steps: StepsFromCommands("deploy")},
+		}},
+	}
+	m := &MockShellExecutor{}
+
+	assert.ErrorContains(t, project.Run(ctx, "deploy", m), "secret TOKEN: set env or file")
+	m.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything)
+}
+
+func TestLoad_Secrets(t *testing.T) {
+	yamlContent := `id: shared
+secrets:
+  TOKEN:
+    env: CI_TOKEN
+  KEY:
+    file: .secrets/key
+codebase:
+  language: go
+`
+	cfg, err := Load(strings.NewReader(yamlContent))
+	require.NoError(t, err)
+	assert.Equal(t, Secret{Env: "CI_TOKEN"}, cfg.Secrets["TOKEN"])
+	assert.Equal(t, Secret{File: ".secrets/key"}, cfg.Secrets["KEY"])
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/jgfranco17/devops/cli/executor"
+)
+
+// Signing methods.
+const (
+	SigningCosign = "cosign"
+	SigningGPG    = "gpg"
+)
+
+var signingMethods = []string{SigningCosign, SigningGPG}
+
+// Signing configures detached signatures of the manifest, checksums and
+// release packages. Cosign signs keyless through Sigstore unless a key is
+// given; GPG signs with the default key unless one is given.
+type Signing struct {
+	Method string `yaml:"method"`
+	Key    string `yaml:"key,omitempty"`
+
+	// Identity and Issuer are the certificate identity and OIDC issuer a
+	// keyless cosign signature must carry to verify. They are given when
+	// verifying rather than in the definition.
+	Identity string `yaml:"-"`
+	Issuer   string `yaml:"-"`
+}
+
+// runSigner runs a signing tool, returning its stderr in the error.
+var runSigner = func(ctx context.Context, name string, args ...string) error {
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("%s is not installed or not on PATH", name)
+	}
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := executor.RunCommand(ctx, cmd); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %s", name, msg)
+		}
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// Enabled reports whether signing is configured.
+func (s Signing) Enabled() bool {
+	return s.Method != ""
+}
+
+// validate checks the method is known.
+func (s Signing) validate() error {
+	for _, method := range signingMethods {
+		if s.Method == method {
+			return nil
+		}
+	}
+	msg := fmt.Sprintf("unknown signing method '%s'", s.Method)
+	if suggestion := closestMatch(s.Method, signingMethods); suggestion != "" {
+		msg += fmt.Sprintf(" (did you mean '%s'?)", suggestion)
+	}
+	return errors.New(msg)
+}
+
+// SignatureFile returns where the signature of path is written: a
+// Sigstore bundle for cosign and an ASCII-armored signature for GPG.
+func (s Signing) SignatureFile(path string) string {
+	if s.Method == SigningGPG {
+		return path + ".asc"
+	}
+	return path + ".sigstore.json"
+}
+
+// Sign writes a detached signature of path and returns its location.
+func (s Signing) Sign(ctx context.Context, path string) (string, error) {
+	if err := s.validate(); err != nil {
+		return "", err
+	}
+	signature := s.SignatureFile(path)
+	var err error
+	switch s.Method {
+	case SigningCosign:
+		args := []string{"sign-blob", "--yes", "--bundle", signature}
+		if s.Key != "" {
+			args = append(args, "--key", s.Key)
+		}
+		err = runSigner(ctx, "cosign", append(args, path)...)
+	case SigningGPG:
+		args := []string{"--batch", "--yes", "--armor", "--detach-sign", "--output", signature}
+		if s.Key != "" {
+			args = append(args, "--local-user", s.Key)
+		}
+		err = runSigner(ctx, "gpg", append(args, path)...)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to sign %s: %w", path, err)
+	}
+	return signature, nil
+}
+
+// SignFiles signs each file, returning the signatures.
+func (s Signing) SignFiles(ctx context.Context, paths ...string) ([]string, error) {
+	signatures := []string{}
+	for _, path := range paths {
+		signature, err := s.Sign(ctx, path)
+		if err != nil {
+			return signatures, err
+		}
+		signatures = append(signatures, signature)
+	}
+	return signatures, nil
+}
+
+// Verify checks the detached signature of path. Keyless cosign
+// signatures need the expected identity and issuer.
+func (s Signing) Verify(ctx context.Context, path string) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	signature := s.SignatureFile(path)
+	if _, err := os.Stat(signature); err != nil {
+		return fmt.Errorf("signature %s not found", signature)
+	}
+	var err error
+	switch s.Method {
+	case SigningCosign:
+		args := []string{"verify-blob", "--bundle", signature}
+		switch {
+		case s.Key != "":
+			args = append(args, "--key", s.Key)
+		case s.Identity == "" || s.Issuer == "":
+			return errors.New("verifying a keyless cosign signature needs the certificate identity and OIDC issuer")
+		default:
+			args = append(args, "--certificate-identity", s.Identity, "--certificate-oidc-issuer", s.Issuer)
+		}
+		err = runSigner(ctx, "cosign", append(args, path)...)
+	case SigningGPG:
+		err = runSigner(ctx, "gpg", "--batch", "--verify", signature, path)
+	}
+	if err != nil {
+		return fmt.Errorf("signature of %s does not verify: %w", path, err)
+	}
+	return nil
+}
+
+// DetectSigning returns the signing method of the signature found next to
+// path, or an error when there is none.
+func DetectSigning(path string) (Signing, error) {
+	for _, method := range signingMethods {
+		s := Signing{Method: method}
+		if _, err := os.Stat(s.SignatureFile(path)); err == nil {
+			return s, nil
+		}
+	}
+	return Signing{}, fmt.Errorf("no signature found for %s", path)
+}
+
+// checkSigning reports an unusable signing section.
+func (d *ProjectDefinition) checkSigning(b *reportBuilder) {
+	if !d.Signing.Enabled() {
+		return
+	}
+	if err := d.Signing.validate(); err != nil {
+		b.fail(RuleSigning, "Set signing.method to cosign or gpg", "signing: %s", err.Error())
+		return
+	}
+	b.pass(RuleSigning, "Signing: %s", d.Signing.Method)
+}
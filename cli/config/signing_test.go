@@ -0,0 +1,101 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubSigner replaces the signing tool, recording each invocation and
+// writing the signature files a real tool would.
+func stubSigner(t *testing.T, err error) *[][]string {
+	calls := [][]string{}
+	original := runSigner
+	runSigner = func(ctx context.Context, name string, args ...string) error {
+		calls = append(calls, append([]string{name}, args...))
+		for i, arg := range args {
+			if (arg == "--bundle" || arg == "--output") && args[0] != "verify-blob" {
+				require.NoError(t, os.WriteFile(args[i+1], []byte("signature"), 0644))
+			}
+		}
+		return err
+	}
+	t.Cleanup(func() { runSigner = original })
+	return &calls
+}
+
+func TestSigning_Sign(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	calls := stubSigner(t, nil)
+
+	signature, err := Signing{Method: SigningCosign}.Sign(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, path+".sigstore.json", signature)
+
+	signature, err = Signing{Method: SigningGPG, Key: "releases@example.com"}.Sign(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, path+".asc", signature)
+
+	assert.Equal(t, [][]string{
+		{"cosign", "sign-blob", "--yes", "--bundle", path + ".sigstore.json", path},
+		{"gpg", "--batch", "--yes", "--armor", "--detach-sign", "--output", path + ".asc", "--local-user", "releases@example.com", path},
+	}, *calls)
+
+	_, err = Signing{Method: "cosing"}.Sign(ctx, path)
+	assert.EqualError(t, err, "unknown signing method 'cosing' (did you mean 'cosign'?)")
+
+	stubSigner(t, errors.New("cosign: no identity token"))
+	_, err = Signing{Method: SigningCosign}.Sign(ctx, path)
+	assert.EqualError(t, err, "failed to sign "+path+": cosign: no identity token")
+}
+
+func TestSigning_Verify(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "SHA256SUMS")
+	calls := stubSigner(t, nil)
+
+	err := Signing{Method: SigningCosign}.Verify(ctx, path)
+	assert.EqualError(t, err, "signature "+path+".sigstore.json not found")
+
+	require.NoError(t, os.WriteFile(path+".sigstore.json", []byte("bundle"), 0644))
+	detected, err := DetectSigning(path)
+	require.NoError(t, err)
+	assert.Equal(t, SigningCosign, detected.Method)
+
+	err = detected.Verify(ctx, path)
+	assert.EqualError(t, err, "verifying a keyless cosign signature needs the certificate identity and OIDC issuer")
+
+	keyless := Signing{Method: SigningCosign, Identity: "ci@example.com", Issuer: "https://token.actions.githubusercontent.com"}
+	require.NoError(t, keyless.Verify(ctx, path))
+	assert.Equal(t, []string{
+		"cosign", "verify-blob", "--bundle", path + ".sigstore.json",
+		"--certificate-identity", "ci@example.com",
+		"--certificate-oidc-issuer", "https://token.actions.githubusercontent.com", path,
+	}, (*calls)[0])
+
+	stubSigner(t, errors.New("gpg: BAD signature"))
+	require.NoError(t, os.WriteFile(path+".asc", []byte("signature"), 0644))
+	err = Signing{Method: SigningGPG}.Verify(ctx, path)
+	assert.EqualError(t, err, "signature of "+path+" does not verify: gpg: BAD signature")
+
+	_, err = DetectSigning(filepath.Join(t.TempDir(), "unsigned"))
+	assert.ErrorContains(t, err, "no signature found")
+}
+
+func TestProjectDefinition_Report_Signing(t *testing.T) {
+	project := ProjectDefinition{ID: "app", Signing: Signing{Method: SigningGPG}}
+	finding, ok := findingFor(project.Report(), RuleSigning)
+	require.True(t, ok)
+	assert.True(t, finding.Passed)
+
+	project.Signing.Method = "pgp"
+	finding, ok = findingFor(project.Report(), RuleSigning)
+	require.True(t, ok)
+	assert.False(t, finding.Passed)
+}
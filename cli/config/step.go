@@ -0,0 +1,188 @@
+package config
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jgfranco17/devops/cli/executor"
+	"gopkg.in/yaml.v3"
+)
+
+// Step is a single command in an operation. In the definition file it can
+// be written either as a plain command string or as a mapping.
+type Step struct {
+	Name         string            `yaml:"name,omitempty"`
+	Run          string            `yaml:"run"`
+	Env          map[string]string `yaml:"env,omitempty"`
+	Timeout      time.Duration     `yaml:"timeout,omitempty"`
+	AllowFailure bool              `yaml:"allow_failure,omitempty"`
+	When         string            `yaml:"when,omitempty"`
+	Shell        string            `yaml:"shell,omitempty"`
+	PTY          bool              `yaml:"pty,omitempty"`
+}
+
+// Label returns the step name if set, otherwise the command.
+func (s Step) Label() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.Run
+}
+
+// UnmarshalYAML accepts both the string and the mapping form of a step.
+// Unknown keys in the mapping form are reported the same way as the
+// strict decoder reports them elsewhere in the file.
+func (s *Step) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*s = Step{Run: value.Value}
+		return nil
+	}
+	if value.Kind != yaml.MappingNode {
+		return &yaml.TypeError{Errors: []string{
+			fmt.Sprintf("line %d: step must be a command string or a mapping", value.Line),
+		}}
+	}
+
+	stepType := reflect.TypeOf(Step{})
+	known := collectYAMLFields(stepType, map[string][]string{})[stepType.String()]
+	unknown := []string{}
+	hasRun := false
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		key := value.Content[i]
+		if key.Value == "run" {
+			hasRun = true
+		}
+		if !slices.Contains(known, key.Value) {
+			unknown = append(unknown, fmt.Sprintf("line %d: field %s not found in type %s", key.Line, key.Value, stepType.String()))
+		}
+	}
+	if !hasRun {
+		unknown = append(unknown, fmt.Sprintf("line %d: step is missing the 'run' command", value.Line))
+	}
+	if len(unknown) > 0 {
+		return &yaml.TypeError{Errors: unknown}
+	}
+
+	type rawStep Step
+	var raw rawStep
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+	*s = Step(raw)
+	return nil
+}
+
+// MarshalYAML writes steps that only carry a command in the string form.
+func (s Step) MarshalYAML() (any, error) {
+	if s.Name == "" && len(s.Env) == 0 && s.Timeout == 0 && !s.AllowFailure && s.When == "" && s.Shell == "" && !s.PTY {
+		return s.Run, nil
+	}
+	type rawStep Step
+	return rawStep(s), nil
+}
+
+// checkShells verifies that every step runs with a known shell, and with a
+// POSIX one if the operation sets a umask.
+func (op *Operation) checkShells() error {
+	for _, step := range slices.Concat(op.Steps, op.Cleanup) {
+		shell := cmp.Or(step.Shell, op.Shell, executor.DefaultShell())
+		if !slices.Contains(executor.Shells, shell) {
+			return fmt.Errorf("step '%s': unknown shell '%s', expected one of: %s", step.Label(), shell, strings.Join(executor.Shells, ", "))
+		}
+		if op.Umask != nil && !executor.IsPOSIXShell(shell) {
+			return fmt.Errorf("step '%s': umask needs a POSIX shell, not %s", step.Label(), shell)
+		}
+	}
+	return nil
+}
+
+// envPairs renders an env map as sorted KEY=VALUE pairs.
+func envPairs(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+env[key])
+	}
+	return pairs
+}
+
+// Commands returns the commands of the given steps in order.
+func Commands(steps []Step) []string {
+	commands := make([]string, 0, len(steps))
+	for _, step := range steps {
+		commands = append(commands, step.Run)
+	}
+	return commands
+}
+
+// StepsFromCommands builds plain steps from command strings.
+func StepsFromCommands(commands ...string) []Step {
+	steps := make([]Step, 0, len(commands))
+	for _, command := range commands {
+		steps = append(steps, Step{Run: command})
+	}
+	return steps
+}
+
+const stepSelectionKey contextKey = "step-selection"
+
+// stepSelection picks a single step of an operation to run.
+type stepSelection struct {
+	operation string
+	selector  string
+}
+
+// WithStep makes the named operation run only the step selected by its
+// 1-based index or its name, for debugging. The operation is set up as for
+// a full run, but the operations it depends on are not run.
+func WithStep(ctx context.Context, operation string, selector string) context.Context {
+	return context.WithValue(ctx, stepSelectionKey, stepSelection{operation: operation, selector: selector})
+}
+
+// selectedStep returns the selector for the operation, if a step of it was
+// selected.
+func selectedStep(ctx context.Context, operation string) (string, bool) {
+	selection, ok := ctx.Value(stepSelectionKey).(stepSelection)
+	if !ok || selection.operation != operation {
+		return "", false
+	}
+	return selection.selector, true
+}
+
+// stepIndex returns the 0-based index of the step matching a selector,
+// which is either a 1-based index or a step name or command.
+func (op *Operation) stepIndex(selector string) (int, error) {
+	if n, err := strconv.Atoi(selector); err == nil {
+		if n < 1 || n > len(op.Steps) {
+			return 0, fmt.Errorf("step %d out of range, the operation has %d step(s)", n, len(op.Steps))
+		}
+		return n - 1, nil
+	}
+	labels := make([]string, len(op.Steps))
+	for idx, step := range op.Steps {
+		if step.Name == selector {
+			return idx, nil
+		}
+		labels[idx] = step.Label()
+	}
+	if idx := slices.Index(labels, selector); idx >= 0 {
+		return idx, nil
+	}
+	msg := fmt.Sprintf("no step named '%s'", selector)
+	if suggestion := closestMatch(selector, labels); suggestion != "" {
+		msg += fmt.Sprintf(" (did you mean '%s'?)", suggestion)
+	}
+	return 0, errors.New(msg)
+}
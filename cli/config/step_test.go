@@ -0,0 +1,211 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestLoad_StructuredSteps(t *testing.T) {
+	yamlContent := `id: structured
+codebase:
+  language: go
+  test:
+    steps:
+      - go vet ./...
+      - name: Unit tests
+        run: go test ./...
+        env:
+          CGO_ENABLED: "1"
+        timeout: 5m
+        allow_failure: true
+`
+	cfg, err := Load(strings.NewReader(yamlContent))
+	require.NoError(t, err)
+
+	steps := cfg.Codebase.Test.Steps
+	require.Len(t, steps, 2)
+	assert.Equal(t, Step{Run: "go vet ./..."}, steps[0])
+	assert.Equal(t, Step{
+		Name:         "Unit tests",
+		Run:          "go test ./...",
+		Env:          map[string]string{"CGO_ENABLED": "1"},
+		Timeout:      5 * time.Minute,
+		AllowFailure: true,
+	}, steps[1])
+	assert.Equal(t, "go vet ./...", steps[0].Label())
+	assert.Equal(t, "Unit tests", steps[1].Label())
+}
+
+func TestLoad_StructuredStepErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		steps         string
+		expectedError string
+	}{
+		{
+			name:          "unknown key",
+			steps:         "      - run: make\n        timout: 1m\n",
+			expectedError: "unknown field 'timout' (did you mean 'timeout'?) at line 6",
+		},
+		{
+			name:          "missing run",
+			steps:         "      - name: Build\n",
+			expectedError: "step is missing the 'run' command",
+		},
+		{
+			name:          "sequence step",
+			steps:         "      - [make, test]\n",
+			expectedError: "step must be a command string or a mapping",
+		},
+		{
+			name:          "invalid timeout",
+			steps:         "      - run: make\n        timeout: soon\n",
+			expectedError: "cannot unmarshal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yamlContent := "id: structured\ncodebase:\n  build:\n    steps:\n" + tt.steps
+			_, err := Load(strings.NewReader(yamlContent))
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestStep_MarshalYAML(t *testing.T) {
+	out, err := yaml.Marshal([]Step{
+		{Run: "make"},
+		{Name: "Slow", Run: "make slow", Timeout: time.Minute},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "- make\n- name: Slow\n  run: make slow\n  timeout: 1m0s\n", string(out))
+}
+
+func TestOperation_Run_StructuredSteps(t *testing.T) {
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+
+	t.Run("allowed failure does not fail the operation", func(t *testing.T) {
+		op := Operation{
+			FailFast: true,
+			Steps: []Step{
+				{Name: "Lint", Run: "golangci-lint run", AllowFailure: true},
+				{Run: "go test ./..."},
+			},
+		}
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, "golangci-lint run").Return(executor.Result{ExitCode: 1}, errors.New("exit status 1"))
+		m.On("Exec", mock.Anything, "go test ./...").Return(executor.Result{}, nil)
+
+		assert.NoError(t, op.Run(ctx, m))
+		m.AssertExpectations(t)
+	})
+
+	t.Run("failures are reported by step name", func(t *testing.T) {
+		op := Operation{Steps: []Step{{Name: "Unit tests", Run: "go test ./..."}}}
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, "go test ./...").Return(executor.Result{ExitCode: 1}, errors.New("exit status 1"))
+
+		assert.ErrorContains(t, op.Run(ctx, m), "[Unit tests]")
+	})
+
+	t.Run("step env applies only to its step", func(t *testing.T) {
+		op := Operation{Steps: []Step{
+			{Run: "make", Env: map[string]string{"STEP_VAR": "1"}},
+			{Run: "make check"},
+		}}
+		m := &MockShellExecutor{}
+		m.On("Exec", withEnv(func(env []string) bool {
+			return slices.Contains(env, "STEP_VAR=1")
+		}), "make").Return(executor.Result{}, nil).Once()
+		m.On("Exec", withEnv(func(env []string) bool {
+			return !slices.Contains(env, "STEP_VAR=1")
+		}), "make check").Return(executor.Result{}, nil).Once()
+
+		require.NoError(t, op.Run(ctx, m))
+		m.AssertExpectations(t)
+	})
+
+	t.Run("step timeout cancels the command", func(t *testing.T) {
+		op := Operation{FailFast: true, Steps: []Step{{Run: "sleep 5", Timeout: 10 * time.Millisecond}}}
+		m := &MockShellExecutor{}
+		m.On("Exec", mock.Anything, "sleep 5").Run(func(args mock.Arguments) {
+			<-args.Get(0).(context.Context).Done()
+		}).Return(executor.Result{ExitCode: -1}, context.DeadlineExceeded)
+
+		assert.ErrorContains(t, op.Run(ctx, m), "timed out after 10ms")
+	})
+}
+
+func TestOperation_StepIndex(t *testing.T) {
+	op := Operation{Steps: []Step{
+		{Run: "go generate ./..."},
+		{Name: "compile", Run: "go build ./..."},
+		{Run: "go vet ./..."},
+	}}
+	tests := []struct {
+		selector      string
+		expected      int
+		expectedError string
+	}{
+		{selector: "1", expected: 0},
+		{selector: "3", expected: 2},
+		{selector: "compile", expected: 1},
+		{selector: "go vet ./...", expected: 2},
+		{selector: "0", expectedError: "step 0 out of range, the operation has 3 step(s)"},
+		{selector: "4", expectedError: "step 4 out of range, the operation has 3 step(s)"},
+		{selector: "compiel", expectedError: "no step named 'compiel' (did you mean 'compile'?)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			idx, err := op.stepIndex(tt.selector)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, idx)
+		})
+	}
+}
+
+func TestProjectDefinition_Run_SingleStep(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	project := ProjectDefinition{
+		ID: "single-step",
+		Codebase: Codebase{
+			Install: Operation{Steps: StepsFromCommands("go mod download")},
+			Build: Operation{
+				DependsOn: []string{"install"},
+				Env:       map[string]string{"CGO_ENABLED": "0"},
+				Steps:     []Step{{Run: "go generate ./..."}, {Name: "compile", Run: "go build ./..."}},
+			},
+		},
+	}
+
+	m := &MockShellExecutor{}
+	m.On("Exec", withEnv(func(env []string) bool {
+		return envValue(env, "CGO_ENABLED") == "0" && envValue(env, EnvStepIndex) == "2"
+	}), "go build ./...").Return(executor.Result{}, nil).Once()
+
+	require.NoError(t, project.Run(WithStep(ctx, "build", "compile"), "build", m))
+	m.AssertExpectations(t)
+
+	err := project.Run(WithStep(ctx, "build", "7"), "build", m)
+	assert.EqualError(t, err, "operation build: step 7 out of range, the operation has 2 step(s)")
+}
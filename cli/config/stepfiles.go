@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+)
+
+// scriptExtensions mark arguments that name a script to run, such as
+// `bash scripts/release.sh`, even without a leading ./.
+var scriptExtensions = []string{".sh", ".bash", ".py", ".rb", ".pl", ".ps1"}
+
+// outputFlags are followed by a path the command creates.
+var outputFlags = []string{">", "-o", "--output"}
+
+var shellSeparators = strings.NewReplacer(
+	"&&", " ; ", "||", " ; ", "|", " ; ", "&", " ; ", ";", " ; ", "\n", " ; ",
+	"(", " ; ", ")", " ; ", ">>", " > ", ">", " > ",
+)
+
+// fileRef is a workspace path referenced by a step.
+type fileRef struct {
+	Path string
+	// Executable is set when the path is run as the command itself.
+	Executable bool
+}
+
+// stepFileRefs returns the local paths a step command refers to: paths
+// starting with ./, scripts passed to an interpreter and paths run as
+// commands. Paths the step writes with a redirect or an output flag are
+// added to created, and are not reported by this or later steps. Checking
+// stops at a cd, since later paths are relative to another directory.
+func stepFileRefs(command string, created map[string]bool) []fileRef {
+	refs := []fileRef{}
+	commandWord := true
+	output := false
+	for _, field := range strings.Fields(shellSeparators.Replace(command)) {
+		if field == ";" {
+			commandWord = true
+			continue
+		}
+		if slices.Contains(outputFlags, field) {
+			output = true
+			continue
+		}
+		token := strings.Trim(field, `"'`)
+		isCommand := commandWord
+		if isCommand && strings.Contains(token, "=") && !strings.HasPrefix(token, "-") {
+			// A leading VAR=value assignment, the command is still to come.
+			continue
+		}
+		commandWord = false
+		if isCommand && token == "cd" {
+			break
+		}
+		if !isLocalPath(token, isCommand) {
+			output = false
+			continue
+		}
+		clean := path.Clean(token)
+		if output {
+			created[clean] = true
+			output = false
+			continue
+		}
+		if created[clean] {
+			continue
+		}
+		refs = append(refs, fileRef{Path: clean, Executable: isCommand})
+	}
+	return refs
+}
+
+// isLocalPath reports whether a token looks like a path inside the
+// workspace rather than a flag, pattern, variable or tool on the PATH.
+func isLocalPath(token string, isCommand bool) bool {
+	if token == "" || token == "." || token == "./" || strings.HasPrefix(token, "-") ||
+		strings.ContainsAny(token, "$`*?{}[]~:=<>") || strings.Contains(token, "...") {
+		return false
+	}
+	if strings.HasPrefix(token, "/") || strings.HasPrefix(token, "../") {
+		return false
+	}
+	if strings.HasPrefix(token, "./") {
+		return true
+	}
+	return strings.Contains(token, "/") && (isCommand || slices.Contains(scriptExtensions, path.Ext(token)))
+}
+
+// checkStepFiles reports step references to files that are missing from
+// the workspace, or that are run as commands without being executable.
+// Paths are relative to the codebase the operations run in.
+func (d *ProjectDefinition) checkStepFiles(b *reportBuilder) {
+	if b.files == nil {
+		return
+	}
+	root := path.Clean(strings.TrimPrefix(d.Codebase.Path, "./"))
+	if !fs.ValidPath(root) {
+		return
+	}
+	for _, name := range d.Codebase.OperationNames() {
+		op, _ := d.Codebase.Lookup(name)
+		created := map[string]bool{}
+		checked := 0
+		failed := false
+		for _, step := range op.Steps {
+			for _, ref := range stepFileRefs(step.Run, created) {
+				checked++
+				info, err := fs.Stat(b.files, path.Join(root, ref.Path))
+				switch {
+				case err != nil:
+					failed = true
+					b.failAt(d.operationPath(name), RuleStepFiles, fmt.Sprintf("Fix the path in %s or restore the file", name),
+						"Step '%s' of %s references %s, which does not exist", step.Label(), name, ref.Path)
+				case ref.Executable && !info.IsDir() && info.Mode().Perm()&0o111 == 0:
+					failed = true
+					b.failAt(d.operationPath(name), RuleStepFiles, fmt.Sprintf("Run 'chmod +x %s'", ref.Path),
+						"Step '%s' of %s runs %s, which is not executable", step.Label(), name, ref.Path)
+				}
+			}
+		}
+		if checked > 0 && !failed {
+			b.pass(RuleStepFiles, "Files referenced by %s steps (%d) present", name, checked)
+		}
+	}
+}
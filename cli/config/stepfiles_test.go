@@ -0,0 +1,114 @@
+package config
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepFileRefs(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []string
+		expected []fileRef
+	}{
+		{
+			name:     "script run directly",
+			commands: []string{"./scripts/build.sh --release"},
+			expected: []fileRef{{Path: "scripts/build.sh", Executable: true}},
+		},
+		{
+			name:     "script passed to an interpreter",
+			commands: []string{"bash scripts/release.sh", "python3 tools/gen.py -c ./configs/gen.yaml"},
+			expected: []fileRef{{Path: "scripts/release.sh"}, {Path: "tools/gen.py"}, {Path: "configs/gen.yaml"}},
+		},
+		{
+			name:     "command after separators and assignments",
+			commands: []string{"make lint && CI=1 ./ci/check | tee out.log"},
+			expected: []fileRef{{Path: "ci/check", Executable: true}},
+		},
+		{
+			name:     "tools, patterns and variables are ignored",
+			commands: []string{"go test ./...", "golangci-lint run", "$HOME/bin/tool ./*.go", "docker build ."},
+			expected: []fileRef{},
+		},
+		{
+			name:     "paths created by earlier steps are ignored",
+			commands: []string{"go build -o ./bin/app ./cmd/app", "./bin/app --version", "echo ok > ./out/report.txt"},
+			expected: []fileRef{{Path: "cmd/app"}},
+		},
+		{
+			name:     "paths after cd are ignored",
+			commands: []string{"./setup.sh && cd web && ./build.sh"},
+			expected: []fileRef{{Path: "setup.sh", Executable: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := map[string]bool{}
+			refs := []fileRef{}
+			for _, command := range tt.commands {
+				refs = append(refs, stepFileRefs(command, created)...)
+			}
+			assert.Equal(t, tt.expected, refs)
+		})
+	}
+}
+
+func TestProjectDefinition_ReportFiles_StepFiles(t *testing.T) {
+	files := fstest.MapFS{
+		"scripts/build.sh": {Data: []byte("#!/bin/sh\n"), Mode: 0o755},
+		"scripts/lint.sh":  {Data: []byte("#!/bin/sh\n"), Mode: 0o644},
+	}
+	project := ProjectDefinition{
+		ID: "step-files",
+		Codebase: Codebase{
+			Build: Operation{Steps: StepsFromCommands("./scripts/build.sh")},
+			Operations: map[string]Operation{
+				"lint":   {Steps: StepsFromCommands("./scripts/lint.sh")},
+				"deploy": {Steps: StepsFromCommands("bash scripts/deploy.sh")},
+			},
+		},
+	}
+
+	report := project.ReportFiles(files)
+	findings := []Finding{}
+	for _, finding := range report.Findings {
+		if finding.RuleID == RuleStepFiles {
+			findings = append(findings, finding)
+		}
+	}
+	require.Len(t, findings, 3)
+	assert.True(t, findings[0].Passed)
+	assert.Equal(t, "Files referenced by build steps (1) present", findings[0].Message)
+	assert.False(t, findings[1].Passed)
+	assert.Equal(t, SeverityWarning, findings[1].Severity)
+	assert.Equal(t, "Step 'bash scripts/deploy.sh' of deploy references scripts/deploy.sh, which does not exist", findings[1].Message)
+	assert.False(t, findings[2].Passed)
+	assert.Equal(t, "Step './scripts/lint.sh' of lint runs scripts/lint.sh, which is not executable", findings[2].Message)
+	assert.Equal(t, "Run 'chmod +x scripts/lint.sh'", findings[2].Remedy)
+
+	_, ok := findingFor(project.Report(), RuleStepFiles)
+	assert.False(t, ok, "step files are only checked against a workspace")
+}
+
+func TestProjectDefinition_ReportFiles_StepFilesInCodebase(t *testing.T) {
+	files := fstest.MapFS{
+		"api/run.sh": {Data: []byte("#!/bin/sh\n"), Mode: 0o755},
+	}
+	project := ProjectDefinition{
+		ID: "step-files",
+		Codebases: []Codebase{{
+			Name:       "api",
+			Path:       "./api",
+			Operations: map[string]Operation{"serve": {Steps: StepsFromCommands("./run.sh")}},
+		}},
+	}
+
+	finding, ok := findingFor(project.ReportFiles(files), RuleStepFiles)
+	require.True(t, ok)
+	assert.True(t, finding.Passed)
+}
@@ -0,0 +1,208 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const logDirKey contextKey = "log-dir"
+
+// WithLogDir makes operations write the output of every step to a log file
+// under dir, in a directory per run, as well as to the console.
+func WithLogDir(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, logDirKey, dir)
+}
+
+// LogDirFromContext returns the log dir set with WithLogDir, if any.
+func LogDirFromContext(ctx context.Context) string {
+	dir, _ := ctx.Value(logDirKey).(string)
+	return dir
+}
+
+// maxLogNameLength bounds the part of a step log name taken from the step.
+const maxLogNameLength = 48
+
+// stepLogPath is where the output of a step of a run is written under dir.
+func stepLogPath(dir string, runID string, index int, step Step) string {
+	return filepath.Join(dir, runID, fmt.Sprintf("%02d-%s.log", index+1, logName(step.Label())))
+}
+
+// logName turns a step label into the name part of its log file.
+func logName(label string) string {
+	words := strings.FieldsFunc(safeName(label), func(r rune) bool { return r == '-' })
+	name := strings.Join(words, "-")
+	if len(name) > maxLogNameLength {
+		name = strings.TrimRight(name[:maxLogNameLength], "-")
+	}
+	return name
+}
+
+func createLogFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return os.Create(path)
+}
+
+// LatestRunLog selects the newest run in FindRunLog.
+const LatestRunLog = "latest"
+
+// RunLog is the step logs kept for a run under a log dir.
+type RunLog struct {
+	RunID   string
+	Dir     string
+	ModTime time.Time
+
+	// Steps are the log files of the steps, in the order they ran.
+	Steps []string
+}
+
+// ListRunLogs returns the runs with step logs under dir, newest first.
+func ListRunLogs(dir string) ([]RunLog, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	runs := []RunLog{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		run := RunLog{RunID: entry.Name(), Dir: filepath.Join(dir, entry.Name())}
+		files, err := os.ReadDir(run.Dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if file.IsDir() || filepath.Ext(file.Name()) != ".log" {
+				continue
+			}
+			run.Steps = append(run.Steps, file.Name())
+			if info, err := file.Info(); err == nil && info.ModTime().After(run.ModTime) {
+				run.ModTime = info.ModTime()
+			}
+		}
+		if len(run.Steps) > 0 {
+			runs = append(runs, run)
+		}
+	}
+	// Run IDs start with their UTC start time, so they sort by age.
+	sort.Slice(runs, func(i, j int) bool { return runs[i].RunID > runs[j].RunID })
+	return runs, nil
+}
+
+// FindRunLog returns the run under dir whose ID is runID, or starts with
+// it. LatestRunLog, or no ID, selects the newest run.
+func FindRunLog(dir string, runID string) (RunLog, error) {
+	runs, err := ListRunLogs(dir)
+	if err != nil {
+		return RunLog{}, err
+	}
+	if len(runs) == 0 {
+		return RunLog{}, fmt.Errorf("no run logs in %s", dir)
+	}
+	if runID == "" || runID == LatestRunLog {
+		return runs[0], nil
+	}
+	ids := make([]string, len(runs))
+	matches := []RunLog{}
+	for i, run := range runs {
+		ids[i] = run.RunID
+		if run.RunID == runID {
+			return run, nil
+		}
+		if strings.HasPrefix(run.RunID, runID) {
+			matches = append(matches, run)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		msg := fmt.Sprintf("no logs for run '%s' in %s", runID, dir)
+		if suggestion := closestMatch(runID, ids); suggestion != "" {
+			msg += fmt.Sprintf(" (did you mean '%s'?)", suggestion)
+		}
+		return RunLog{}, errors.New(msg)
+	}
+	return RunLog{}, fmt.Errorf("run '%s' is ambiguous, it matches %d runs", runID, len(matches))
+}
+
+// StepLogs returns the log files of the steps matching selector: a step
+// number, or a step name as it appears in the file name. An empty selector
+// matches every step.
+func (r RunLog) StepLogs(selector string) ([]string, error) {
+	if selector == "" {
+		return r.Steps, nil
+	}
+	wanted, byNumber := strconv.Atoi(selector)
+	names := make([]string, len(r.Steps))
+	for i, file := range r.Steps {
+		number, name, _ := strings.Cut(strings.TrimSuffix(file, ".log"), "-")
+		names[i] = name
+		if n, err := strconv.Atoi(number); err == nil && byNumber == nil && n == wanted {
+			return []string{file}, nil
+		}
+		if name == logName(selector) || file == selector {
+			return []string{file}, nil
+		}
+	}
+	msg := fmt.Sprintf("run %s has no step '%s'", r.RunID, selector)
+	if suggestion := closestMatch(selector, names); suggestion != "" {
+		msg += fmt.Sprintf(" (did you mean '%s'?)", suggestion)
+	}
+	return nil, errors.New(msg)
+}
+
+// WriteStepLogs writes the given step logs of the run to w, each under a
+// header when there are several. With a pattern, only the matching lines
+// are written, prefixed with their file and line number.
+func (r RunLog) WriteStepLogs(w io.Writer, files []string, pattern *regexp.Regexp) error {
+	for i, file := range files {
+		f, err := os.Open(filepath.Join(r.Dir, file))
+		if err != nil {
+			return err
+		}
+		if pattern == nil {
+			if len(files) > 1 {
+				if i > 0 {
+					_, _ = fmt.Fprintln(w)
+				}
+				_, _ = fmt.Fprintf(w, "==> %s <==\n", file)
+			}
+			_, err = io.Copy(w, f)
+		} else {
+			err = grepLines(w, f, file, pattern)
+		}
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func grepLines(w io.Writer, r io.Reader, name string, pattern *regexp.Regexp) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if pattern.MatchString(scanner.Text()) {
+			_, _ = fmt.Fprintf(w, "%s:%d:%s\n", name, line, scanner.Text())
+		}
+	}
+	return scanner.Err()
+}
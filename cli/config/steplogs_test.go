@@ -0,0 +1,158 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepLogPath(t *testing.T) {
+	path := stepLogPath(LogsDir, "20260301T100000Z-test", 2, Step{Run: "go test ./..."})
+	assert.Equal(t, filepath.Join(LogsDir, "20260301T100000Z-test", "03-go-test.log"), path)
+	assert.Equal(t, filepath.Join("logs", "run", "01-unit-tests.log"), stepLogPath("logs", "run", 0, Step{Name: "Unit tests", Run: "make"}))
+}
+
+func TestOperation_Run_LogDir(t *testing.T) {
+	t.Chdir(t.TempDir())
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	ctx = withRunInfo(WithLogDir(ctx, "ci-logs"), RunInfo{Operation: "build", RunID: "20260301T100000Z-build"})
+
+	op := Operation{
+		Steps: []Step{{Name: "compile", Run: "echo compiling; echo warning >&2"}, {Run: "echo done"}},
+	}
+	require.NoError(t, op.Run(ctx, &executor.DefaultExecutor{}))
+
+	runDir := filepath.Join("ci-logs", "20260301T100000Z-build")
+	compile, err := os.ReadFile(filepath.Join(runDir, "01-compile.log"))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"compiling", "warning"}, strings.Fields(string(compile)))
+	done, err := os.ReadFile(filepath.Join(runDir, "02-echo-done.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "done\n", string(done))
+}
+
+func TestOperation_Run_LogDirMasksSecrets(t *testing.T) {
+	t.Chdir(t.TempDir())
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	ctx = withRunInfo(WithLogDir(ctx, LogsDir), RunInfo{Operation: "deploy", RunID: "20260301T100000Z-deploy"})
+	ctx = executor.WithSecrets(ctx, "supersecret123")
+
+	op := Operation{Steps: []Step{{Name: "token", Run: "echo token=supersecret123"}}}
+	require.NoError(t, op.Run(ctx, &executor.DefaultExecutor{}))
+
+	log, err := os.ReadFile(filepath.Join(LogsDir, "20260301T100000Z-deploy", "01-token.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "token=***\n", string(log))
+	assert.NotContains(t, string(log), "supersecret123")
+}
+
+func TestLoad_LogDir(t *testing.T) {
+	cfg, err := Load(strings.NewReader("id: logs\nlog_dir: build/logs\ncodebase:\n  build:\n    steps:\n      - make\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "build/logs", cfg.LogDir)
+}
+
+func writeRunLogs(t *testing.T, dir string, runs map[string]map[string]string) {
+	t.Helper()
+	for runID, steps := range runs {
+		for name, content := range steps {
+			path := filepath.Join(dir, runID, name)
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		}
+	}
+}
+
+func TestListRunLogs(t *testing.T) {
+	dir := t.TempDir()
+	writeRunLogs(t, dir, map[string]map[string]string{
+		"20260301T100000Z-build": {"01-compile.log": "compiling\n", "02-echo-done.log": "done\n"},
+		"20260302T100000Z-test":  {"01-go-test.log": "ok\n", "notes.txt": "skipped"},
+		"20260303T100000Z-empty": {"notes.txt": "skipped"},
+	})
+
+	runs, err := ListRunLogs(dir)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, "20260302T100000Z-test", runs[0].RunID)
+	assert.Equal(t, []string{"01-go-test.log"}, runs[0].Steps)
+	assert.Equal(t, []string{"01-compile.log", "02-echo-done.log"}, runs[1].Steps)
+
+	runs, err = ListRunLogs(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+}
+
+func TestFindRunLog(t *testing.T) {
+	dir := t.TempDir()
+	writeRunLogs(t, dir, map[string]map[string]string{
+		"20260301T100000Z-build": {"01-compile.log": ""},
+		"20260301T110000Z-build": {"01-compile.log": ""},
+		"20260302T100000Z-test":  {"01-go-test.log": ""},
+	})
+
+	run, err := FindRunLog(dir, LatestRunLog)
+	require.NoError(t, err)
+	assert.Equal(t, "20260302T100000Z-test", run.RunID)
+
+	run, err = FindRunLog(dir, "20260301T10")
+	require.NoError(t, err)
+	assert.Equal(t, "20260301T100000Z-build", run.RunID)
+
+	_, err = FindRunLog(dir, "20260301")
+	assert.ErrorContains(t, err, "run '20260301' is ambiguous, it matches 2 runs")
+
+	_, err = FindRunLog(dir, "20260302T100000Z-tset")
+	assert.ErrorContains(t, err, "(did you mean '20260302T100000Z-test'?)")
+
+	_, err = FindRunLog(t.TempDir(), "")
+	assert.ErrorContains(t, err, "no run logs in")
+}
+
+func TestRunLog_StepLogs(t *testing.T) {
+	run := RunLog{RunID: "run", Steps: []string{"01-compile.log", "02-go-test.log"}}
+
+	for _, selector := range []string{"2", "02", "go-test", "go test", "02-go-test.log"} {
+		files, err := run.StepLogs(selector)
+		require.NoError(t, err, selector)
+		assert.Equal(t, []string{"02-go-test.log"}, files, selector)
+	}
+
+	files, err := run.StepLogs("")
+	require.NoError(t, err)
+	assert.Equal(t, run.Steps, files)
+
+	_, err = run.StepLogs("compiel")
+	assert.ErrorContains(t, err, "run run has no step 'compiel' (did you mean 'compile'?)")
+	_, err = run.StepLogs("3")
+	assert.ErrorContains(t, err, "run run has no step '3'")
+}
+
+func TestRunLog_WriteStepLogs(t *testing.T) {
+	dir := t.TempDir()
+	writeRunLogs(t, dir, map[string]map[string]string{
+		"run": {"01-compile.log": "compiling\nwarning: unused\n", "02-go-test.log": "ok\nwarning: slow\n"},
+	})
+	run := RunLog{RunID: "run", Dir: filepath.Join(dir, "run"), Steps: []string{"01-compile.log", "02-go-test.log"}}
+
+	var out strings.Builder
+	require.NoError(t, run.WriteStepLogs(&out, run.Steps[:1], nil))
+	assert.Equal(t, "compiling\nwarning: unused\n", out.String())
+
+	out.Reset()
+	require.NoError(t, run.WriteStepLogs(&out, run.Steps, nil))
+	assert.Equal(t, "==> 01-compile.log <==\ncompiling\nwarning: unused\n\n==> 02-go-test.log <==\nok\nwarning: slow\n", out.String())
+
+	out.Reset()
+	require.NoError(t, run.WriteStepLogs(&out, run.Steps, regexp.MustCompile("^warning")))
+	assert.Equal(t, "01-compile.log:2:warning: unused\n02-go-test.log:2:warning: slow\n", out.String())
+}
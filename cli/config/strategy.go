@@ -0,0 +1,218 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+)
+
+// Deploy strategies.
+const (
+	StrategyRolling   = "rolling"
+	StrategyCanary    = "canary"
+	StrategyBlueGreen = "blue-green"
+)
+
+var deployStrategies = []string{StrategyRolling, StrategyCanary, StrategyBlueGreen}
+
+// defaultCanarySteps are the traffic percentages of a canary without steps.
+var defaultCanarySteps = []int{10, 50, 100}
+
+// DeployStrategy orchestrates a deployment in phases, each a helm upgrade
+// setting chart values, with the health check run after each phase. A
+// canary sets WeightValue to each percentage of Steps in turn. Blue-green
+// deploys the idle color with ColorValue while ActiveValue keeps traffic
+// on the live color, then switches ActiveValue to the new color.
+type DeployStrategy struct {
+	Type        string      `yaml:"type,omitempty"`
+	Steps       []int       `yaml:"steps,omitempty"`
+	WeightValue string      `yaml:"weight_value,omitempty"`
+	ColorValue  string      `yaml:"color_value,omitempty"`
+	ActiveValue string      `yaml:"active_value,omitempty"`
+	HealthCheck HealthCheck `yaml:"health_check,omitempty"`
+}
+
+// HealthCheck is a command that must succeed after each deploy phase.
+// It is retried Retries times, Interval apart, before the deployment is
+// aborted.
+type HealthCheck struct {
+	Run      string        `yaml:"run,omitempty"`
+	Retries  int           `yaml:"retries,omitempty"`
+	Interval time.Duration `yaml:"interval,omitempty"`
+}
+
+// deployPhase is one helm upgrade of a deployment. Abort holds the values
+// that undo the phase when its health check fails, if any.
+type deployPhase struct {
+	Name  string
+	Set   map[string]string
+	Env   map[string]string
+	Abort map[string]string
+}
+
+func (s DeployStrategy) kind() string {
+	if s.Type == "" {
+		return StrategyRolling
+	}
+	return s.Type
+}
+
+func (s DeployStrategy) steps() []int {
+	if len(s.Steps) == 0 {
+		return defaultCanarySteps
+	}
+	return s.Steps
+}
+
+func (s DeployStrategy) weightValue() string {
+	if s.WeightValue == "" {
+		return "canary.weight"
+	}
+	return s.WeightValue
+}
+
+func (s DeployStrategy) colorValue() string {
+	if s.ColorValue == "" {
+		return "blueGreen.color"
+	}
+	return s.ColorValue
+}
+
+func (s DeployStrategy) activeValue() string {
+	if s.ActiveValue == "" {
+		return "blueGreen.active"
+	}
+	return s.ActiveValue
+}
+
+// validate reports an unknown strategy or canary steps that do not rise
+// to 100.
+func (s DeployStrategy) validate() error {
+	if !slices.Contains(deployStrategies, s.kind()) {
+		msg := fmt.Sprintf("unknown strategy '%s'", s.Type)
+		if suggestion := closestMatch(s.Type, deployStrategies); suggestion != "" {
+			msg += fmt.Sprintf(" (did you mean '%s'?)", suggestion)
+		}
+		return errors.New(msg)
+	}
+	if s.kind() == StrategyCanary {
+		previous := 0
+		for _, step := range s.steps() {
+			if step <= previous || step > 100 {
+				return fmt.Errorf("canary steps must rise from 1 to 100, got %v", s.steps())
+			}
+			previous = step
+		}
+		if previous != 100 {
+			return fmt.Errorf("the last canary step must be 100, got %d", previous)
+		}
+	}
+	if s.HealthCheck.Retries < 0 || s.HealthCheck.Interval < 0 {
+		return errors.New("health_check retries and interval must not be negative")
+	}
+	return nil
+}
+
+// phases returns the phases of a deployment. live is the color receiving
+// traffic before a blue-green deployment, empty for the first one.
+func (s DeployStrategy) phases(live string) []deployPhase {
+	switch s.kind() {
+	case StrategyCanary:
+		phases := []deployPhase{}
+		for _, step := range s.steps() {
+			weight := strconv.Itoa(step)
+			phases = append(phases, deployPhase{
+				Name:  "canary " + weight + "%",
+				Set:   map[string]string{s.weightValue(): weight},
+				Env:   map[string]string{"DEVOPS_CANARY_WEIGHT": weight},
+				Abort: map[string]string{s.weightValue(): "0"},
+			})
+		}
+		return phases
+	case StrategyBlueGreen:
+		color := "blue"
+		if live == "blue" {
+			color = "green"
+		}
+		deploy := deployPhase{
+			Name: "deploy " + color,
+			Set:  map[string]string{s.colorValue(): color},
+			Env:  map[string]string{"DEVOPS_DEPLOY_COLOR": color},
+		}
+		switchover := deployPhase{
+			Name: "switch to " + color,
+			Set:  map[string]string{s.colorValue(): color, s.activeValue(): color},
+			Env:  map[string]string{"DEVOPS_DEPLOY_COLOR": color},
+		}
+		if live != "" {
+			deploy.Set[s.activeValue()] = live
+			switchover.Abort = map[string]string{s.activeValue(): live}
+		}
+		return []deployPhase{deploy, switchover}
+	default:
+		return []deployPhase{{Name: StrategyRolling}}
+	}
+}
+
+// runPhases upgrades the release once per phase of the strategy, running
+// the health check after each. When a phase fails its abort values are
+// applied and the remaining phases are skipped. dep.Set ends with the
+// values of the last phase applied.
+func (d *ProjectDefinition) runPhases(ctx context.Context, dep *Deployment, phases []deployPhase, shellExecutor ShellExecutor) error {
+	logger := logging.FromContext(ctx)
+	base := maps.Clone(dep.Set)
+	for _, phase := range phases {
+		logger.Infof("Deploy phase: %s", phase.Name)
+		dep.Set = maps.Clone(base)
+		maps.Copy(dep.Set, phase.Set)
+		err := d.runCommands(ctx, "deploy", shellExecutor, nil, dep.upgradeCommand(""))
+		if err == nil {
+			err = d.checkHealth(ctx, phase, shellExecutor)
+		}
+		if err == nil {
+			continue
+		}
+		err = fmt.Errorf("phase %s: %w", phase.Name, err)
+		if phase.Abort != nil {
+			logger.Warnf("Aborting deploy phase %s", phase.Name)
+			maps.Copy(dep.Set, phase.Abort)
+			if abortErr := d.runCommands(ctx, "deploy", shellExecutor, nil, dep.upgradeCommand("")); abortErr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to abort phase %s: %w", phase.Name, abortErr))
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// checkHealth runs the health check of the strategy, if any, until it
+// passes or its retries are spent.
+func (d *ProjectDefinition) checkHealth(ctx context.Context, phase deployPhase, shellExecutor ShellExecutor) error {
+	check := d.Deploy.Strategy.HealthCheck
+	if check.Run == "" {
+		return nil
+	}
+	env := map[string]string{"DEVOPS_DEPLOY_PHASE": phase.Name}
+	maps.Copy(env, phase.Env)
+	for attempt := 1; ; attempt++ {
+		err := d.runCommands(ctx, "health-check", shellExecutor, env, check.Run)
+		if err == nil {
+			return nil
+		}
+		if attempt > check.Retries {
+			return fmt.Errorf("health check failed after %d attempt(s): %w", attempt, err)
+		}
+		logging.FromContext(ctx).Warnf("Health check attempt %d failed, retrying in %s", attempt, check.Interval)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(check.Interval):
+		}
+	}
+}
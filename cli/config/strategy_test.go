@@ -0,0 +1,146 @@
+package config
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeployStrategy_Validate(t *testing.T) {
+	tests := []struct {
+		strategy DeployStrategy
+		err      string
+	}{
+		{strategy: DeployStrategy{}},
+		{strategy: DeployStrategy{Type: StrategyCanary}},
+		{strategy: DeployStrategy{Type: StrategyCanary, Steps: []int{25, 100}}},
+		{strategy: DeployStrategy{Type: "canery"}, err: "unknown strategy 'canery' (did you mean 'canary'?)"},
+		{strategy: DeployStrategy{Type: StrategyCanary, Steps: []int{50, 20, 100}}, err: "canary steps must rise from 1 to 100, got [50 20 100]"},
+		{strategy: DeployStrategy{Type: StrategyCanary, Steps: []int{10, 50}}, err: "the last canary step must be 100, got 50"},
+		{strategy: DeployStrategy{HealthCheck: HealthCheck{Run: "true", Retries: -1}}, err: "health_check retries and interval must not be negative"},
+	}
+	for _, tt := range tests {
+		err := tt.strategy.validate()
+		if tt.err == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.EqualError(t, err, tt.err)
+		}
+	}
+}
+
+func TestDeployStrategy_Phases(t *testing.T) {
+	phases := DeployStrategy{Type: StrategyCanary, Steps: []int{20, 100}, WeightValue: "weight"}.phases("")
+	require.Len(t, phases, 2)
+	assert.Equal(t, deployPhase{
+		Name:  "canary 20%",
+		Set:   map[string]string{"weight": "20"},
+		Env:   map[string]string{"DEVOPS_CANARY_WEIGHT": "20"},
+		Abort: map[string]string{"weight": "0"},
+	}, phases[0])
+
+	phases = DeployStrategy{Type: StrategyBlueGreen}.phases("blue")
+	assert.Equal(t, []deployPhase{
+		{
+			Name: "deploy green",
+			Set:  map[string]string{"blueGreen.color": "green", "blueGreen.active": "blue"},
+			Env:  map[string]string{"DEVOPS_DEPLOY_COLOR": "green"},
+		},
+		{
+			Name:  "switch to green",
+			Set:   map[string]string{"blueGreen.color": "green", "blueGreen.active": "green"},
+			Env:   map[string]string{"DEVOPS_DEPLOY_COLOR": "green"},
+			Abort: map[string]string{"blueGreen.active": "blue"},
+		},
+	}, phases)
+
+	phases = DeployStrategy{Type: StrategyBlueGreen}.phases("")
+	assert.Equal(t, map[string]string{"blueGreen.color": "blue"}, phases[0].Set)
+	assert.Nil(t, phases[1].Abort)
+
+	assert.Equal(t, []deployPhase{{Name: StrategyRolling}}, DeployStrategy{}.phases(""))
+}
+
+func TestProjectDefinition_DeployHelmCanary(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeChart(t)
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	project := ProjectDefinition{
+		ID: "app",
+		Deploy: Deploy{
+			Helm: HelmConfig{Chart: "deploy/chart"},
+			Strategy: DeployStrategy{
+				Type:        StrategyCanary,
+				Steps:       []int{10, 100},
+				HealthCheck: HealthCheck{Run: "curl -fsS http://app/healthz", Retries: 1},
+			},
+		},
+	}
+	m := &MockShellExecutor{}
+	calls := []string{}
+	recordCalls(m, &calls,
+		"helm package 'deploy/chart' --destination '.devops/artifacts/charts'",
+		"helm upgrade --install 'app' '.devops/artifacts/charts/app.tgz' --set 'canary.weight=10'",
+		"helm upgrade --install 'app' '.devops/artifacts/charts/app.tgz' --set 'canary.weight=100'",
+		"helm upgrade --install 'app' '.devops/artifacts/charts/app.tgz' --set 'canary.weight=0'",
+	)
+	record := func(args mock.Arguments) { calls = append(calls, args.String(1)) }
+	m.On("Exec", mock.Anything, "curl -fsS http://app/healthz").Run(record).Return(executor.Result{}, nil).Once()
+	m.On("Exec", mock.Anything, "curl -fsS http://app/healthz").Run(record).Return(executor.Result{ExitCode: 1}, nil)
+
+	deployment, err := project.DeployHelm(ctx, "", m, time.Now())
+	assert.ErrorContains(t, err, "phase canary 100%: health check failed after 2 attempt(s)")
+	assert.Equal(t, DeployFailed, deployment.Status)
+	assert.Equal(t, map[string]string{"canary.weight": "0"}, deployment.Set)
+	assert.Equal(t, []string{
+		"helm package 'deploy/chart' --destination '.devops/artifacts/charts'",
+		"helm upgrade --install 'app' '.devops/artifacts/charts/app.tgz' --set 'canary.weight=10'",
+		"curl -fsS http://app/healthz",
+		"helm upgrade --install 'app' '.devops/artifacts/charts/app.tgz' --set 'canary.weight=100'",
+		"curl -fsS http://app/healthz",
+		"curl -fsS http://app/healthz",
+		"helm upgrade --install 'app' '.devops/artifacts/charts/app.tgz' --set 'canary.weight=0'",
+	}, calls)
+}
+
+func TestProjectDefinition_DeployHelmBlueGreen(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeChart(t)
+	writeDeployFiles(t, ".devops/artifacts/charts/app.tgz")
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	project := ProjectDefinition{
+		ID: "app",
+		Deploy: Deploy{
+			Helm:     HelmConfig{Chart: "deploy/chart"},
+			Strategy: DeployStrategy{Type: StrategyBlueGreen, ActiveValue: "active", ColorValue: "color"},
+		},
+	}
+	m := &MockShellExecutor{}
+	calls := []string{}
+	recordCalls(m, &calls,
+		"helm package 'deploy/chart' --destination '.devops/artifacts/charts'",
+		"helm upgrade --install 'app' '.devops/artifacts/charts/app.tgz' --set 'color=blue'",
+		"helm upgrade --install 'app' '.devops/artifacts/charts/app.tgz' --set 'active=blue' --set 'color=blue'",
+		"helm upgrade --install 'app' '.devops/artifacts/charts/app.tgz' --set 'active=blue' --set 'color=green'",
+		"helm upgrade --install 'app' '.devops/artifacts/charts/app.tgz' --set 'active=green' --set 'color=green'",
+	)
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	first, err := project.DeployHelm(ctx, "", m, now)
+	require.NoError(t, err)
+	assert.Equal(t, "blue", first.Color)
+
+	second, err := project.DeployHelm(ctx, "", m, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "green", second.Color)
+	assert.Equal(t, map[string]string{"active": "green", "color": "green"}, second.Set)
+	assert.Equal(t, "helm upgrade --install 'app' '.devops/artifacts/charts/app.tgz' --set 'active=blue' --set 'color=green'", calls[len(calls)-2])
+}
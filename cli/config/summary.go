@@ -0,0 +1,233 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jgfranco17/devops/internal/outputs"
+)
+
+// GitHubStepSummaryEnv is set by GitHub Actions to the file whose markdown
+// is rendered in the job summary panel.
+const GitHubStepSummaryEnv = "GITHUB_STEP_SUMMARY"
+
+const summaryKey contextKey = "summary"
+
+// OperationResult is the outcome of a single operation run.
+type OperationResult struct {
+	Name     string
+	Codebase string
+	RunID    string
+	Start    time.Time
+	Duration time.Duration
+	Steps    []StepResult
+	Err      error
+
+	// Attempts holds every attempt of an operation re-run after
+	// infrastructure failures, the last one included. It is empty for
+	// operations that ran once.
+	Attempts []AttemptResult
+}
+
+// AttemptResult is a single attempt of an operation re-run after
+// infrastructure failures.
+type AttemptResult struct {
+	Attempt int
+	Steps   []StepResult
+	Err     error
+}
+
+// RunSummary collects the operations run by a single devops invocation.
+type RunSummary struct {
+	mu         sync.Mutex
+	Command    string
+	Operations []OperationResult
+
+	// LocalTime renders start times in the local time zone instead of UTC.
+	LocalTime bool
+
+	// ReportPath is the report written by the command, if any.
+	ReportPath string
+
+	// ConfigHash is the hash of the definition file the command loaded.
+	ConfigHash string
+
+	// steps holds the step results of the operation currently running,
+	// until it is recorded.
+	steps []StepResult
+
+	// attempts holds the earlier attempts of the operation currently
+	// running, if it was re-run.
+	attempts []AttemptResult
+}
+
+// WithRunSummary attaches a run summary to the context.
+func WithRunSummary(ctx context.Context, summary *RunSummary) context.Context {
+	return context.WithValue(ctx, summaryKey, summary)
+}
+
+// RunSummaryFromContext returns the run summary in the context, or nil. A
+// nil summary is safe to use and records nothing.
+func RunSummaryFromContext(ctx context.Context) *RunSummary {
+	summary, _ := ctx.Value(summaryKey).(*RunSummary)
+	return summary
+}
+
+func (s *RunSummary) record(codebase string, name string, start time.Time, err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var attempts []AttemptResult
+	if len(s.attempts) > 0 {
+		attempts = append(s.attempts, AttemptResult{Attempt: len(s.attempts) + 1, Steps: s.steps, Err: err})
+	}
+	s.Operations = append(s.Operations, OperationResult{
+		Name: name, Codebase: codebase, RunID: newRunID(codebase, name, start), Start: start, Duration: time.Since(start), Steps: s.steps, Err: err,
+		Attempts: attempts,
+	})
+	s.steps = nil
+	s.attempts = nil
+}
+
+// retry keeps the step results of an attempt of the running operation
+// that failed with err before the operation is re-run.
+func (s *RunSummary) retry(err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts = append(s.attempts, AttemptResult{Attempt: len(s.attempts) + 1, Steps: s.steps, Err: err})
+	s.steps = nil
+}
+
+// succeeded reports whether the operation already completed successfully
+// in the codebase during this invocation.
+func (s *RunSummary) succeeded(codebase string, name string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, result := range s.Operations {
+		if result.Name == name && result.Codebase == codebase && result.Err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// setSteps stores the step results of the operation about to be recorded.
+// Those of earlier attempts of a retried operation are kept by retry.
+func (s *RunSummary) setSteps(steps []StepResult) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.steps = steps
+}
+
+// Markdown renders the summary as a markdown table.
+func (s *RunSummary) Markdown() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var b strings.Builder
+	fmt.Fprintf(&b, "### %s\n\n", s.Command)
+	b.WriteString("| Operation | Status | Started | Duration |\n")
+	b.WriteString("|---|---|---|---|\n")
+	for _, op := range s.Operations {
+		status := "✅ passed"
+		if op.Err != nil {
+			status = "❌ failed"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", op.Name, status, outputs.FormatTimestamp(op.Start, s.LocalTime), op.Duration.Round(time.Millisecond))
+	}
+	for _, op := range s.Operations {
+		if op.Err != nil {
+			fmt.Fprintf(&b, "\n**%s**: `%s`\n", op.Name, reportText(op.Err.Error()))
+		}
+	}
+	return b.String()
+}
+
+type jsonStep struct {
+	StepResult
+	DurationMs int64 `json:"duration_ms"`
+}
+
+type jsonOperation struct {
+	Name       string     `json:"name"`
+	Success    bool       `json:"success"`
+	Started    string     `json:"started"`
+	DurationMs int64      `json:"duration_ms"`
+	Steps      []jsonStep `json:"steps"`
+	Error      string     `json:"error,omitempty"`
+}
+
+type jsonSummary struct {
+	Command    string          `json:"command"`
+	Success    bool            `json:"success"`
+	Operations []jsonOperation `json:"operations"`
+}
+
+// WriteJSON writes the summary as a JSON document for --output json.
+func (s *RunSummary) WriteJSON(w io.Writer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc := jsonSummary{Command: s.Command, Success: true, Operations: []jsonOperation{}}
+	for _, op := range s.Operations {
+		entry := jsonOperation{
+			Name:       op.Name,
+			Success:    op.Err == nil,
+			Started:    outputs.FormatTimestamp(op.Start, s.LocalTime),
+			DurationMs: op.Duration.Milliseconds(),
+			Steps:      []jsonStep{},
+		}
+		for _, step := range op.Steps {
+			entry.Steps = append(entry.Steps, jsonStep{StepResult: step, DurationMs: step.Duration.Milliseconds()})
+		}
+		if op.Err != nil {
+			entry.Error = op.Err.Error()
+			doc.Success = false
+		}
+		doc.Operations = append(doc.Operations, entry)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// WriteGitHubStepSummary appends the summary to the GitHub Actions step
+// summary file. It does nothing outside Actions or if no operation ran.
+func (s *RunSummary) WriteGitHubStepSummary() error {
+	path := os.Getenv(GitHubStepSummaryEnv)
+	if s == nil || path == "" || len(s.Operations) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open step summary: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(s.Markdown() + "\n"); err != nil {
+		return fmt.Errorf("failed to write step summary: %w", err)
+	}
+	return nil
+}
+
+// reportText flattens text for inline markdown, treating CRLF and LF line
+// endings alike.
+func reportText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\n", " ")
+	return strings.ReplaceAll(s, "`", "'")
+}
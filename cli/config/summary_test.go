@@ -0,0 +1,127 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunSummary_RecordsOperations(t *testing.T) {
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	summary := &RunSummary{Command: "devops pipeline"}
+	ctx := WithRunSummary(logging.WithContext(context.Background(), logger), summary)
+
+	project := ProjectDefinition{
+		Codebase: Codebase{
+			Test:  Operation{Steps: StepsFromCommands("go test ./...")},
+			Build: Operation{Steps: StepsFromCommands("go build ./...")},
+		},
+	}
+	m := &MockShellExecutor{}
+	m.On("Exec", mock.Anything, "go test ./...").Return(executor.Result{}, nil)
+	m.On("Exec", mock.Anything, "go build ./...").Return(executor.Result{ExitCode: 1}, errors.New("exit status 1"))
+
+	require.NoError(t, project.Test(ctx, m))
+	require.Error(t, project.Build(ctx, m))
+	require.Error(t, project.Run(ctx, "deploy", m))
+
+	require.Len(t, summary.Operations, 2)
+	assert.Equal(t, "test", summary.Operations[0].Name)
+	assert.NoError(t, summary.Operations[0].Err)
+	assert.Equal(t, "build", summary.Operations[1].Name)
+	assert.Error(t, summary.Operations[1].Err)
+
+	markdown := summary.Markdown()
+	assert.True(t, strings.HasPrefix(markdown, "### devops pipeline\n\n| Operation | Status | Started | Duration |\n|---|---|---|---|\n"))
+	assert.Regexp(t, `\| test \| ✅ passed \| \d{4}-\d\d-\d\dT\d\d:\d\d:\d\dZ \|`, markdown)
+	assert.Contains(t, markdown, "| build | ❌ failed |")
+	assert.Contains(t, markdown, "**build**: `failed to run build steps:")
+}
+
+func TestRunSummary_MarkdownNormalizesLineEndings(t *testing.T) {
+	summary := &RunSummary{Command: "devops build", Operations: []OperationResult{
+		{Name: "build", Err: errors.New("step failed:\r\n`make` exited\n")},
+	}}
+	assert.Contains(t, summary.Markdown(), "**build**: `step failed: 'make' exited `\n")
+}
+
+func TestRunSummary_WriteGitHubStepSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.md")
+	summary := &RunSummary{
+		Command:    "devops test",
+		Operations: []OperationResult{{Name: "test"}},
+	}
+
+	t.Setenv(GitHubStepSummaryEnv, "")
+	require.NoError(t, summary.WriteGitHubStepSummary())
+	assert.NoFileExists(t, path)
+
+	t.Setenv(GitHubStepSummaryEnv, path)
+	require.NoError(t, os.WriteFile(path, []byte("previous step\n"), 0644))
+	require.NoError(t, summary.WriteGitHubStepSummary())
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), "previous step\n### devops test\n"))
+
+	var nilSummary *RunSummary
+	assert.NoError(t, nilSummary.WriteGitHubStepSummary())
+	assert.NoError(t, (&RunSummary{}).WriteGitHubStepSummary())
+}
+
+func TestRunSummary_WriteJSON(t *testing.T) {
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	summary := &RunSummary{Command: "devops test"}
+	ctx := WithRunSummary(logging.WithContext(context.Background(), logger), summary)
+	ctx = WithOutputFormat(ctx, OutputJSON)
+
+	project := ProjectDefinition{
+		Codebase: Codebase{
+			Test: Operation{Steps: StepsFromCommands("go vet ./...", "go test ./...")},
+		},
+	}
+	m := &MockShellExecutor{}
+	m.On("Exec", mock.Anything, "go vet ./...").Return(executor.Result{Duration: 2 * time.Second}, nil)
+	m.On("Exec", mock.Anything, "go test ./...").Return(executor.Result{ExitCode: 1}, errors.New("exit status 1"))
+	require.Error(t, project.Test(ctx, m))
+
+	var buf bytes.Buffer
+	require.NoError(t, summary.WriteJSON(&buf))
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, "devops test", doc["command"])
+	assert.Equal(t, false, doc["success"])
+	operations := doc["operations"].([]any)
+	require.Len(t, operations, 1)
+	operation := operations[0].(map[string]any)
+	assert.Equal(t, "test", operation["name"])
+	assert.Contains(t, operation["error"], "failed to run steps")
+	steps := operation["steps"].([]any)
+	require.Len(t, steps, 2)
+	assert.Equal(t, map[string]any{"name": "go vet ./...", "exit_code": float64(0), "status": "ok", "duration_ms": float64(2000)}, steps[0])
+	assert.Equal(t, float64(1), steps[1].(map[string]any)["exit_code"])
+	assert.Equal(t, "failed", steps[1].(map[string]any)["status"])
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	for _, format := range []string{OutputText, OutputJSON} {
+		parsed, err := ParseOutputFormat(format)
+		assert.NoError(t, err)
+		assert.Equal(t, format, parsed)
+	}
+	_, err := ParseOutputFormat("yaml")
+	assert.ErrorContains(t, err, "unsupported output format 'yaml'")
+}
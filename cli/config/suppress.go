@@ -0,0 +1,77 @@
+package config
+
+import (
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var suppressionPattern = regexp.MustCompile(`devops:disable\s+([A-Za-z0-9_-]+)(?:\s+reason="([^"]*)")?`)
+
+// Suppression acknowledges a validation rule from an inline comment in
+// the definition file, e.g. `# devops:disable repo-url-required reason="internal"`.
+type Suppression struct {
+	RuleID string
+	Reason string
+	Line   int
+
+	// Path is the dotted key path of the node the comment is attached to,
+	// e.g. codebase.operations.lint, or empty for the whole document.
+	Path string
+}
+
+// covers reports whether the suppression applies to a finding about the
+// key at path. Findings without a path are about the whole project, so
+// any suppression of their rule covers them.
+func (s Suppression) covers(path string) bool {
+	if path == "" || s.Path == "" {
+		return true
+	}
+	return withinKey(path, s.Path) || withinKey(s.Path, path)
+}
+
+// withinKey reports whether path is key or one of the keys under it.
+func withinKey(path string, key string) bool {
+	return path == key || strings.HasPrefix(path, key+".")
+}
+
+// parseSuppressions collects every suppression comment in the document.
+func parseSuppressions(root *yaml.Node) []Suppression {
+	suppressions := []Suppression{}
+	var walk func(node *yaml.Node, path string)
+	walk = func(node *yaml.Node, path string) {
+		for _, comment := range []string{node.HeadComment, node.LineComment, node.FootComment} {
+			for _, line := range strings.Split(comment, "\n") {
+				match := suppressionPattern.FindStringSubmatch(line)
+				if match == nil {
+					continue
+				}
+				suppressions = append(suppressions, Suppression{
+					RuleID: match[1],
+					Reason: match[2],
+					Line:   node.Line,
+					Path:   path,
+				})
+			}
+		}
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key := joinPath(path, node.Content[i].Value)
+				walk(node.Content[i], key)
+				walk(node.Content[i+1], key)
+			}
+		case yaml.SequenceNode:
+			for i, item := range node.Content {
+				walk(item, itemPath(path, item, i))
+			}
+		default:
+			for _, child := range node.Content {
+				walk(child, path)
+			}
+		}
+	}
+	walk(root, "")
+	return suppressions
+}
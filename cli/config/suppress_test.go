@@ -0,0 +1,190 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseSuppressions(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		expected []Suppression
+	}{
+		{
+			name: "head comment with reason",
+			yaml: `id: test-project
+# devops:disable repo-url-required reason="internal tooling"
+codebase:
+  language: go
+`,
+			expected: []Suppression{{RuleID: RuleRepoURLRequired, Reason: "internal tooling", Line: 3, Path: "codebase"}},
+		},
+		{
+			name: "line comment without reason",
+			yaml: `id: test-project
+codebase:
+  language: go # devops:disable dependencies-defined
+`,
+			expected: []Suppression{{RuleID: RuleDependenciesDefined, Line: 3, Path: "codebase.language"}},
+		},
+		{
+			name: "multiple suppressions in one comment block",
+			yaml: `# devops:disable test-steps-defined reason="no tests yet"
+# devops:disable build-steps-defined
+id: test-project
+`,
+			expected: []Suppression{
+				{RuleID: RuleTestStepsDefined, Reason: "no tests yet", Line: 3, Path: "id"},
+				{RuleID: RuleBuildStepsDefined, Line: 3, Path: "id"},
+			},
+		},
+		{
+			name: "comment inside an operation of a named codebase",
+			yaml: `id: test-project
+codebases:
+  - name: api
+    operations:
+      lint:
+        when: nope # devops:disable when-conditions
+`,
+			expected: []Suppression{{RuleID: RuleWhenConditions, Line: 6, Path: "codebases.api.operations.lint.when"}},
+		},
+		{
+			name: "comment on a step",
+			yaml: `id: test-project
+codebase:
+  test:
+    steps:
+      - go vet ./...
+      - go test ./... # devops:disable duplicate-steps
+`,
+			expected: []Suppression{{RuleID: RuleDuplicateSteps, Line: 6, Path: "codebase.test.steps[1]"}},
+		},
+		{
+			name: "ordinary comments are ignored",
+			yaml: `# this is a project
+id: test-project # the ID
+`,
+			expected: []Suppression{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var root yaml.Node
+			require.NoError(t, yaml.Unmarshal([]byte(tt.yaml), &root))
+			assert.Equal(t, tt.expected, parseSuppressions(&root))
+		})
+	}
+}
+
+func TestProjectDefinition_ValidateTo_Suppressed(t *testing.T) {
+	yamlContent := `id: test-project
+# devops:disable repo-url-required reason="internal tooling"
+codebase:
+  language: go
+  dependencies: [go.mod]
+  test:
+    steps: [go test ./...]
+  build:
+    steps: [go build ./...]
+`
+	cfg, err := Load(strings.NewReader(yamlContent))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+
+	err = cfg.ValidateTo(ctx, &buf)
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "[-] Repository URL is required (suppressed: internal tooling)")
+	assert.NotContains(t, buf.String(), "Fixes:")
+
+	report := cfg.Report()
+	suppressed := report.Suppressed()
+	require.Len(t, suppressed, 1)
+	assert.Equal(t, RuleRepoURLRequired, suppressed[0].RuleID)
+	assert.Equal(t, "internal tooling", suppressed[0].SuppressionReason)
+}
+
+func TestProjectDefinition_Report_SuppressionScopedToKey(t *testing.T) {
+	yamlContent := `id: test-project
+repo_url: https://github.com/example/test
+codebase:
+  language: go
+  dependencies: [go.mod]
+  test:
+    steps: [go test ./...]
+  build:
+    steps: [go build ./...]
+  operations:
+    lint:
+      when: os === linux # devops:disable when-conditions reason="rolled out later"
+      steps: [golangci-lint run]
+    vet:
+      when: os === linux
+      steps: [go vet ./...]
+`
+	cfg, err := Load(strings.NewReader(yamlContent))
+	require.NoError(t, err)
+
+	report := cfg.Report()
+	var findings []Finding
+	for _, finding := range report.Findings {
+		if finding.RuleID == RuleWhenConditions && !finding.Passed {
+			findings = append(findings, finding)
+		}
+	}
+	require.Len(t, findings, 2)
+	assert.Equal(t, "codebase.operations.lint", findings[0].Path)
+	assert.True(t, findings[0].Suppressed)
+	assert.Equal(t, "rolled out later", findings[0].SuppressionReason)
+	assert.Equal(t, "codebase.operations.vet", findings[1].Path)
+	assert.False(t, findings[1].Suppressed)
+}
+
+func TestSuppression_Covers(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		finding  string
+		expected bool
+	}{
+		{name: "whole document", path: "", finding: "codebase.operations.lint", expected: true},
+		{name: "project-wide finding", path: "codebase.operations.lint", finding: "", expected: true},
+		{name: "same key", path: "codebase.operations.lint", finding: "codebase.operations.lint", expected: true},
+		{name: "enclosing key", path: "codebase", finding: "codebase.operations.lint", expected: true},
+		{name: "key inside the finding", path: "codebase.operations.lint.when", finding: "codebase.operations.lint", expected: true},
+		{name: "sibling key", path: "codebase.operations.vet", finding: "codebase.operations.lint", expected: false},
+		{name: "key sharing a prefix", path: "codebase.operations.lint-docs", finding: "codebase.operations.lint", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Suppression{RuleID: RuleWhenConditions, Path: tt.path}.covers(tt.finding))
+		})
+	}
+}
+
+func TestProjectDefinition_Report_UnknownSuppression(t *testing.T) {
+	project := ProjectDefinition{
+		Suppressions: []Suppression{{RuleID: "repo-url-requird", Line: 4}},
+	}
+
+	report := project.Report()
+	finding, ok := findingFor(report, RuleValidationConfig)
+	require.True(t, ok)
+	assert.Contains(t, finding.Message, "near line 4")
+	assert.Equal(t, "Did you mean 'repo-url-required'?", finding.Remedy)
+}
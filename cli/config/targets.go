@@ -0,0 +1,212 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/internal/fileutils"
+)
+
+// defaultPackageTemplate names the package of each target when the
+// operation does not set one.
+const defaultPackageTemplate = "{{.ID}}_{{.Version}}_{{.Target}}.tar.gz"
+
+// targetLabel matches a platform label or either half of an os/arch pair.
+var targetLabel = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// target is a platform an operation is run for, given as os/arch, such as
+// linux/amd64, or as a generic label, such as wasm.
+type target struct {
+	Name string
+	OS   string
+	Arch string
+}
+
+// packageInfo is the data package templates are rendered with. Target is
+// the target with "/" replaced by "_"; OS and Arch are empty for labels.
+type packageInfo struct {
+	ID      string
+	Version string
+	Target  string
+	OS      string
+	Arch    string
+}
+
+func parseTarget(value string) (target, error) {
+	t := target{Name: value}
+	if osName, arch, ok := strings.Cut(value, "/"); ok {
+		if !targetLabel.MatchString(osName) || !targetLabel.MatchString(arch) {
+			return t, fmt.Errorf("invalid target '%s', expected os/arch or a platform label", value)
+		}
+		t.OS, t.Arch = osName, arch
+		return t, nil
+	}
+	if !targetLabel.MatchString(value) {
+		return t, fmt.Errorf("invalid target '%s', expected os/arch or a platform label", value)
+	}
+	return t, nil
+}
+
+// env returns the variables a target's steps run with: DEVOPS_TARGET
+// always, and GOOS, GOARCH, DEVOPS_TARGET_OS and DEVOPS_TARGET_ARCH for
+// os/arch targets.
+func (t target) env() map[string]string {
+	env := map[string]string{"DEVOPS_TARGET": t.Name}
+	if t.OS != "" {
+		env["GOOS"] = t.OS
+		env["GOARCH"] = t.Arch
+		env["DEVOPS_TARGET_OS"] = t.OS
+		env["DEVOPS_TARGET_ARCH"] = t.Arch
+	}
+	return env
+}
+
+func (d *ProjectDefinition) packageInfo(t target) packageInfo {
+	return packageInfo{
+		ID:      d.ID,
+		Version: d.Version,
+		Target:  strings.ReplaceAll(t.Name, "/", "_"),
+		OS:      t.OS,
+		Arch:    t.Arch,
+	}
+}
+
+// targets parses the targets of the operation, rejecting duplicates.
+func (op *Operation) targets() ([]target, error) {
+	targets := make([]target, 0, len(op.Targets))
+	seen := map[string]bool{}
+	for _, value := range op.Targets {
+		t, err := parseTarget(value)
+		if err != nil {
+			return nil, err
+		}
+		if seen[t.Name] {
+			return nil, fmt.Errorf("target '%s' is listed more than once", t.Name)
+		}
+		seen[t.Name] = true
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// packageName renders the package template of the operation for a target.
+func (op *Operation) packageName(info packageInfo) (string, error) {
+	text := op.Package
+	if text == "" {
+		text = defaultPackageTemplate
+	}
+	tmpl, err := template.New("package").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid package template: %w", err)
+	}
+	var name strings.Builder
+	if err := tmpl.Execute(&name, info); err != nil {
+		return "", fmt.Errorf("invalid package template: %w", err)
+	}
+	switch result := name.String(); {
+	case strings.ContainsAny(result, `/\`) || result == "" || result == "." || result == "..":
+		return "", fmt.Errorf("package template renders '%s', which is not a file name", result)
+	case !strings.HasSuffix(result, ".tar.gz") && !strings.HasSuffix(result, ".tgz") && !strings.HasSuffix(result, ".zip"):
+		return "", fmt.Errorf("package template renders '%s', expected a .tar.gz, .tgz or .zip name", result)
+	default:
+		return result, nil
+	}
+}
+
+// runTargets runs the operation once per target, with the target's env,
+// and packages the artifacts of each run into PackagesDir. It returns the
+// package paths. Without targets it runs the operation once and returns
+// nothing. Artifacts are not removed between targets, so steps should
+// overwrite or clean their own outputs.
+func (d *ProjectDefinition) runTargets(ctx context.Context, op Operation, shellExecutor ShellExecutor) ([]string, error) {
+	if len(op.Targets) == 0 {
+		return nil, op.Run(ctx, shellExecutor)
+	}
+	logger := logging.FromContext(ctx)
+	targets, err := op.targets()
+	if err != nil {
+		return nil, err
+	}
+	if len(op.Artifacts) == 0 {
+		return nil, errors.New("targets are set but no artifacts are declared to package")
+	}
+	packages := []string{}
+	for _, t := range targets {
+		targetOp := op
+		targetOp.Env = maps.Clone(op.Env)
+		if targetOp.Env == nil {
+			targetOp.Env = map[string]string{}
+		}
+		maps.Copy(targetOp.Env, t.env())
+
+		logger.Infof("Running for target %s", t.Name)
+		if err := targetOp.Run(ctx, shellExecutor); err != nil {
+			return packages, fmt.Errorf("target %s: %w", t.Name, err)
+		}
+		path, err := d.packageTarget(&targetOp, t)
+		if err != nil {
+			return packages, fmt.Errorf("target %s: %w", t.Name, err)
+		}
+		logger.Infof("Packaged %s", path)
+		packages = append(packages, path)
+	}
+	return packages, nil
+}
+
+// packageTarget archives the artifacts of the operation into PackagesDir
+// under the name its package template renders for the target.
+func (d *ProjectDefinition) packageTarget(op *Operation, t target) (string, error) {
+	name, err := op.packageName(d.packageInfo(t))
+	if err != nil {
+		return "", err
+	}
+	matches, err := op.MatchArtifacts()
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no artifacts matching %s to package", strings.Join(op.Artifacts, ", "))
+	}
+	if err := os.MkdirAll(PackagesDir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(PackagesDir, name)
+	if err := fileutils.Archive(path, os.DirFS("."), matches); err != nil {
+		return "", fmt.Errorf("failed to package %s: %w", name, err)
+	}
+	return filepath.ToSlash(path), nil
+}
+
+// checkTargets reports unusable targets and package templates.
+func (d *ProjectDefinition) checkTargets(b *reportBuilder) {
+	for _, name := range d.Codebase.OperationNames() {
+		op, _ := d.Codebase.Lookup(name)
+		if len(op.Targets) == 0 {
+			if op.Package != "" {
+				b.failAt(d.operationPath(name), RuleBuildTargets, "Add targets to the operation or remove package", "Operation '%s' sets package without targets", name)
+			}
+			continue
+		}
+		targets, err := op.targets()
+		if err == nil && len(op.Artifacts) == 0 {
+			err = errors.New("targets are set but no artifacts are declared to package")
+		}
+		if err == nil && len(targets) > 0 {
+			_, err = op.packageName(d.packageInfo(targets[0]))
+		}
+		if err != nil {
+			b.failAt(d.operationPath(name), RuleBuildTargets, "Use os/arch pairs or platform labels, declare artifacts and name packages .tar.gz, .tgz or .zip",
+				"Operation '%s': %s", name, err.Error())
+			continue
+		}
+		b.pass(RuleBuildTargets, "Operation '%s' targets: %s", name, strings.Join(op.Targets, ", "))
+	}
+}
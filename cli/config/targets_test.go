@@ -0,0 +1,187 @@
+package config
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTarget(t *testing.T) {
+	target, err := parseTarget("linux/arm64")
+	require.NoError(t, err)
+	assert.Equal(t, "linux", target.OS)
+	assert.Equal(t, "arm64", target.Arch)
+	assert.Equal(t, map[string]string{
+		"DEVOPS_TARGET":      "linux/arm64",
+		"DEVOPS_TARGET_OS":   "linux",
+		"DEVOPS_TARGET_ARCH": "arm64",
+		"GOOS":               "linux",
+		"GOARCH":             "arm64",
+	}, target.env())
+
+	target, err = parseTarget("wasm")
+	require.NoError(t, err)
+	assert.Empty(t, target.OS)
+	assert.Equal(t, map[string]string{"DEVOPS_TARGET": "wasm"}, target.env())
+
+	for _, value := range []string{"", "linux/", "/amd64", "linux/amd64/v2", "my target"} {
+		_, err := parseTarget(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestOperation_PackageName(t *testing.T) {
+	info := packageInfo{ID: "app", Version: "1.2.0", Target: "darwin_arm64", OS: "darwin", Arch: "arm64"}
+
+	name, err := (&Operation{}).packageName(info)
+	require.NoError(t, err)
+	assert.Equal(t, "app_1.2.0_darwin_arm64.tar.gz", name)
+
+	name, err = (&Operation{Package: "{{.ID}}-{{.OS}}-{{.Arch}}.zip"}).packageName(info)
+	require.NoError(t, err)
+	assert.Equal(t, "app-darwin-arm64.zip", name)
+
+	_, err = (&Operation{Package: "{{.Missing}}.tar.gz"}).packageName(info)
+	assert.ErrorContains(t, err, "invalid package template")
+
+	_, err = (&Operation{Package: "{{.OS}}/{{.Arch}}.tar.gz"}).packageName(info)
+	assert.ErrorContains(t, err, "is not a file name")
+
+	_, err = (&Operation{Package: "{{.ID}}.rar"}).packageName(info)
+	assert.ErrorContains(t, err, "expected a .tar.gz, .tgz or .zip name")
+}
+
+func TestProjectDefinition_Run_Targets(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	t.Chdir(t.TempDir())
+	project := ProjectDefinition{
+		ID:      "app",
+		Version: "1.0.0",
+		Codebase: Codebase{
+			Build: Operation{
+				Targets:   []string{"linux/amd64", "windows/arm64"},
+				Package:   "{{.ID}}_{{.Version}}_{{.OS}}_{{.Arch}}.tar.gz",
+				Artifacts: []string{"dist/*"},
+				Steps:     StepsFromCommands("make build"),
+			},
+		},
+	}
+	m := &MockShellExecutor{}
+	m.On("Exec", mock.Anything, "make build").Run(func(args mock.Arguments) {
+		env := executor.EnvFromContext(args.Get(0).(context.Context))
+		goos := ""
+		for _, entry := range env {
+			if value, ok := strings.CutPrefix(entry, "GOOS="); ok {
+				goos = value
+			}
+		}
+		require.NoError(t, os.MkdirAll("dist", 0755))
+		require.NoError(t, os.WriteFile("dist/app", []byte(goos), 0755))
+	}).Return(executor.Result{}, nil)
+
+	require.NoError(t, project.Run(ctx, "build", m))
+	m.AssertNumberOfCalls(t, "Exec", 2)
+
+	for goos, name := range map[string]string{
+		"linux":   "app_1.0.0_linux_amd64.tar.gz",
+		"windows": "app_1.0.0_windows_arm64.tar.gz",
+	} {
+		f, err := os.Open(PackagesDir + "/" + name)
+		require.NoError(t, err)
+		gz, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		tr := tar.NewReader(gz)
+		header, err := tr.Next()
+		require.NoError(t, err)
+		assert.Equal(t, "dist/app", header.Name)
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		assert.Equal(t, goos, string(data))
+		require.NoError(t, f.Close())
+	}
+}
+
+func TestProjectDefinition_Run_TargetsZip(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	t.Chdir(t.TempDir())
+	project := ProjectDefinition{
+		ID: "app",
+		Codebase: Codebase{
+			Build: Operation{
+				Targets:   []string{"wasm"},
+				Package:   "{{.ID}}-{{.Target}}.zip",
+				Artifacts: []string{"out"},
+				Steps:     StepsFromCommands("make wasm"),
+			},
+		},
+	}
+	m := &MockShellExecutor{}
+	m.On("Exec", mock.Anything, "make wasm").Run(func(args mock.Arguments) {
+		require.NoError(t, os.MkdirAll("out/web", 0755))
+		require.NoError(t, os.WriteFile("out/web/app.wasm", []byte("wasm"), 0644))
+	}).Return(executor.Result{}, nil)
+
+	require.NoError(t, project.Run(ctx, "build", m))
+	zr, err := zip.OpenReader(PackagesDir + "/app-wasm.zip")
+	require.NoError(t, err)
+	defer zr.Close()
+	require.Len(t, zr.File, 1)
+	assert.Equal(t, "out/web/app.wasm", zr.File[0].Name)
+}
+
+func TestProjectDefinition_Run_TargetsWithoutArtifacts(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	t.Chdir(t.TempDir())
+	project := ProjectDefinition{
+		ID: "app",
+		Codebase: Codebase{
+			Build: Operation{
+				Targets: []string{"linux/amd64"},
+				Steps:   StepsFromCommands("make build"),
+			},
+		},
+	}
+	err := project.Run(ctx, "build", &MockShellExecutor{})
+	assert.ErrorContains(t, err, "no artifacts are declared to package")
+}
+
+func TestProjectDefinition_Report_Targets(t *testing.T) {
+	project := ProjectDefinition{
+		ID: "app",
+		Codebase: Codebase{
+			Build: Operation{
+				Targets:   []string{"linux/amd64", "darwin/arm64"},
+				Artifacts: []string{"bin/app"},
+				Steps:     StepsFromCommands("make build"),
+			},
+		},
+	}
+	finding, ok := findingFor(project.Report(), RuleBuildTargets)
+	require.True(t, ok)
+	assert.True(t, finding.Passed)
+
+	project.Codebase.Build.Targets = append(project.Codebase.Build.Targets, "linux/amd64")
+	finding, ok = findingFor(project.Report(), RuleBuildTargets)
+	require.True(t, ok)
+	assert.False(t, finding.Passed)
+	assert.Contains(t, finding.Message, "listed more than once")
+
+	project.Codebase.Build.Targets = nil
+	project.Codebase.Build.Package = "{{.ID}}.zip"
+	finding, ok = findingFor(project.Report(), RuleBuildTargets)
+	require.True(t, ok)
+	assert.False(t, finding.Passed)
+	assert.Equal(t, "Operation 'build' sets package without targets", finding.Message)
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+
+	"github.com/jgfranco17/devops/internal/fileutils"
+)
+
+// filesPattern matches {{ files "<glob>" }} in step commands.
+var filesPattern = regexp.MustCompile(`\{\{\s*files\s+"([^"]*)"\s*\}\}`)
+
+// plainWord matches paths that need no quoting in a shell command.
+var plainWord = regexp.MustCompile(`^[A-Za-z0-9_./@%+=:,-]+$`)
+
+// renderFiles expands {{ files "<glob>" }} in command to the matching
+// paths in fsys, space-separated and sorted. Patterns support "**" and
+// always use forward slashes, so the same step works on every OS. A
+// pattern matching nothing expands to nothing.
+func renderFiles(command string, fsys fs.FS) (string, error) {
+	var renderErr error
+	result := filesPattern.ReplaceAllStringFunc(command, func(match string) string {
+		pattern := filesPattern.FindStringSubmatch(match)[1]
+		paths, err := fileutils.Glob(fsys, pattern, ".git", WorkDir)
+		if err != nil {
+			if renderErr == nil {
+				renderErr = fmt.Errorf("invalid files pattern '%s': %w", pattern, err)
+			}
+			return ""
+		}
+		words := make([]string, len(paths))
+		for i, path := range paths {
+			words[i] = path
+			if !plainWord.MatchString(path) {
+				words[i] = shellQuote(path)
+			}
+		}
+		return strings.Join(words, " ")
+	})
+	return result, renderErr
+}
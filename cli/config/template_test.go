@@ -0,0 +1,62 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"api/v1/user.proto":     {},
+		"api/v1/order.proto":    {},
+		"api/my docs/doc.proto": {},
+		"README.md":             {},
+		".git/x.proto":          {},
+	}
+
+	command, err := renderFiles(`protoc --go_out=gen {{ files "**/*.proto" }}`, fsys)
+	require.NoError(t, err)
+	assert.Equal(t, `protoc --go_out=gen 'api/my docs/doc.proto' api/v1/order.proto api/v1/user.proto`, command)
+
+	command, err = renderFiles(`lint {{files "*.md"}} {{ files "*.txt" }}`, fsys)
+	require.NoError(t, err)
+	assert.Equal(t, "lint README.md ", command)
+
+	command, err = renderFiles(`docker inspect --format '{{.State}}' app`, fsys)
+	require.NoError(t, err)
+	assert.Equal(t, `docker inspect --format '{{.State}}' app`, command)
+
+	_, err = renderFiles(`ls {{ files "[" }}`, fsys)
+	assert.ErrorContains(t, err, "invalid files pattern '['")
+}
+
+func TestOperation_Run_RendersFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "proto"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "proto", "a.proto"), nil, 0o644))
+	t.Chdir(dir)
+
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	op := Operation{Steps: []Step{
+		{Run: "touch proto/b.proto"},
+		{Run: `protoc {{ files "proto/*.proto" }}`},
+	}}
+	m := &MockShellExecutor{}
+	m.On("Exec", mock.Anything, "touch proto/b.proto").Run(func(mock.Arguments) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "proto", "b.proto"), nil, 0o644))
+	}).Return(executor.Result{}, nil)
+	m.On("Exec", mock.Anything, "protoc proto/a.proto proto/b.proto").Return(executor.Result{}, nil)
+
+	require.NoError(t, op.Run(ctx, m))
+	m.AssertExpectations(t)
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// StepResult is the outcome of a single step of an operation.
+type StepResult struct {
+	Name     string        `json:"name"`
+	ExitCode int           `json:"exit_code"`
+	Duration time.Duration `json:"-"`
+	Status   string        `json:"status"`
+
+	// Stderr is kept for failed steps so reports can show why they failed.
+	Stderr string `json:"-"`
+}
+
+func (s StepResult) withStatus(status string) StepResult {
+	s.Status = status
+	return s
+}
+
+// printStepTimings writes a table of step durations with the total.
+func printStepTimings(w io.Writer, steps []StepResult) {
+	if len(steps) == 0 {
+		return
+	}
+	var total time.Duration
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "STEP\tDURATION\tSTATUS")
+	for _, step := range steps {
+		total += step.Duration
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", step.Name, step.Duration.Round(time.Millisecond), step.Status)
+	}
+	_, _ = fmt.Fprintf(tw, "total\t%s\t\n", total.Round(time.Millisecond))
+	_ = tw.Flush()
+}
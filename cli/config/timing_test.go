@@ -0,0 +1,28 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintStepTimings(t *testing.T) {
+	var buf bytes.Buffer
+	printStepTimings(&buf, []StepResult{
+		{Name: "build", Duration: 1500 * time.Millisecond, Status: "ok"},
+		{Name: "test", Duration: 250 * time.Millisecond, Status: "failed"},
+	})
+
+	assert.Equal(t, "STEP   DURATION  STATUS\n"+
+		"build  1.5s      ok\n"+
+		"test   250ms     failed\n"+
+		"total  1.75s     \n", buf.String())
+}
+
+func TestPrintStepTimings_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	printStepTimings(&buf, nil)
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// userConfigFile is the per-user settings file, relative to the user
+// config directory (e.g. ~/.config on Linux).
+const userConfigFile = "devops/config.yaml"
+
+// defaultRetention is how long prunable files are kept when the user
+// config does not say otherwise.
+const defaultRetention = 7 * 24 * time.Hour
+
+// UserConfig holds settings that belong to the user rather than to a
+// project definition.
+type UserConfig struct {
+	Retention    Retention `yaml:"retention"`
+	Experimental []string  `yaml:"experimental"`
+
+	// Locale selects the language of messages, overriding LANG.
+	Locale string `yaml:"locale"`
+}
+
+// Retention controls how long devops keeps files it generates. A zero
+// duration for a category falls back to MaxAge.
+type Retention struct {
+	MaxAge     time.Duration `yaml:"max_age"`
+	Logs       time.Duration `yaml:"logs"`
+	Reports    time.Duration `yaml:"reports"`
+	History    time.Duration `yaml:"history"`
+	Cache      time.Duration `yaml:"cache"`
+	Failures   time.Duration `yaml:"failures"`
+	Runs       time.Duration `yaml:"runs"`
+	Workspaces time.Duration `yaml:"workspaces"`
+}
+
+// UserConfigPath returns the location of the user config file.
+func UserConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(dir, userConfigFile), nil
+}
+
+// UserLocalesDir returns the directory holding the user's message
+// catalogs, named after their locale (e.g. pt_BR.yaml).
+func UserLocalesDir() (string, error) {
+	path, err := UserConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "locales"), nil
+}
+
+// LoadUserConfig reads the user config, returning the defaults if the file
+// does not exist.
+func LoadUserConfig() (UserConfig, error) {
+	cfg := UserConfig{Retention: Retention{MaxAge: defaultRetention}}
+	path, err := UserConfigPath()
+	if err != nil {
+		return cfg, err
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read user config: %w", err)
+	}
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse user config %s: %w", path, err)
+	}
+	if cfg.Retention.MaxAge <= 0 {
+		cfg.Retention.MaxAge = defaultRetention
+	}
+	return cfg, nil
+}
+
+func (r Retention) orMaxAge(age time.Duration) time.Duration {
+	if age > 0 {
+		return age
+	}
+	return r.MaxAge
+}
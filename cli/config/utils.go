@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/jgfranco17/dev-tooling-go/logging"
 	"github.com/sirupsen/logrus"
@@ -12,6 +13,32 @@ import (
 
 const (
 	DefinitionFile = "devops-definition.yaml"
+	WorkDir        = ".devops"
+	ArtifactsDir   = WorkDir + "/artifacts"
+
+	// PackagesDir holds the per-target packages of operations with targets.
+	PackagesDir = ArtifactsDir + "/packages"
+
+	// ChartsDir holds the Helm chart packages built by devops deploy.
+	ChartsDir = ArtifactsDir + "/charts"
+
+	// DeploymentsDir keeps the chart and values of each successful
+	// deployment so it can be rolled back to.
+	DeploymentsDir = WorkDir + "/deployments"
+
+	// FailureArtifactsDir holds diagnostics collected from failed operations.
+	FailureArtifactsDir = ArtifactsDir + "/failures"
+
+	LogsDir    = WorkDir + "/logs"
+	ReportsDir = WorkDir + "/reports"
+	HistoryDir = WorkDir + "/history"
+	CacheDir   = WorkDir + "/cache"
+
+	// RunsDir keeps the artifacts of successful runs for promotion.
+	RunsDir = WorkDir + "/runs"
+
+	// ReleasesDir holds the packaged artifacts of releases.
+	ReleasesDir = WorkDir + "/releases"
 )
 
 // GetFilePath returns the path to the project definition file.
@@ -25,6 +52,53 @@ func GetFilePath() (string, error) {
 	return projectConfigPath, err
 }
 
+// inDir runs fn with dir as the working directory, restoring the previous
+// one afterwards. An empty dir runs fn in place.
+func inDir(dir string, fn func() error) error {
+	if dir == "" || dir == "." {
+		return fn()
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if err := os.Chdir(dir); err != nil {
+		return fmt.Errorf("failed to enter %s: %w", dir, err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	return fn()
+}
+
+// projectRoot is the directory devops runs in while inCodebase has
+// entered the directory of a codebase, and empty otherwise.
+var projectRoot string
+
+// inCodebase runs fn from the directory of a codebase. Unlike the inputs
+// and outputs of its steps, the logs, retained runs, cache and failure
+// artifacts of its operations stay in the project's WorkDir, through
+// workPath.
+func inCodebase(dir string, fn func() error) error {
+	if projectRoot != "" || dir == "" || dir == "." {
+		return inDir(dir, fn)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	projectRoot = cwd
+	defer func() { projectRoot = "" }()
+	return inDir(dir, fn)
+}
+
+// workPath resolves a relative path in the project's WorkDir, such as
+// LogsDir, against the project root when running in a codebase.
+func workPath(path string) string {
+	if projectRoot == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(projectRoot, path)
+}
+
 // WithTempEnv sets environment variables from the provided map,
 // saves any existing values, and restores them after the callback.
 func WithTempEnv(ctx context.Context, vars map[string]string) (func(), error) {
@@ -60,3 +134,17 @@ func WithTempEnv(ctx context.Context, vars map[string]string) (func(), error) {
 
 	return restoreFunc, nil
 }
+
+// safeName lowercases name and replaces anything but letters, digits, '-'
+// and '_' with '-', so it can be used in file and project names.
+func safeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '-'
+	}, name)
+}
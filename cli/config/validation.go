@@ -0,0 +1,288 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+
+	"github.com/jgfranco17/devops/internal/i18n"
+	"github.com/jgfranco17/devops/internal/outputs"
+)
+
+// Severity determines how a failed validation rule is reported.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityOff     Severity = "off"
+)
+
+// Rule IDs for the checks performed by the doctor.
+const (
+	RuleIDRequired            = "id-required"
+	RuleIDFormat              = "id-format"
+	RuleRepoURLRequired       = "repo-url-required"
+	RuleLanguageRequired      = "language-required"
+	RuleDependenciesDefined   = "dependencies-defined"
+	RuleTestStepsDefined      = "test-steps-defined"
+	RuleBuildStepsDefined     = "build-steps-defined"
+	RuleDuplicateSteps        = "duplicate-steps"
+	RuleOperationNames        = "operation-names"
+	RuleOperationInputs       = "operation-inputs"
+	RuleWorkDirTracked        = "workdir-tracked"
+	RuleEncryptedValues       = "encrypted-values"
+	RulePipeline              = "pipeline"
+	RuleRemoteConfig          = "remote-config"
+	RuleWhenConditions        = "when-conditions"
+	RuleValidationConfig      = "validation-config"
+	RuleEnvFiles              = "env-files"
+	RuleOperationDependencies = "operation-dependencies"
+	RuleStepFiles             = "step-files"
+	RuleCacheConfig           = "cache-config"
+	RuleArtifactStores        = "artifact-stores"
+	RuleBuildTargets          = "build-targets"
+	RuleSigning               = "signing"
+	RuleContainerConfig       = "container-config"
+	RuleDeployConfig          = "deploy-config"
+	RuleComposeFile           = "compose-file"
+)
+
+// defaultSeverities lists every configurable rule and the severity it is
+// reported with when the definition does not override it.
+var defaultSeverities = map[string]Severity{
+	RuleIDRequired:            SeverityError,
+	RuleIDFormat:              SeverityError,
+	RuleRepoURLRequired:       SeverityError,
+	RuleLanguageRequired:      SeverityError,
+	RuleDependenciesDefined:   SeverityWarning,
+	RuleTestStepsDefined:      SeverityWarning,
+	RuleBuildStepsDefined:     SeverityWarning,
+	RuleDuplicateSteps:        SeverityWarning,
+	RuleOperationNames:        SeverityError,
+	RuleOperationInputs:       SeverityError,
+	RuleWorkDirTracked:        SeverityWarning,
+	RuleEncryptedValues:       SeverityWarning,
+	RulePipeline:              SeverityError,
+	RuleRemoteConfig:          SeverityError,
+	RuleWhenConditions:        SeverityError,
+	RuleEnvFiles:              SeverityWarning,
+	RuleOperationDependencies: SeverityError,
+	RuleStepFiles:             SeverityWarning,
+	RuleCacheConfig:           SeverityError,
+	RuleArtifactStores:        SeverityError,
+	RuleBuildTargets:          SeverityError,
+	RuleSigning:               SeverityError,
+	RuleContainerConfig:       SeverityError,
+	RuleDeployConfig:          SeverityError,
+	RuleComposeFile:           SeverityError,
+}
+
+// ValidationConfig lets a project adjust the severity of doctor rules.
+type ValidationConfig struct {
+	Rules map[string]Severity `yaml:"rules,omitempty"`
+}
+
+// Finding is the outcome of a single validation rule.
+type Finding struct {
+	RuleID   string   `json:"rule_id"`
+	Passed   bool     `json:"passed"`
+	Severity Severity `json:"severity,omitempty"`
+	Message  string   `json:"message"`
+	Remedy   string   `json:"remedy,omitempty"`
+
+	// Path is the dotted key path of the definition the finding is about,
+	// e.g. codebase.operations.lint, or empty for the whole project.
+	Path string `json:"path,omitempty"`
+
+	// Suppressed marks a failed finding acknowledged by an inline
+	// devops:disable comment; it no longer counts towards errors or warnings.
+	Suppressed        bool   `json:"suppressed,omitempty"`
+	SuppressionReason string `json:"suppression_reason,omitempty"`
+}
+
+// ValidationReport collects the findings for a project definition.
+type ValidationReport struct {
+	Findings []Finding `json:"findings"`
+}
+
+// Suppressed returns the failed findings acknowledged by suppression comments.
+func (r *ValidationReport) Suppressed() []Finding {
+	matched := []Finding{}
+	for _, finding := range r.Findings {
+		if finding.Suppressed {
+			matched = append(matched, finding)
+		}
+	}
+	return matched
+}
+
+// Errors returns the failed findings reported as errors.
+func (r *ValidationReport) Errors() []Finding {
+	return r.withSeverity(SeverityError)
+}
+
+// Warnings returns the failed findings reported as warnings.
+func (r *ValidationReport) Warnings() []Finding {
+	return r.withSeverity(SeverityWarning)
+}
+
+func (r *ValidationReport) withSeverity(severity Severity) []Finding {
+	matched := []Finding{}
+	for _, finding := range r.Findings {
+		if !finding.Passed && !finding.Suppressed && finding.Severity == severity {
+			matched = append(matched, finding)
+		}
+	}
+	return matched
+}
+
+// Render writes the report in the doctor's colored text format.
+func (r *ValidationReport) Render(w io.Writer) {
+	fixes := []string{}
+	suggestions := []string{}
+	for _, finding := range r.Findings {
+		switch {
+		case finding.Passed:
+			outputs.PrintColoredMessageTo(w, "green", "[✔] %s", finding.Message)
+		case finding.Suppressed:
+			if finding.SuppressionReason != "" {
+				outputs.PrintColoredMessageTo(w, "cyan", "[-] %s (suppressed: %s)", finding.Message, finding.SuppressionReason)
+			} else {
+				outputs.PrintColoredMessageTo(w, "cyan", "[-] %s (suppressed)", finding.Message)
+			}
+		case finding.Severity == SeverityError:
+			outputs.PrintColoredMessageTo(w, "red", "[✘] %s", finding.Message)
+			if finding.Remedy != "" {
+				fixes = append(fixes, finding.Remedy)
+			}
+		case finding.Severity == SeverityWarning:
+			outputs.PrintColoredMessageTo(w, "yellow", "[~] %s", finding.Message)
+			if finding.Remedy != "" {
+				suggestions = append(suggestions, finding.Remedy)
+			}
+		}
+	}
+
+	outputs.PrintTerminalWideLineTo(w, "=")
+	if len(suggestions) > 0 {
+		outputs.PrintColoredMessageTo(w, "yellow", "%s", i18n.Translate("Suggestions:"))
+		for _, suggestion := range suggestions {
+			outputs.PrintColoredMessageTo(w, "yellow", "  - %s", suggestion)
+		}
+	}
+	if len(fixes) > 0 {
+		outputs.PrintColoredMessageTo(w, "red", "%s", i18n.Translate("Fixes:"))
+		for _, fix := range fixes {
+			outputs.PrintColoredMessageTo(w, "red", "  - %s", fix)
+		}
+	}
+}
+
+// reportBuilder accumulates findings, applying the configured severity
+// overrides to failed rules as they are recorded.
+type reportBuilder struct {
+	overrides    map[string]Severity
+	suppressions []Suppression
+	report       ValidationReport
+
+	// files is the workspace the step file references are checked
+	// against, or nil to skip those checks.
+	files fs.FS
+}
+
+func (b *reportBuilder) pass(ruleID string, message string, args ...any) {
+	b.report.Findings = append(b.report.Findings, Finding{
+		RuleID:  ruleID,
+		Passed:  true,
+		Message: i18n.T(message, args...),
+	})
+}
+
+func (b *reportBuilder) fail(ruleID string, remedy string, message string, args ...any) {
+	b.failAt("", ruleID, remedy, message, args...)
+}
+
+// failAt records a failed finding about the key at path, which only
+// suppression comments on that key, around it or inside it acknowledge.
+func (b *reportBuilder) failAt(path string, ruleID string, remedy string, message string, args ...any) {
+	severity := defaultSeverities[ruleID]
+	switch override := b.overrides[ruleID]; override {
+	case SeverityError, SeverityWarning, SeverityOff:
+		severity = override
+	}
+	if severity == SeverityOff {
+		return
+	}
+	finding := Finding{
+		RuleID:   ruleID,
+		Severity: severity,
+		Message:  i18n.T(message, args...),
+		Remedy:   i18n.Translate(remedy),
+		Path:     path,
+	}
+	for _, suppression := range b.suppressions {
+		if suppression.RuleID == ruleID && suppression.covers(path) {
+			finding.Suppressed = true
+			finding.SuppressionReason = suppression.Reason
+			break
+		}
+	}
+	b.report.Findings = append(b.report.Findings, finding)
+}
+
+// checkOverrides reports rule IDs and severities in the validation block
+// or suppression comments that are not recognised, so typos do not
+// silently disable a rule.
+func (b *reportBuilder) checkOverrides() {
+	ruleIDs := make([]string, 0, len(b.overrides))
+	for ruleID := range b.overrides {
+		ruleIDs = append(ruleIDs, ruleID)
+	}
+	sort.Strings(ruleIDs)
+
+	knownRules := make([]string, 0, len(defaultSeverities))
+	for ruleID := range defaultSeverities {
+		knownRules = append(knownRules, ruleID)
+	}
+
+	unknownRule := func(ruleID string, message string, remedy string) {
+		if suggestion := closestMatch(ruleID, knownRules); suggestion != "" {
+			remedy = fmt.Sprintf("Did you mean '%s'?", suggestion)
+		}
+		b.report.Findings = append(b.report.Findings, Finding{
+			RuleID:   RuleValidationConfig,
+			Severity: SeverityError,
+			Message:  message,
+			Remedy:   remedy,
+		})
+	}
+
+	for _, suppression := range b.suppressions {
+		if _, ok := defaultSeverities[suppression.RuleID]; !ok {
+			unknownRule(suppression.RuleID,
+				fmt.Sprintf("Unknown rule in suppression comment near line %d: %s", suppression.Line, suppression.RuleID),
+				"Remove the suppression comment")
+		}
+	}
+
+	for _, ruleID := range ruleIDs {
+		if _, ok := defaultSeverities[ruleID]; !ok {
+			unknownRule(ruleID,
+				fmt.Sprintf("Unknown validation rule: %s", ruleID),
+				"Remove the unknown rule from the validation block")
+			continue
+		}
+		switch b.overrides[ruleID] {
+		case SeverityError, SeverityWarning, SeverityOff:
+		default:
+			b.report.Findings = append(b.report.Findings, Finding{
+				RuleID:   RuleValidationConfig,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("Invalid severity for %s: %s", ruleID, b.overrides[ruleID]),
+				Remedy:   "Use one of: error, warning, off",
+			})
+		}
+	}
+}
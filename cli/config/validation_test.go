@@ -0,0 +1,197 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/internal/i18n"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func findingFor(report *ValidationReport, ruleID string) (Finding, bool) {
+	for _, finding := range report.Findings {
+		if finding.RuleID == ruleID {
+			return finding, true
+		}
+	}
+	return Finding{}, false
+}
+
+func TestProjectDefinition_Report(t *testing.T) {
+	tests := []struct {
+		name             string
+		project          ProjectDefinition
+		expectedErrors   []string
+		expectedWarnings []string
+		expectedMissing  []string
+	}{
+		{
+			name: "default severities",
+			project: ProjectDefinition{
+				ID: "test-project",
+				Codebase: Codebase{
+					Language: "go",
+				},
+			},
+			expectedErrors:   []string{RuleRepoURLRequired},
+			expectedWarnings: []string{RuleDependenciesDefined, RuleTestStepsDefined, RuleBuildStepsDefined},
+		},
+		{
+			name: "error downgraded to warning",
+			project: ProjectDefinition{
+				ID: "test-project",
+				Codebase: Codebase{
+					Language: "go",
+				},
+				Validation: ValidationConfig{
+					Rules: map[string]Severity{RuleRepoURLRequired: SeverityWarning},
+				},
+			},
+			expectedWarnings: []string{RuleRepoURLRequired, RuleDependenciesDefined},
+		},
+		{
+			name: "warning promoted to error",
+			project: ProjectDefinition{
+				ID:      "test-project",
+				RepoUrl: "https://github.com/test/project",
+				Codebase: Codebase{
+					Language: "go",
+				},
+				Validation: ValidationConfig{
+					Rules: map[string]Severity{RuleTestStepsDefined: SeverityError},
+				},
+			},
+			expectedErrors:   []string{RuleTestStepsDefined},
+			expectedWarnings: []string{RuleBuildStepsDefined},
+		},
+		{
+			name: "rule turned off",
+			project: ProjectDefinition{
+				ID:      "test-project",
+				RepoUrl: "https://github.com/test/project",
+				Codebase: Codebase{
+					Language: "go",
+				},
+				Validation: ValidationConfig{
+					Rules: map[string]Severity{RuleDependenciesDefined: SeverityOff},
+				},
+			},
+			expectedMissing: []string{RuleDependenciesDefined},
+		},
+		{
+			name: "unknown rule and invalid severity",
+			project: ProjectDefinition{
+				ID:      "test-project",
+				RepoUrl: "https://github.com/test/project",
+				Codebase: Codebase{
+					Language: "go",
+				},
+				Validation: ValidationConfig{
+					Rules: map[string]Severity{
+						"repo-url-requried":  SeverityWarning,
+						RuleTestStepsDefined: "fatal",
+					},
+				},
+			},
+			expectedErrors: []string{RuleValidationConfig},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := tt.project.Report()
+
+			for _, ruleID := range tt.expectedErrors {
+				finding, ok := findingFor(report, ruleID)
+				require.True(t, ok, "expected finding for %s", ruleID)
+				assert.Equal(t, SeverityError, finding.Severity)
+			}
+			for _, ruleID := range tt.expectedWarnings {
+				finding, ok := findingFor(report, ruleID)
+				require.True(t, ok, "expected finding for %s", ruleID)
+				assert.False(t, finding.Passed)
+				assert.Equal(t, SeverityWarning, finding.Severity)
+			}
+			for _, ruleID := range tt.expectedMissing {
+				_, ok := findingFor(report, ruleID)
+				assert.False(t, ok, "expected no finding for %s", ruleID)
+			}
+		})
+	}
+}
+
+func TestProjectDefinition_Report_UnknownRuleSuggestion(t *testing.T) {
+	project := ProjectDefinition{
+		Validation: ValidationConfig{
+			Rules: map[string]Severity{"repo-url-requried": SeverityWarning},
+		},
+	}
+
+	report := project.Report()
+	finding, ok := findingFor(report, RuleValidationConfig)
+	require.True(t, ok)
+	assert.Contains(t, finding.Message, "repo-url-requried")
+	assert.Equal(t, "Did you mean 'repo-url-required'?", finding.Remedy)
+}
+
+func TestProjectDefinition_ValidateTo_SeverityOverride(t *testing.T) {
+	project := ProjectDefinition{
+		ID: "test-project",
+		Codebase: Codebase{
+			Language:     "go",
+			Dependencies: []string{"go.mod"},
+			Test:         Operation{Steps: StepsFromCommands("go test ./...")},
+			Build:        Operation{Steps: StepsFromCommands("go build ./...")},
+		},
+		Validation: ValidationConfig{
+			Rules: map[string]Severity{RuleRepoURLRequired: SeverityWarning},
+		},
+	}
+
+	var buf bytes.Buffer
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+
+	err := project.ValidateTo(ctx, &buf)
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "[~] Repository URL is required")
+	assert.Contains(t, buf.String(), "Suggestions:")
+	assert.NotContains(t, buf.String(), "Fixes:")
+}
+
+func TestLoad_ValidationBlock(t *testing.T) {
+	yamlContent := `id: internal-tool
+codebase:
+  language: go
+validation:
+  rules:
+    repo-url-required: warning
+    dependencies-defined: off
+`
+	cfg, err := Load(strings.NewReader(yamlContent))
+	require.NoError(t, err)
+	assert.Equal(t, SeverityWarning, cfg.Validation.Rules[RuleRepoURLRequired])
+	assert.Equal(t, SeverityOff, cfg.Validation.Rules[RuleDependenciesDefined])
+}
+
+func TestProjectDefinition_Report_Translated(t *testing.T) {
+	_, err := i18n.SetLocale("es")
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = i18n.SetLocale(i18n.DefaultLocale) })
+
+	report := (&ProjectDefinition{}).Report()
+	finding, ok := findingFor(report, RuleIDRequired)
+	require.True(t, ok)
+	assert.Equal(t, "El ID es obligatorio", finding.Message)
+	assert.Equal(t, "Define un ID para el proyecto", finding.Remedy)
+
+	var buf bytes.Buffer
+	report.Render(&buf)
+	assert.Contains(t, buf.String(), "Correcciones:")
+}
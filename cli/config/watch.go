@@ -0,0 +1,89 @@
+package config
+
+import (
+	"context"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+)
+
+// maxListedChanges bounds how many changed paths are logged per batch.
+const maxListedChanges = 5
+
+// WatchIgnore returns the paths a watch of the operation leaves out: the
+// git and devops directories and the artifacts and outputs the operation
+// declares, which it writes itself.
+func (d *ProjectDefinition) WatchIgnore(name string) []string {
+	ignore := []string{".git", WorkDir}
+	for _, view := range d.codebaseViews() {
+		op, ok := view.Codebase.Lookup(name)
+		if !ok {
+			continue
+		}
+		for _, pattern := range append(slices.Clone(op.Artifacts), op.Outputs...) {
+			ignore = append(ignore, path.Join(view.Codebase.Path, pattern))
+		}
+	}
+	return ignore
+}
+
+// Watch runs the operation, then runs it again for every batch of changes
+// received until ctx is done or changes is closed. A change while a run
+// is in progress cancels it before the next run starts. Failed runs are
+// logged and do not stop watching.
+func (d *ProjectDefinition) Watch(ctx context.Context, name string, changes <-chan []string, shellExecutor ShellExecutor) error {
+	logger := logging.FromContext(ctx)
+	if !d.hasOperation(name) {
+		return d.unknownOperation(name)
+	}
+	start := func() (context.CancelFunc, <-chan struct{}) {
+		runCtx, cancel := context.WithCancel(ctx)
+		// Each run starts with an empty summary, so prerequisites that
+		// succeeded before the change are run again.
+		runCtx = WithRunSummary(runCtx, &RunSummary{Command: "watch " + name})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			err := d.Run(runCtx, name, shellExecutor)
+			switch {
+			case runCtx.Err() != nil:
+				logger.Infof("Cancelled %s", name)
+			case err != nil:
+				logger.Errorf("%s failed: %v", name, err)
+			default:
+				logger.Infof("%s succeeded", name)
+			}
+			logger.Infof("Watching for changes, press Ctrl+C to stop")
+		}()
+		return cancel, done
+	}
+
+	cancel, done := start()
+	for {
+		select {
+		case <-ctx.Done():
+			cancel()
+			<-done
+			return nil
+		case changed, ok := <-changes:
+			cancel()
+			<-done
+			if !ok {
+				return nil
+			}
+			logger.Infof("Changed: %s", describeChanges(changed))
+			cancel, done = start()
+		}
+	}
+}
+
+// describeChanges lists the first few changed paths and counts the rest.
+func describeChanges(changed []string) string {
+	if len(changed) <= maxListedChanges {
+		return strings.Join(changed, ", ")
+	}
+	return strings.Join(changed[:maxListedChanges], ", ") + " and " + strconv.Itoa(len(changed)-maxListedChanges) + " more"
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectDefinition_Watch(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	project := ProjectDefinition{
+		ID: "watch-project",
+		Codebase: Codebase{
+			Test: Operation{Steps: StepsFromCommands("go test ./...")},
+		},
+	}
+
+	t.Run("re-runs on each change", func(t *testing.T) {
+		m := &MockShellExecutor{}
+		runs := make(chan struct{}, 3)
+		m.On("Exec", mock.Anything, "go test ./...").Run(func(args mock.Arguments) {
+			runs <- struct{}{}
+		}).Return(executor.Result{}, nil)
+		changes := make(chan []string)
+		go func() {
+			<-runs
+			changes <- []string{"main.go"}
+			<-runs
+			close(changes)
+		}()
+
+		require.NoError(t, project.Watch(ctx, "test", changes, m))
+		m.AssertNumberOfCalls(t, "Exec", 2)
+	})
+
+	t.Run("cancels a run in progress", func(t *testing.T) {
+		m := &MockShellExecutor{}
+		started := make(chan struct{}, 2)
+		cancelled := make(chan struct{}, 2)
+		m.On("Exec", mock.Anything, "go test ./...").Run(func(args mock.Arguments) {
+			started <- struct{}{}
+			select {
+			case <-args.Get(0).(context.Context).Done():
+				cancelled <- struct{}{}
+			case <-time.After(time.Second):
+			}
+		}).Return(executor.Result{}, nil)
+		changes := make(chan []string)
+		go func() {
+			<-started
+			changes <- []string{"main.go"}
+			<-started
+			close(changes)
+		}()
+
+		require.NoError(t, project.Watch(ctx, "test", changes, m))
+		assert.Len(t, cancelled, 2, "both runs end cancelled, the second by the closed channel")
+	})
+
+	t.Run("unknown operation", func(t *testing.T) {
+		err := project.Watch(ctx, "tset", nil, &MockShellExecutor{})
+		assert.EqualError(t, err, "unknown operation 'tset' (did you mean 'test'?)")
+	})
+}
+
+func TestProjectDefinition_WatchIgnore(t *testing.T) {
+	project := ProjectDefinition{
+		Codebases: []Codebase{{
+			Name:  "api",
+			Path:  "./api",
+			Build: Operation{Artifacts: []string{"dist/*.tar.gz"}, Outputs: []string{"./bin/api"}},
+		}},
+	}
+	assert.Equal(t, []string{".git", WorkDir, "api/dist/*.tar.gz", "api/bin/api"}, project.WatchIgnore("build"))
+}
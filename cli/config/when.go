@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+
+	"github.com/jgfranco17/devops/internal/condition"
+	"github.com/jgfranco17/devops/internal/environment"
+)
+
+// conditionVars returns the identifiers available to `when:` conditions:
+// ci, os, arch and env.NAME. Variables in the given maps take precedence
+// over the process environment, later maps over earlier ones.
+func conditionVars(envs ...map[string]string) condition.Vars {
+	return condition.Vars{
+		Values: map[string]string{
+			"ci":   strconv.FormatBool(environment.IsRunningInCI()),
+			"os":   runtime.GOOS,
+			"arch": runtime.GOARCH,
+		},
+		Env: func(name string) string {
+			for i := len(envs) - 1; i >= 0; i-- {
+				if value, ok := envs[i][name]; ok {
+					return value
+				}
+			}
+			return os.Getenv(name)
+		},
+	}
+}
+
+// evaluateWhen reports whether a `when:` condition holds. An empty
+// condition always holds.
+func evaluateWhen(when string, envs ...map[string]string) (bool, error) {
+	if when == "" {
+		return true, nil
+	}
+	ok, err := condition.Evaluate(when, conditionVars(envs...))
+	if err != nil {
+		return false, fmt.Errorf("invalid when condition '%s': %w", when, err)
+	}
+	return ok, nil
+}
+
+// checkConditions reports `when:` conditions that cannot be evaluated.
+func (d *ProjectDefinition) checkConditions(b *reportBuilder) {
+	for _, name := range d.Codebase.OperationNames() {
+		op, _ := d.Codebase.Lookup(name)
+		conditions := 0
+		failed := false
+		if op.When != "" {
+			conditions++
+			if _, err := evaluateWhen(op.When, op.Env); err != nil {
+				failed = true
+				b.failAt(d.operationPath(name), RuleWhenConditions, "Use ci, os, arch or env.NAME with ==, !=, &&, || and !",
+					"Operation '%s': %s", name, err.Error())
+			}
+		}
+		for _, step := range op.Steps {
+			if step.When == "" {
+				continue
+			}
+			conditions++
+			if _, err := evaluateWhen(step.When, op.Env, step.Env); err != nil {
+				failed = true
+				b.failAt(d.operationPath(name), RuleWhenConditions, "Use ci, os, arch or env.NAME with ==, !=, &&, || and !",
+					"Step '%s' of '%s': %s", step.Label(), name, err.Error())
+			}
+		}
+		if conditions > 0 && !failed {
+			b.pass(RuleWhenConditions, "Operation '%s' conditions are valid", name)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateWhen(t *testing.T) {
+	t.Setenv("BRANCH", "feature")
+
+	ok, err := evaluateWhen("")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = evaluateWhen(`os == "` + runtime.GOOS + `"`)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = evaluateWhen(`env.BRANCH == "main"`)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = evaluateWhen(`env.BRANCH == "main"`, map[string]string{"BRANCH": "dev"}, map[string]string{"BRANCH": "main"})
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = evaluateWhen(`branch == "main"`)
+	assert.ErrorContains(t, err, "invalid when condition 'branch == \"main\"': unknown identifier 'branch'")
+}
+
+func TestOperation_Run_SkipsStepsByCondition(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	op := Operation{
+		Env: map[string]string{"TARGET": "prod"},
+		Steps: []Step{
+			{Run: "make"},
+			{Run: "make deploy-staging", When: `env.TARGET == "staging"`},
+			{Run: "make deploy-prod", When: `env.TARGET == "prod"`},
+		},
+	}
+	m := &MockShellExecutor{}
+	m.On("Exec", mock.Anything, "make").Return(executor.Result{}, nil)
+	m.On("Exec", mock.Anything, "make deploy-prod").Return(executor.Result{}, nil)
+
+	require.NoError(t, op.Run(ctx, m))
+	m.AssertNotCalled(t, "Exec", mock.Anything, "make deploy-staging")
+	m.AssertExpectations(t)
+}
+
+func TestProjectDefinition_Run_SkipsOperationByCondition(t *testing.T) {
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	project := ProjectDefinition{Codebase: Codebase{
+		Build: Operation{When: `os == "plan9"`, Steps: StepsFromCommands("make")},
+	}}
+	m := &MockShellExecutor{}
+
+	require.NoError(t, project.Build(ctx, m))
+	m.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything)
+
+	project.Codebase.Build.When = `os ==`
+	assert.ErrorContains(t, project.Build(ctx, m), "operation build: invalid when condition")
+}
+
+func TestLoad_When(t *testing.T) {
+	yamlContent := `id: conditional
+codebase:
+  build:
+    when: ci
+    steps:
+      - make
+      - run: make release
+        when: env.BRANCH == "main"
+`
+	cfg, err := Load(strings.NewReader(yamlContent))
+	require.NoError(t, err)
+	assert.Equal(t, "ci", cfg.Codebase.Build.When)
+	assert.Equal(t, `env.BRANCH == "main"`, cfg.Codebase.Build.Steps[1].When)
+
+	report := cfg.Report()
+	finding, ok := findingFor(report, RuleWhenConditions)
+	require.True(t, ok)
+	assert.True(t, finding.Passed)
+
+	cfg.Codebase.Build.Steps[1].When = `branch == "main"`
+	finding, _ = findingFor(cfg.Report(), RuleWhenConditions)
+	assert.False(t, finding.Passed)
+	assert.Contains(t, finding.Message, "Step 'make release' of 'build'")
+}
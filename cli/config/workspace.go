@@ -0,0 +1,158 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"gopkg.in/yaml.v3"
+)
+
+// WorkspaceFile lists the projects of a monorepo, each with its own
+// definition file.
+const WorkspaceFile = "devops-workspace.yaml"
+
+// Workspace is a set of projects in one repository.
+type Workspace struct {
+	Projects map[string]WorkspaceProject `yaml:"projects"`
+
+	// dir is the directory holding the workspace file, which project
+	// paths are relative to.
+	dir string
+}
+
+// WorkspaceProject points at a directory containing a definition file and
+// names the projects that must run before it.
+type WorkspaceProject struct {
+	Path      string   `yaml:"path"`
+	DependsOn []string `yaml:"depends_on,omitempty"`
+}
+
+// FindWorkspace returns the path of the nearest workspace file in the
+// working directory or its parents.
+func FindWorkspace() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current working directory: %w", err)
+	}
+	for {
+		path := filepath.Join(dir, WorkspaceFile)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no %s found in this directory or its parents: %w", WorkspaceFile, fs.ErrNotExist)
+		}
+		dir = parent
+	}
+}
+
+// LoadWorkspace reads and checks the workspace file at path.
+func LoadWorkspace(path string) (*Workspace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace: %w", err)
+	}
+	var ws Workspace
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&ws); err != nil {
+		return nil, fmt.Errorf("failed to decode workspace: %w", explainDecodeError(err, reflect.TypeOf(ws)))
+	}
+	if len(ws.Projects) == 0 {
+		return nil, errors.New("workspace defines no projects")
+	}
+	for name, project := range ws.Projects {
+		if project.Path == "" {
+			return nil, fmt.Errorf("workspace project '%s' has no path", name)
+		}
+		for _, dep := range project.DependsOn {
+			if _, ok := ws.Projects[dep]; !ok {
+				return nil, fmt.Errorf("workspace project '%s' depends on %s", name, ws.unknownProject(dep))
+			}
+		}
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	ws.dir = filepath.Dir(abs)
+	return &ws, nil
+}
+
+func (w *Workspace) unknownProject(name string) string {
+	names := make([]string, 0, len(w.Projects))
+	for project := range w.Projects {
+		names = append(names, project)
+	}
+	sort.Strings(names)
+	msg := fmt.Sprintf("unknown project '%s'", name)
+	if suggestion := closestMatch(name, names); suggestion != "" {
+		msg += fmt.Sprintf(" (did you mean '%s'?)", suggestion)
+	}
+	return msg
+}
+
+// ProjectDir returns the absolute directory of the named project.
+func (w *Workspace) ProjectDir(name string) (string, error) {
+	project, ok := w.Projects[name]
+	if !ok {
+		return "", errors.New(w.unknownProject(name))
+	}
+	return filepath.Join(w.dir, filepath.FromSlash(project.Path)), nil
+}
+
+// Order returns the project names so each comes after the projects it
+// depends on. Ties are broken alphabetically.
+func (w *Workspace) Order() ([]string, error) {
+	needs := map[string][]string{}
+	for name, project := range w.Projects {
+		needs[name] = project.DependsOn
+	}
+	order, cyclic := dependencyOrder(needs)
+	if len(cyclic) > 0 {
+		return nil, fmt.Errorf("workspace has a dependency cycle between: %s", strings.Join(cyclic, ", "))
+	}
+	return order, nil
+}
+
+// Run runs the operation in every project, in dependency order, from the
+// project's directory. It stops at the first project that fails.
+func (w *Workspace) Run(ctx context.Context, operation string, shellExecutor ShellExecutor) error {
+	logger := logging.FromContext(ctx)
+	order, err := w.Order()
+	if err != nil {
+		return err
+	}
+	logger.Infof("Workspace order: %s", strings.Join(order, " -> "))
+	for _, name := range order {
+		if err := w.runProject(ctx, name, operation, shellExecutor); err != nil {
+			return fmt.Errorf("workspace stopped at %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (w *Workspace) runProject(ctx context.Context, name string, operation string, shellExecutor ShellExecutor) error {
+	dir, err := w.ProjectDir(name)
+	if err != nil {
+		return err
+	}
+	return inDir(dir, func() error {
+		definition, err := LoadFile(DefinitionFile)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %w", filepath.Join(w.Projects[name].Path, DefinitionFile), err)
+		}
+		logging.FromContext(ctx).Infof("Running %s in project %s", operation, name)
+		return definition.Run(ctx, operation, shellExecutor)
+	})
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testWorkspace = `projects:
+  api:
+    path: services/api
+    depends_on: [common]
+  web:
+    path: services/web
+    depends_on: [api]
+  common:
+    path: libs/common
+`
+
+func TestLoadWorkspace(t *testing.T) {
+	dir := writeDefinitionFiles(t, map[string]string{WorkspaceFile: testWorkspace})
+
+	ws, err := LoadWorkspace(filepath.Join(dir, WorkspaceFile))
+	require.NoError(t, err)
+	order, err := ws.Order()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"common", "api", "web"}, order)
+
+	projectDir, err := ws.ProjectDir("api")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "services", "api"), projectDir)
+
+	_, err = ws.ProjectDir("apu")
+	assert.ErrorContains(t, err, "unknown project 'apu' (did you mean 'api'?)")
+}
+
+func TestLoadWorkspace_Invalid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"no projects", "projects: {}\n", "workspace defines no projects"},
+		{"missing path", "projects:\n  api: {}\n", "workspace project 'api' has no path"},
+		{"unknown dependency", "projects:\n  api:\n    path: api\n    depends_on: [comon]\n  common:\n    path: common\n", "workspace project 'api' depends on unknown project 'comon' (did you mean 'common'?)"},
+		{"unknown key", "projects:\n  api:\n    path: api\n    depend_on: [x]\n", "failed to decode workspace"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeDefinitionFiles(t, map[string]string{WorkspaceFile: tc.content})
+			_, err := LoadWorkspace(filepath.Join(dir, WorkspaceFile))
+			assert.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
+
+func TestWorkspace_Order_Cycle(t *testing.T) {
+	ws := Workspace{Projects: map[string]WorkspaceProject{
+		"a": {Path: "a", DependsOn: []string{"b"}},
+		"b": {Path: "b", DependsOn: []string{"a"}},
+		"c": {Path: "c"},
+	}}
+	_, err := ws.Order()
+	assert.ErrorContains(t, err, "workspace has a dependency cycle between: a, b")
+}
+
+func TestFindWorkspace(t *testing.T) {
+	dir := writeDefinitionFiles(t, map[string]string{
+		WorkspaceFile:             testWorkspace,
+		"services/api/.gitkeep":   "",
+		"libs/common/placeholder": "",
+	})
+	t.Chdir(filepath.Join(dir, "services", "api"))
+
+	path, err := FindWorkspace()
+	require.NoError(t, err)
+	want, err := filepath.EvalSymlinks(filepath.Join(dir, WorkspaceFile))
+	require.NoError(t, err)
+	got, err := filepath.EvalSymlinks(path)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestWorkspace_Run(t *testing.T) {
+	dir := writeDefinitionFiles(t, map[string]string{
+		WorkspaceFile:                    testWorkspace,
+		"libs/common/" + DefinitionFile:  "id: common\ncodebase:\n  test:\n    steps: [go test ./common/...]\n",
+		"services/api/" + DefinitionFile: "id: api\ncodebase:\n  test:\n    steps: [go test ./api/...]\n",
+		"services/web/" + DefinitionFile: "id: web\ncodebase:\n  test:\n    steps: [npm test]\n",
+	})
+	t.Chdir(dir)
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	ws, err := LoadWorkspace(WorkspaceFile)
+	require.NoError(t, err)
+
+	ran := []string{}
+	m := &MockShellExecutor{}
+	m.On("Exec", mock.Anything, mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+		cwd, err := os.Getwd()
+		require.NoError(t, err)
+		ran = append(ran, filepath.Base(cwd)+": "+args.String(1))
+	}).Return(executor.Result{}, nil).Times(2)
+	m.On("Exec", mock.Anything, "npm test").Return(executor.Result{ExitCode: 1}, nil)
+
+	err = ws.Run(ctx, "test", m)
+	assert.ErrorContains(t, err, "workspace stopped at web")
+	assert.Equal(t, []string{"common: go test ./common/...", "api: go test ./api/..."}, ran)
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Base(dir), filepath.Base(cwd))
+}
@@ -1,10 +1,21 @@
 package core
 
 import (
+	"bufio"
+	"cmp"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -13,88 +24,832 @@ import (
 	"github.com/jgfranco17/devops/cli/config"
 	"github.com/jgfranco17/devops/cli/executor"
 	"github.com/jgfranco17/devops/internal/doc"
+	"github.com/jgfranco17/devops/internal/fileutils"
+	"github.com/jgfranco17/devops/internal/i18n"
+	"github.com/jgfranco17/devops/internal/outputs"
+	"github.com/jgfranco17/devops/internal/watch"
 )
 
 type BashExecutor interface {
 	Exec(ctx context.Context, command string) (executor.Result, error)
-	AddEnv(env []string)
 }
 
 func GetBuildCommand(shellExecutor BashExecutor) *cobra.Command {
+	var envPairs []string
+	var step string
+	var noCache bool
 	cmd := &cobra.Command{
-		Use:   "build",
-		Short: "Run the build operations",
-		Long:  "Build the project according to the configuration..",
-		Args:  cobra.NoArgs,
+		Use:         "build",
+		Short:       i18n.Translate("Run the build operations"),
+		Long:        i18n.Translate("Build the project according to the configuration.."),
+		Annotations: map[string]string{lockAnnotation: "true"},
+		Args:        cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := cmd.Context()
+			ctx, err := withEnvFlag(cmd.Context(), envPairs)
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("build failed: %w"), err)
+			}
+			ctx = withStepFlag(ctx, "build", step)
+			if noCache {
+				ctx = config.WithoutCache(ctx)
+			}
 			cfg := config.FromContext(ctx)
 			if err := cfg.Build(ctx, shellExecutor); err != nil {
-				return fmt.Errorf("build failed: %w", err)
+				return fmt.Errorf(i18n.Translate("build failed: %w"), err)
 			}
 			return nil
 		},
 		SilenceUsage:  true,
 		SilenceErrors: true,
 	}
+	addEnvFlag(cmd, &envPairs)
+	addStepFlag(cmd, &step)
+	addNoCacheFlag(cmd, &noCache)
 	return cmd
 }
 
 func GetTestCommand(shellExecutor BashExecutor) *cobra.Command {
+	var report string
+	var envPairs []string
+	var step string
+	var noCache bool
+	cmd := &cobra.Command{
+		Use:         "test",
+		Short:       i18n.Translate("Run the test operations"),
+		Long:        i18n.Translate("Run the designated test operations."),
+		Annotations: map[string]string{lockAnnotation: "true"},
+		Args:        cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := withEnvFlag(cmd.Context(), envPairs)
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("tests failed: %w"), err)
+			}
+			ctx = withStepFlag(ctx, "test", step)
+			if noCache {
+				ctx = config.WithoutCache(ctx)
+			}
+			cfg := config.FromContext(ctx)
+			summary := config.RunSummaryFromContext(ctx)
+			if report != "" && summary == nil {
+				summary = &config.RunSummary{Command: cmd.CommandPath()}
+				ctx = config.WithRunSummary(ctx, summary)
+			}
+			if report != "" {
+				summary.ReportPath = report
+			}
+			testErr := cfg.Test(ctx, shellExecutor)
+			if report != "" {
+				if err := summary.WriteJUnitFile(report); err != nil {
+					return err
+				}
+			}
+			if testErr != nil {
+				return fmt.Errorf(i18n.Translate("tests failed: %w"), testErr)
+			}
+			return nil
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().StringVar(&report, "report", "", "Write the step results as a JUnit XML report to this path")
+	addEnvFlag(cmd, &envPairs)
+	addStepFlag(cmd, &step)
+	addNoCacheFlag(cmd, &noCache)
+	return cmd
+}
+
+func GetRunCommand(shellExecutor BashExecutor) *cobra.Command {
+	var envPairs []string
+	var only bool
+	var step string
+	var noCache bool
+	cmd := &cobra.Command{
+		Use:         "run <operation>",
+		Short:       i18n.Translate("Run a named operation"),
+		Long:        i18n.Translate("Run any built-in or custom operation defined in the configuration, after the operations it depends on."),
+		Annotations: map[string]string{lockAnnotation: "true"},
+		Args:        cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := withEnvFlag(cmd.Context(), envPairs)
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("%s failed: %w"), args[0], err)
+			}
+			if only {
+				ctx = config.WithoutDependencies(ctx)
+			}
+			ctx = withStepFlag(ctx, args[0], step)
+			if noCache {
+				ctx = config.WithoutCache(ctx)
+			}
+			cfg := config.FromContext(ctx)
+			if err := cfg.Run(ctx, args[0], shellExecutor); err != nil {
+				return fmt.Errorf(i18n.Translate("%s failed: %w"), args[0], err)
+			}
+			return nil
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	addEnvFlag(cmd, &envPairs)
+	cmd.Flags().BoolVar(&only, "only", false, "Run only this operation, without the operations it depends on")
+	addStepFlag(cmd, &step)
+	addNoCacheFlag(cmd, &noCache)
+	return cmd
+}
+
+// addEnvFlag registers the repeatable --env flag of the commands that run
+// operations.
+func addEnvFlag(cmd *cobra.Command, pairs *[]string) {
+	cmd.Flags().StringArrayVar(pairs, "env", nil, "Set an environment variable for this run as KEY=VALUE (repeatable)")
+}
+
+// addStepFlag registers the --step flag of the commands that run a single
+// operation.
+func addStepFlag(cmd *cobra.Command, step *string) {
+	cmd.Flags().StringVar(step, "step", "", "Run only this step of the operation, by 1-based index or name")
+}
+
+// addNoCacheFlag registers the --no-cache flag of the commands that run
+// operations with cached steps.
+func addNoCacheFlag(cmd *cobra.Command, noCache *bool) {
+	cmd.Flags().BoolVar(noCache, "no-cache", false, "Run every step, ignoring cached results")
+}
+
+// withStepFlag selects the --step of the operation, if one was given.
+func withStepFlag(ctx context.Context, operation string, step string) context.Context {
+	if step == "" {
+		return ctx
+	}
+	return config.WithStep(ctx, operation, step)
+}
+
+// withEnvFlag adds the --env values to the context as env overrides.
+func withEnvFlag(ctx context.Context, pairs []string) (context.Context, error) {
+	if len(pairs) == 0 {
+		return ctx, nil
+	}
+	overrides, err := config.ParseEnvOverrides(pairs)
+	if err != nil {
+		return ctx, err
+	}
+	return config.WithEnvOverrides(ctx, overrides), nil
+}
+
+// outputFileAlias keeps the deprecated --output spelling of --output-file
+// working on commands that write a file. Output formats still set the
+// global --output flag, which the alias would otherwise shadow.
+type outputFileAlias struct {
+	cmd  *cobra.Command
+	file *string
+}
+
+func (a *outputFileAlias) String() string { return *a.file }
+
+func (a *outputFileAlias) Type() string { return "string" }
+
+func (a *outputFileAlias) Set(value string) error {
+	if _, err := config.ParseOutputFormat(value); err == nil {
+		return a.cmd.Root().PersistentFlags().Set("output", value)
+	}
+	fmt.Fprintln(a.cmd.ErrOrStderr(), "Flag --output has been deprecated for the output file, use --output-file instead")
+	*a.file = value
+	return nil
+}
+
+// addOutputFileFlag adds the --output-file flag, along with its hidden,
+// deprecated --output alias.
+func addOutputFileFlag(cmd *cobra.Command, file *string, value string, usage string) {
+	cmd.Flags().StringVarP(file, "output-file", "o", value, usage)
+	cmd.Flags().Var(&outputFileAlias{cmd: cmd, file: file}, "output", usage)
+	_ = cmd.Flags().MarkHidden("output")
+}
+
+func GetPromoteCommand() *cobra.Command {
+	var environment string
 	cmd := &cobra.Command{
-		Use:   "test",
-		Short: "Run the test operations",
-		Long:  "Run the designated test operations.",
+		Use:   "promote <run-id>",
+		Short: i18n.Translate("Promote a run's artifacts to an environment"),
+		Long:  i18n.Translate("Copy the artifacts retained from an earlier run to an environment without rebuilding, and record the promotion in the history."),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			cfg := config.FromContext(ctx)
+			promotion, err := cfg.Promote(ctx, args[0], environment, time.Now())
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("promote failed: %w"), err)
+			}
+			outputs.PrintColoredMessageTo(cmd.OutOrStdout(), "green", "Promoted %s (%s) to %s at %s",
+				promotion.RunID, promotion.Operation, promotion.Environment, promotion.Destination)
+			return nil
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().StringVar(&environment, "to", "", "Environment to promote the artifacts to")
+	_ = cmd.MarkFlagRequired("to")
+	return cmd
+}
+
+func GetArtifactsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "artifacts",
+		Short: i18n.Translate("Manage retained run artifacts"),
 		Args:  cobra.NoArgs,
+	}
+	cmd.AddCommand(getArtifactsPushCommand())
+	return cmd
+}
+
+func getArtifactsPushCommand() *cobra.Command {
+	var store string
+	cmd := &cobra.Command{
+		Use:   "push [run-id]",
+		Short: i18n.Translate("Upload a run's artifacts to an artifact store"),
+		Long:  i18n.Translate("Upload the artifacts retained from a run, the latest one by default, to a store of artifact_stores."),
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			cfg := config.FromContext(ctx)
-			if err := cfg.Test(ctx, shellExecutor); err != nil {
-				return fmt.Errorf("tests failed: %w", err)
+			runID := ""
+			if len(args) > 0 {
+				runID = args[0]
+			} else {
+				record, err := config.LatestRun()
+				if err != nil {
+					return fmt.Errorf(i18n.Translate("push failed: %w"), err)
+				}
+				runID = record.ID
+			}
+			push, err := cfg.PushArtifacts(ctx, runID, store)
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("push failed: %w"), err)
+			}
+			outputs.PrintColoredMessageTo(cmd.OutOrStdout(), "green", "Pushed %d artifact(s) of %s (%s) to %s",
+				len(push.Objects), push.RunID, push.Operation, push.Store)
+			return nil
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().StringVar(&store, "to", "", "Artifact store to push to, required when several are configured")
+	return cmd
+}
+
+func GetImageCommand(shellExecutor BashExecutor) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "image",
+		Short: i18n.Translate("Build and push the container image"),
+		Args:  cobra.NoArgs,
+	}
+	cmd.AddCommand(getImageBuildCommand(shellExecutor), getImagePushCommand(shellExecutor))
+	return cmd
+}
+
+func getImageBuildCommand(shellExecutor BashExecutor) *cobra.Command {
+	return &cobra.Command{
+		Use:   "build",
+		Short: i18n.Translate("Build the container image"),
+		Long:  i18n.Translate("Build the image of the container section with docker, tagged with each rendered tag."),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			cfg := config.FromContext(ctx)
+			refs, err := cfg.BuildImage(ctx, shellExecutor)
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("image build failed: %w"), err)
+			}
+			outputs.PrintColoredMessageTo(cmd.OutOrStdout(), "green", "Built %s", strings.Join(refs, ", "))
+			return nil
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+}
+
+func getImagePushCommand(shellExecutor BashExecutor) *cobra.Command {
+	return &cobra.Command{
+		Use:   "push",
+		Short: i18n.Translate("Push the container image"),
+		Long:  i18n.Translate("Push every tag of the image built by devops image build to the container registry."),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			cfg := config.FromContext(ctx)
+			refs, err := cfg.PushImage(ctx, shellExecutor)
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("image push failed: %w"), err)
+			}
+			outputs.PrintColoredMessageTo(cmd.OutOrStdout(), "green", "Pushed %s", strings.Join(refs, ", "))
+			return nil
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+}
+
+func GetLoginCommand(shellExecutor BashExecutor) *cobra.Command {
+	return &cobra.Command{
+		Use:   "login [registry]",
+		Short: i18n.Translate("Log in to a container registry"),
+		Long:  i18n.Translate("Log docker in to a registry, the container registry by default, with credentials from secrets or the environment: <HOST>_USERNAME and <HOST>_PASSWORD, or REGISTRY_USERNAME and REGISTRY_PASSWORD."),
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			cfg := config.FromContext(ctx)
+			registry := ""
+			if len(args) == 1 {
+				registry = args[0]
+			}
+			host, err := cfg.Login(ctx, registry, shellExecutor)
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("login failed: %w"), err)
+			}
+			outputs.PrintColoredMessageTo(cmd.OutOrStdout(), "green", "Logged in to %s", host)
+			return nil
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+}
+
+func GetReleaseCommand(shellExecutor BashExecutor) *cobra.Command {
+	var publish bool
+	cmd := &cobra.Command{
+		Use:         "release <major|minor|patch|version>",
+		Short:       i18n.Translate("Cut a release of the project"),
+		Long:        i18n.Translate("Bump the definition version, build and package the artifacts, then commit and tag the release. With --github, push the tag and publish a GitHub release with the package attached."),
+		Annotations: map[string]string{lockAnnotation: "true"},
+		Args:        cobra.ExactArgs(1),
+		ValidArgs:   []string{config.BumpMajor, config.BumpMinor, config.BumpPatch},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			cfg := config.FromContext(ctx)
+			definitionPath := config.DefinitionFile
+			if flag := cmd.Flag("file"); flag != nil {
+				definitionPath = flag.Value.String()
+			}
+			result, err := cfg.Release(ctx, config.ReleaseOptions{
+				DefinitionPath: definitionPath,
+				Bump:           args[0],
+				GitHub:         publish,
+			}, shellExecutor)
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("release failed: %w"), err)
+			}
+			w := cmd.OutOrStdout()
+			outputs.PrintColoredMessageTo(w, "green", "Released %s", result.Tag)
+			if result.Package != "" {
+				fmt.Fprintf(w, "Package: %s\n", result.Package)
+			}
+			if result.Checksums != "" {
+				fmt.Fprintf(w, "Checksums: %s\n", result.Checksums)
+			}
+			for _, signature := range result.Signatures {
+				fmt.Fprintf(w, "Signature: %s\n", signature)
+			}
+			if result.ReleaseURL != "" {
+				fmt.Fprintf(w, "GitHub release: %s\n", result.ReleaseURL)
+			}
+			return nil
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().BoolVar(&publish, "github", false, "Push the tag and publish a GitHub release (needs GITHUB_TOKEN)")
+	return cmd
+}
+
+func GetDeployCommand(shellExecutor BashExecutor) *cobra.Command {
+	var helm bool
+	var packageOnly bool
+	var profile string
+	cmd := &cobra.Command{
+		Use:         "deploy",
+		Short:       i18n.Translate("Deploy the project"),
+		Long:        i18n.Translate("With --helm, package the chart of the deploy section and install or upgrade its release in the phases of its strategy, with the values of the profile given by --profile. With --package-only, only package the chart as a build artifact."),
+		Annotations: map[string]string{lockAnnotation: "true"},
+		Args:        cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			cfg := config.FromContext(ctx)
+			if !helm {
+				return fmt.Errorf(i18n.Translate("deploy failed: %w"), errors.New("no deploy method given, use --helm"))
+			}
+			w := cmd.OutOrStdout()
+			if packageOnly {
+				pkg, err := cfg.PackageChart(ctx, shellExecutor)
+				if err != nil {
+					return fmt.Errorf(i18n.Translate("deploy failed: %w"), err)
+				}
+				outputs.PrintColoredMessageTo(w, "green", "Packaged %s", pkg)
+				return nil
+			}
+			deployment, err := cfg.DeployHelm(ctx, profile, shellExecutor, time.Now())
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("deploy failed: %w"), err)
+			}
+			target := deployment.Release
+			if deployment.Namespace != "" {
+				target = deployment.Namespace + "/" + target
+			}
+			outputs.PrintColoredMessageTo(w, "green", "Deployed %s to %s", deployment.Package, target)
+			return nil
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().BoolVar(&helm, "helm", false, "Deploy the Helm chart of the deploy section")
+	cmd.Flags().BoolVar(&packageOnly, "package-only", false, "Package the chart without deploying it")
+	cmd.Flags().StringVar(&profile, "profile", "", "Deploy profile whose values override the helm section")
+	return cmd
+}
+
+func GetRollbackCommand(shellExecutor BashExecutor) *cobra.Command {
+	var environment string
+	cmd := &cobra.Command{
+		Use:         "rollback",
+		Short:       i18n.Translate("Roll back the last deployment"),
+		Long:        i18n.Translate("Redeploy the previous successful deployment of an environment, from the chart and values kept when it was deployed. The environment is the deploy profile, or default for deployments without one."),
+		Annotations: map[string]string{lockAnnotation: "true"},
+		Args:        cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			cfg := config.FromContext(ctx)
+			current, rollback, err := cfg.Rollback(ctx, environment, shellExecutor, time.Now())
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("rollback failed: %w"), err)
+			}
+			outputs.PrintColoredMessageTo(cmd.OutOrStdout(), "green", "Rolled back %s from %s to %s (%s)",
+				rollback.Environment, current.ID, rollback.Package, rollback.ID)
+			return nil
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().StringVar(&environment, "env", config.DefaultDeployEnvironment, "Environment, the deploy profile, to roll back")
+	return cmd
+}
+
+func GetPipelineCommand(shellExecutor BashExecutor) *cobra.Command {
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:         "pipeline",
+		Short:       i18n.Translate("Run the operation pipeline"),
+		Long:        i18n.Translate("Run the operations in the pipeline in dependency order, stopping at the first failure."),
+		Annotations: map[string]string{lockAnnotation: "true"},
+		Args:        cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if err := config.RequireFeature(ctx, config.FeaturePipelineDAG); err != nil {
+				return fmt.Errorf(i18n.Translate("pipeline failed: %w"), err)
+			}
+			cfg := config.FromContext(ctx)
+			if dryRun {
+				order, err := cfg.PipelineOrder()
+				if err != nil {
+					return fmt.Errorf(i18n.Translate("pipeline failed: %w"), err)
+				}
+				for idx, name := range order {
+					fmt.Fprintf(cmd.OutOrStdout(), "%d. %s\n", idx+1, name)
+				}
+				return nil
+			}
+			if err := cfg.RunPipeline(ctx, shellExecutor); err != nil {
+				return fmt.Errorf(i18n.Translate("pipeline failed: %w"), err)
+			}
+			return nil
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the execution order without running anything")
+	return cmd
+}
+
+func GetCICommand(shellExecutor BashExecutor) *cobra.Command {
+	var envPairs []string
+	var only []string
+	var skip []string
+	cmd := &cobra.Command{
+		Use:         "ci",
+		Short:       i18n.Translate("Run the canonical CI pipeline"),
+		Long:        i18n.T("Run %s in order, skipping operations that are not defined. A failed fail_fast operation stops the run.", strings.Join(config.CIStages, " -> ")),
+		Annotations: map[string]string{lockAnnotation: "true"},
+		Args:        cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := withEnvFlag(cmd.Context(), envPairs)
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("ci failed: %w"), err)
+			}
+			stages, err := config.SelectCIStages(only, skip)
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("ci failed: %w"), err)
+			}
+			cfg := config.FromContext(ctx)
+			if err := cfg.RunCI(ctx, stages, shellExecutor); err != nil {
+				return fmt.Errorf(i18n.Translate("ci failed: %w"), err)
+			}
+			return nil
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	addEnvFlag(cmd, &envPairs)
+	cmd.Flags().StringSliceVar(&only, "only", nil, "Run only these stages, e.g. --only test,build")
+	cmd.Flags().StringSliceVar(&skip, "skip", nil, "Skip these stages, e.g. --skip install")
+	return cmd
+}
+
+func GetWatchCommand(shellExecutor BashExecutor) *cobra.Command {
+	var envPairs []string
+	var ignore []string
+	var interval time.Duration
+	var debounce time.Duration
+	cmd := &cobra.Command{
+		Use:   "watch <operation>",
+		Short: i18n.Translate("Re-run an operation when files change"),
+		Long:  i18n.T("Run the operation, then run it again whenever project files change, cancelling a run still in progress. Paths in %s, the operation's artifacts and outputs, and --ignore patterns are not watched.", watch.IgnoreFile),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := withEnvFlag(cmd.Context(), envPairs)
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("watch failed: %w"), err)
+			}
+			cfg := config.FromContext(ctx)
+			rootDir := fileutils.RootDirFromContext(ctx)
+			watcher := &watch.Watcher{
+				FS:       rootDir,
+				Ignore:   slices.Concat(cfg.WatchIgnore(args[0]), watch.IgnorePatterns(rootDir), ignore),
+				Interval: interval,
+				Debounce: debounce,
+			}
+			if err := cfg.Watch(ctx, args[0], watcher.Changes(ctx), shellExecutor); err != nil {
+				return fmt.Errorf(i18n.Translate("watch failed: %w"), err)
 			}
 			return nil
 		},
 		SilenceUsage:  true,
 		SilenceErrors: true,
 	}
+	addEnvFlag(cmd, &envPairs)
+	cmd.Flags().StringArrayVar(&ignore, "ignore", nil, "Do not watch paths matching this glob pattern (repeatable)")
+	cmd.Flags().DurationVar(&interval, "interval", 500*time.Millisecond, "How often to scan the project for changes")
+	cmd.Flags().DurationVar(&debounce, "debounce", 300*time.Millisecond, "How long files must stay unchanged before re-running")
 	return cmd
 }
 
+func GetWorkspaceCommand(shellExecutor BashExecutor) *cobra.Command {
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "workspace <operation>",
+		Short: i18n.Translate("Run an operation across every workspace project"),
+		Long:  i18n.T("Run the operation in each project listed in %s, in dependency order, stopping at the first failure.", config.WorkspaceFile),
+		Args:  cobra.ExactArgs(1),
+		Annotations: map[string]string{
+			skipDefinitionAnnotation: "true",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			path, err := config.FindWorkspace()
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("workspace failed: %w"), err)
+			}
+			ws, err := config.LoadWorkspace(path)
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("workspace failed: %w"), err)
+			}
+			if dryRun {
+				order, err := ws.Order()
+				if err != nil {
+					return fmt.Errorf(i18n.Translate("workspace failed: %w"), err)
+				}
+				for idx, name := range order {
+					fmt.Fprintf(cmd.OutOrStdout(), "%d. %s\n", idx+1, name)
+				}
+				return nil
+			}
+			if err := ws.Run(ctx, args[0], shellExecutor); err != nil {
+				return fmt.Errorf(i18n.Translate("workspace failed: %w"), err)
+			}
+			return nil
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the project order without running anything")
+	return cmd
+}
+
+func GetExecCommand(shellExecutor BashExecutor) *cobra.Command {
+	var operation string
+	cmd := &cobra.Command{
+		Use:   "exec -- <command>",
+		Short: i18n.Translate("Run an ad-hoc command in the project environment"),
+		Long:  i18n.Translate("Run an arbitrary command through the executor with the same environment an operation's steps get."),
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			cfg := config.FromContext(ctx)
+			if err := cfg.Exec(ctx, args, operation, shellExecutor); err != nil {
+				return fmt.Errorf(i18n.Translate("exec failed: %w"), err)
+			}
+			return nil
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().StringVar(&operation, "operation", "", "Use the environment of this operation")
+	return cmd
+}
+
+// doctorFormatSARIF renders doctor findings for code scanning upload.
+const doctorFormatSARIF = "sarif"
+
 func GetDoctorCommand(shellExecutor BashExecutor) *cobra.Command {
+	var suggestPreset bool
+	var interactive bool
+	var format string
 	cmd := &cobra.Command{
 		Use:   "doctor",
-		Short: "Validate your configuration",
-		Long:  "Run checks on your configuration file to ensure it is ready for use.",
+		Short: i18n.Translate("Validate your configuration"),
+		Long:  i18n.Translate("Run checks on your configuration file to ensure it is ready for use."),
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			cfg := config.FromContext(ctx)
 			w := cmd.OutOrStdout()
+			files, _ := fileutils.LookupRootDir(ctx)
+			reportFormat := format
+			if reportFormat == "" {
+				reportFormat = config.OutputFormatFromContext(ctx)
+			}
+			switch reportFormat {
+			case config.OutputText:
+			case config.OutputJSON:
+				return writeReportJSON(w, cfg.ReportFiles(files))
+			case doctorFormatSARIF:
+				definitionPath := config.DefinitionFile
+				if flag := cmd.Flag("file"); flag != nil {
+					definitionPath = flag.Value.String()
+				}
+				report := cfg.ReportFiles(files)
+				if err := report.WriteSARIF(w, definitionPath, cmd.Root().Version); err != nil {
+					return err
+				}
+				return reportErrors(report)
+			default:
+				return fmt.Errorf("unsupported doctor format '%s' (expected text, json or sarif)", reportFormat)
+			}
 			fmt.Fprintln(w, "===== DEVOPS DOCTOR =====")
-			if err := cfg.ValidateTo(ctx, w); err != nil {
-				return fmt.Errorf("validation failed: %w", err)
+			validationErr := cfg.ValidateTo(ctx, w)
+			if suggestPreset {
+				if err := printPresetSuggestion(w, fileutils.RootDirFromContext(ctx), cfg.Codebase); err != nil {
+					return err
+				}
+			}
+			if interactive {
+				definitionPath := config.DefinitionFile
+				if flag := cmd.Flag("file"); flag != nil {
+					definitionPath = flag.Value.String()
+				}
+				fixed, err := applyFixesInteractively(cmd.InOrStdin(), w, fileutils.RootDirFromContext(ctx), definitionPath, &cfg)
+				if err != nil {
+					return err
+				}
+				if fixed != nil && len(fixed.ReportFiles(files).Errors()) == 0 {
+					validationErr = nil
+				}
+			}
+			if validationErr != nil {
+				return fmt.Errorf(i18n.Translate("validation failed: %w"), validationErr)
 			}
 			return nil
 		},
 		SilenceUsage:  true,
 		SilenceErrors: true,
 	}
+	cmd.Flags().BoolVar(&suggestPreset, "suggest-preset", false, "Suggest a preset matching the repository layout")
+	cmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Walk through fixable findings and apply accepted fixes")
+	cmd.Flags().StringVar(&format, "format", "", "Report format: text, json or sarif (defaults to --output)")
 	return cmd
 }
 
+// writeReportJSON writes the doctor findings as JSON, failing like the text
+// output does when any finding is an error.
+func writeReportJSON(w io.Writer, report *config.ValidationReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	return reportErrors(report)
+}
+
+// reportErrors fails when the report has error findings.
+func reportErrors(report *config.ValidationReport) error {
+	if errs := report.Errors(); len(errs) > 0 {
+		return fmt.Errorf("validation failed: found %d required fixes", len(errs))
+	}
+	return nil
+}
+
+// printPresetSuggestion reports the preset matching the repository layout
+// and the definition lines it would add.
+func printPresetSuggestion(w io.Writer, rootDir fs.FS, codebase config.Codebase) error {
+	preset, ok := config.DetectPreset(rootDir)
+	if !ok {
+		outputs.PrintColoredMessageTo(w, "yellow", "No known preset matches this repository layout")
+		return nil
+	}
+	additions, err := preset.AdditionsYAML(codebase)
+	if err != nil {
+		return fmt.Errorf("failed to render preset %s: %w", preset.Name, err)
+	}
+	if additions == nil {
+		outputs.PrintColoredMessageTo(w, "green", "Definition already covers the %s preset", preset.Name)
+		return nil
+	}
+	if codebase.Language != "" && codebase.Language != preset.Codebase.Language {
+		outputs.PrintColoredMessageTo(w, "yellow", "Layout matches the %s preset but language is set to %s", preset.Name, codebase.Language)
+	}
+	outputs.PrintColoredMessageTo(w, "cyan", "Layout matches the %s preset, which would add:", preset.Name)
+	for _, line := range strings.Split(strings.TrimRight(string(additions), "\n"), "\n") {
+		outputs.PrintColoredMessageTo(w, "green", "+ %s", line)
+	}
+	return nil
+}
+
+// applyFixesInteractively offers each fix from the preset matching the
+// repository layout, writing accepted fixes to the definition file as it
+// goes, and shows the resulting diff. It returns the reloaded definition,
+// or nil if nothing was changed.
+func applyFixesInteractively(in io.Reader, w io.Writer, rootDir fs.FS, path string, cfg *config.ProjectDefinition) (*config.ProjectDefinition, error) {
+	if len(cfg.Codebases) > 0 {
+		return nil, fmt.Errorf("interactive fixes are only supported for a single codebase")
+	}
+	preset, ok := config.DetectPreset(rootDir)
+	if !ok {
+		outputs.PrintColoredMessageTo(w, "yellow", "%s", i18n.Translate("No known preset matches this repository layout"))
+		return nil, nil
+	}
+	fixes := preset.Fixes(cfg.Report(), cfg.Codebase)
+	if len(fixes) == 0 {
+		outputs.PrintColoredMessageTo(w, "green", "%s", i18n.Translate("No fixable findings"))
+		return nil, nil
+	}
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read definition: %w", err)
+	}
+	data := original
+	reader := bufio.NewReader(in)
+	for _, fix := range fixes {
+		if !confirmDefaultYes(reader, w, fix.Prompt) {
+			continue
+		}
+		updated, err := fix.Apply(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply fix for %s: %w", fix.RuleID, err)
+		}
+		if err := os.WriteFile(path, updated, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write definition: %w", err)
+		}
+		data = updated
+	}
+	if !outputs.UnifiedDiff(w, path, string(original), string(data)) {
+		outputs.PrintColoredMessageTo(w, "yellow", "%s", i18n.Translate("No changes made"))
+		return nil, nil
+	}
+	return config.LoadFile(path)
+}
+
 func GetManifestCommand() *cobra.Command {
 	var outputFile string
 	cmd := &cobra.Command{
 		Use:   "manifest",
-		Short: "Generate a manifest file",
-		Long:  "Generate a manifest file for the project.",
+		Short: i18n.Translate("Generate a manifest file"),
+		Long:  i18n.Translate("Generate a manifest file for the project."),
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			cfg := config.FromContext(ctx)
 			logger := logging.FromContext(ctx)
 
-			manifest, err := cfg.GenerateManifest()
+			definitionPath := config.DefinitionFile
+			if flag := cmd.Flag("file"); flag != nil {
+				definitionPath = flag.Value.String()
+			}
+			definition, err := os.ReadFile(definitionPath)
+			if err != nil {
+				return fmt.Errorf("failed to read definition for fingerprint: %w", err)
+			}
+			fingerprint := cfg.CollectFingerprint(ctx, cmd.Root().Version, definition)
+
+			manifest, err := cfg.BuildManifest(&fingerprint)
+			if err != nil {
+				return fmt.Errorf("failed to generate manifest: %w", err)
+			}
+			data, err := manifest.Render()
 			if err != nil {
 				return fmt.Errorf("failed to generate manifest: %w", err)
 			}
@@ -104,19 +859,451 @@ func GetManifestCommand() *cobra.Command {
 			if err := os.MkdirAll(dir, 0755); err != nil {
 				return fmt.Errorf("failed to create directory %s: %w", dir, err)
 			}
-			if err := os.WriteFile(outputFile, manifest, 0644); err != nil {
+			if err := os.WriteFile(outputFile, data, 0644); err != nil {
 				return fmt.Errorf("failed to write manifest to file %s: %w", outputFile, err)
 			}
 
 			logger.WithFields(logrus.Fields{
 				"path": outputFile,
 			}).Info("Manifest generated successfully")
+
+			signed := []string{outputFile}
+			if digests := manifest.Artifacts; len(digests) > 0 {
+				checksumsFile := filepath.Join(dir, config.ChecksumsFile)
+				if err := os.WriteFile(checksumsFile, config.FormatChecksums(digests), 0644); err != nil {
+					return fmt.Errorf("failed to write checksums to file %s: %w", checksumsFile, err)
+				}
+				logger.WithFields(logrus.Fields{
+					"path":      checksumsFile,
+					"artifacts": len(digests),
+				}).Info("Checksums written")
+				signed = append(signed, checksumsFile)
+			}
+			if cfg.Signing.Enabled() {
+				signatures, err := cfg.Signing.SignFiles(ctx, signed...)
+				if err != nil {
+					return err
+				}
+				logger.WithFields(logrus.Fields{
+					"signatures": signatures,
+				}).Info("Signed with " + cfg.Signing.Method)
+			}
+			return nil
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	addOutputFileFlag(cmd, &outputFile, ".devops/manifest.json", "Output file path")
+	return cmd
+}
+
+func GetSBOMCommand() *cobra.Command {
+	var format string
+	var outputFile string
+	cmd := &cobra.Command{
+		Use:   "sbom",
+		Short: i18n.Translate("Generate a software bill of materials"),
+		Long:  i18n.Translate("Generate a CycloneDX or SPDX SBOM for the codebase with syft, or from go.mod when syft is not installed. The SBOM is listed in the artifacts of the manifest."),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			cfg := config.FromContext(ctx)
+			data, generator, err := cfg.GenerateSBOM(ctx, format, cmd.Root().Version, time.Now())
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("sbom failed: %w"), err)
+			}
+			if outputFile == "" {
+				outputFile = config.SBOMFile(format)
+			}
+			if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(outputFile), err)
+			}
+			if err := os.WriteFile(outputFile, data, 0644); err != nil {
+				return fmt.Errorf("failed to write SBOM to file %s: %w", outputFile, err)
+			}
+			logging.FromContext(ctx).WithFields(logrus.Fields{
+				"path":      outputFile,
+				"generator": generator,
+			}).Info("SBOM generated successfully")
+			return nil
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().StringVar(&format, "format", config.SBOMCycloneDX, "SBOM format, cyclonedx or spdx")
+	addOutputFileFlag(cmd, &outputFile, "", "Output file path (default .devops/sbom.cdx.json or .devops/sbom.spdx.json)")
+	return cmd
+}
+
+func GetVerifyCommand() *cobra.Command {
+	var dir string
+	var signature bool
+	var signing config.Signing
+	cmd := &cobra.Command{
+		Use:   "verify [checksums-file]",
+		Short: i18n.Translate("Verify artifacts against their checksums"),
+		Long:  i18n.T("Re-hash the artifacts listed in a %s file, or in the artifacts of a manifest, and report any that changed or are missing. With --signature, first check the cosign or GPG signature of the file.", config.ChecksumsFile),
+		Args:  cobra.MaximumNArgs(1),
+		Annotations: map[string]string{
+			skipDefinitionAnnotation: "true",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := filepath.Join(config.WorkDir, config.ChecksumsFile)
+			if len(args) > 0 {
+				path = args[0]
+			}
+			if signature {
+				detected, err := config.DetectSigning(path)
+				if err != nil {
+					return fmt.Errorf(i18n.Translate("verify failed: %w"), err)
+				}
+				signing.Method = detected.Method
+				if err := signing.Verify(cmd.Context(), path); err != nil {
+					return fmt.Errorf(i18n.Translate("verify failed: %w"), err)
+				}
+				outputs.PrintColoredMessageTo(cmd.OutOrStdout(), "green", "%s: signature OK (%s)", path, signing.Method)
+			}
+			digests, err := config.LoadChecksums(path)
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("verify failed: %w"), err)
+			}
+			if len(digests) == 0 {
+				return fmt.Errorf(i18n.Translate("verify failed: %w"), fmt.Errorf("%s lists no artifacts", path))
+			}
+			results, err := config.VerifyChecksums(dir, digests)
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("verify failed: %w"), err)
+			}
+			w := cmd.OutOrStdout()
+			failed := 0
+			for _, result := range results {
+				color := "green"
+				if result.Status != config.ChecksumOK {
+					color = "red"
+					failed++
+				}
+				outputs.PrintColoredMessageTo(w, color, "%s: %s", result.Path, result.Status)
+			}
+			if failed > 0 {
+				return fmt.Errorf(i18n.Translate("%d of %d artifact(s) failed verification"), failed, len(results))
+			}
+			return nil
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().StringVar(&dir, "dir", ".", "Directory the artifact paths are relative to")
+	cmd.Flags().BoolVar(&signature, "signature", false, "Verify the signature of the checksums file first")
+	cmd.Flags().StringVar(&signing.Key, "key", "", "cosign public key to verify with, instead of a keyless certificate")
+	cmd.Flags().StringVar(&signing.Identity, "certificate-identity", "", "Identity a keyless cosign signature must carry")
+	cmd.Flags().StringVar(&signing.Issuer, "certificate-oidc-issuer", "", "OIDC issuer a keyless cosign signature must come from")
+	return cmd
+}
+
+func GetFeaturesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "features",
+		Short: i18n.Translate("List experimental features"),
+		Long:  i18n.T("List the available experiments and whether each is enabled, via %s or the user config.", config.ExperimentalEnv),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			features := config.FeaturesFromContext(cmd.Context())
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "EXPERIMENT\tSTATUS\tDESCRIPTION")
+			for _, experiment := range config.Experiments {
+				status := "disabled"
+				if features.Enabled(experiment.Name) {
+					status = "enabled"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", experiment.Name, status, experiment.Description)
+			}
+			return tw.Flush()
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	return cmd
+}
+
+func GetPruneCommand() *cobra.Command {
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: i18n.Translate("Remove old generated files"),
+		Long:  i18n.Translate("Remove logs, reports, history, caches and leftover workspaces older than the retention set in the user config."),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			userConfig, err := config.LoadUserConfig()
+			if err != nil {
+				return err
+			}
+			result, err := config.Prune(userConfig.Retention, time.Now(), dryRun)
+			w := cmd.OutOrStdout()
+			verb := "Removed"
+			if dryRun {
+				verb = "Would remove"
+			}
+			for _, removed := range result.Removed {
+				fmt.Fprintf(w, "%s %s (%s)\n", verb, removed.Path, config.FormatSize(removed.Size))
+			}
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("prune failed: %w"), err)
+			}
+			if dryRun {
+				fmt.Fprintf(w, "Would reclaim %s\n", config.FormatSize(result.Reclaimed))
+			} else {
+				fmt.Fprintf(w, "Reclaimed %s\n", config.FormatSize(result.Reclaimed))
+			}
+			return nil
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List what would be removed without deleting anything")
+	return cmd
+}
+
+func GetLogsCommand() *cobra.Command {
+	var step, grep string
+	cmd := &cobra.Command{
+		Use:   "logs [run-id]",
+		Short: i18n.Translate("Show the output of past runs"),
+		Long:  i18n.Translate("List the runs with step logs kept by --log-dir or spill_output, or print the logs of a run, the latest by default, without running anything again. A run can be given by a prefix of its ID."),
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := cmp.Or(config.LogDirFromContext(cmd.Context()), config.LogsDir)
+			w := cmd.OutOrStdout()
+			if len(args) == 0 && step == "" && grep == "" {
+				runs, err := config.ListRunLogs(dir)
+				if err != nil {
+					return fmt.Errorf(i18n.Translate("logs failed: %w"), err)
+				}
+				if len(runs) == 0 {
+					fmt.Fprintf(w, "No run logs in %s\n", dir)
+					return nil
+				}
+				tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(tw, "RUN\tSTEPS\tLAST WRITTEN")
+				for _, run := range runs {
+					fmt.Fprintf(tw, "%s\t%d\t%s\n", run.RunID, len(run.Steps), run.ModTime.Local().Format(time.DateTime))
+				}
+				return tw.Flush()
+			}
+
+			var pattern *regexp.Regexp
+			if grep != "" {
+				var err error
+				if pattern, err = regexp.Compile(grep); err != nil {
+					return fmt.Errorf(i18n.Translate("logs failed: %w"), fmt.Errorf("invalid --grep pattern: %w", err))
+				}
+			}
+			runID := config.LatestRunLog
+			if len(args) > 0 {
+				runID = args[0]
+			}
+			run, err := config.FindRunLog(dir, runID)
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("logs failed: %w"), err)
+			}
+			files, err := run.StepLogs(step)
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("logs failed: %w"), err)
+			}
+			if err := run.WriteStepLogs(w, files, pattern); err != nil {
+				return fmt.Errorf(i18n.Translate("logs failed: %w"), err)
+			}
+			return nil
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().StringVar(&step, "step", "", "Only show the step with this number or name")
+	cmd.Flags().StringVar(&grep, "grep", "", "Only show lines matching this regular expression")
+	return cmd
+}
+
+func GetHistoryCommand() *cobra.Command {
+	var operation string
+	var limit int
+	var trends bool
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: i18n.Translate("Show past runs and their trends"),
+		Long:  i18n.Translate("List the runs recorded in the run history, newest first, with their status, duration, commit and definition hash. With --trends, sum up the pass rate and durations of each operation instead."),
+		Args:  cobra.NoArgs,
+		Annotations: map[string]string{
+			skipDefinitionAnnotation: "true",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			entries, err := config.LoadHistory()
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("history failed: %w"), err)
+			}
+			if operation != "" {
+				entries = config.FilterHistory(entries, operation)
+			}
+			w := cmd.OutOrStdout()
+			if trends {
+				return printHistoryTrends(w, config.HistoryTrends(entries))
+			}
+			slices.Reverse(entries)
+			if limit > 0 && len(entries) > limit {
+				entries = entries[:limit]
+			}
+			if config.OutputFormatFromContext(ctx) == config.OutputJSON {
+				encoder := json.NewEncoder(w)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(entries)
+			}
+			if len(entries) == 0 {
+				fmt.Fprintln(w, "No runs recorded yet")
+				return nil
+			}
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "STARTED\tCOMMAND\tSTATUS\tDURATION\tCOMMIT\tCONFIG")
+			for _, entry := range entries {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", outputs.Timestamp(ctx, entry.StartedAt), entry.Command, entry.Status,
+					entry.Duration().Round(time.Millisecond), shortHash(entry.GitSHA, 7), shortHash(entry.ConfigHash, 12))
+			}
+			return tw.Flush()
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().StringVar(&operation, "operation", "", "Only show runs of this operation, given as name or codebase/name")
+	cmd.Flags().IntVar(&limit, "limit", 20, "Show at most this many runs, 0 for all")
+	cmd.Flags().BoolVar(&trends, "trends", false, "Show the pass rate and durations of each operation")
+	return cmd
+}
+
+func GetStatusCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: i18n.Translate("Show the last result of each operation"),
+		Long:  i18n.Translate("Show whether each operation passed or failed the last time it ran, when, for how long and on which commit, from the run history."),
+		Args:  cobra.NoArgs,
+		Annotations: map[string]string{
+			skipDefinitionAnnotation: "true",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			entries, err := config.LoadHistory()
+			if err != nil {
+				return fmt.Errorf(i18n.Translate("status failed: %w"), err)
+			}
+			trends := config.HistoryTrends(entries)
+			w := cmd.OutOrStdout()
+			if config.OutputFormatFromContext(ctx) == config.OutputJSON {
+				return writeStatusJSON(w, trends)
+			}
+			if len(trends) == 0 {
+				fmt.Fprintln(w, "No runs recorded yet")
+				return nil
+			}
+			failed := 0
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "OPERATION\tSTATUS\tWHEN\tDURATION\tCOMMIT")
+			for _, trend := range trends {
+				if trend.Last.Status != config.LastRunSuccess {
+					failed++
+				}
+				last := time.Duration(trend.Last.DurationMs) * time.Millisecond
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", trend.Operation, trend.Last.Status, outputs.Timestamp(ctx, trend.LastRun.StartedAt),
+					last.Round(time.Millisecond), shortHash(trend.LastRun.GitSHA, 7))
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			if failed > 0 {
+				outputs.PrintColoredMessageTo(w, "red", "%d of %d operation(s) failed their last run", failed, len(trends))
+			} else {
+				outputs.PrintColoredMessageTo(w, "green", "All %d operation(s) passed their last run", len(trends))
+			}
+			return nil
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	return cmd
+}
+
+type jsonOperationStatus struct {
+	Operation  string `json:"operation"`
+	Status     string `json:"status"`
+	Started    string `json:"started"`
+	DurationMs int64  `json:"duration_ms"`
+	GitSHA     string `json:"git_sha,omitempty"`
+	RunID      string `json:"run_id"`
+}
+
+func writeStatusJSON(w io.Writer, trends []config.OperationTrend) error {
+	statuses := []jsonOperationStatus{}
+	for _, trend := range trends {
+		statuses = append(statuses, jsonOperationStatus{
+			Operation:  trend.Operation,
+			Status:     trend.Last.Status,
+			Started:    trend.LastRun.StartedAt.Format(time.RFC3339),
+			DurationMs: trend.Last.DurationMs,
+			GitSHA:     trend.LastRun.GitSHA,
+			RunID:      trend.Last.RunID,
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(statuses)
+}
+
+func printHistoryTrends(w io.Writer, trends []config.OperationTrend) error {
+	if len(trends) == 0 {
+		fmt.Fprintln(w, "No runs recorded yet")
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "OPERATION\tRUNS\tPASS RATE\tMEAN\tLAST\tLAST STATUS")
+	for _, trend := range trends {
+		last := time.Duration(trend.Last.DurationMs) * time.Millisecond
+		fmt.Fprintf(tw, "%s\t%d\t%.0f%%\t%s\t%s\t%s\n", trend.Operation, trend.Runs, trend.PassRate()*100,
+			trend.MeanDuration.Round(time.Millisecond), last.Round(time.Millisecond), trend.Last.Status)
+	}
+	return tw.Flush()
+}
+
+// shortHash abbreviates a hash for display, or shows "-" for none.
+func shortHash(hash string, length int) string {
+	return cmp.Or(hash[:min(len(hash), length)], "-")
+}
+
+func GetCompletionConfigCommand(schema []byte) *cobra.Command {
+	var schemaPath string
+	cmd := &cobra.Command{
+		Use:   "completion-config",
+		Short: i18n.Translate("Set up editor validation and completion"),
+		Long:  i18n.Translate("Write the definition JSON schema, a yaml-language-server modeline and the VS Code schema association into the repository."),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			definitionPath := config.DefinitionFile
+			if flag := cmd.Flag("file"); flag != nil {
+				definitionPath = flag.Value.String()
+			}
+			editor := config.EditorConfig{
+				Schema:         schema,
+				SchemaPath:     schemaPath,
+				DefinitionPath: definitionPath,
+			}
+			changed, err := editor.Write()
+			for _, path := range changed {
+				fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s\n", path)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to write editor config: %w", err)
+			}
 			return nil
 		},
 		SilenceUsage:  true,
 		SilenceErrors: true,
 	}
-	cmd.Flags().StringVarP(&outputFile, "output", "o", ".devops/manifest.json", "Output file path")
+	cmd.Flags().StringVar(&schemaPath, "schema-path", config.DefaultSchemaPath, "Where to write the JSON schema")
 	return cmd
 }
 
@@ -124,8 +1311,8 @@ func GetDocsCommand() *cobra.Command {
 	var outputFile string
 	cmd := &cobra.Command{
 		Use:    "docs",
-		Short:  "Generate documentation for the CLI",
-		Long:   "Generate markdown documentation for all available commands and their usage.",
+		Short:  i18n.Translate("Generate documentation for the CLI"),
+		Long:   i18n.Translate("Generate markdown documentation for all available commands and their usage."),
 		Hidden: true,
 		Args:   cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -145,6 +1332,6 @@ func GetDocsCommand() *cobra.Command {
 		SilenceErrors: true,
 	}
 
-	cmd.Flags().StringVarP(&outputFile, "output", "o", "docs/cli/devops.md", "Output file path")
+	addOutputFileFlag(cmd, &outputFile, "docs/cli/devops.md", "Output file path")
 	return cmd
 }
@@ -3,16 +3,25 @@ package core
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/jgfranco17/dev-tooling-go/logging"
 	"github.com/jgfranco17/devops/cli/config"
 	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/jgfranco17/devops/internal/fileutils"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type CliCommandFunction func() *cobra.Command
@@ -34,11 +43,14 @@ func (m *MockShellExecutor) Exec(ctx context.Context, command string) (executor.
 	return args.Get(0).(executor.Result), args.Error(1)
 }
 
-func (m *MockShellExecutor) AddEnv(env []string) {
-	m.Called(env)
+// Helper function to simulate CLI execution
+// withEnv matches a context whose environment satisfies match.
+func withEnv(match func(env []string) bool) any {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		return match(executor.EnvFromContext(ctx))
+	})
 }
 
-// Helper function to simulate CLI execution
 func ExecuteCommand(t *testing.T, cmd *cobra.Command, args ...string) CliRunResult {
 	t.Helper()
 
@@ -69,13 +81,12 @@ func TestGetTestCommand(t *testing.T) {
 					ID: "test-project",
 					Codebase: config.Codebase{
 						Test: config.Operation{
-							Steps: []string{"go test ./...", "go test -race ./..."},
+							Steps: config.StepsFromCommands("go test ./...", "go test -race ./..."),
 						},
 					},
 				}
 			},
 			mockSetup: func(m *MockShellExecutor) {
-				m.On("AddEnv", mock.AnythingOfType("[]string")).Return()
 				m.On("Exec", mock.Anything, "go test ./...").Return(executor.Result{ExitCode: 0, Stdout: "PASS"}, nil)
 				m.On("Exec", mock.Anything, "go test -race ./...").Return(executor.Result{ExitCode: 0, Stdout: "PASS"}, nil)
 			},
@@ -87,7 +98,7 @@ func TestGetTestCommand(t *testing.T) {
 					ID: "test-project",
 					Codebase: config.Codebase{
 						Test: config.Operation{
-							Steps: []string{},
+							Steps: []config.Step{},
 						},
 					},
 				}
@@ -104,13 +115,12 @@ func TestGetTestCommand(t *testing.T) {
 					ID: "test-project",
 					Codebase: config.Codebase{
 						Test: config.Operation{
-							Steps: []string{"go test ./..."},
+							Steps: config.StepsFromCommands("go test ./..."),
 						},
 					},
 				}
 			},
 			mockSetup: func(m *MockShellExecutor) {
-				m.On("AddEnv", mock.AnythingOfType("[]string")).Return()
 				m.On("Exec", mock.Anything, "go test ./...").Return(executor.Result{ExitCode: 1, Stderr: "test failed"}, nil)
 			},
 			expectedError: "tests failed",
@@ -126,13 +136,13 @@ func TestGetTestCommand(t *testing.T) {
 								"TEST_ENV":    "test_value",
 								"GO111MODULE": "on",
 							},
-							Steps: []string{"go test ./..."},
+							Steps: config.StepsFromCommands("go test ./..."),
 						},
 					},
 				}
 			},
 			mockSetup: func(m *MockShellExecutor) {
-				m.On("AddEnv", mock.MatchedBy(func(env []string) bool {
+				m.On("Exec", withEnv(func(env []string) bool {
 					// Check that our env vars are included
 					envStr := ""
 					for _, e := range env {
@@ -140,8 +150,7 @@ func TestGetTestCommand(t *testing.T) {
 					}
 					return contains(envStr, "TEST_ENV=test_value") &&
 						contains(envStr, "GO111MODULE=on")
-				})).Return()
-				m.On("Exec", mock.Anything, "go test ./...").Return(executor.Result{ExitCode: 0, Stdout: "PASS"}, nil)
+				}), "go test ./...").Return(executor.Result{ExitCode: 0, Stdout: "PASS"}, nil)
 			},
 		},
 		{
@@ -152,13 +161,12 @@ func TestGetTestCommand(t *testing.T) {
 					Codebase: config.Codebase{
 						Test: config.Operation{
 							FailFast: true,
-							Steps:    []string{"go test ./pkg1", "go test ./pkg2"},
+							Steps:    config.StepsFromCommands("go test ./pkg1", "go test ./pkg2"),
 						},
 					},
 				}
 			},
 			mockSetup: func(m *MockShellExecutor) {
-				m.On("AddEnv", mock.AnythingOfType("[]string")).Return()
 				m.On("Exec", mock.Anything, "go test ./pkg1").Return(executor.Result{ExitCode: 0, Stdout: "PASS"}, nil)
 				m.On("Exec", mock.Anything, "go test ./pkg2").Return(executor.Result{ExitCode: 1, Stderr: "test failed"}, nil)
 			},
@@ -216,6 +224,32 @@ func TestGetTestCommand_CommandProperties(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestGetTestCommand_JUnitReport(t *testing.T) {
+	reportPath := filepath.Join(t.TempDir(), "junit.xml")
+	mockExecutor := &MockShellExecutor{}
+	mockExecutor.On("Exec", mock.Anything, "go test ./...").Return(executor.Result{ExitCode: 1, Stderr: "FAIL"}, errors.New("exit status 1"))
+
+	cmd := GetTestCommand(mockExecutor)
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	ctx = config.WithContext(ctx, config.ProjectDefinition{
+		ID: "test-project",
+		Codebase: config.Codebase{
+			Test: config.Operation{Steps: config.StepsFromCommands("go test ./...")},
+		},
+	})
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"--report", reportPath})
+
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "tests failed")
+
+	content, readErr := os.ReadFile(reportPath)
+	assert.NoError(t, readErr)
+	assert.Contains(t, string(content), `<testcase name="go test ./..." classname="test"`)
+	assert.Contains(t, string(content), `<failure message="failed with exit code 1">FAIL</failure>`)
+}
+
 func TestGetBuildCommand(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -231,13 +265,12 @@ func TestGetBuildCommand(t *testing.T) {
 					ID: "build-project",
 					Codebase: config.Codebase{
 						Build: config.Operation{
-							Steps: []string{"go build ./...", "go build -o ./bin/app ."},
+							Steps: config.StepsFromCommands("go build ./...", "go build -o ./bin/app ."),
 						},
 					},
 				}
 			},
 			mockSetup: func(m *MockShellExecutor) {
-				m.On("AddEnv", mock.AnythingOfType("[]string")).Return()
 				m.On("Exec", mock.Anything, "go build ./...").Return(executor.Result{ExitCode: 0, Stdout: "built"}, nil)
 				m.On("Exec", mock.Anything, "go build -o ./bin/app .").Return(executor.Result{ExitCode: 0, Stdout: "built"}, nil)
 			},
@@ -249,7 +282,7 @@ func TestGetBuildCommand(t *testing.T) {
 					ID: "build-project",
 					Codebase: config.Codebase{
 						Build: config.Operation{
-							Steps: []string{},
+							Steps: []config.Step{},
 						},
 					},
 				}
@@ -266,13 +299,12 @@ func TestGetBuildCommand(t *testing.T) {
 					ID: "build-project",
 					Codebase: config.Codebase{
 						Build: config.Operation{
-							Steps: []string{"go build ./..."},
+							Steps: config.StepsFromCommands("go build ./..."),
 						},
 					},
 				}
 			},
 			mockSetup: func(m *MockShellExecutor) {
-				m.On("AddEnv", mock.AnythingOfType("[]string")).Return()
 				m.On("Exec", mock.Anything, "go build ./...").Return(executor.Result{ExitCode: 1, Stderr: "build failed"}, nil)
 			},
 			expectedError: "build failed",
@@ -288,13 +320,13 @@ func TestGetBuildCommand(t *testing.T) {
 								"BUILD_ENV":   "production",
 								"GO111MODULE": "on",
 							},
-							Steps: []string{"go build ./..."},
+							Steps: config.StepsFromCommands("go build ./..."),
 						},
 					},
 				}
 			},
 			mockSetup: func(m *MockShellExecutor) {
-				m.On("AddEnv", mock.MatchedBy(func(env []string) bool {
+				m.On("Exec", withEnv(func(env []string) bool {
 					// Check that our env vars are included
 					envStr := ""
 					for _, e := range env {
@@ -302,8 +334,7 @@ func TestGetBuildCommand(t *testing.T) {
 					}
 					return contains(envStr, "BUILD_ENV=production") &&
 						contains(envStr, "GO111MODULE=on")
-				})).Return()
-				m.On("Exec", mock.Anything, "go build ./...").Return(executor.Result{ExitCode: 0, Stdout: "built"}, nil)
+				}), "go build ./...").Return(executor.Result{ExitCode: 0, Stdout: "built"}, nil)
 			},
 		},
 		{
@@ -314,13 +345,12 @@ func TestGetBuildCommand(t *testing.T) {
 					Codebase: config.Codebase{
 						Build: config.Operation{
 							FailFast: true,
-							Steps:    []string{"go build ./pkg1", "go build ./pkg2"},
+							Steps:    config.StepsFromCommands("go build ./pkg1", "go build ./pkg2"),
 						},
 					},
 				}
 			},
 			mockSetup: func(m *MockShellExecutor) {
-				m.On("AddEnv", mock.AnythingOfType("[]string")).Return()
 				m.On("Exec", mock.Anything, "go build ./pkg1").Return(executor.Result{ExitCode: 0, Stdout: "built"}, nil)
 				m.On("Exec", mock.Anything, "go build ./pkg2").Return(executor.Result{ExitCode: 1, Stderr: "build failed"}, nil)
 			},
@@ -358,6 +388,57 @@ func TestGetBuildCommand(t *testing.T) {
 	}
 }
 
+func TestGetBuildCommand_Step(t *testing.T) {
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	ctx = config.WithContext(ctx, config.ProjectDefinition{
+		ID: "build-project",
+		Codebase: config.Codebase{
+			Build: config.Operation{Steps: config.StepsFromCommands("go build ./...", "go build -o ./bin/app .")},
+		},
+	})
+
+	mockExecutor := &MockShellExecutor{}
+	mockExecutor.On("Exec", mock.Anything, "go build -o ./bin/app .").Return(executor.Result{}, nil).Once()
+	cmd := GetBuildCommand(mockExecutor)
+	cmd.SetContext(ctx)
+
+	result := ExecuteCommand(t, cmd, "--step", "2")
+	assert.NoError(t, result.Error)
+	mockExecutor.AssertExpectations(t)
+
+	cmd = GetBuildCommand(mockExecutor)
+	cmd.SetContext(ctx)
+	result = ExecuteCommand(t, cmd, "--step", "link")
+	assert.ErrorContains(t, result.Error, "build failed: operation build: no step named 'link'")
+}
+
+func TestGetBuildCommand_NoCache(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile("main.go", []byte("package main"), 0644))
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	ctx = config.WithContext(ctx, config.ProjectDefinition{
+		ID: "build-project",
+		Codebase: config.Codebase{
+			Build: config.Operation{
+				Cache:  true,
+				Inputs: config.Inputs{Files: []string{"*.go"}},
+				Steps:  config.StepsFromCommands("go build ./..."),
+			},
+		},
+	})
+
+	mockExecutor := &MockShellExecutor{}
+	mockExecutor.On("Exec", mock.Anything, "go build ./...").Return(executor.Result{}, nil)
+	for _, args := range [][]string{{}, {}, {"--no-cache"}} {
+		cmd := GetBuildCommand(mockExecutor)
+		cmd.SetContext(ctx)
+		assert.NoError(t, ExecuteCommand(t, cmd, args...).Error)
+	}
+	mockExecutor.AssertNumberOfCalls(t, "Exec", 2)
+}
+
 func TestGetBuildCommand_CommandProperties(t *testing.T) {
 	mockExecutor := &MockShellExecutor{}
 	cmd := GetBuildCommand(mockExecutor)
@@ -392,7 +473,6 @@ func TestGetBuildCommand_Integration(t *testing.T) {
 	mockExecutor := &MockShellExecutor{}
 
 	// Setup mock expectations
-	mockExecutor.On("AddEnv", mock.AnythingOfType("[]string")).Return()
 	mockExecutor.On("Exec", mock.Anything, "go clean -testcache").Return(executor.Result{ExitCode: 0, Stdout: "cleaned"}, nil)
 	mockExecutor.On("Exec", mock.Anything, "go test -cover ./...").Return(executor.Result{ExitCode: 0, Stdout: "PASS"}, nil)
 	mockExecutor.On("Exec", mock.Anything, "go build -ldflags=\"-s -w\" -o ./devops .").Return(executor.Result{ExitCode: 0, Stdout: "built"}, nil)
@@ -408,7 +488,7 @@ func TestGetBuildCommand_Integration(t *testing.T) {
 		ID: "integration-build",
 		Codebase: config.Codebase{
 			Build: config.Operation{
-				Steps: []string{"go clean -testcache", "go test -cover ./...", "go build -ldflags=\"-s -w\" -o ./devops .", "chmod +x ./devops"},
+				Steps: config.StepsFromCommands("go clean -testcache", "go test -cover ./...", "go build -ldflags=\"-s -w\" -o ./devops .", "chmod +x ./devops"),
 			},
 		},
 	}
@@ -426,7 +506,6 @@ func TestGetTestCommand_Integration(t *testing.T) {
 	mockExecutor := &MockShellExecutor{}
 
 	// Setup mock expectations
-	mockExecutor.On("AddEnv", mock.AnythingOfType("[]string")).Return()
 	mockExecutor.On("Exec", mock.Anything, "go test ./...").Return(executor.Result{ExitCode: 0, Stdout: "PASS"}, nil)
 	mockExecutor.On("Exec", mock.Anything, "go test -race ./...").Return(executor.Result{ExitCode: 0, Stdout: "PASS"}, nil)
 
@@ -440,7 +519,7 @@ func TestGetTestCommand_Integration(t *testing.T) {
 		ID: "integration-test",
 		Codebase: config.Codebase{
 			Test: config.Operation{
-				Steps: []string{"go test ./...", "go test -race ./..."},
+				Steps: config.StepsFromCommands("go test ./...", "go test -race ./..."),
 			},
 		},
 	}
@@ -500,13 +579,13 @@ func TestGetDoctorCommand(t *testing.T) {
 						Language:     "go",
 						Dependencies: []string{"github.com/stretchr/testify"},
 						Install: config.Operation{
-							Steps: []string{"go mod download"},
+							Steps: config.StepsFromCommands("go mod download"),
 						},
 						Test: config.Operation{
-							Steps: []string{"go test ./..."},
+							Steps: config.StepsFromCommands("go test ./..."),
 						},
 						Build: config.Operation{
-							Steps: []string{"go build ./..."},
+							Steps: config.StepsFromCommands("go build ./..."),
 						},
 					},
 				}
@@ -520,10 +599,10 @@ func TestGetDoctorCommand(t *testing.T) {
 					RepoUrl: "https://github.com/test/project",
 					Codebase: config.Codebase{
 						Test: config.Operation{
-							Steps: []string{"go test ./..."},
+							Steps: config.StepsFromCommands("go test ./..."),
 						},
 						Build: config.Operation{
-							Steps: []string{"go build ./..."},
+							Steps: config.StepsFromCommands("go build ./..."),
 						},
 					},
 				}
@@ -539,7 +618,7 @@ func TestGetDoctorCommand(t *testing.T) {
 					Codebase: config.Codebase{
 						Language: "go",
 						Build: config.Operation{
-							Steps: []string{"go build ./..."},
+							Steps: config.StepsFromCommands("go build ./..."),
 						},
 					},
 				}
@@ -555,7 +634,7 @@ func TestGetDoctorCommand(t *testing.T) {
 					Codebase: config.Codebase{
 						Language: "go",
 						Test: config.Operation{
-							Steps: []string{"go test ./..."},
+							Steps: config.StepsFromCommands("go test ./..."),
 						},
 					},
 				}
@@ -571,10 +650,10 @@ func TestGetDoctorCommand(t *testing.T) {
 					Codebase: config.Codebase{
 						Language: "go",
 						Test: config.Operation{
-							Steps: []string{"go test ./..."},
+							Steps: config.StepsFromCommands("go test ./..."),
 						},
 						Build: config.Operation{
-							Steps: []string{"go build ./..."},
+							Steps: config.StepsFromCommands("go build ./..."),
 						},
 					},
 				}
@@ -603,10 +682,10 @@ func TestGetDoctorCommand(t *testing.T) {
 					Codebase: config.Codebase{
 						Language: "",
 						Test: config.Operation{
-							Steps: []string{"go test ./..."},
+							Steps: config.StepsFromCommands("go test ./..."),
 						},
 						Build: config.Operation{
-							Steps: []string{"go build ./..."},
+							Steps: config.StepsFromCommands("go build ./..."),
 						},
 					},
 				}
@@ -672,6 +751,54 @@ func TestGetDoctorCommand_CommandProperties(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestGetDoctorCommand_JSONOutput(t *testing.T) {
+	cmd := GetDoctorCommand(&MockShellExecutor{})
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	ctx = config.WithContext(ctx, config.ProjectDefinition{ID: "json-doctor"})
+	ctx = config.WithOutputFormat(ctx, config.OutputJSON)
+	cmd.SetContext(ctx)
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "validation failed")
+
+	var report config.ValidationReport
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+	assert.NotEmpty(t, report.Findings)
+	assert.NotEmpty(t, report.Errors())
+}
+
+func TestGetDoctorCommand_SARIFFormat(t *testing.T) {
+	cmd := GetDoctorCommand(&MockShellExecutor{})
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	ctx = config.WithContext(ctx, config.ProjectDefinition{ID: "sarif-doctor"})
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"--format", "sarif"})
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "validation failed")
+
+	var log map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log["version"])
+	assert.Contains(t, buf.String(), `"ruleId": "repo-url-required"`)
+}
+
+func TestGetDoctorCommand_UnknownFormat(t *testing.T) {
+	cmd := GetDoctorCommand(&MockShellExecutor{})
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	cmd.SetContext(config.WithContext(ctx, config.ProjectDefinition{}))
+	cmd.SetArgs([]string{"--format", "xml"})
+
+	assert.ErrorContains(t, cmd.Execute(), "unsupported doctor format 'xml'")
+}
+
 func TestGetDoctorCommand_NoContext(t *testing.T) {
 	mockExecutor := &MockShellExecutor{}
 	cmd := GetDoctorCommand(mockExecutor)
@@ -700,13 +827,13 @@ func TestGetDoctorCommand_Integration(t *testing.T) {
 			Language:     "go",
 			Dependencies: []string{"github.com/stretchr/testify", "github.com/spf13/cobra"},
 			Install: config.Operation{
-				Steps: []string{"go mod download", "go mod tidy"},
+				Steps: config.StepsFromCommands("go mod download", "go mod tidy"),
 			},
 			Test: config.Operation{
-				Steps: []string{"go test ./...", "go test -race ./..."},
+				Steps: config.StepsFromCommands("go test ./...", "go test -race ./..."),
 			},
 			Build: config.Operation{
-				Steps: []string{"go build ./...", "go build -o ./bin/app ."},
+				Steps: config.StepsFromCommands("go build ./...", "go build -o ./bin/app ."),
 			},
 		},
 	}
@@ -735,3 +862,715 @@ func TestGetDoctorCommand_Integration(t *testing.T) {
 	// Verify no shell executor calls were made
 	mockExecutor.AssertExpectations(t)
 }
+
+func TestGetDoctorCommand_SuggestPreset(t *testing.T) {
+	tests := []struct {
+		name           string
+		files          fstest.MapFS
+		codebase       config.Codebase
+		expectedOutput []string
+	}{
+		{
+			name:  "suggests missing preset fields",
+			files: fstest.MapFS{"go.mod": {}},
+			codebase: config.Codebase{
+				Language: "go",
+				Test:     config.Operation{Steps: config.StepsFromCommands("go test ./...")},
+			},
+			expectedOutput: []string{
+				"Layout matches the go preset, which would add:",
+				"+   build:",
+				"+       - go build ./...",
+			},
+		},
+		{
+			name:           "no matching preset",
+			files:          fstest.MapFS{"README.md": {}},
+			codebase:       config.Codebase{Language: "go"},
+			expectedOutput: []string{"No known preset matches this repository layout"},
+		},
+		{
+			name:  "language mismatch is reported",
+			files: fstest.MapFS{"Cargo.toml": {}},
+			codebase: config.Codebase{
+				Language: "shell",
+			},
+			expectedOutput: []string{"Layout matches the rust preset but language is set to shell"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := GetDoctorCommand(&MockShellExecutor{})
+
+			logger := logging.New(os.Stderr, logrus.InfoLevel)
+			ctx := logging.WithContext(context.Background(), logger)
+			ctx = config.WithContext(ctx, config.ProjectDefinition{
+				ID:       "test-project",
+				RepoUrl:  "https://github.com/test/project",
+				Codebase: tt.codebase,
+			})
+			ctx = fileutils.ApplyRootDirToContext(ctx, tt.files)
+			cmd.SetContext(ctx)
+
+			result := ExecuteCommand(t, cmd, "--suggest-preset")
+			assert.NoError(t, result.Error)
+			for _, expected := range tt.expectedOutput {
+				assert.Contains(t, result.ShellOutput, expected)
+			}
+		})
+	}
+}
+
+func TestGetRunCommand(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		mockSetup     func(*MockShellExecutor)
+		expectedError string
+	}{
+		{
+			name: "runs custom operation",
+			args: []string{"lint"},
+			mockSetup: func(m *MockShellExecutor) {
+				m.On("Exec", mock.Anything, "golangci-lint run").Return(executor.Result{ExitCode: 0}, nil)
+			},
+		},
+		{
+			name:          "unknown operation",
+			args:          []string{"deploy"},
+			mockSetup:     func(m *MockShellExecutor) {},
+			expectedError: "deploy failed: unknown operation 'deploy'",
+		},
+		{
+			name:          "requires an operation name",
+			args:          []string{},
+			mockSetup:     func(m *MockShellExecutor) {},
+			expectedError: "accepts 1 arg(s)",
+		},
+		{
+			name: "env overrides",
+			args: []string{"lint", "--env", "LINT_MODE=strict", "--env", "TAGS=a,b"},
+			mockSetup: func(m *MockShellExecutor) {
+				m.On("Exec", withEnv(func(env []string) bool {
+					return slices.Contains(env, "LINT_MODE=strict") && slices.Contains(env, "TAGS=a,b")
+				}), "golangci-lint run").Return(executor.Result{}, nil)
+			},
+		},
+		{
+			name:          "invalid env override",
+			args:          []string{"lint", "--env", "LINT_MODE"},
+			mockSetup:     func(m *MockShellExecutor) {},
+			expectedError: "lint failed: invalid env override 'LINT_MODE', expected KEY=VALUE",
+		},
+		{
+			name: "runs dependencies first",
+			args: []string{"e2e"},
+			mockSetup: func(m *MockShellExecutor) {
+				m.On("Exec", mock.Anything, "golangci-lint run").Return(executor.Result{}, nil).Once()
+				m.On("Exec", mock.Anything, "./e2e.sh").Return(executor.Result{}, nil).Once()
+			},
+		},
+		{
+			name: "only skips dependencies",
+			args: []string{"e2e", "--only"},
+			mockSetup: func(m *MockShellExecutor) {
+				m.On("Exec", mock.Anything, "./e2e.sh").Return(executor.Result{}, nil).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExecutor := &MockShellExecutor{}
+			tt.mockSetup(mockExecutor)
+			cmd := GetRunCommand(mockExecutor)
+
+			logger := logging.New(os.Stderr, logrus.InfoLevel)
+			ctx := logging.WithContext(context.Background(), logger)
+			ctx = config.WithContext(ctx, config.ProjectDefinition{
+				ID: "test-project",
+				Codebase: config.Codebase{
+					Operations: map[string]config.Operation{
+						"lint": {Steps: config.StepsFromCommands("golangci-lint run")},
+						"e2e":  {DependsOn: []string{"lint"}, Steps: config.StepsFromCommands("./e2e.sh")},
+					},
+				},
+			})
+			cmd.SetContext(ctx)
+
+			result := ExecuteCommand(t, cmd, tt.args...)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, result.Error, tt.expectedError)
+			} else {
+				assert.NoError(t, result.Error)
+			}
+			mockExecutor.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetWorkspaceCommand(t *testing.T) {
+	dir := t.TempDir()
+	for path, content := range map[string]string{
+		config.WorkspaceFile:           "projects:\n  api:\n    path: api\n    depends_on: [lib]\n  lib:\n    path: lib\n",
+		"lib/" + config.DefinitionFile: "id: lib\ncodebase:\n  build:\n    steps: [make lib]\n",
+		"api/" + config.DefinitionFile: "id: api\ncodebase:\n  build:\n    steps: [make api]\n",
+	} {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0o755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0o644))
+	}
+	t.Chdir(dir)
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+
+	t.Run("dry run prints order", func(t *testing.T) {
+		mockExecutor := &MockShellExecutor{}
+		cmd := GetWorkspaceCommand(mockExecutor)
+		cmd.SetContext(ctx)
+
+		result := ExecuteCommand(t, cmd, "build", "--dry-run")
+		assert.NoError(t, result.Error)
+		assert.Equal(t, "1. lib\n2. api\n", result.ShellOutput)
+		mockExecutor.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything)
+	})
+
+	t.Run("runs every project", func(t *testing.T) {
+		mockExecutor := &MockShellExecutor{}
+		mockExecutor.On("Exec", mock.Anything, "make lib").Return(executor.Result{}, nil).Once()
+		mockExecutor.On("Exec", mock.Anything, "make api").Return(executor.Result{}, nil).Once()
+		cmd := GetWorkspaceCommand(mockExecutor)
+		cmd.SetContext(ctx)
+
+		result := ExecuteCommand(t, cmd, "build")
+		assert.NoError(t, result.Error)
+		mockExecutor.AssertExpectations(t)
+	})
+}
+
+func TestGetExecCommand(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		mockSetup     func(*MockShellExecutor)
+		expectedError string
+	}{
+		{
+			name: "runs command after separator",
+			args: []string{"--", "go", "env", "GOOS"},
+			mockSetup: func(m *MockShellExecutor) {
+				m.On("Exec", mock.Anything, "go env GOOS").Return(executor.Result{Stdout: "linux"}, nil)
+			},
+		},
+		{
+			name: "keeps arguments with spaces whole",
+			args: []string{"--", "printf", "%s|", "a b", "c"},
+			mockSetup: func(m *MockShellExecutor) {
+				m.On("Exec", mock.Anything, "printf '%s|' 'a b' c").Return(executor.Result{}, nil)
+			},
+		},
+		{
+			name: "uses operation env",
+			args: []string{"--operation", "build", "--", "env"},
+			mockSetup: func(m *MockShellExecutor) {
+				m.On("Exec", withEnv(func(env []string) bool {
+					return slices.Contains(env, "GOOS=linux")
+				}), "env").Return(executor.Result{}, nil)
+			},
+		},
+		{
+			name:          "unknown operation",
+			args:          []string{"--operation", "buld", "--", "env"},
+			mockSetup:     func(m *MockShellExecutor) {},
+			expectedError: "unknown operation 'buld' (did you mean 'build'?)",
+		},
+		{
+			name: "command failure",
+			args: []string{"--", "false"},
+			mockSetup: func(m *MockShellExecutor) {
+				m.On("Exec", mock.Anything, "false").Return(executor.Result{ExitCode: 1}, errors.New("exit status 1"))
+			},
+			expectedError: "exec failed",
+		},
+		{
+			name:          "requires a command",
+			args:          []string{},
+			mockSetup:     func(m *MockShellExecutor) {},
+			expectedError: "requires at least 1 arg(s)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExecutor := &MockShellExecutor{}
+			tt.mockSetup(mockExecutor)
+			cmd := GetExecCommand(mockExecutor)
+
+			logger := logging.New(os.Stderr, logrus.InfoLevel)
+			ctx := logging.WithContext(context.Background(), logger)
+			ctx = config.WithContext(ctx, config.ProjectDefinition{
+				ID: "test-project",
+				Codebase: config.Codebase{
+					Build: config.Operation{
+						Env:   map[string]string{"GOOS": "linux"},
+						Steps: config.StepsFromCommands("go build ./..."),
+					},
+				},
+			})
+			cmd.SetContext(ctx)
+
+			result := ExecuteCommand(t, cmd, tt.args...)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, result.Error, tt.expectedError)
+			} else {
+				assert.NoError(t, result.Error)
+			}
+			mockExecutor.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetManifestCommand_NonJSONOutput(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile(config.DefinitionFile, []byte("id: app\n"), 0644))
+	require.NoError(t, os.MkdirAll("dist", 0755))
+	require.NoError(t, os.WriteFile("dist/app", []byte("hello"), 0755))
+
+	cmd := GetManifestCommand()
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	cmd.SetContext(config.WithContext(ctx, config.ProjectDefinition{
+		ID:       "app",
+		Codebase: config.Codebase{Build: config.Operation{Artifacts: []string{"dist/*"}}},
+	}))
+
+	result := ExecuteCommand(t, cmd, "-o", "build/manifest.out")
+	require.NoError(t, result.Error)
+	assert.FileExists(t, "build/manifest.out")
+	checksums, err := os.ReadFile(filepath.Join("build", config.ChecksumsFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(checksums), "  dist/app\n")
+}
+
+func TestGetFeaturesCommand(t *testing.T) {
+	cmd := GetFeaturesCommand()
+	cmd.SetContext(config.WithFeatures(context.Background(), config.Features{config.FeaturePipelineDAG: true}))
+
+	result := ExecuteCommand(t, cmd)
+	assert.NoError(t, result.Error)
+	assert.Contains(t, result.ShellOutput, "EXPERIMENT")
+	assert.Regexp(t, `pipeline-dag\s+enabled`, result.ShellOutput)
+}
+
+func TestGetLogsCommand(t *testing.T) {
+	dir := t.TempDir()
+	runDir := filepath.Join(dir, "20260301T100000Z-build")
+	require.NoError(t, os.MkdirAll(runDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(runDir, "01-compile.log"), []byte("compiling\nwarning: unused\n"), 0o644))
+	ctx := config.WithLogDir(context.Background(), dir)
+
+	cmd := GetLogsCommand()
+	cmd.SetContext(ctx)
+	result := ExecuteCommand(t, cmd)
+	require.NoError(t, result.Error)
+	assert.Regexp(t, `20260301T100000Z-build\s+1\s`, result.ShellOutput)
+
+	cmd = GetLogsCommand()
+	cmd.SetContext(ctx)
+	result = ExecuteCommand(t, cmd, "20260301", "--step", "compile", "--grep", "warn")
+	require.NoError(t, result.Error)
+	assert.Equal(t, "01-compile.log:2:warning: unused\n", result.ShellOutput)
+
+	cmd = GetLogsCommand()
+	cmd.SetContext(ctx)
+	result = ExecuteCommand(t, cmd, "--grep", "(")
+	assert.ErrorContains(t, result.Error, "invalid --grep pattern")
+}
+
+func TestGetHistoryCommand(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cmd := GetHistoryCommand()
+	cmd.SetContext(context.Background())
+	result := ExecuteCommand(t, cmd)
+	require.NoError(t, result.Error)
+	assert.Equal(t, "No runs recorded yet\n", result.ShellOutput)
+
+	for _, runErr := range []error{nil, errors.New("exit status 1")} {
+		summary := &config.RunSummary{Command: "devops build", ConfigHash: "0123456789abcdef"}
+		summary.Operations = []config.OperationResult{{Name: "build", Start: time.Now(), Duration: time.Second, Err: runErr}}
+		require.NoError(t, config.RecordHistory(context.Background(), summary, runErr, time.Now()))
+	}
+
+	cmd = GetHistoryCommand()
+	cmd.SetContext(context.Background())
+	result = ExecuteCommand(t, cmd, "--limit", "1")
+	require.NoError(t, result.Error)
+	assert.Regexp(t, `devops build\s+failure\s`, result.ShellOutput)
+	assert.Regexp(t, `\s-\s+0123456789ab\n`, result.ShellOutput)
+	assert.NotContains(t, result.ShellOutput, "success")
+
+	cmd = GetHistoryCommand()
+	cmd.SetContext(context.Background())
+	result = ExecuteCommand(t, cmd, "--trends", "--operation", "build")
+	require.NoError(t, result.Error)
+	assert.Regexp(t, `build\s+2\s+50%\s+1s\s+1s\s+failure`, result.ShellOutput)
+}
+
+func TestGetStatusCommand(t *testing.T) {
+	t.Chdir(t.TempDir())
+	record := func(name string, runErr error) {
+		summary := &config.RunSummary{Command: "devops " + name}
+		summary.Operations = []config.OperationResult{{Name: name, Start: time.Now(), Duration: 2 * time.Second, Err: runErr}}
+		require.NoError(t, config.RecordHistory(context.Background(), summary, runErr, time.Now()))
+	}
+	record("build", errors.New("exit status 1"))
+	record("build", nil)
+	record("test", nil)
+
+	cmd := GetStatusCommand()
+	cmd.SetContext(context.Background())
+	result := ExecuteCommand(t, cmd)
+	require.NoError(t, result.Error)
+	assert.Regexp(t, `build\s+success\s+\S+\s+2s\s+-`, result.ShellOutput)
+	assert.Contains(t, result.ShellOutput, "All 2 operation(s) passed their last run")
+
+	record("test", errors.New("exit status 1"))
+	cmd = GetStatusCommand()
+	cmd.SetContext(config.WithOutputFormat(context.Background(), config.OutputJSON))
+	result = ExecuteCommand(t, cmd)
+	require.NoError(t, result.Error)
+	var statuses []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(result.ShellOutput), &statuses))
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "test", statuses[1]["operation"])
+	assert.Equal(t, config.LastRunFailure, statuses[1]["status"])
+}
+
+func TestGetPipelineCommand(t *testing.T) {
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	ctx = config.WithContext(ctx, config.ProjectDefinition{
+		ID: "test-project",
+		Codebase: config.Codebase{
+			Test:  config.Operation{Steps: config.StepsFromCommands("go test ./...")},
+			Build: config.Operation{Steps: config.StepsFromCommands("go build ./...")},
+		},
+		Pipeline: map[string]config.PipelineStage{
+			"test":  {},
+			"build": {Needs: []string{"test"}},
+		},
+	})
+
+	t.Run("requires the experiment", func(t *testing.T) {
+		mockExecutor := &MockShellExecutor{}
+		cmd := GetPipelineCommand(mockExecutor)
+		cmd.SetContext(ctx)
+
+		result := ExecuteCommand(t, cmd)
+		assert.ErrorContains(t, result.Error, "'pipeline-dag' is experimental")
+		mockExecutor.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything)
+	})
+
+	ctx = config.WithFeatures(ctx, config.Features{config.FeaturePipelineDAG: true})
+
+	t.Run("dry run prints order", func(t *testing.T) {
+		mockExecutor := &MockShellExecutor{}
+		cmd := GetPipelineCommand(mockExecutor)
+		cmd.SetContext(ctx)
+
+		result := ExecuteCommand(t, cmd, "--dry-run")
+		assert.NoError(t, result.Error)
+		assert.Equal(t, "1. test\n2. build\n", result.ShellOutput)
+		mockExecutor.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything)
+	})
+
+	t.Run("runs pipeline", func(t *testing.T) {
+		mockExecutor := &MockShellExecutor{}
+		mockExecutor.On("Exec", mock.Anything, "go test ./...").Return(executor.Result{}, nil)
+		mockExecutor.On("Exec", mock.Anything, "go build ./...").Return(executor.Result{}, nil)
+		cmd := GetPipelineCommand(mockExecutor)
+		cmd.SetContext(ctx)
+
+		result := ExecuteCommand(t, cmd)
+		assert.NoError(t, result.Error)
+		mockExecutor.AssertExpectations(t)
+	})
+}
+
+func TestGetCICommand(t *testing.T) {
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	ctx = config.WithContext(ctx, config.ProjectDefinition{
+		ID: "test-project",
+		Codebase: config.Codebase{
+			Install: config.Operation{Steps: config.StepsFromCommands("go mod download")},
+			Test:    config.Operation{Steps: config.StepsFromCommands("go test ./...")},
+			Build:   config.Operation{Steps: config.StepsFromCommands("go build ./...")},
+			Operations: map[string]config.Operation{
+				"lint": {Steps: config.StepsFromCommands("golangci-lint run")},
+			},
+		},
+	})
+
+	mockExecutor := &MockShellExecutor{}
+	calls := []string{}
+	for _, command := range []string{"go mod download", "golangci-lint run", "go test ./...", "go build ./..."} {
+		mockExecutor.On("Exec", withEnv(func(env []string) bool {
+			return slices.Contains(env, "CI_MODE=strict")
+		}), command).Run(func(args mock.Arguments) {
+			calls = append(calls, args.String(1))
+		}).Return(executor.Result{}, nil)
+	}
+	cmd := GetCICommand(mockExecutor)
+	cmd.SetContext(ctx)
+
+	result := ExecuteCommand(t, cmd, "--env", "CI_MODE=strict")
+	assert.NoError(t, result.Error)
+	assert.Equal(t, []string{"go mod download", "golangci-lint run", "go test ./...", "go build ./..."}, calls)
+
+	calls = nil
+	cmd = GetCICommand(mockExecutor)
+	cmd.SetContext(ctx)
+	result = ExecuteCommand(t, cmd, "--env", "CI_MODE=strict", "--only", "build,test")
+	assert.NoError(t, result.Error)
+	assert.Equal(t, []string{"go test ./...", "go build ./..."}, calls)
+
+	calls = nil
+	cmd = GetCICommand(mockExecutor)
+	cmd.SetContext(ctx)
+	result = ExecuteCommand(t, cmd, "--env", "CI_MODE=strict", "--skip", "install", "--skip", "lint")
+	assert.NoError(t, result.Error)
+	assert.Equal(t, []string{"go test ./...", "go build ./..."}, calls)
+
+	cmd = GetCICommand(mockExecutor)
+	cmd.SetContext(ctx)
+	result = ExecuteCommand(t, cmd, "--only", "tests")
+	assert.ErrorContains(t, result.Error, "ci failed: unknown ci stage 'tests' (did you mean 'test'?)")
+}
+
+func TestGetWatchCommand(t *testing.T) {
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx, cancel := context.WithCancel(logging.WithContext(context.Background(), logger))
+	defer cancel()
+	ctx = config.WithContext(ctx, config.ProjectDefinition{
+		ID: "test-project",
+		Codebase: config.Codebase{
+			Test: config.Operation{Steps: config.StepsFromCommands("go test ./...")},
+		},
+	})
+	ctx = fileutils.ApplyRootDirToContext(ctx, fstest.MapFS{"main.go": {Data: []byte("package main")}})
+
+	mockExecutor := &MockShellExecutor{}
+	mockExecutor.On("Exec", mock.Anything, "go test ./...").Run(func(args mock.Arguments) {
+		cancel()
+	}).Return(executor.Result{}, nil).Once()
+	cmd := GetWatchCommand(mockExecutor)
+	cmd.SetContext(ctx)
+
+	result := ExecuteCommand(t, cmd, "test", "--interval", "10ms")
+	assert.NoError(t, result.Error)
+	mockExecutor.AssertExpectations(t)
+}
+
+func TestGetDoctorCommand_Interactive(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	require.NoError(t, os.WriteFile("go.mod", []byte("module example.com/demo\n"), 0644))
+	definition := "id: demo\nrepo_url: https://github.com/test/demo\ncodebase:\n  dependencies:\n    - go.mod\n"
+	require.NoError(t, os.WriteFile(config.DefinitionFile, []byte(definition), 0644))
+	cfg, err := config.LoadFile(config.DefinitionFile)
+	require.NoError(t, err)
+
+	cmd := GetDoctorCommand(&MockShellExecutor{})
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	ctx = config.WithContext(ctx, *cfg)
+	ctx = fileutils.ApplyRootDirToContext(ctx, os.DirFS(dir))
+	cmd.SetContext(ctx)
+	// Accept the language and test fixes, decline the build fix.
+	cmd.SetIn(strings.NewReader("\ny\nn\n"))
+
+	result := ExecuteCommand(t, cmd, "--interactive")
+
+	assert.NoError(t, result.Error)
+	assert.Contains(t, result.ShellOutput, "Set the language to go? [Y/n]")
+	assert.Contains(t, result.ShellOutput, "Add default test steps for Go? [Y/n]")
+	assert.Contains(t, result.ShellOutput, "Add default build steps for Go? [Y/n]")
+	assert.Contains(t, result.ShellOutput, "+  language: go")
+	assert.Contains(t, result.ShellOutput, "+      - go test ./...")
+
+	fixed, err := config.LoadFile(config.DefinitionFile)
+	require.NoError(t, err)
+	assert.Equal(t, "go", fixed.Codebase.Language)
+	require.Len(t, fixed.Codebase.Test.Steps, 1)
+	assert.Equal(t, "go test ./...", fixed.Codebase.Test.Steps[0].Run)
+	assert.Empty(t, fixed.Codebase.Build.Steps)
+}
+
+func TestGetDoctorCommand_InteractiveNoChanges(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	require.NoError(t, os.WriteFile("go.mod", []byte("module example.com/demo\n"), 0644))
+	definition := "id: demo\nrepo_url: https://github.com/test/demo\ncodebase:\n  language: go\n"
+	require.NoError(t, os.WriteFile(config.DefinitionFile, []byte(definition), 0644))
+	cfg, err := config.LoadFile(config.DefinitionFile)
+	require.NoError(t, err)
+
+	cmd := GetDoctorCommand(&MockShellExecutor{})
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	ctx = config.WithContext(ctx, *cfg)
+	ctx = fileutils.ApplyRootDirToContext(ctx, os.DirFS(dir))
+	cmd.SetContext(ctx)
+	cmd.SetIn(strings.NewReader("n\nno\nn\n"))
+
+	result := ExecuteCommand(t, cmd, "--interactive")
+
+	assert.NoError(t, result.Error)
+	assert.Contains(t, result.ShellOutput, "No changes made")
+	data, err := os.ReadFile(config.DefinitionFile)
+	require.NoError(t, err)
+	assert.Equal(t, definition, string(data))
+}
+
+func TestGetPromoteCommand(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cmd := GetPromoteCommand()
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	ctx = config.WithContext(ctx, config.ProjectDefinition{
+		Environments: map[string]config.Environment{"staging": {Path: "envs/staging"}},
+	})
+	cmd.SetContext(ctx)
+
+	result := ExecuteCommand(t, cmd, "20260501T120000Z-build")
+	assert.ErrorContains(t, result.Error, `required flag(s) "to" not set`)
+
+	result = ExecuteCommand(t, cmd, "20260501T120000Z-build", "--to", "staging")
+	assert.ErrorContains(t, result.Error, "promote failed: run 20260501T120000Z-build not found")
+}
+
+func TestGetReleaseCommand(t *testing.T) {
+	cmd := GetReleaseCommand(&MockShellExecutor{})
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	ctx = config.WithContext(ctx, config.ProjectDefinition{Version: "1.2"})
+	cmd.SetContext(ctx)
+
+	result := ExecuteCommand(t, cmd)
+	assert.ErrorContains(t, result.Error, "accepts 1 arg(s), received 0")
+
+	result = ExecuteCommand(t, cmd, "minor")
+	assert.EqualError(t, result.Error, "release failed: current version '1.2' is not a semantic version")
+}
+
+func TestGetArtifactsCommand_Push(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cmd := GetArtifactsCommand()
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	ctx = config.WithContext(ctx, config.ProjectDefinition{
+		ArtifactStores: map[string]config.StoreConfig{"releases": {Backend: "s3", Bucket: "releases"}},
+	})
+	cmd.SetContext(ctx)
+
+	result := ExecuteCommand(t, cmd, "push")
+	assert.ErrorContains(t, result.Error, "push failed: no retained runs in "+config.RunsDir)
+
+	result = ExecuteCommand(t, cmd, "push", "20260501T120000Z-build", "--to", "mirror")
+	assert.ErrorContains(t, result.Error, "push failed: unknown artifact store 'mirror'")
+}
+
+func TestGetVerifyCommand(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.MkdirAll(config.WorkDir, 0755))
+	require.NoError(t, os.WriteFile("app", []byte("hello"), 0644))
+	sums := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824  app\n"
+	require.NoError(t, os.WriteFile(config.WorkDir+"/"+config.ChecksumsFile, []byte(sums), 0644))
+
+	result := ExecuteCommand(t, GetVerifyCommand())
+	require.NoError(t, result.Error)
+	assert.Contains(t, result.ShellOutput, "app: OK")
+
+	require.NoError(t, os.WriteFile("app", []byte("tampered"), 0644))
+	result = ExecuteCommand(t, GetVerifyCommand())
+	assert.EqualError(t, result.Error, "1 of 1 artifact(s) failed verification")
+	assert.Contains(t, result.ShellOutput, "app: FAILED")
+
+	result = ExecuteCommand(t, GetVerifyCommand(), "missing.txt")
+	assert.ErrorContains(t, result.Error, "verify failed: ")
+}
+
+func TestGetVerifyCommand_Signature(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile(config.ChecksumsFile, []byte("2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824  app\n"), 0644))
+
+	result := ExecuteCommand(t, GetVerifyCommand(), config.ChecksumsFile, "--signature")
+	assert.EqualError(t, result.Error, "verify failed: no signature found for "+config.ChecksumsFile)
+
+	require.NoError(t, os.WriteFile(config.ChecksumsFile+".sigstore.json", []byte("bundle"), 0644))
+	result = ExecuteCommand(t, GetVerifyCommand(), config.ChecksumsFile, "--signature")
+	assert.EqualError(t, result.Error, "verify failed: verifying a keyless cosign signature needs the certificate identity and OIDC issuer")
+}
+
+func TestGetSBOMCommand(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cmd := GetSBOMCommand()
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	ctx = config.WithContext(ctx, config.ProjectDefinition{ID: "app"})
+	cmd.SetContext(ctx)
+
+	result := ExecuteCommand(t, cmd, "--format", "spdx-json")
+	assert.EqualError(t, result.Error, "sbom failed: unknown SBOM format 'spdx-json'")
+	assert.NoFileExists(t, config.SBOMFile(config.SBOMSPDX))
+}
+
+func TestGetImageCommand(t *testing.T) {
+	cmd := GetImageCommand(&MockShellExecutor{})
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	ctx = config.WithContext(ctx, config.ProjectDefinition{ID: "app"})
+	cmd.SetContext(ctx)
+
+	result := ExecuteCommand(t, cmd, "build")
+	assert.EqualError(t, result.Error, "image build failed: no container section is defined")
+
+	result = ExecuteCommand(t, cmd, "push")
+	assert.EqualError(t, result.Error, "image push failed: no container section is defined")
+}
+
+func TestGetDeployCommand(t *testing.T) {
+	cmd := GetDeployCommand(&MockShellExecutor{})
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	ctx = config.WithContext(ctx, config.ProjectDefinition{ID: "app"})
+	cmd.SetContext(ctx)
+
+	result := ExecuteCommand(t, cmd)
+	assert.EqualError(t, result.Error, "deploy failed: no deploy method given, use --helm")
+
+	result = ExecuteCommand(t, cmd, "--helm")
+	assert.EqualError(t, result.Error, "deploy failed: deploy.helm.chart is not set")
+}
+
+func TestGetRollbackCommand(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cmd := GetRollbackCommand(&MockShellExecutor{})
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	ctx = config.WithContext(ctx, config.ProjectDefinition{ID: "app"})
+	cmd.SetContext(ctx)
+
+	result := ExecuteCommand(t, cmd, "--env", "prod")
+	assert.EqualError(t, result.Error, "rollback failed: no earlier successful deployment to roll back to in environment 'prod'")
+}
+
+func TestGetLoginCommand(t *testing.T) {
+	cmd := GetLoginCommand(&MockShellExecutor{})
+	logger := logging.New(os.Stderr, logrus.InfoLevel)
+	ctx := logging.WithContext(context.Background(), logger)
+	ctx = config.WithContext(ctx, config.ProjectDefinition{ID: "app"})
+	cmd.SetContext(ctx)
+
+	result := ExecuteCommand(t, cmd)
+	assert.EqualError(t, result.Error, "login failed: no registry given and container.registry is not set")
+}
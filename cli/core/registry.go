@@ -1,17 +1,25 @@
 package core
 
 import (
+	"bufio"
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/jgfranco17/dev-tooling-go/logging"
 	"github.com/jgfranco17/devops/cli/config"
+	"github.com/jgfranco17/devops/cli/executor"
 	"github.com/jgfranco17/devops/internal/fileutils"
+	"github.com/jgfranco17/devops/internal/i18n"
+	"github.com/jgfranco17/devops/internal/outputs"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -19,12 +27,37 @@ import (
 type CommandRegistry struct {
 	rootCmd   *cobra.Command
 	verbosity int
+	summary   *config.RunSummary
+	output    *string
+	lock      **config.Lock
 }
 
+// skipDefinitionAnnotation marks commands that run without loading the
+// project definition.
+const skipDefinitionAnnotation = "devops/skip-definition"
+
+// lockAnnotation marks commands that take the workspace lock, unless
+// --no-lock is given.
+const lockAnnotation = "devops/lock"
+
 // NewCommandRegistry creates a new instance of CommandRegistry
 func NewCommandRegistry(name string, description string, version string) *CommandRegistry {
 	var verbosity int
 	var path string
+	var trace bool
+	var assumeYes bool
+	var interleave bool
+	var localTime bool
+	var readOnly bool
+	var project string
+	var codebase string
+	var noLock bool
+	var logDir string
+	var lock *config.Lock
+	output := config.OutputText
+	var workDirExisted bool
+	summary := &config.RunSummary{}
+	useLocale()
 
 	root := &cobra.Command{
 		Use:     name,
@@ -44,14 +77,67 @@ func NewCommandRegistry(name string, description string, version string) *Comman
 				level = logrus.WarnLevel
 			}
 
+			if _, err := config.ParseOutputFormat(output); err != nil {
+				return err
+			}
+
 			logger := logging.New(cmd.ErrOrStderr(), level)
+			useTimestamps(logger, localTime)
 			ctx := logging.WithContext(cmd.Context(), logger)
+			if localTime {
+				ctx = outputs.WithLocalTime(ctx)
+			}
+			ctx = config.WithOutputFormat(ctx, output)
+			if readOnly || config.ReadOnlyEnforced() {
+				ctx = config.WithReadOnly(ctx)
+			}
 
-			definition, err := loadConfig(ctx, path)
+			userConfig, err := config.LoadUserConfig()
 			if err != nil {
 				return err
 			}
+			features, unknown := config.LoadFeatures(userConfig)
+			if len(unknown) > 0 {
+				logger.Warnf("Ignoring unknown experiments: %s", strings.Join(unknown, ", "))
+			}
+			ctx = config.WithFeatures(ctx, features)
+
+			if project != "" {
+				if err := enterProject(project); err != nil {
+					return err
+				}
+			}
+			definition := config.ProjectDefinition{}
+			if _, skip := cmd.Annotations[skipDefinitionAnnotation]; !skip {
+				if definition, err = loadConfig(ctx, path); err != nil {
+					return err
+				}
+				if data, err := os.ReadFile(path); err == nil {
+					summary.ConfigHash = config.ConfigHash(data)
+				}
+			}
+			if codebase != "" {
+				if _, err := definition.SelectCodebases(codebase); err != nil {
+					return err
+				}
+				ctx = config.WithCodebase(ctx, codebase)
+			}
+			if dir := cmp.Or(logDir, definition.LogDir); dir != "" {
+				ctx = config.WithLogDir(ctx, dir)
+			}
 			ctx = config.WithContext(ctx, definition)
+			summary.Command = cmd.CommandPath()
+			summary.LocalTime = localTime
+			ctx = config.WithRunSummary(ctx, summary)
+			if err := applyDefaults(cmd, definition.Defaults); err != nil {
+				return err
+			}
+			if trace {
+				ctx = executor.WithTracer(ctx, executor.NewTracer(cmd.ErrOrStderr(), localTime))
+			}
+			if interleave {
+				ctx = executor.WithInterleavedOutput(ctx)
+			}
 
 			cwd, err := os.Getwd()
 			if err != nil {
@@ -70,16 +156,60 @@ func NewCommandRegistry(name string, description string, version string) *Comman
 				}
 			}()
 
+			workDirExisted = fileutils.IsDir(config.WorkDir)
+			if _, locks := cmd.Annotations[lockAnnotation]; locks && !noLock {
+				if lock, err = definition.AcquireLock(ctx, cmd.CommandPath()); err != nil {
+					return err
+				}
+			}
 			cmd.SetContext(ctx)
 			return nil
 		},
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			// Written before the .gitignore check so a work dir created
+			// just for this file is offered for ignoring too. Failed
+			// commands skip this hook and are recorded by Execute.
+			if err := config.WriteLastRun(config.NewLastRun(summary, nil, time.Now())); err != nil {
+				logging.FromContext(ctx).Warn(err.Error())
+			}
+			if err := config.RecordHistory(ctx, summary, nil, time.Now()); err != nil {
+				logging.FromContext(ctx).Warn(err.Error())
+			}
+			if workDirExisted || !config.WorkDirNeedsIgnore(ctx) {
+				return nil
+			}
+			question := fmt.Sprintf("devops created %s/, add it to .gitignore?", config.WorkDir)
+			if !assumeYes && !confirm(cmd.InOrStdin(), cmd.ErrOrStderr(), question) {
+				return nil
+			}
+			if err := config.IgnoreWorkDir(); err != nil {
+				return err
+			}
+			logging.FromContext(ctx).Infof("Added %s/ to .gitignore", config.WorkDir)
+			return nil
+		},
 	}
 
 	root.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "Increase verbosity (-v or -vv)")
+	root.PersistentFlags().BoolVar(&trace, "trace", false, "Print every command executed, with timestamps and durations")
+	root.PersistentFlags().BoolVar(&interleave, "interleave-output", false, "Print step stdout and stderr in the order they were written")
+	root.PersistentFlags().StringVar(&output, "output", config.OutputText, "Output format, text or json")
+	root.PersistentFlags().BoolVar(&localTime, "local-time", false, "Show timestamps in the local time zone instead of UTC")
+	root.PersistentFlags().BoolVar(&readOnly, "read-only", false, "Refuse to run operations marked mutating (also set by "+config.ReadOnlyEnv+")")
+	root.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "Answer yes to any prompts")
+	root.PersistentFlags().StringVar(&project, "project", "", "Run in the named project of the "+config.WorkspaceFile+" workspace")
+	root.PersistentFlags().StringVar(&codebase, "codebase", "", "Only run operations in the named codebase of the definition")
 	root.PersistentFlags().StringVarP(&path, "file", "f", config.DefinitionFile, "Path to the project definition file")
+	root.PersistentFlags().BoolVar(&noLock, "no-lock", false, "Run without taking the workspace lock")
+	root.PersistentFlags().StringVar(&logDir, "log-dir", "", "Also write each step's output to <dir>/<run-id>/<step>.log ("+config.LogsDir+" if given without a value)")
+	root.PersistentFlags().Lookup("log-dir").NoOptDefVal = config.LogsDir
 	return &CommandRegistry{
 		rootCmd:   root,
 		verbosity: verbosity,
+		summary:   summary,
+		output:    &output,
+		lock:      &lock,
 	}
 }
 
@@ -94,9 +224,130 @@ func (cr *CommandRegistry) RegisterCommands(commands []*cobra.Command) {
 	}
 }
 
-// Execute executes the root command
+// Execute executes the root command and releases the workspace lock it
+// took, then writes the run summary to the GitHub Actions job summary
+// when running in Actions, and to stdout as JSON when --output json is
+// set. A command that fails is recorded in the last run file and the run
+// history.
 func (cr *CommandRegistry) Execute() error {
-	return cr.rootCmd.Execute()
+	err := cr.rootCmd.Execute()
+	if lockErr := (*cr.lock).Release(); lockErr != nil {
+		logrus.Warn(lockErr.Error())
+	}
+	if err != nil && cr.summary.Command != "" {
+		if lastRunErr := config.WriteLastRun(config.NewLastRun(cr.summary, err, time.Now())); lastRunErr != nil {
+			logrus.Warn(lastRunErr.Error())
+		}
+		if historyErr := config.RecordHistory(context.Background(), cr.summary, err, time.Now()); historyErr != nil {
+			logrus.Warn(historyErr.Error())
+		}
+	}
+	if *cr.output == config.OutputJSON && len(cr.summary.Operations) > 0 {
+		if jsonErr := cr.summary.WriteJSON(cr.rootCmd.OutOrStdout()); jsonErr != nil {
+			logrus.Warn(jsonErr.Error())
+		}
+	}
+	if summaryErr := cr.summary.WriteGitHubStepSummary(); summaryErr != nil {
+		logrus.Warn(summaryErr.Error())
+	}
+	return err
+}
+
+// useLocale selects the message language from the user config or the
+// environment. It runs before commands are built so their help is
+// translated too; problems with the user config are reported later, when
+// a command runs.
+func useLocale() {
+	userConfig, _ := config.LoadUserConfig()
+	var dirs []string
+	if dir, err := config.UserLocalesDir(); err == nil {
+		dirs = append(dirs, dir)
+	}
+	if _, err := i18n.SetLocale(i18n.Detect(userConfig.Locale), dirs...); err != nil {
+		logrus.Warn(err.Error())
+	}
+}
+
+// applyDefaults sets the flags configured under defaults for the running
+// command, leaving flags given on the command line untouched.
+func applyDefaults(cmd *cobra.Command, defaults map[string]map[string]config.FlagValue) error {
+	name := strings.TrimSpace(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()))
+	for flagName, values := range defaults[name] {
+		flag := cmd.Flags().Lookup(flagName)
+		if flag == nil {
+			return fmt.Errorf("defaults for %s: unknown flag --%s", name, flagName)
+		}
+		if flag.Changed {
+			continue
+		}
+		for _, value := range values {
+			if err := flag.Value.Set(value); err != nil {
+				return fmt.Errorf("defaults for %s: invalid value %q for --%s: %w", name, value, flagName, err)
+			}
+		}
+	}
+	return nil
+}
+
+// useTimestamps switches the log timestamps to RFC3339, in UTC unless
+// localTime is set.
+func useTimestamps(logger *logrus.Logger, localTime bool) {
+	if formatter, ok := logger.Formatter.(*logrus.TextFormatter); ok {
+		formatter.TimestampFormat = time.RFC3339
+	}
+	if !localTime {
+		logger.AddHook(utcHook{})
+	}
+}
+
+// utcHook converts log entry times to UTC before they are formatted.
+type utcHook struct{}
+
+func (utcHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (utcHook) Fire(entry *logrus.Entry) error {
+	entry.Time = entry.Time.UTC()
+	return nil
+}
+
+// confirm asks a yes/no question, defaulting to no.
+func confirm(in io.Reader, w io.Writer, question string) bool {
+	fmt.Fprintf(w, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// confirmDefaultYes asks a yes/no question that is accepted unless the
+// user answers no. The reader is shared so several questions can be asked.
+func confirmDefaultYes(in *bufio.Reader, w io.Writer, question string) bool {
+	fmt.Fprintf(w, "%s [Y/n] ", question)
+	answer, _ := in.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer != "n" && answer != "no"
+}
+
+// enterProject changes into the directory of the named workspace project,
+// so its definition is loaded and its steps run from there.
+func enterProject(name string) error {
+	workspacePath, err := config.FindWorkspace()
+	if err != nil {
+		return err
+	}
+	ws, err := config.LoadWorkspace(workspacePath)
+	if err != nil {
+		return err
+	}
+	dir, err := ws.ProjectDir(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Chdir(dir); err != nil {
+		return fmt.Errorf("failed to enter project %s: %w", name, err)
+	}
+	return nil
 }
 
 func loadConfig(ctx context.Context, path string) (config.ProjectDefinition, error) {
@@ -122,13 +373,7 @@ func loadConfig(ctx context.Context, path string) (config.ProjectDefinition, err
 	logger.WithFields(logrus.Fields{
 		"path": pathToUse,
 	}).Trace("Found config file")
-	file, err := os.Open(pathToUse)
-	if err != nil {
-		return config.ProjectDefinition{}, fmt.Errorf("failed to open config (%s): %w", pathToUse, err)
-	}
-	defer file.Close()
-
-	cfg, err := config.Load(file)
+	cfg, err := config.LoadFile(pathToUse)
 	if err != nil {
 		return config.ProjectDefinition{}, fmt.Errorf("failed to load config (%s): %w", pathToUse, err)
 	}
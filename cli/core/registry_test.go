@@ -0,0 +1,276 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/jgfranco17/devops/cli/config"
+	"github.com/jgfranco17/devops/cli/executor"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDefaultsTestCommand() (*cobra.Command, *cobra.Command) {
+	root := &cobra.Command{Use: "devops"}
+	child := &cobra.Command{Use: "test", RunE: func(cmd *cobra.Command, args []string) error { return nil }}
+	child.Flags().Bool("fail-fast", false, "")
+	child.Flags().StringSlice("tags", nil, "")
+	root.AddCommand(child)
+	return root, child
+}
+
+func TestApplyDefaults(t *testing.T) {
+	defaults := map[string]map[string]config.FlagValue{
+		"test": {
+			"fail-fast": {"true"},
+			"tags":      {"unit", "fast"},
+		},
+		"build": {
+			"unknown": {"x"},
+		},
+	}
+
+	t.Run("fills unset flags", func(t *testing.T) {
+		_, child := newDefaultsTestCommand()
+		require.NoError(t, applyDefaults(child, defaults))
+
+		failFast, _ := child.Flags().GetBool("fail-fast")
+		tags, _ := child.Flags().GetStringSlice("tags")
+		assert.True(t, failFast)
+		assert.Equal(t, []string{"unit", "fast"}, tags)
+	})
+
+	t.Run("command line wins", func(t *testing.T) {
+		_, child := newDefaultsTestCommand()
+		require.NoError(t, child.ParseFlags([]string{"--tags", "integration"}))
+		require.NoError(t, applyDefaults(child, defaults))
+
+		tags, _ := child.Flags().GetStringSlice("tags")
+		assert.Equal(t, []string{"integration"}, tags)
+	})
+
+	t.Run("unknown flag", func(t *testing.T) {
+		root := &cobra.Command{Use: "devops"}
+		build := &cobra.Command{Use: "build"}
+		root.AddCommand(build)
+		assert.EqualError(t, applyDefaults(build, defaults), "defaults for build: unknown flag --unknown")
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		_, child := newDefaultsTestCommand()
+		err := applyDefaults(child, map[string]map[string]config.FlagValue{"test": {"fail-fast": {"maybe"}}})
+		assert.ErrorContains(t, err, `invalid value "maybe" for --fail-fast`)
+	})
+}
+
+func TestConfirm(t *testing.T) {
+	var out strings.Builder
+	assert.True(t, confirm(strings.NewReader("y\n"), &out, "Proceed?"))
+	assert.True(t, confirm(strings.NewReader("YES\n"), &out, "Proceed?"))
+	assert.False(t, confirm(strings.NewReader("\n"), &out, "Proceed?"))
+	assert.False(t, confirm(strings.NewReader(""), &out, "Proceed?"))
+	assert.Equal(t, strings.Repeat("Proceed? [y/N] ", 4), out.String())
+}
+
+func TestUseTimestamps_UTC(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New(&buf, logrus.InfoLevel)
+	useTimestamps(logger, false)
+
+	logger.Info("hello")
+	assert.Regexp(t, `@timestamp="?\d{4}-\d\d-\d\dT\d\d:\d\d:\d\dZ`, buf.String())
+}
+
+func TestEnterProject(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "services", "api"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, config.WorkspaceFile), []byte("projects:\n  api:\n    path: services/api\n"), 0o644))
+	t.Chdir(dir)
+
+	assert.ErrorContains(t, enterProject("web"), "unknown project 'web'")
+	assert.NoError(t, enterProject("api"))
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.Equal(t, "api", filepath.Base(cwd))
+}
+
+func TestCommandRegistry_Execute_LastRun(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	run := func(fail bool) config.LastRun {
+		registry := NewCommandRegistry("devops", "test", "0.0.0")
+		registry.RegisterCommands([]*cobra.Command{{
+			Use:         "check",
+			Annotations: map[string]string{skipDefinitionAnnotation: "true"},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if fail {
+					return errors.New("check failed")
+				}
+				return nil
+			},
+			SilenceUsage:  true,
+			SilenceErrors: true,
+		}})
+		registry.GetMain().SetArgs([]string{"check"})
+		registry.GetMain().SetOut(&bytes.Buffer{})
+		registry.GetMain().SetErr(&bytes.Buffer{})
+		_ = registry.Execute()
+		lastRun, err := config.LoadLastRun()
+		require.NoError(t, err)
+		return lastRun
+	}
+
+	lastRun := run(false)
+	assert.Equal(t, "devops check", lastRun.Command)
+	assert.Equal(t, config.LastRunSuccess, lastRun.Status)
+	assert.Equal(t, 0, lastRun.ExitCode)
+
+	lastRun = run(true)
+	assert.Equal(t, config.LastRunFailure, lastRun.Status)
+	assert.Equal(t, 1, lastRun.ExitCode)
+	assert.Equal(t, "check failed", lastRun.Error)
+}
+
+func TestCommandRegistry_Execute_Lock(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	run := func(args ...string) error {
+		var lockErr error
+		registry := NewCommandRegistry("devops", "test", "0.0.0")
+		registry.RegisterCommands([]*cobra.Command{{
+			Use:         "check",
+			Annotations: map[string]string{skipDefinitionAnnotation: "true", lockAnnotation: "true"},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				contender := config.ProjectDefinition{Lock: config.LockConfig{Timeout: time.Millisecond}}
+				lock, err := contender.AcquireLock(cmd.Context(), "contender")
+				lockErr = err
+				return lock.Release()
+			},
+			SilenceUsage:  true,
+			SilenceErrors: true,
+		}})
+		registry.GetMain().SetArgs(append([]string{"check"}, args...))
+		registry.GetMain().SetOut(&bytes.Buffer{})
+		registry.GetMain().SetErr(&bytes.Buffer{})
+		require.NoError(t, registry.Execute())
+		return lockErr
+	}
+
+	assert.ErrorContains(t, run(), "timed out waiting for the workspace lock held by devops check")
+	assert.NoError(t, run("--no-lock"))
+}
+
+func TestCommandRegistry_Execute_LogDir(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	run := func(args ...string) string {
+		var dir string
+		registry := NewCommandRegistry("devops", "test", "0.0.0")
+		registry.RegisterCommands([]*cobra.Command{{
+			Use:         "check",
+			Annotations: map[string]string{skipDefinitionAnnotation: "true"},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				dir = config.LogDirFromContext(cmd.Context())
+				return nil
+			},
+		}})
+		registry.GetMain().SetArgs(append([]string{"check"}, args...))
+		registry.GetMain().SetOut(&bytes.Buffer{})
+		registry.GetMain().SetErr(&bytes.Buffer{})
+		require.NoError(t, registry.Execute())
+		return dir
+	}
+
+	assert.Empty(t, run())
+	assert.Equal(t, config.LogsDir, run("--log-dir"))
+	assert.Equal(t, "ci-logs", run("--log-dir=ci-logs"))
+}
+
+func TestCommandRegistry_Execute_OutputFormatWithFileCommand(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	require.NoError(t, os.WriteFile(config.DefinitionFile, []byte("id: app\nversion: 1.0.0\n"), 0644))
+
+	registry := NewCommandRegistry("devops", "test", "0.0.0")
+	registry.RegisterCommands([]*cobra.Command{GetManifestCommand()})
+	registry.GetMain().SetArgs([]string{"--output", "json", "manifest", "-o", "build/manifest.json"})
+	registry.GetMain().SetOut(&bytes.Buffer{})
+	registry.GetMain().SetErr(&bytes.Buffer{})
+	require.NoError(t, registry.Execute())
+
+	assert.FileExists(t, "build/manifest.json")
+	assert.NoFileExists(t, "json")
+}
+
+func TestCommandRegistry_Execute_DeprecatedOutputFileFlag(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	require.NoError(t, os.WriteFile(config.DefinitionFile, []byte("id: app\nversion: 1.0.0\n"), 0644))
+
+	run := func(args ...string) string {
+		registry := NewCommandRegistry("devops", "test", "0.0.0")
+		registry.RegisterCommands([]*cobra.Command{GetManifestCommand()})
+		var stderr bytes.Buffer
+		registry.GetMain().SetArgs(args)
+		registry.GetMain().SetOut(&bytes.Buffer{})
+		registry.GetMain().SetErr(&stderr)
+		require.NoError(t, registry.Execute())
+		return stderr.String()
+	}
+
+	assert.Contains(t, run("manifest", "--output", "build/legacy.json"), "use --output-file instead")
+	assert.FileExists(t, "build/legacy.json")
+
+	assert.NotContains(t, run("manifest", "--output", "json", "-o", "build/manifest.json"), "deprecated")
+	assert.FileExists(t, "build/manifest.json")
+	assert.NoFileExists(t, "json")
+}
+
+func TestCommandRegistry_Execute_TraceMasksSandboxSecrets(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("MY_TOKEN", "supersecret123")
+	definition := `id: app
+repo_url: https://github.com/example/app
+secrets:
+  API_TOKEN:
+    env: MY_TOKEN
+codebase:
+  language: go
+  build:
+    sandbox: true
+    steps: ["echo token=$API_TOKEN"]
+`
+	require.NoError(t, os.WriteFile(config.DefinitionFile, []byte(definition), 0644))
+
+	shellExecutor := executor.Chain(&executor.DefaultExecutor{}, executor.Logging(), executor.Masking())
+	registry := NewCommandRegistry("devops", "test", "0.0.0")
+	registry.RegisterCommands([]*cobra.Command{GetBuildCommand(shellExecutor)})
+	var stdout, stderr bytes.Buffer
+	registry.GetMain().SetArgs([]string{"--trace", "-vv", "build"})
+	registry.GetMain().SetOut(&stdout)
+	registry.GetMain().SetErr(&stderr)
+	require.NoError(t, registry.Execute())
+
+	assert.Contains(t, stderr.String(), "[trace] ")
+	assert.Contains(t, stderr.String(), "bash --noprofile --norc -c 'echo token=$API_TOKEN'")
+	assert.NotContains(t, stderr.String(), "supersecret123")
+	assert.NotContains(t, stdout.String(), "supersecret123")
+}
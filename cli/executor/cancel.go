@@ -0,0 +1,60 @@
+package executor
+
+import (
+	"context"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// DefaultGracePeriod is how long a cancelled command has to exit after
+// SIGTERM before it is killed.
+const DefaultGracePeriod = 10 * time.Second
+
+const gracePeriodKey contextKey = "grace-period"
+
+// WithGracePeriod sets how long commands run with the context have to
+// exit after SIGTERM when the context is cancelled, before SIGKILL.
+func WithGracePeriod(ctx context.Context, period time.Duration) context.Context {
+	return context.WithValue(ctx, gracePeriodKey, period)
+}
+
+// GracePeriodFromContext returns the grace period set with
+// WithGracePeriod, or DefaultGracePeriod.
+func GracePeriodFromContext(ctx context.Context) time.Duration {
+	if period, ok := ctx.Value(gracePeriodKey).(time.Duration); ok && period > 0 {
+		return period
+	}
+	return DefaultGracePeriod
+}
+
+// terminateOnCancel runs a command created with exec.CommandContext in
+// its own process group, which receives SIGTERM when the context is done
+// and SIGKILL once the grace period has passed. The returned stop must be
+// called once the command was waited for, so a group ID the system may
+// have reused since is not killed.
+func terminateOnCancel(ctx context.Context, cmd *exec.Cmd) (stop func()) {
+	period := GracePeriodFromContext(ctx)
+	setProcessGroup(cmd)
+	var mu sync.Mutex
+	var kill *time.Timer
+	stopped := false
+	cmd.Cancel = func() error {
+		mu.Lock()
+		if !stopped {
+			kill = time.AfterFunc(period, func() { _ = killGroup(cmd) })
+		}
+		mu.Unlock()
+		return terminateGroup(cmd)
+	}
+	// Leave the group kill time to land before giving up on the output.
+	cmd.WaitDelay = period + time.Second
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		stopped = true
+		if kill != nil {
+			kill.Stop()
+		}
+	}
+}
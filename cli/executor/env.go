@@ -0,0 +1,40 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+)
+
+const envKey contextKey = "env"
+
+// WithEnv sets the full environment of the commands run with the context.
+// Without it, commands inherit the environment of the devops process.
+func WithEnv(ctx context.Context, env []string) context.Context {
+	return context.WithValue(ctx, envKey, env)
+}
+
+// EnvFromContext returns the environment set on the context, or nil.
+func EnvFromContext(ctx context.Context) []string {
+	env, _ := ctx.Value(envKey).([]string)
+	return env
+}
+
+// changedEnv returns the variables of env that are not already set to the
+// same value in the local process environment, sorted.
+func changedEnv(env []string) []string {
+	changed := []string{}
+	for _, kv := range env {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || key == "" {
+			continue
+		}
+		if local, set := os.LookupEnv(key); set && local == value {
+			continue
+		}
+		changed = append(changed, kv)
+	}
+	sort.Strings(changed)
+	return changed
+}
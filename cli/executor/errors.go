@@ -0,0 +1,28 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+)
+
+// InfraError marks a failure caused by the execution environment rather
+// than by the step itself, such as the shell failing to start or the
+// process being killed by the system.
+type InfraError struct {
+	Reason string
+	Err    error
+}
+
+func (e *InfraError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Reason, e.Err)
+}
+
+func (e *InfraError) Unwrap() error {
+	return e.Err
+}
+
+// IsInfraError reports whether err was caused by an infrastructure failure.
+func IsInfraError(err error) bool {
+	var infraErr *InfraError
+	return errors.As(err, &infraErr)
+}
@@ -0,0 +1,47 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsInfraError(t *testing.T) {
+	infraErr := &InfraError{Reason: "failed to start shell", Err: errors.New("exec: not found")}
+
+	assert.True(t, IsInfraError(infraErr))
+	assert.True(t, IsInfraError(fmt.Errorf("wrapped: %w", infraErr)))
+	assert.False(t, IsInfraError(errors.New("exit status 1")))
+	assert.False(t, IsInfraError(nil))
+	assert.Equal(t, "failed to start shell: exec: not found", infraErr.Error())
+	assert.ErrorContains(t, errors.Unwrap(infraErr), "exec: not found")
+}
+
+func TestDefaultExecutor_Exec_InfraFailures(t *testing.T) {
+	executor := &DefaultExecutor{}
+
+	t.Run("killed by SIGKILL", func(t *testing.T) {
+		result, err := executor.Exec(context.Background(), "kill -9 $$")
+		require.Error(t, err)
+		assert.True(t, IsInfraError(err))
+		assert.Equal(t, -1, result.ExitCode)
+	})
+
+	t.Run("step failure is not infrastructure", func(t *testing.T) {
+		_, err := executor.Exec(context.Background(), "exit 3")
+		require.Error(t, err)
+		assert.False(t, IsInfraError(err))
+	})
+
+	t.Run("cancellation is not infrastructure", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := executor.Exec(ctx, "sleep 1")
+		require.Error(t, err)
+		assert.False(t, IsInfraError(err))
+	})
+}
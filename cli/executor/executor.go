@@ -1,18 +1,35 @@
 package executor
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"syscall"
+	"time"
 )
 
 type Result struct {
 	Stdout   string
 	Stderr   string
 	ExitCode int
+
+	StartTime time.Time
+	EndTime   time.Time
+	Duration  time.Duration
+
+	// Output holds stdout and stderr lines in the order they were written.
+	// It is only filled when interleaved output is enabled on the context.
+	Output []OutputLine
+
+	// Truncated is set when Stdout or Stderr hold only the start and end
+	// of the output, as it went past the output limit.
+	Truncated bool
+
+	// Terminal is set when the command ran attached to a terminal. Its
+	// output was already shown there, and is all in Stdout.
+	Terminal bool
 }
 
 func (r *Result) PrintStdOut() {
@@ -27,18 +44,44 @@ func (r *Result) PrintStdErr() {
 	}
 }
 
-type DefaultExecutor struct {
-	Env []string
-}
+// DefaultExecutor runs commands locally with the shell set on the context,
+// bash by default, in the environment set on the context. Each command runs
+// in its own process group: the group gets SIGTERM when the context is
+// cancelled, then SIGKILL after the grace period, and is killed when the
+// command fails.
+type DefaultExecutor struct{}
 
 func (c *DefaultExecutor) Exec(ctx context.Context, command string) (Result, error) {
-	var stdoutBuf, stderrBuf bytes.Buffer
-
-	cmd := exec.CommandContext(ctx, "bash", "-c", command)
-	cmd.Stdout = &stdoutBuf
-	cmd.Stderr = &stderrBuf
+	shell := ShellFromContext(ctx)
+	argv, err := ShellArgs(shell, command)
+	if err != nil {
+		return Result{ExitCode: -1}, err
+	}
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	setCommandLine(cmd, shell, command)
+	cmd.Env = EnvFromContext(ctx)
+	stopKill := terminateOnCancel(ctx, cmd)
+	output := captureOutput(ctx, cmd)
+	term, inTerminal := TerminalFromContext(ctx)
 
-	err := cmd.Run()
+	start := time.Now()
+	if inTerminal {
+		shown := newMaskingWriter(term.Out, SecretsFromContext(ctx))
+		term.Out = shown
+		err = runInTerminal(cmd, term)
+		_ = shown.Flush()
+	} else {
+		err = cmd.Run()
+	}
+	stopKill()
+	if errors.Is(err, exec.ErrWaitDelay) {
+		// The command succeeded, but left a process holding its output.
+		err = nil
+	}
+	if err != nil && cmd.Process != nil {
+		// Take down anything the failed step left running in the background.
+		_ = killGroup(cmd)
+	}
 
 	exitCode := 0
 	if err != nil {
@@ -46,27 +89,30 @@ func (c *DefaultExecutor) Exec(ctx context.Context, command string) (Result, err
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok {
 				exitCode = ws.ExitStatus()
+				// A SIGKILL we did not send ourselves usually means the
+				// system (e.g. the OOM killer) terminated the step.
+				if ws.Signaled() && ws.Signal() == syscall.SIGKILL && ctx.Err() == nil {
+					err = &InfraError{Reason: "process killed by SIGKILL (possibly out of memory)", Err: err}
+				}
 			} else {
 				// Unix-only, fallback
 				exitCode = -1
 			}
 		} else {
-			// Non-exit error, e.g., binary not found
+			// Non-exit error, e.g., binary not found. Without a shell that
+			// binary is the step's own command, so it is the step failing.
 			exitCode = -1
+			if ctx.Err() == nil && shell != ShellNone {
+				err = &InfraError{Reason: "failed to start shell", Err: err}
+			}
 		}
 	}
 
-	return Result{
-		Stdout:   stdoutBuf.String(),
-		Stderr:   stderrBuf.String(),
-		ExitCode: exitCode,
-	}, err
-}
+	trace(ctx, command, start, exitCode)
 
-func (c *DefaultExecutor) AddEnv(envs []string) {
-	baseEnv := os.Environ()
-	if len(envs) > 0 {
-		baseEnv = append(baseEnv, envs...)
+	result := output.result(exitCode, start)
+	if inTerminal {
+		result = terminalResult(result)
 	}
-	c.Env = baseEnv
+	return result, err
 }
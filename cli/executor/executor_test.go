@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestResult_PrintStdOut(t *testing.T) {
@@ -251,88 +252,35 @@ func TestDefaultExecutor_Exec(t *testing.T) {
 
 func TestDefaultExecutor_Exec_WithEnvironment(t *testing.T) {
 	executor := &DefaultExecutor{}
-	executor.AddEnv([]string{"TEST_VAR=test_value", "ANOTHER_VAR=another_value"})
+	ctx := WithEnv(context.Background(), append(os.Environ(), "TEST_VAR=test_value", "ANOTHER_VAR=another_value"))
 
-	// Note: AddEnv only stores the environment variables but doesn't actually set them
-	// for the command execution. The command will use the current process environment.
-	ctx := context.Background()
 	result, err := executor.Exec(ctx, "echo $TEST_VAR $ANOTHER_VAR")
 
 	assert.NoError(t, err)
 	assert.Equal(t, 0, result.ExitCode)
-	// The variables won't be set in the command execution, so we expect empty output
-	assert.Equal(t, "\n", result.Stdout)
+	assert.Equal(t, "test_value another_value\n", result.Stdout)
 }
 
-func TestDefaultExecutor_AddEnv(t *testing.T) {
-	tests := []struct {
-		name        string
-		envVars     []string
-		expectedLen int
-	}{
-		{
-			name:        "add single environment variable",
-			envVars:     []string{"TEST_VAR=value1"},
-			expectedLen: len(os.Environ()) + 1,
-		},
-		{
-			name:        "add multiple environment variables",
-			envVars:     []string{"VAR1=value1", "VAR2=value2", "VAR3=value3"},
-			expectedLen: len(os.Environ()) + 3,
-		},
-		{
-			name:        "add empty environment variables",
-			envVars:     []string{},
-			expectedLen: len(os.Environ()),
-		},
-		{
-			name:        "add nil environment variables",
-			envVars:     nil,
-			expectedLen: len(os.Environ()),
-		},
-		{
-			name:        "add environment variables with special characters",
-			envVars:     []string{"SPECIAL_VAR=!@#$%^&*()", "PATH_VAR=/usr/bin:/bin"},
-			expectedLen: len(os.Environ()) + 2,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			executor := &DefaultExecutor{}
-			executor.AddEnv(tt.envVars)
-
-			assert.Equal(t, tt.expectedLen, len(executor.Env))
+func TestDefaultExecutor_Exec_EnvIsPerCall(t *testing.T) {
+	executor := &DefaultExecutor{}
+	withVar := WithEnv(context.Background(), append(os.Environ(), "TEST_VAR=set"))
 
-			// Verify that the original environment is preserved
-			originalEnv := os.Environ()
-			for _, env := range originalEnv {
-				assert.Contains(t, executor.Env, env)
-			}
+	result, err := executor.Exec(withVar, "echo $TEST_VAR")
+	require.NoError(t, err)
+	assert.Equal(t, "set\n", result.Stdout)
 
-			// Verify that new environment variables are added
-			for _, env := range tt.envVars {
-				assert.Contains(t, executor.Env, env)
-			}
-		})
-	}
+	result, err = executor.Exec(context.Background(), "echo $TEST_VAR")
+	require.NoError(t, err)
+	assert.Equal(t, "\n", result.Stdout)
 }
 
-func TestDefaultExecutor_AddEnv_Overwrite(t *testing.T) {
-	executor := &DefaultExecutor{}
-
-	// Add initial environment variables
-	executor.AddEnv([]string{"TEST_VAR=initial_value"})
+func TestDefaultExecutor_Exec_InheritsProcessEnv(t *testing.T) {
+	t.Setenv("DEVOPS_INHERITED_VAR", "inherited")
 
-	// Add more environment variables (this will replace the entire Env slice)
-	executor.AddEnv([]string{"NEW_VAR=new_value"})
+	result, err := (&DefaultExecutor{}).Exec(context.Background(), "echo $DEVOPS_INHERITED_VAR")
 
-	// Should have original + new (AddEnv replaces the entire slice)
-	expectedLen := len(os.Environ()) + 1
-	assert.Equal(t, expectedLen, len(executor.Env))
-	assert.Contains(t, executor.Env, "NEW_VAR=new_value")
-	// The previous TEST_VAR should not be present as AddEnv replaces the entire slice
-	assert.NotContains(t, executor.Env, "TEST_VAR=initial_value")
+	require.NoError(t, err)
+	assert.Equal(t, "inherited\n", result.Stdout)
 }
 
 func TestDefaultExecutor_Exec_ContextCancellation(t *testing.T) {
@@ -350,8 +298,47 @@ func TestDefaultExecutor_Exec_ContextCancellation(t *testing.T) {
 
 	assert.Error(t, err)
 	assert.Equal(t, -1, result.ExitCode)
-	// The error could be either "context canceled" or "signal: killed" depending on timing
-	assert.True(t, err.Error() == "context canceled" || err.Error() == "signal: killed")
+	// The error could be either "context canceled" or "signal: terminated" depending on timing
+	assert.True(t, err.Error() == "context canceled" || err.Error() == "signal: terminated")
+}
+
+func TestDefaultExecutor_Exec_CancellationTrapsTerm(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	result, _ := (&DefaultExecutor{}).Exec(ctx, "trap 'echo stopping; exit 3' TERM; sleep 5 & wait")
+
+	assert.Less(t, time.Since(start), 2*time.Second)
+	assert.Equal(t, 3, result.ExitCode)
+	assert.Contains(t, result.Stdout, "stopping")
+}
+
+func TestDefaultExecutor_Exec_CancellationKillsAfterGracePeriod(t *testing.T) {
+	ctx, cancel := context.WithCancel(WithGracePeriod(context.Background(), 200*time.Millisecond))
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	_, err := (&DefaultExecutor{}).Exec(ctx, "trap '' TERM; sleep 5")
+
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestDefaultExecutor_Exec_Timing(t *testing.T) {
+	executor := &DefaultExecutor{}
+
+	result, err := executor.Exec(context.Background(), "sleep 0.05")
+
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, result.Duration, 50*time.Millisecond)
+	assert.Equal(t, result.Duration, result.EndTime.Sub(result.StartTime))
 }
 
 func TestDefaultExecutor_Exec_EmptyCommand(t *testing.T) {
@@ -413,13 +400,3 @@ func TestResult_Struct(t *testing.T) {
 	assert.Equal(t, "test error", result.Stderr)
 	assert.Equal(t, 42, result.ExitCode)
 }
-
-func TestDefaultExecutor_Struct(t *testing.T) {
-	// Test DefaultExecutor struct creation
-	executor := &DefaultExecutor{
-		Env: []string{"TEST=value"},
-	}
-
-	assert.Equal(t, []string{"TEST=value"}, executor.Env)
-	assert.NotNil(t, executor)
-}
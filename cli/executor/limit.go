@@ -0,0 +1,105 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// DefaultOutputLimit is how much of each output stream is kept in memory
+// when no limit is set on the context.
+const DefaultOutputLimit = 16 << 20
+
+const (
+	outputLimitKey contextKey = "output-limit"
+	outputSpillKey contextKey = "output-spill"
+)
+
+// WithOutputLimit caps how many bytes of each output stream executors keep
+// in the result. Past the limit the first and last halves are kept, with a
+// marker in between. A limit of zero or less keeps everything.
+func WithOutputLimit(ctx context.Context, limit int) context.Context {
+	return context.WithValue(ctx, outputLimitKey, limit)
+}
+
+// OutputLimitFromContext returns the limit set with WithOutputLimit, or
+// DefaultOutputLimit.
+func OutputLimitFromContext(ctx context.Context) int {
+	if limit, ok := ctx.Value(outputLimitKey).(int); ok {
+		return limit
+	}
+	return DefaultOutputLimit
+}
+
+// WithOutputSpill makes executors also write the full, untruncated output
+// of commands to w, stdout and stderr together in the order written, with
+// the secrets on the context masked. Failing writes to w are ignored rather than failing the command.
+func WithOutputSpill(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, outputSpillKey, &spillWriter{w: w})
+}
+
+func outputSpillFromContext(ctx context.Context) (io.Writer, bool) {
+	w, ok := ctx.Value(outputSpillKey).(*spillWriter)
+	return w, ok
+}
+
+// spillWriter serializes writes from both output streams and swallows
+// errors, so a full disk does not break the command's pipes.
+type spillWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *spillWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, _ = s.w.Write(p)
+	return len(p), nil
+}
+
+// truncationMarker stands in for the output dropped from the middle of a
+// stream.
+func truncationMarker(dropped int64, unit string) string {
+	return fmt.Sprintf("... [%d %s truncated] ...", dropped, unit)
+}
+
+// cappedBuffer keeps the first and last halves of up to max bytes written
+// to it. With max zero or less it keeps everything.
+type cappedBuffer struct {
+	max     int
+	head    []byte
+	tail    []byte
+	dropped int64
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if b.max <= 0 {
+		b.head = append(b.head, p...)
+		return n, nil
+	}
+	if room := b.max/2 - len(b.head); room > 0 {
+		take := min(room, len(p))
+		b.head = append(b.head, p[:take]...)
+		p = p[take:]
+	}
+	b.tail = append(b.tail, p...)
+	if over := len(b.tail) - (b.max - b.max/2); over > 0 {
+		b.dropped += int64(over)
+		b.tail = b.tail[over:]
+	}
+	return n, nil
+}
+
+// Truncated reports whether any output was dropped.
+func (b *cappedBuffer) Truncated() bool {
+	return b.dropped > 0
+}
+
+func (b *cappedBuffer) String() string {
+	if b.dropped == 0 {
+		return string(b.head) + string(b.tail)
+	}
+	return string(b.head) + "\n" + truncationMarker(b.dropped, "bytes") + "\n" + string(b.tail)
+}
@@ -0,0 +1,73 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCappedBuffer(t *testing.T) {
+	b := &cappedBuffer{max: 8}
+	_, _ = b.Write([]byte("abc"))
+	assert.Equal(t, "abc", b.String())
+	assert.False(t, b.Truncated())
+
+	_, _ = b.Write([]byte("defgh"))
+	assert.Equal(t, "abcdefgh", b.String())
+	assert.False(t, b.Truncated())
+
+	_, _ = b.Write([]byte("ijklmnop"))
+	assert.Equal(t, "abcd\n... [8 bytes truncated] ...\nmnop", b.String())
+	assert.True(t, b.Truncated())
+
+	unlimited := &cappedBuffer{}
+	_, _ = unlimited.Write([]byte(strings.Repeat("x", 100)))
+	assert.Len(t, unlimited.String(), 100)
+}
+
+func TestLineCapture_Limit(t *testing.T) {
+	capture := &lineCapture{max: 8}
+	for _, line := range []string{"one", "two", "three", "four", "five"} {
+		capture.add(StreamStdout, line)
+	}
+	assert.Equal(t, []OutputLine{
+		{Stream: StreamStdout, Text: "one"},
+		{Stream: StreamStdout, Text: "... [3 lines truncated] ..."},
+		{Stream: StreamStdout, Text: "five"},
+	}, capture.all())
+}
+
+func TestDefaultExecutor_Exec_OutputLimit(t *testing.T) {
+	var spill bytes.Buffer
+	ctx := WithOutputSpill(WithOutputLimit(context.Background(), 20), &spill)
+	result, err := (&DefaultExecutor{}).Exec(ctx, "seq 1 100; echo oops >&2")
+
+	require.NoError(t, err)
+	assert.True(t, result.Truncated)
+	assert.True(t, strings.HasPrefix(result.Stdout, "1\n2\n3\n4\n5\n"))
+	assert.Contains(t, result.Stdout, "bytes truncated")
+	assert.True(t, strings.HasSuffix(result.Stdout, "\n99\n100\n"))
+	assert.Equal(t, "oops\n", result.Stderr)
+
+	assert.Contains(t, spill.String(), "\n50\n51\n")
+	assert.Contains(t, spill.String(), "oops\n")
+}
+
+func TestDefaultExecutor_Exec_OutputSpillMasksSecrets(t *testing.T) {
+	var spill bytes.Buffer
+	ctx := WithOutputSpill(WithSecrets(context.Background(), "supersecret123"), &spill)
+	_, err := (&DefaultExecutor{}).Exec(ctx, "echo token=supersecret123; printf supersecret >&2; printf 123 >&2")
+
+	require.NoError(t, err)
+	assert.Contains(t, spill.String(), "token=***\n")
+	assert.NotContains(t, spill.String(), "supersecret")
+}
+
+func TestOutputLimitFromContext(t *testing.T) {
+	assert.Equal(t, DefaultOutputLimit, OutputLimitFromContext(context.Background()))
+	assert.Equal(t, 0, OutputLimitFromContext(WithOutputLimit(context.Background(), 0)))
+}
@@ -0,0 +1,115 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/sirupsen/logrus"
+)
+
+// Executor runs shell commands on behalf of operations.
+type Executor interface {
+	Exec(ctx context.Context, command string) (Result, error)
+}
+
+// ExecFunc is the Exec step a middleware wraps.
+type ExecFunc func(ctx context.Context, command string) (Result, error)
+
+// Middleware wraps the Exec of the next executor in the chain to add a
+// cross-cutting concern such as logging or recording.
+type Middleware func(next ExecFunc) ExecFunc
+
+// Chained is an executor whose commands pass through a chain of
+// middlewares before reaching the base executor.
+type Chained struct {
+	base        Executor
+	middlewares []Middleware
+	exec        ExecFunc
+}
+
+// Chain wraps base with the given middlewares. The first middleware is the
+// outermost and sees each command first.
+func Chain(base Executor, middlewares ...Middleware) *Chained {
+	exec := base.Exec
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		exec = middlewares[i](exec)
+	}
+	return &Chained{base: base, middlewares: middlewares, exec: exec}
+}
+
+// WithBase returns the same middleware chain around a different base
+// executor, e.g. to run an operation remotely.
+func (c *Chained) WithBase(base Executor) *Chained {
+	return Chain(base, c.middlewares...)
+}
+
+func (c *Chained) Exec(ctx context.Context, command string) (Result, error) {
+	return c.exec(ctx, command)
+}
+
+// Logging logs every command at debug level along with its outcome, with
+// the secrets on the context masked.
+func Logging() Middleware {
+	return func(next ExecFunc) ExecFunc {
+		return func(ctx context.Context, command string) (Result, error) {
+			logger := logging.FromContext(ctx)
+			start := time.Now()
+			shown := Mask(command, SecretsFromContext(ctx))
+			logger.WithField("command", shown).Debug("Executing command")
+			result, err := next(ctx, command)
+			logger.WithFields(logrus.Fields{
+				"command":   shown,
+				"exit_code": result.ExitCode,
+				"duration":  time.Since(start),
+			}).Debug("Command finished")
+			return result, err
+		}
+	}
+}
+
+// DryRun prints each command to w instead of running it.
+func DryRun(w io.Writer) Middleware {
+	return func(next ExecFunc) ExecFunc {
+		return func(ctx context.Context, command string) (Result, error) {
+			_, _ = fmt.Fprintf(w, "[dry-run] %s\n", Mask(command, SecretsFromContext(ctx)))
+			return Result{}, nil
+		}
+	}
+}
+
+// Recording is a command and the result it produced.
+type Recording struct {
+	Command string
+	Result  Result
+	Err     error
+}
+
+// Recorder keeps every command passing through its middleware.
+type Recorder struct {
+	mu         sync.Mutex
+	recordings []Recording
+}
+
+// Middleware returns the middleware feeding the recorder.
+func (r *Recorder) Middleware() Middleware {
+	return func(next ExecFunc) ExecFunc {
+		return func(ctx context.Context, command string) (Result, error) {
+			result, err := next(ctx, command)
+			r.mu.Lock()
+			r.recordings = append(r.recordings, Recording{Command: command, Result: result, Err: err})
+			r.mu.Unlock()
+			return result, err
+		}
+	}
+}
+
+// Recordings returns a copy of everything recorded so far.
+func (r *Recorder) Recordings() []Recording {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Recording(nil), r.recordings...)
+}
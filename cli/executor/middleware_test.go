@@ -0,0 +1,110 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeExecutor returns canned results and remembers what it was given.
+type fakeExecutor struct {
+	commands []string
+	result   Result
+	err      error
+}
+
+func (f *fakeExecutor) Exec(ctx context.Context, command string) (Result, error) {
+	f.commands = append(f.commands, command)
+	return f.result, f.err
+}
+
+func tagging(tag string, order *[]string) Middleware {
+	return func(next ExecFunc) ExecFunc {
+		return func(ctx context.Context, command string) (Result, error) {
+			*order = append(*order, tag)
+			return next(ctx, command+" "+tag)
+		}
+	}
+}
+
+func TestChain(t *testing.T) {
+	base := &fakeExecutor{result: Result{Stdout: "ok"}}
+	order := []string{}
+	chained := Chain(base, tagging("outer", &order), tagging("inner", &order))
+
+	result, err := chained.Exec(context.Background(), "make")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", result.Stdout)
+	assert.Equal(t, []string{"outer", "inner"}, order)
+	assert.Equal(t, []string{"make outer inner"}, base.commands)
+}
+
+func TestChained_WithBase(t *testing.T) {
+	local := &fakeExecutor{}
+	remote := &fakeExecutor{}
+	order := []string{}
+	chained := Chain(local, tagging("mw", &order)).WithBase(remote)
+
+	_, err := chained.Exec(context.Background(), "make")
+	require.NoError(t, err)
+	assert.Empty(t, local.commands)
+	assert.Equal(t, []string{"make mw"}, remote.commands)
+}
+
+func TestLogging(t *testing.T) {
+	var logs bytes.Buffer
+	ctx := logging.WithContext(context.Background(), logging.New(&logs, logrus.DebugLevel))
+	base := &fakeExecutor{result: Result{ExitCode: 2}, err: errors.New("exit status 2")}
+
+	_, err := Chain(base, Logging()).Exec(ctx, "make lint")
+	assert.Error(t, err)
+	assert.Contains(t, logs.String(), "Executing command")
+	assert.Contains(t, logs.String(), "Command finished")
+	assert.Contains(t, logs.String(), "exit_code=2")
+}
+
+func TestLogging_MasksSecrets(t *testing.T) {
+	var logs bytes.Buffer
+	ctx := logging.WithContext(context.Background(), logging.New(&logs, logrus.DebugLevel))
+	ctx = WithSecrets(ctx, "hunter2")
+	base := &fakeExecutor{}
+
+	_, err := Chain(base, Logging()).Exec(ctx, "login --password hunter2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"login --password hunter2"}, base.commands)
+	assert.Contains(t, logs.String(), "login --password ***")
+	assert.NotContains(t, logs.String(), "hunter2")
+}
+
+func TestDryRun(t *testing.T) {
+	var out bytes.Buffer
+	base := &fakeExecutor{err: errors.New("must not run")}
+
+	result, err := Chain(base, DryRun(&out)).Exec(context.Background(), "rm -rf build")
+	require.NoError(t, err)
+	assert.Equal(t, Result{}, result)
+	assert.Empty(t, base.commands)
+	assert.Equal(t, "[dry-run] rm -rf build\n", out.String())
+}
+
+func TestRecorder(t *testing.T) {
+	recorder := &Recorder{}
+	base := &fakeExecutor{result: Result{Stdout: "done"}}
+	chained := Chain(base, recorder.Middleware())
+
+	_, _ = chained.Exec(context.Background(), "make")
+	base.err = errors.New("boom")
+	_, _ = chained.Exec(context.Background(), "make test")
+
+	recordings := recorder.Recordings()
+	require.Len(t, recordings, 2)
+	assert.Equal(t, Recording{Command: "make", Result: Result{Stdout: "done"}}, recordings[0])
+	assert.Equal(t, "make test", recordings[1].Command)
+	assert.EqualError(t, recordings[1].Err, "boom")
+}
@@ -0,0 +1,184 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Output streams a line can come from.
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+const interleaveKey contextKey = "interleave"
+
+// OutputLine is a single line of command output tagged with its stream.
+type OutputLine struct {
+	Stream string
+	Text   string
+}
+
+// WithInterleavedOutput makes executors also capture stdout and stderr as
+// one list of lines in the order they were written.
+func WithInterleavedOutput(ctx context.Context) context.Context {
+	return context.WithValue(ctx, interleaveKey, true)
+}
+
+func interleavedOutput(ctx context.Context) bool {
+	enabled, _ := ctx.Value(interleaveKey).(bool)
+	return enabled
+}
+
+// CombinedOutput renders interleaved output with stderr lines marked, so
+// errors read next to the output around them.
+func (r *Result) CombinedOutput() string {
+	lines := make([]string, 0, len(r.Output))
+	for _, line := range r.Output {
+		if line.Stream == StreamStderr {
+			lines = append(lines, "[stderr] "+line.Text)
+			continue
+		}
+		lines = append(lines, line.Text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lineCapture collects lines from several streams into a single ordered
+// list. Past max bytes, like cappedBuffer, it keeps the first and last
+// lines of about half of max each.
+type lineCapture struct {
+	mu      sync.Mutex
+	max     int
+	lines   []OutputLine
+	size    int
+	tail    []OutputLine
+	tailLen int
+	dropped int64
+}
+
+func (c *lineCapture) writer(stream string) *lineWriter {
+	return &lineWriter{capture: c, stream: stream}
+}
+
+func (c *lineCapture) add(stream string, text string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	line := OutputLine{Stream: stream, Text: text}
+	if c.max <= 0 || (len(c.tail) == 0 && c.size+len(text) <= c.max/2) {
+		c.lines = append(c.lines, line)
+		c.size += len(text)
+		return
+	}
+	c.tail = append(c.tail, line)
+	c.tailLen += len(text)
+	for len(c.tail) > 1 && c.tailLen > c.max-c.max/2 {
+		c.tailLen -= len(c.tail[0].Text)
+		c.tail = c.tail[1:]
+		c.dropped++
+	}
+}
+
+// all returns the kept lines, with a marker line where lines were dropped.
+func (c *lineCapture) all() []OutputLine {
+	if c.dropped == 0 {
+		return append(c.lines, c.tail...)
+	}
+	marker := OutputLine{Stream: StreamStdout, Text: truncationMarker(c.dropped, "lines")}
+	return append(append(c.lines, marker), c.tail...)
+}
+
+// lineWriter splits written bytes into lines for a lineCapture.
+type lineWriter struct {
+	capture *lineCapture
+	stream  string
+	partial []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	data := append(w.partial, p...)
+	for {
+		idx := bytes.IndexByte(data, '\n')
+		if idx < 0 {
+			break
+		}
+		w.capture.add(w.stream, strings.TrimSuffix(string(data[:idx]), "\r"))
+		data = data[idx+1:]
+	}
+	w.partial = append([]byte(nil), data...)
+	if limit := w.capture.max; limit > 0 && len(w.partial) > limit {
+		// A line this long is split rather than held in memory whole.
+		w.flush()
+	}
+	return len(p), nil
+}
+
+// flush records a trailing line that did not end with a newline.
+func (w *lineWriter) flush() {
+	if len(w.partial) > 0 {
+		w.capture.add(w.stream, strings.TrimSuffix(string(w.partial), "\r"))
+		w.partial = nil
+	}
+}
+
+// outputCapture collects the output of a command into a Result.
+type outputCapture struct {
+	stdout   cappedBuffer
+	stderr   cappedBuffer
+	lines    *lineCapture
+	outLines *lineWriter
+	errLines *lineWriter
+	spills   []*maskingWriter
+}
+
+// captureOutput wires the command's stdout and stderr to buffers capped at
+// the output limit, plus an ordered line capture when interleaved output is
+// enabled, and the spill writer when one is set. Spilled output has the
+// secrets on the context masked, as it is written out before the Masking
+// middleware runs.
+func captureOutput(ctx context.Context, cmd *exec.Cmd) *outputCapture {
+	limit := OutputLimitFromContext(ctx)
+	c := &outputCapture{stdout: cappedBuffer{max: limit}, stderr: cappedBuffer{max: limit}}
+	stdout, stderr := []io.Writer{&c.stdout}, []io.Writer{&c.stderr}
+	if interleavedOutput(ctx) {
+		c.lines = &lineCapture{max: limit}
+		c.outLines, c.errLines = c.lines.writer(StreamStdout), c.lines.writer(StreamStderr)
+		stdout, stderr = append(stdout, c.outLines), append(stderr, c.errLines)
+	}
+	if spill, ok := outputSpillFromContext(ctx); ok {
+		secrets := SecretsFromContext(ctx)
+		c.spills = []*maskingWriter{newMaskingWriter(spill, secrets), newMaskingWriter(spill, secrets)}
+		stdout, stderr = append(stdout, c.spills[0]), append(stderr, c.spills[1])
+	}
+	cmd.Stdout = io.MultiWriter(stdout...)
+	cmd.Stderr = io.MultiWriter(stderr...)
+	return c
+}
+
+// result builds the Result for a finished command started at start.
+func (c *outputCapture) result(exitCode int, start time.Time) Result {
+	for _, spill := range c.spills {
+		_ = spill.Flush()
+	}
+	end := time.Now()
+	result := Result{
+		Stdout:    c.stdout.String(),
+		Stderr:    c.stderr.String(),
+		ExitCode:  exitCode,
+		StartTime: start,
+		EndTime:   end,
+		Duration:  end.Sub(start),
+		Truncated: c.stdout.Truncated() || c.stderr.Truncated(),
+	}
+	if c.lines != nil {
+		c.outLines.flush()
+		c.errLines.flush()
+		result.Output = c.lines.all()
+	}
+	return result
+}
@@ -0,0 +1,45 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultExecutor_InterleavedOutput(t *testing.T) {
+	ctx := WithInterleavedOutput(context.Background())
+	command := `echo first; sleep 0.05; echo oops >&2; sleep 0.05; printf 'last'`
+
+	result, err := (&DefaultExecutor{}).Exec(ctx, command)
+	require.NoError(t, err)
+	assert.Equal(t, []OutputLine{
+		{Stream: StreamStdout, Text: "first"},
+		{Stream: StreamStderr, Text: "oops"},
+		{Stream: StreamStdout, Text: "last"},
+	}, result.Output)
+	assert.Equal(t, "first\nlast", result.Stdout)
+	assert.Equal(t, "oops\n", result.Stderr)
+	assert.Equal(t, "first\n[stderr] oops\nlast", result.CombinedOutput())
+}
+
+func TestDefaultExecutor_OutputNotInterleavedByDefault(t *testing.T) {
+	result, err := (&DefaultExecutor{}).Exec(context.Background(), "echo out; echo err >&2")
+	require.NoError(t, err)
+	assert.Nil(t, result.Output)
+	assert.Equal(t, "out\n", result.Stdout)
+}
+
+func TestLineWriter(t *testing.T) {
+	capture := &lineCapture{}
+	w := capture.writer(StreamStdout)
+	_, _ = w.Write([]byte("par"))
+	_, _ = w.Write([]byte("tial\r\nnext\nend\r"))
+	w.flush()
+	assert.Equal(t, []OutputLine{
+		{Stream: StreamStdout, Text: "partial"},
+		{Stream: StreamStdout, Text: "next"},
+		{Stream: StreamStdout, Text: "end"},
+	}, capture.lines)
+}
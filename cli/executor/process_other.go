@@ -0,0 +1,19 @@
+//go:build !unix && !windows
+
+package executor
+
+import "os/exec"
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// terminateGroup kills the command outright, as there is no portable
+// way to ask it to stop.
+func terminateGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+func killGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+func setCommandLine(cmd *exec.Cmd, shell string, command string) {}
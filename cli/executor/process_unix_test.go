@@ -0,0 +1,87 @@
+//go:build unix
+
+package executor
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// running reports whether pid is alive, counting zombies as gone.
+func running(pid int) bool {
+	if syscall.Kill(pid, 0) != nil {
+		return false
+	}
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return true
+	}
+	fields := strings.Fields(string(stat))
+	return len(fields) < 3 || fields[2] != "Z"
+}
+
+func backgroundPid(t *testing.T, result Result) int {
+	pid, err := strconv.Atoi(strings.TrimSpace(result.Stdout))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = syscall.Kill(pid, syscall.SIGKILL) })
+	return pid
+}
+
+func TestDefaultExecutor_Exec_KillsBackgroundProcessesOnFailure(t *testing.T) {
+	result, err := (&DefaultExecutor{}).Exec(context.Background(), "sleep 30 >/dev/null 2>&1 & echo $!; exit 1")
+
+	require.Error(t, err)
+	pid := backgroundPid(t, result)
+	assert.Eventually(t, func() bool { return !running(pid) }, time.Second, 10*time.Millisecond)
+}
+
+func TestDefaultExecutor_Exec_KillsBackgroundProcessesOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	result, err := (&DefaultExecutor{}).Exec(ctx, "sleep 30 >/dev/null 2>&1 & echo $!; wait")
+
+	require.Error(t, err)
+	pid := backgroundPid(t, result)
+	assert.Eventually(t, func() bool { return !running(pid) }, time.Second, 10*time.Millisecond)
+}
+
+func TestDefaultExecutor_Exec_KeepsBackgroundProcessesOnSuccess(t *testing.T) {
+	result, err := (&DefaultExecutor{}).Exec(context.Background(), "sleep 30 >/dev/null 2>&1 & echo $!")
+
+	require.NoError(t, err)
+	assert.True(t, running(backgroundPid(t, result)))
+}
+
+func TestTerminateOnCancel_NoKillAfterExit(t *testing.T) {
+	ctx, cancel := context.WithCancel(WithGracePeriod(context.Background(), 100*time.Millisecond))
+	cmd := exec.CommandContext(ctx, "sh", "-c", `trap "exit 0" TERM; sleep 5 & wait`)
+	stop := terminateOnCancel(ctx, cmd)
+	require.NoError(t, cmd.Start())
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	_ = cmd.Wait()
+	stop()
+
+	// Stand in for a process that got the exited group's ID.
+	other := exec.Command("sleep", "5")
+	setProcessGroup(other)
+	require.NoError(t, other.Start())
+	t.Cleanup(func() { _ = other.Process.Kill(); _ = other.Wait() })
+	cmd.Process = other.Process
+
+	time.Sleep(300 * time.Millisecond)
+	assert.True(t, running(other.Process.Pid))
+}
@@ -0,0 +1,30 @@
+//go:build windows
+
+package executor
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// terminateGroup kills the command outright, as Windows has no signal
+// asking it to stop.
+func terminateGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+func killGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// setCommandLine passes a cmd.exe command through verbatim, as cmd.exe
+// parses its own command line and does not follow the quoting rules Go
+// applies to arguments.
+func setCommandLine(cmd *exec.Cmd, shell string, command string) {
+	if shell == ShellCmd {
+		cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: fmt.Sprintf(`cmd /d /s /c "%s"`, command)}
+	}
+}
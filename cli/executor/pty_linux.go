@@ -0,0 +1,121 @@
+//go:build linux
+
+package executor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+func ioctl(fd uintptr, request uintptr, arg unsafe.Pointer) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// control runs an ioctl on f without switching it to blocking mode, as
+// calling Fd would.
+func control(f *os.File, request uintptr, arg unsafe.Pointer) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var ioctlErr error
+	if err := conn.Control(func(fd uintptr) { ioctlErr = ioctl(fd, request, arg) }); err != nil {
+		return err
+	}
+	return ioctlErr
+}
+
+// openPTY opens a new pseudo-terminal pair, sized like in when it is a
+// terminal.
+func openPTY(in *os.File) (master *os.File, slave *os.File, err error) {
+	master, err = os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open pseudo-terminal: %w", err)
+	}
+	var unlock int32
+	var index uint32
+	if err = control(master, syscall.TIOCSPTLCK, unsafe.Pointer(&unlock)); err == nil {
+		err = control(master, syscall.TIOCGPTN, unsafe.Pointer(&index))
+	}
+	if err == nil {
+		slave, err = os.OpenFile("/dev/pts/"+strconv.Itoa(int(index)), os.O_RDWR|syscall.O_NOCTTY, 0)
+	}
+	if err != nil {
+		_ = master.Close()
+		return nil, nil, fmt.Errorf("failed to open pseudo-terminal: %w", err)
+	}
+
+	if in != nil {
+		var size [4]uint16
+		if control(in, syscall.TIOCGWINSZ, unsafe.Pointer(&size)) == nil {
+			_ = control(master, syscall.TIOCSWINSZ, unsafe.Pointer(&size))
+		}
+	}
+	return master, slave, nil
+}
+
+// setControllingTerminal starts the command in a new session with its
+// stdin as controlling terminal. The session leader also leads the
+// process group, so group signals still reach everything it spawns.
+func setControllingTerminal(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
+}
+
+// makeRaw switches the terminal f to raw mode and returns a func restoring
+// its previous mode. It reports false if f is not a terminal.
+func makeRaw(f *os.File) (restore func(), ok bool) {
+	var saved syscall.Termios
+	if control(f, syscall.TCGETS, unsafe.Pointer(&saved)) != nil {
+		return nil, false
+	}
+	raw := saved
+	raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	raw.Oflag &^= syscall.OPOST
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if control(f, syscall.TCSETS, unsafe.Pointer(&raw)) != nil {
+		return nil, false
+	}
+	return func() { _ = control(f, syscall.TCSETS, unsafe.Pointer(&saved)) }, true
+}
+
+// pollableInput returns a non-blocking duplicate of f whose reads can be
+// interrupted with a deadline, and a func closing it that puts f back in
+// blocking mode.
+func pollableInput(f *os.File) (*os.File, func(), bool) {
+	var fd int
+	var err error
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return nil, nil, false
+	}
+	if controlErr := conn.Control(func(orig uintptr) { fd, err = syscall.Dup(int(orig)) }); controlErr != nil || err != nil {
+		return nil, nil, false
+	}
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		_ = syscall.Close(fd)
+		return nil, nil, false
+	}
+	dup := os.NewFile(uintptr(fd), f.Name())
+	release := func() {
+		_ = dup.Close()
+		// The duplicate shares f's file status flags.
+		_ = conn.Control(func(orig uintptr) { _ = syscall.SetNonblock(int(orig), false) })
+	}
+	if err := dup.SetReadDeadline(time.Time{}); err != nil {
+		release()
+		return nil, nil, false
+	}
+	return dup, release, true
+}
@@ -0,0 +1,24 @@
+//go:build !linux
+
+package executor
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+func openPTY(in *os.File) (*os.File, *os.File, error) {
+	return nil, nil, errors.New("pseudo-terminals are not supported on " + runtime.GOOS)
+}
+
+func setControllingTerminal(cmd *exec.Cmd) {}
+
+func makeRaw(f *os.File) (func(), bool) {
+	return nil, false
+}
+
+func pollableInput(f *os.File) (*os.File, func(), bool) {
+	return nil, nil, false
+}
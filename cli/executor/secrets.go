@@ -0,0 +1,116 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+)
+
+const secretsKey contextKey = "secrets"
+
+// maskedValue replaces secret values in command output.
+const maskedValue = "***"
+
+// WithSecrets adds values that are masked from the output of every command
+// run through the Masking middleware.
+func WithSecrets(ctx context.Context, values ...string) context.Context {
+	secrets := append(SecretsFromContext(ctx), values...)
+	return context.WithValue(ctx, secretsKey, secrets)
+}
+
+// SecretsFromContext returns the secret values on the context.
+func SecretsFromContext(ctx context.Context) []string {
+	secrets, _ := ctx.Value(secretsKey).([]string)
+	return append([]string(nil), secrets...)
+}
+
+// Mask replaces every secret value in text. Longer values are replaced
+// first so a secret containing another is fully masked.
+func Mask(text string, secrets []string) string {
+	sorted := append([]string(nil), secrets...)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	for _, secret := range sorted {
+		if secret == "" {
+			continue
+		}
+		text = strings.ReplaceAll(text, secret, maskedValue)
+	}
+	return text
+}
+
+// Masking redacts the secrets on the context from the stdout and stderr of
+// every command before anything else can print them.
+func Masking() Middleware {
+	return func(next ExecFunc) ExecFunc {
+		return func(ctx context.Context, command string) (Result, error) {
+			result, err := next(ctx, command)
+			secrets := SecretsFromContext(ctx)
+			if len(secrets) == 0 {
+				return result, err
+			}
+			result.Stdout = Mask(result.Stdout, secrets)
+			result.Stderr = Mask(result.Stderr, secrets)
+			if result.Output != nil {
+				lines := make([]OutputLine, len(result.Output))
+				for i, line := range result.Output {
+					lines[i] = OutputLine{Stream: line.Stream, Text: Mask(line.Text, secrets)}
+				}
+				result.Output = lines
+			}
+			return result, err
+		}
+	}
+}
+
+// maskingWriter masks secrets in output streamed to w, for output that is
+// shown or stored before the Masking middleware sees the result. The end
+// of a write that could be the start of a secret is held back until the
+// next write tells, or until Flush.
+type maskingWriter struct {
+	w       io.Writer
+	secrets []string
+	pending string
+}
+
+func newMaskingWriter(w io.Writer, secrets []string) *maskingWriter {
+	return &maskingWriter{w: w, secrets: secrets}
+}
+
+func (m *maskingWriter) Write(p []byte) (int, error) {
+	if len(m.secrets) == 0 {
+		return m.w.Write(p)
+	}
+	text := Mask(m.pending+string(p), m.secrets)
+	held := m.secretPrefixLen(text)
+	m.pending = text[len(text)-held:]
+	if _, err := io.WriteString(m.w, text[:len(text)-held]); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes out what is held back.
+func (m *maskingWriter) Flush() error {
+	if m.pending == "" {
+		return nil
+	}
+	_, err := io.WriteString(m.w, m.pending)
+	m.pending = ""
+	return err
+}
+
+// secretPrefixLen returns the length of the longest end of text that is
+// the start of a secret.
+func (m *maskingWriter) secretPrefixLen(text string) int {
+	longest := 0
+	for _, secret := range m.secrets {
+		for n := min(len(secret)-1, len(text)); n > longest; n-- {
+			if strings.HasSuffix(text, secret[:n]) {
+				longest = n
+				break
+			}
+		}
+	}
+	return longest
+}
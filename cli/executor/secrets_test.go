@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		secrets  []string
+		expected string
+	}{
+		{
+			name:     "replaces every occurrence",
+			text:     "token=abc123 again abc123",
+			secrets:  []string{"abc123"},
+			expected: "token=*** again ***",
+		},
+		{
+			name:     "longer secrets are masked first",
+			text:     "key=abc123xyz",
+			secrets:  []string{"abc", "abc123xyz"},
+			expected: "key=***",
+		},
+		{
+			name:     "empty secrets are ignored",
+			text:     "nothing to hide",
+			secrets:  []string{""},
+			expected: "nothing to hide",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Mask(tt.text, tt.secrets))
+		})
+	}
+}
+
+func TestWithSecrets_Accumulates(t *testing.T) {
+	ctx := WithSecrets(context.Background(), "one")
+	ctx = WithSecrets(ctx, "two")
+
+	assert.Equal(t, []string{"one", "two"}, SecretsFromContext(ctx))
+	assert.Empty(t, SecretsFromContext(context.Background()))
+}
+
+func TestMasking(t *testing.T) {
+	base := &fakeExecutor{result: Result{
+		Stdout: "deploying with s3cr3t",
+		Stderr: "warning: s3cr3t is visible",
+		Output: []OutputLine{{Stream: StreamStdout, Text: "token s3cr3t"}},
+	}}
+	chained := Chain(base, Masking())
+
+	result, err := chained.Exec(WithSecrets(context.Background(), "s3cr3t"), "echo $TOKEN")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"echo $TOKEN"}, base.commands)
+	assert.Equal(t, "deploying with ***", result.Stdout)
+	assert.Equal(t, "warning: *** is visible", result.Stderr)
+	assert.Equal(t, "token ***", result.Output[0].Text)
+	assert.Equal(t, "token s3cr3t", base.result.Output[0].Text)
+}
+
+func TestMasking_NoSecrets(t *testing.T) {
+	base := &fakeExecutor{result: Result{Stdout: "plain output"}}
+
+	result, err := Chain(base, Masking()).Exec(context.Background(), "echo")
+
+	require.NoError(t, err)
+	assert.Equal(t, "plain output", result.Stdout)
+}
+
+func TestMaskingWriter(t *testing.T) {
+	var out strings.Builder
+	w := newMaskingWriter(&out, []string{"s3cr3t", "abc"})
+	for _, chunk := range []string{"token=s3", "cr", "3t and s3", "cret, ab", "c!\n", "trailing s3c"} {
+		_, err := w.Write([]byte(chunk))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, "token=*** and s3cret, ***!\ntrailing ", out.String())
+
+	require.NoError(t, w.Flush())
+	assert.Equal(t, "token=*** and s3cret, ***!\ntrailing s3c", out.String())
+}
@@ -0,0 +1,170 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Shells that commands can be run with. ShellNone runs the command as an
+// argument list, split like a shell would but without any expansion.
+const (
+	ShellBash       = "bash"
+	ShellSh         = "sh"
+	ShellZsh        = "zsh"
+	ShellPwsh       = "pwsh"
+	ShellPowershell = "powershell"
+	ShellCmd        = "cmd"
+	ShellNone       = "none"
+)
+
+// Shells lists the supported shells.
+var Shells = []string{ShellBash, ShellSh, ShellZsh, ShellPwsh, ShellPowershell, ShellCmd, ShellNone}
+
+// hostShell is the default shell of this machine, looked up once.
+var hostShell = sync.OnceValue(func() string {
+	return defaultShell(runtime.GOOS, exec.LookPath)
+})
+
+// DefaultShell returns the shell commands run with when none is set: bash,
+// or on Windows PowerShell, preferring PowerShell 7 when it is installed.
+func DefaultShell() string {
+	return hostShell()
+}
+
+func defaultShell(goos string, lookPath func(string) (string, error)) string {
+	if goos != "windows" {
+		return ShellBash
+	}
+	if _, err := lookPath(ShellPwsh); err == nil {
+		return ShellPwsh
+	}
+	return ShellPowershell
+}
+
+// IsPOSIXShell reports whether shell understands POSIX shell syntax.
+func IsPOSIXShell(shell string) bool {
+	return slices.Contains([]string{ShellBash, ShellSh, ShellZsh}, shell)
+}
+
+const shellKey contextKey = "shell"
+
+// WithShell sets the shell commands run with the context are run with.
+// An empty shell leaves the executor's default in place.
+func WithShell(ctx context.Context, shell string) context.Context {
+	return context.WithValue(ctx, shellKey, shell)
+}
+
+// ShellFromContext returns the shell set with WithShell, or DefaultShell.
+func ShellFromContext(ctx context.Context) string {
+	if shell, ok := ctx.Value(shellKey).(string); ok && shell != "" {
+		return shell
+	}
+	return DefaultShell()
+}
+
+// ShellArgs returns the argument list that runs command with shell.
+func ShellArgs(shell string, command string) ([]string, error) {
+	switch shell {
+	case ShellBash, ShellSh, ShellZsh:
+		return []string{shell, "-c", command}, nil
+	case ShellPwsh, ShellPowershell:
+		return []string{shell, "-NoProfile", "-NonInteractive", "-Command", command}, nil
+	case ShellCmd:
+		return []string{shell, "/d", "/s", "/c", command}, nil
+	case ShellNone:
+		return SplitArgs(command)
+	default:
+		return nil, fmt.Errorf("unknown shell '%s', expected one of: %s", shell, strings.Join(Shells, ", "))
+	}
+}
+
+// QuoteArgs joins args into a command that shell runs as exactly those
+// arguments. Arguments with characters the shell could interpret are
+// quoted, the others are left as they are.
+func QuoteArgs(shell string, args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quoteArg(shell, arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func quoteArg(shell string, arg string) string {
+	if arg != "" && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-./:=@%+,") == "" {
+		return arg
+	}
+	switch shell {
+	case ShellPwsh, ShellPowershell:
+		return "'" + strings.ReplaceAll(arg, "'", "''") + "'"
+	case ShellCmd:
+		return `"` + strings.ReplaceAll(arg, `"`, `""`) + `"`
+	default:
+		return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+}
+
+// SplitArgs splits a command into words the way a POSIX shell does,
+// honouring quotes and backslash escapes, but without expanding variables,
+// globs or anything else.
+func SplitArgs(command string) ([]string, error) {
+	var args []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range command {
+		switch {
+		case escaped:
+			// Inside double quotes a backslash only escapes a few characters.
+			if quote == '"' && !strings.ContainsRune("\"\\$`\n", r) {
+				word.WriteRune('\\')
+			}
+			if r != '\n' {
+				word.WriteRune(r)
+			}
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				args = append(args, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if escaped || quote != 0 {
+		return nil, fmt.Errorf("unterminated quote or escape in command: %s", command)
+	}
+	if inWord {
+		args = append(args, word.String())
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	return args, nil
+}
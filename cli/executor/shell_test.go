@@ -0,0 +1,122 @@
+package executor
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"go build ./...", []string{"go", "build", "./..."}},
+		{"  echo \t spaced  ", []string{"echo", "spaced"}},
+		{`echo 'single $HOME' "double $HOME"`, []string{"echo", "single $HOME", "double $HOME"}},
+		{`echo it\'s a\ b`, []string{"echo", "it's", "a b"}},
+		{`echo "say \"hi\"" "back\slash"`, []string{"echo", `say "hi"`, `back\slash`}},
+		{`echo '' ""`, []string{"echo", "", ""}},
+		{"echo a\\\nb", []string{"echo", "ab"}},
+		{`echo pre'fix'"ed"`, []string{"echo", "prefixed"}},
+		{"echo $(rm -rf /) ; ls", []string{"echo", "$(rm", "-rf", "/)", ";", "ls"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			args, err := SplitArgs(tt.command)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, args)
+		})
+	}
+
+	for _, command := range []string{`echo 'open`, `echo "open`, `echo trailing\`, "", "   "} {
+		_, err := SplitArgs(command)
+		assert.Error(t, err, command)
+	}
+}
+
+func TestShellArgs(t *testing.T) {
+	args, err := ShellArgs(ShellZsh, "echo hi")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zsh", "-c", "echo hi"}, args)
+
+	args, err = ShellArgs(ShellPwsh, "Write-Output hi")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pwsh", "-NoProfile", "-NonInteractive", "-Command", "Write-Output hi"}, args)
+
+	args, err = ShellArgs(ShellCmd, "echo hi")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cmd", "/d", "/s", "/c", "echo hi"}, args)
+
+	_, err = ShellArgs("fish", "echo hi")
+	assert.ErrorContains(t, err, "unknown shell 'fish', expected one of: bash, sh, zsh, pwsh, powershell, cmd, none")
+}
+
+func TestQuoteArgs(t *testing.T) {
+	assert.Equal(t, "go env GOOS", QuoteArgs(ShellBash, []string{"go", "env", "GOOS"}))
+	assert.Equal(t, `printf '%s|' 'a b' c ''`, QuoteArgs(ShellBash, []string{"printf", "%s|", "a b", "c", ""}))
+	assert.Equal(t, `sh -c 'echo "$HOME" it'\''s'`, QuoteArgs(ShellSh, []string{"sh", "-c", `echo "$HOME" it's`}))
+	assert.Equal(t, `Write-Output 'it''s $HOME'`, QuoteArgs(ShellPwsh, []string{"Write-Output", "it's $HOME"}))
+	assert.Equal(t, `echo "say ""hi"""`, QuoteArgs(ShellCmd, []string{"echo", `say "hi"`}))
+
+	for _, shell := range []string{ShellBash, ShellNone} {
+		args := []string{"printf", "%s|", "a b", `it's "quoted" $HOME`, ""}
+		ctx := WithShell(context.Background(), shell)
+		result, err := (&DefaultExecutor{}).Exec(ctx, QuoteArgs(shell, args))
+		require.NoError(t, err, shell)
+		assert.Equal(t, `a b|it's "quoted" $HOME||`, result.Stdout, shell)
+	}
+}
+
+func TestDefaultShell(t *testing.T) {
+	found := func(string) (string, error) { return "/bin/found", nil }
+	missing := func(name string) (string, error) { return "", exec.ErrNotFound }
+
+	assert.Equal(t, ShellBash, defaultShell("linux", found))
+	assert.Equal(t, ShellBash, defaultShell("darwin", missing))
+	assert.Equal(t, ShellPwsh, defaultShell("windows", found))
+	assert.Equal(t, ShellPowershell, defaultShell("windows", missing))
+}
+
+func TestShellFromContext(t *testing.T) {
+	assert.Equal(t, DefaultShell(), ShellFromContext(context.Background()))
+	assert.Equal(t, DefaultShell(), ShellFromContext(WithShell(context.Background(), "")))
+	assert.Equal(t, ShellSh, ShellFromContext(WithShell(context.Background(), ShellSh)))
+}
+
+func TestDefaultExecutor_Exec_Shell(t *testing.T) {
+	t.Run("sh", func(t *testing.T) {
+		ctx := WithShell(context.Background(), ShellSh)
+		result, err := (&DefaultExecutor{}).Exec(ctx, `echo "$0"`)
+
+		require.NoError(t, err)
+		assert.Equal(t, "sh\n", result.Stdout)
+	})
+
+	t.Run("none passes arguments untouched", func(t *testing.T) {
+		ctx := WithShell(WithEnv(context.Background(), []string{"NAME=world"}), ShellNone)
+		result, err := (&DefaultExecutor{}).Exec(ctx, `echo "hello $NAME" ; 'a b'`)
+
+		require.NoError(t, err)
+		assert.Equal(t, "hello $NAME ; a b\n", result.Stdout)
+	})
+
+	t.Run("none with a missing binary fails the step", func(t *testing.T) {
+		ctx := WithShell(context.Background(), ShellNone)
+		result, err := (&DefaultExecutor{}).Exec(ctx, "devops-no-such-binary --flag")
+
+		require.Error(t, err)
+		assert.False(t, IsInfraError(err))
+		assert.Equal(t, -1, result.ExitCode)
+	})
+
+	t.Run("unknown shell", func(t *testing.T) {
+		ctx := WithShell(context.Background(), "fish")
+		_, err := (&DefaultExecutor{}).Exec(ctx, "echo hi")
+
+		assert.ErrorContains(t, err, "unknown shell 'fish'")
+	})
+}
@@ -0,0 +1,177 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sshConnectionFailed is the exit status ssh uses for its own errors, as
+// opposed to the exit status of the remote command.
+const sshConnectionFailed = 255
+
+// SSHExecutor runs commands on a remote host through the system ssh client.
+type SSHExecutor struct {
+	Host         string
+	User         string
+	Port         int
+	IdentityFile string
+	Dir          string
+
+	// Env holds variables sent with every command, in addition to those
+	// of the environment set on the context.
+	Env []string
+}
+
+func (s *SSHExecutor) Exec(ctx context.Context, command string) (Result, error) {
+	env := append(slices.Clone(s.Env), changedEnv(EnvFromContext(ctx))...)
+	term, inTerminal := TerminalFromContext(ctx)
+	// The env is kept off the command line, where ps would show it: it is
+	// sent on stdin, or in terminal mode, where stdin is the user's, in a
+	// private file copied ahead.
+	loadEnv := ""
+	var stdin io.Reader
+	if len(env) > 0 {
+		if inTerminal {
+			path, err := s.uploadEnv(ctx, env)
+			if err != nil {
+				return Result{ExitCode: -1}, &InfraError{Reason: fmt.Sprintf("ssh to %s failed", s.Host), Err: err}
+			}
+			loadEnv = fmt.Sprintf(". %s && rm -f %s &&", quote(path), quote(path))
+		} else {
+			loadEnv = `eval "$(cat)" &&`
+			stdin = strings.NewReader(envScript(env))
+		}
+	}
+	args, err := s.args(ShellFromContext(ctx), command, loadEnv)
+	if err != nil {
+		return Result{ExitCode: -1}, err
+	}
+	if inTerminal {
+		args = append([]string{"-tt"}, args...)
+	}
+	cmd := exec.CommandContext(ctx, "ssh", args...)
+	cmd.Stdin = stdin
+	stopKill := terminateOnCancel(ctx, cmd)
+	output := captureOutput(ctx, cmd)
+	var shown *maskingWriter
+	if inTerminal {
+		// ssh drives the local terminal itself; the remote side merges
+		// stderr into the tty.
+		shown = newMaskingWriter(term.Out, SecretsFromContext(ctx))
+		cmd.Stdin = term.In
+		cmd.Stdout = io.MultiWriter(cmd.Stdout, shown)
+	}
+
+	start := time.Now()
+	err = cmd.Run()
+	stopKill()
+	if shown != nil {
+		_ = shown.Flush()
+	}
+
+	exitCode := 0
+	if err != nil {
+		exitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+		if ctx.Err() == nil && (exitCode == -1 || exitCode == sshConnectionFailed) {
+			err = &InfraError{Reason: fmt.Sprintf("ssh to %s failed", s.Host), Err: err}
+		}
+	}
+	trace(ctx, "ssh "+s.target()+" "+command, start, exitCode)
+
+	result := output.result(exitCode, start)
+	if inTerminal {
+		result = terminalResult(result)
+	}
+	return result, err
+}
+
+func (s *SSHExecutor) target() string {
+	if s.User == "" {
+		return s.Host
+	}
+	return s.User + "@" + s.Host
+}
+
+// options returns the ssh options that select the host and how to log in.
+func (s *SSHExecutor) options() []string {
+	args := []string{"-o", "BatchMode=yes"}
+	if s.Port != 0 {
+		args = append(args, "-p", strconv.Itoa(s.Port))
+	}
+	if s.IdentityFile != "" {
+		args = append(args, "-i", s.IdentityFile)
+	}
+	// Ends the options, so a host starting with '-' is not read as one.
+	return append(args, "--", s.target())
+}
+
+// args builds the ssh arguments for running command remotely with shell,
+// after the loadEnv snippet sets up its environment.
+func (s *SSHExecutor) args(shell string, command string, loadEnv string) ([]string, error) {
+	argv, err := ShellArgs(shell, command)
+	if err != nil {
+		return nil, err
+	}
+	return append(s.options(), s.remoteCommand(shell, argv, loadEnv)), nil
+}
+
+// uploadEnv copies env to a remote file only the user can read, and
+// returns its path.
+func (s *SSHExecutor) uploadEnv(ctx context.Context, env []string) (string, error) {
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ssh", append(s.options(), `umask 077 && f=$(mktemp) && cat > "$f" && echo "$f"`)...)
+	cmd.Stdin = strings.NewReader(envScript(env))
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to send the environment: %w", err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// envScript renders env as shell export commands.
+func envScript(env []string) string {
+	var b strings.Builder
+	for _, kv := range env {
+		b.WriteString("export " + quote(kv) + "\n")
+	}
+	return b.String()
+}
+
+// remoteCommand wraps the argument list of a command so it runs in Dir,
+// after loadEnv. Without a shell every argument is quoted, so the remote
+// login shell passes them through untouched.
+func (s *SSHExecutor) remoteCommand(shell string, argv []string, loadEnv string) string {
+	parts := []string{}
+	if loadEnv != "" {
+		parts = append(parts, loadEnv)
+	}
+	if s.Dir != "" {
+		parts = append(parts, "cd", quote(s.Dir), "&&")
+	}
+	if shell != ShellNone {
+		// The shell and its flags are plain words; only the script needs quoting.
+		parts = append(parts, argv[:len(argv)-1]...)
+		argv = argv[len(argv)-1:]
+	}
+	for _, arg := range argv {
+		parts = append(parts, quote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// quote quotes a string for safe use as a single remote shell word.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
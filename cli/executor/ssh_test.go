@@ -0,0 +1,120 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSHExecutor_Args(t *testing.T) {
+	s := &SSHExecutor{
+		Host:         "builder.internal",
+		User:         "ci",
+		Port:         2222,
+		IdentityFile: "/keys/id_ed25519",
+		Dir:          "/srv/app",
+	}
+	assert.Equal(t, []string{
+		"-o", "BatchMode=yes",
+		"-p", "2222",
+		"-i", "/keys/id_ed25519",
+		"--", "ci@builder.internal",
+		`eval "$(cat)" && cd '/srv/app' && bash -c 'go build ./...'`,
+	}, mustArgs(t, s, ShellBash, "go build ./...", `eval "$(cat)" &&`))
+
+	minimal := &SSHExecutor{Host: "builder"}
+	assert.Equal(t, []string{"-o", "BatchMode=yes", "--", "builder", "bash -c 'make'"}, mustArgs(t, minimal, ShellBash, "make", ""))
+	assert.Equal(t, []string{"-o", "BatchMode=yes", "--", "builder", "sh -c 'make'"}, mustArgs(t, minimal, ShellSh, "make", ""))
+	assert.Equal(t, []string{"-o", "BatchMode=yes", "--", "builder", `'echo' '$HOME' 'a b'`}, mustArgs(t, minimal, ShellNone, `echo $HOME "a b"`, ""))
+	assert.Equal(t, []string{"-o", "BatchMode=yes", "--", "-oProxyCommand=x", "bash -c 'make'"}, mustArgs(t, &SSHExecutor{Host: "-oProxyCommand=x"}, ShellBash, "make", ""))
+
+	_, err := minimal.args("fish", "make", "")
+	assert.ErrorContains(t, err, "unknown shell 'fish'")
+}
+
+func mustArgs(t *testing.T, s *SSHExecutor, shell string, command string, loadEnv string) []string {
+	t.Helper()
+	args, err := s.args(shell, command, loadEnv)
+	require.NoError(t, err)
+	return args
+}
+
+func TestEnvScript(t *testing.T) {
+	assert.Equal(t, "export 'GOOS=linux'\nexport 'MSG=it'\\''s'\n", envScript([]string{"GOOS=linux", "MSG=it's"}))
+}
+
+func TestChangedEnv(t *testing.T) {
+	t.Setenv("DEVOPS_SSH_LOCAL", "same")
+	changed := changedEnv(append(os.Environ(), "DEVOPS_SSH_LOCAL=same", "GOOS=plan9", "DEVOPS_SSH_NEW=1", "malformed"))
+	assert.Equal(t, []string{"DEVOPS_SSH_NEW=1", "GOOS=plan9"}, changed)
+}
+
+func TestSSHExecutor_Exec(t *testing.T) {
+	// A fake ssh on PATH runs the remote command locally, which exercises
+	// quoting and exit status handling without a real host.
+	bin := t.TempDir()
+	script := "#!/bin/sh\necho \"$@\" >> \"$(dirname \"$0\")/argv\"\nfor last; do :; done\nexec sh -c \"$last\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "ssh"), []byte(script), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	ctx := context.Background()
+	s := &SSHExecutor{Host: "builder", Env: []string{"GREETING=hello"}}
+	result, err := s.Exec(WithEnv(ctx, append(os.Environ(), "TARGET=remote", "TOKEN=s3cr3t")), `echo "$GREETING $TARGET"; echo "$TOKEN" >&2`)
+	require.NoError(t, err)
+	assert.Equal(t, "hello remote\n", result.Stdout)
+	assert.Equal(t, "s3cr3t\n", result.Stderr)
+	argv, err := os.ReadFile(filepath.Join(bin, "argv"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(argv), "s3cr3t")
+
+	result, err = s.Exec(ctx, "exit 3")
+	assert.Error(t, err)
+	assert.False(t, IsInfraError(err))
+	assert.Equal(t, 3, result.ExitCode)
+}
+
+func TestSSHExecutor_Exec_Terminal(t *testing.T) {
+	bin := t.TempDir()
+	// Without -tt only the env file may be copied.
+	script := "#!/bin/sh\nfor last; do :; done\n[ \"$1\" = -tt ] || case \"$last\" in umask*) ;; *) exit 9 ;; esac\nexec sh -c \"$last\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "ssh"), []byte(script), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	var shown bytes.Buffer
+	ctx := WithTerminal(context.Background(), Terminal{In: strings.NewReader("yes\n"), Out: &shown})
+	result, err := (&SSHExecutor{Host: "builder"}).Exec(ctx, `read answer; echo "answer: $answer"`)
+
+	require.NoError(t, err)
+	assert.Equal(t, "answer: yes\n", result.Stdout)
+	assert.Equal(t, "answer: yes\n", shown.String())
+	assert.True(t, result.Terminal)
+
+	shown.Reset()
+	ctx = WithTerminal(WithSecrets(context.Background(), "yes"), Terminal{In: strings.NewReader("yes\n"), Out: &shown})
+	_, err = (&SSHExecutor{Host: "builder"}).Exec(ctx, `read answer; echo "answer: $answer"`)
+	require.NoError(t, err)
+	assert.Equal(t, "answer: ***\n", shown.String())
+
+	shown.Reset()
+	ctx = WithTerminal(context.Background(), Terminal{In: strings.NewReader(""), Out: &shown})
+	result, err = (&SSHExecutor{Host: "builder", Env: []string{"GREETING=hello there"}}).Exec(ctx, `echo "$GREETING"`)
+	require.NoError(t, err)
+	assert.Equal(t, "hello there\n", result.Stdout)
+}
+
+func TestSSHExecutor_ConnectionFailureIsInfraError(t *testing.T) {
+	bin := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "ssh"), []byte("#!/bin/sh\nexit 255\n"), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	result, err := (&SSHExecutor{Host: "builder"}).Exec(context.Background(), "make")
+	assert.True(t, IsInfraError(err))
+	assert.ErrorContains(t, err, "ssh to builder failed")
+	assert.Equal(t, 255, result.ExitCode)
+}
@@ -0,0 +1,115 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Terminal is where a command run under a pseudo-terminal reads its input
+// from and shows its output.
+type Terminal struct {
+	In  io.Reader
+	Out io.Writer
+}
+
+const terminalKey contextKey = "terminal"
+
+// terminalDrainTimeout bounds how long output is read after the command
+// exits, in case a background process still holds the terminal open.
+const terminalDrainTimeout = time.Second
+
+// WithTerminal makes executors run commands attached to term: locally under
+// a pseudo-terminal, remotely with a forced ssh tty. Output is shown as it
+// is written, with the secrets on the context masked, and is also captured,
+// merged, in the result's Stdout.
+func WithTerminal(ctx context.Context, term Terminal) context.Context {
+	return context.WithValue(ctx, terminalKey, term)
+}
+
+// TerminalFromContext returns the terminal set with WithTerminal, if any.
+func TerminalFromContext(ctx context.Context) (Terminal, bool) {
+	term, ok := ctx.Value(terminalKey).(Terminal)
+	return term, ok
+}
+
+// runInTerminal runs cmd under a new pseudo-terminal wired to term. What
+// the command writes is copied to term.Out and to the writer cmd.Stdout
+// was set to. A terminal input is switched to raw mode meanwhile, so keys
+// reach the command as they are typed.
+func runInTerminal(cmd *exec.Cmd, term Terminal) error {
+	in, _ := term.In.(*os.File)
+	master, slave, err := openPTY(in)
+	if err != nil {
+		return err
+	}
+	defer master.Close()
+
+	capture := cmd.Stdout
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = slave, slave, slave
+	setControllingTerminal(cmd)
+	err = cmd.Start()
+	// Only the command keeps the terminal open, so reads end when it exits.
+	_ = slave.Close()
+	if err != nil {
+		return err
+	}
+
+	copied := make(chan struct{})
+	go func() {
+		defer close(copied)
+		// Reading fails with EIO once the terminal is closed.
+		_, _ = io.Copy(io.MultiWriter(term.Out, capture), master)
+	}()
+
+	if in != nil {
+		if restore, ok := makeRaw(in); ok {
+			defer restore()
+		}
+	}
+	stopInput := forwardInput(master, term.In)
+	err = cmd.Wait()
+	stopInput()
+
+	select {
+	case <-copied:
+	case <-time.After(terminalDrainTimeout):
+	}
+	return err
+}
+
+// forwardInput copies in to w until the returned stop is called. A file
+// input is read through a non-blocking duplicate where possible, so that
+// stop does not leave a read pending that would swallow the next key.
+func forwardInput(w io.Writer, in io.Reader) (stop func()) {
+	if in == nil {
+		return func() {}
+	}
+	if file, ok := in.(*os.File); ok {
+		if pollable, release, ok := pollableInput(file); ok {
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				_, _ = io.Copy(w, pollable)
+			}()
+			return func() {
+				_ = pollable.SetReadDeadline(time.Now())
+				<-done
+				release()
+			}
+		}
+	}
+	go func() { _, _ = io.Copy(w, in) }()
+	return func() {}
+}
+
+// terminalResult marks a result as coming from a terminal, turning the CRLF
+// line endings a terminal writes back into plain newlines.
+func terminalResult(result Result) Result {
+	result.Stdout = strings.ReplaceAll(result.Stdout, "\r\n", "\n")
+	result.Terminal = true
+	return result
+}
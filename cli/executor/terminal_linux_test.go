@@ -0,0 +1,63 @@
+//go:build linux
+
+package executor
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultExecutor_Exec_Terminal(t *testing.T) {
+	t.Run("runs attached to a terminal", func(t *testing.T) {
+		var shown bytes.Buffer
+		ctx := WithTerminal(context.Background(), Terminal{In: strings.NewReader(""), Out: &shown})
+		result, err := (&DefaultExecutor{}).Exec(ctx, "test -t 0 && test -t 1 && test -t 2 && echo tty")
+
+		require.NoError(t, err)
+		assert.Equal(t, "tty\n", result.Stdout)
+		assert.Equal(t, "tty\r\n", shown.String())
+		assert.True(t, result.Terminal)
+	})
+
+	t.Run("passes input through", func(t *testing.T) {
+		var shown bytes.Buffer
+		ctx := WithTerminal(context.Background(), Terminal{In: strings.NewReader("yes\n"), Out: &shown})
+		result, err := (&DefaultExecutor{}).Exec(ctx, `read answer; echo "answer: $answer"`)
+
+		require.NoError(t, err)
+		assert.Contains(t, result.Stdout, "answer: yes\n")
+		assert.Contains(t, shown.String(), "answer: yes")
+	})
+
+	t.Run("masks secrets on the terminal", func(t *testing.T) {
+		var shown bytes.Buffer
+		ctx := WithSecrets(context.Background(), "supersecret123")
+		ctx = WithTerminal(ctx, Terminal{In: strings.NewReader(""), Out: &shown})
+		_, err := (&DefaultExecutor{}).Exec(ctx, "printf token=supersecret; echo 123")
+
+		require.NoError(t, err)
+		assert.Equal(t, "token=***\r\n", shown.String())
+	})
+
+	t.Run("keeps the exit code", func(t *testing.T) {
+		ctx := WithTerminal(context.Background(), Terminal{Out: &bytes.Buffer{}})
+		result, err := (&DefaultExecutor{}).Exec(ctx, "echo failing >&2; exit 3")
+
+		assert.Error(t, err)
+		assert.Equal(t, 3, result.ExitCode)
+		assert.Equal(t, "failing\n", result.Stdout)
+	})
+}
+
+func TestDefaultExecutor_Exec_TerminalIsPlainOutsideTerminalMode(t *testing.T) {
+	result, err := (&DefaultExecutor{}).Exec(context.Background(), "test -t 1 || echo plain")
+
+	require.NoError(t, err)
+	assert.Equal(t, "plain\n", result.Stdout)
+	assert.False(t, result.Terminal)
+}
@@ -0,0 +1,80 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+type contextKey string
+
+const tracerKey contextKey = "tracer"
+
+// Tracer writes a line for every command devops runs, with its start
+// time, duration and exit code.
+type Tracer struct {
+	mu        sync.Mutex
+	w         io.Writer
+	localTime bool
+}
+
+// NewTracer returns a tracer writing to w, with timestamps in UTC unless
+// localTime is set.
+func NewTracer(w io.Writer, localTime bool) *Tracer {
+	return &Tracer{w: w, localTime: localTime}
+}
+
+// WithTracer attaches a tracer to the context.
+func WithTracer(ctx context.Context, tracer *Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey, tracer)
+}
+
+// TracerFromContext returns the tracer in the context, or nil if tracing
+// is disabled. A nil tracer is safe to use and records nothing.
+func TracerFromContext(ctx context.Context) *Tracer {
+	tracer, _ := ctx.Value(tracerKey).(*Tracer)
+	return tracer
+}
+
+// Record writes a trace line for a command that started at start.
+func (t *Tracer) Record(command string, start time.Time, exitCode int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	timestamp := start.UTC()
+	if t.localTime {
+		timestamp = start.Local()
+	}
+	_, _ = fmt.Fprintf(t.w, "[trace] %s %s (%s, exit %d)\n",
+		timestamp.Format(time.RFC3339Nano), command, time.Since(start).Round(time.Millisecond), exitCode)
+}
+
+// trace records a command with the context's tracer, with the secrets on
+// the context masked.
+func trace(ctx context.Context, command string, start time.Time, exitCode int) {
+	TracerFromContext(ctx).Record(Mask(command, SecretsFromContext(ctx)), start, exitCode)
+}
+
+// RunCommand runs a helper command such as git or docker, recording it with
+// the context's tracer.
+func RunCommand(ctx context.Context, cmd *exec.Cmd) error {
+	start := time.Now()
+	err := cmd.Run()
+	exitCode := 0
+	if err != nil {
+		exitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+	}
+	trace(ctx, strings.Join(cmd.Args, " "), start, exitCode)
+	return err
+}
@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracer_RecordsExecutorCommands(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := WithTracer(context.Background(), NewTracer(&buf, false))
+
+	executor := &DefaultExecutor{}
+	_, err := executor.Exec(ctx, "echo traced")
+	require.NoError(t, err)
+	_, err = executor.Exec(ctx, "exit 3")
+	require.Error(t, err)
+
+	assert.Regexp(t, `^\[trace\] \S+Z echo traced \(\d+ms, exit 0\)\n\[trace\] \S+Z exit 3 \(\d+ms, exit 3\)\n$`, buf.String())
+}
+
+func TestTracer_MasksSecrets(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := WithTracer(context.Background(), NewTracer(&buf, false))
+	ctx = WithSecrets(ctx, "hunter2")
+
+	_, err := (&DefaultExecutor{}).Exec(ctx, "echo hunter2 >/dev/null")
+	require.NoError(t, err)
+	require.NoError(t, RunCommand(ctx, exec.CommandContext(ctx, "true", "hunter2")))
+
+	assert.Contains(t, buf.String(), " echo *** >/dev/null (")
+	assert.Contains(t, buf.String(), " true *** (")
+	assert.NotContains(t, buf.String(), "hunter2")
+}
+
+func TestTracer_RunCommand(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := WithTracer(context.Background(), NewTracer(&buf, false))
+
+	require.NoError(t, RunCommand(ctx, exec.CommandContext(ctx, "true")))
+	assert.Error(t, RunCommand(ctx, exec.CommandContext(ctx, "false")))
+	assert.Error(t, RunCommand(ctx, exec.CommandContext(ctx, "definitely-not-a-command-devops")))
+
+	assert.Contains(t, buf.String(), " true (")
+	assert.Contains(t, buf.String(), " false (")
+	assert.Contains(t, buf.String(), "exit 1)")
+	assert.Contains(t, buf.String(), "definitely-not-a-command-devops (")
+	assert.Contains(t, buf.String(), "exit -1)")
+}
+
+func TestTracer_DisabledByDefault(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, TracerFromContext(ctx))
+	assert.NotPanics(t, func() {
+		_, _ = (&DefaultExecutor{}).Exec(ctx, "true")
+		TracerFromContext(ctx).Record("noop", time.Now(), 0)
+	})
+}
@@ -0,0 +1,233 @@
+// Package condition evaluates the small boolean expressions used in
+// `when:` fields, such as `ci && env.BRANCH == "main"`.
+package condition
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Vars resolves the identifiers an expression may reference. Env looks up
+// `env.NAME` references; any other identifier is read from Values.
+type Vars struct {
+	Values map[string]string
+	Env    func(name string) string
+}
+
+// Evaluate parses and evaluates expression. Strings are truthy unless empty
+// or "false"; == and != compare values as strings.
+func Evaluate(expression string, vars Vars) (bool, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return false, err
+	}
+	p := &parser{tokens: tokens, vars: vars}
+	value, err := p.or()
+	if err != nil {
+		return false, err
+	}
+	if !p.done() {
+		return false, fmt.Errorf("unexpected '%s' in condition", p.peek().text)
+	}
+	return truthy(value), nil
+}
+
+func truthy(value string) bool {
+	return value != "" && value != "false"
+}
+
+func boolString(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
+
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	tokenString
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+var operators = []string{"==", "!=", "&&", "||", "!", "(", ")"}
+
+func tokenize(expression string) ([]token, error) {
+	tokens := []token{}
+	for i := 0; i < len(expression); {
+		c := rune(expression[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexRune(expression[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in condition '%s'", expression)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: expression[i+1 : i+1+end]})
+			i += end + 2
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(expression) && isIdentChar(rune(expression[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: expression[start:i]})
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(expression[i:], op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character '%c' in condition '%s'", c, expression)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+func isIdentChar(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' || c == '.' || c == '-'
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	vars   Vars
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	if p.done() {
+		return token{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) accept(op string) bool {
+	if t := p.peek(); !p.done() && t.kind == tokenOperator && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) or() (string, error) {
+	left, err := p.and()
+	if err != nil {
+		return "", err
+	}
+	for p.accept("||") {
+		right, err := p.and()
+		if err != nil {
+			return "", err
+		}
+		left = boolString(truthy(left) || truthy(right))
+	}
+	return left, nil
+}
+
+func (p *parser) and() (string, error) {
+	left, err := p.unary()
+	if err != nil {
+		return "", err
+	}
+	for p.accept("&&") {
+		right, err := p.unary()
+		if err != nil {
+			return "", err
+		}
+		left = boolString(truthy(left) && truthy(right))
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (string, error) {
+	if p.accept("!") {
+		value, err := p.unary()
+		if err != nil {
+			return "", err
+		}
+		return boolString(!truthy(value)), nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (string, error) {
+	left, err := p.operand()
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case p.accept("=="):
+		right, err := p.operand()
+		if err != nil {
+			return "", err
+		}
+		return boolString(left == right), nil
+	case p.accept("!="):
+		right, err := p.operand()
+		if err != nil {
+			return "", err
+		}
+		return boolString(left != right), nil
+	}
+	return left, nil
+}
+
+func (p *parser) operand() (string, error) {
+	if p.done() {
+		return "", fmt.Errorf("condition ends unexpectedly")
+	}
+	if p.accept("(") {
+		value, err := p.or()
+		if err != nil {
+			return "", err
+		}
+		if !p.accept(")") {
+			return "", fmt.Errorf("missing ')' in condition")
+		}
+		return value, nil
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	switch t.kind {
+	case tokenString:
+		return t.text, nil
+	case tokenIdent:
+		return p.resolve(t.text)
+	}
+	return "", fmt.Errorf("unexpected '%s' in condition", t.text)
+}
+
+func (p *parser) resolve(name string) (string, error) {
+	switch name {
+	case "true", "false":
+		return name, nil
+	}
+	if key, ok := strings.CutPrefix(name, "env."); ok {
+		if p.vars.Env == nil {
+			return "", nil
+		}
+		return p.vars.Env(key), nil
+	}
+	value, ok := p.vars.Values[name]
+	if !ok {
+		return "", fmt.Errorf("unknown identifier '%s' in condition", name)
+	}
+	return value, nil
+}
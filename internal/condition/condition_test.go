@@ -0,0 +1,59 @@
+package condition
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	env := map[string]string{"BRANCH": "main", "DEBUG": "false"}
+	vars := Vars{
+		Values: map[string]string{"ci": "true", "os": "linux", "arch": "amd64"},
+		Env:    func(name string) string { return env[name] },
+	}
+
+	tests := []struct {
+		expression string
+		expected   bool
+	}{
+		{`ci`, true},
+		{`!ci`, false},
+		{`os == "linux"`, true},
+		{`os != 'linux'`, false},
+		{`env.BRANCH == "main"`, true},
+		{`env.MISSING`, false},
+		{`env.DEBUG`, false},
+		{`ci && os == "darwin"`, false},
+		{`ci && (os == "darwin" || arch == "amd64")`, true},
+		{`!(os == "windows") && env.BRANCH != ""`, true},
+		{`false || true`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			result, err := Evaluate(tt.expression, vars)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestEvaluate_Errors(t *testing.T) {
+	vars := Vars{Values: map[string]string{"os": "linux"}}
+
+	tests := map[string]string{
+		`branch == "main"`: "unknown identifier 'branch'",
+		`os == "linux`:     "unterminated string",
+		`os = "linux"`:     "unexpected character '='",
+		`(os == "linux"`:   "missing ')'",
+		`os ==`:            "condition ends unexpectedly",
+		`os "linux"`:       "unexpected 'linux'",
+	}
+	for expression, expected := range tests {
+		t.Run(expression, func(t *testing.T) {
+			_, err := Evaluate(expression, vars)
+			assert.ErrorContains(t, err, expected)
+		})
+	}
+}
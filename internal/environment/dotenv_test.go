@@ -0,0 +1,51 @@
+package environment
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDotenv(t *testing.T) {
+	content := `# Local settings
+API_URL=http://localhost:8080
+export REGION = eu-west-1
+EMPTY=
+COMMENTED=value # trailing comment
+DOUBLE="line one\nline two # kept"
+SINGLE='raw $HOME \n'
+API_URL=http://localhost:9090
+`
+	values, err := ParseDotenv(strings.NewReader(content))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"API_URL":   "http://localhost:9090",
+		"REGION":    "eu-west-1",
+		"EMPTY":     "",
+		"COMMENTED": "value",
+		"DOUBLE":    "line one\nline two # kept",
+		"SINGLE":    `raw $HOME \n`,
+	}, values)
+}
+
+func TestParseDotenv_Invalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedError string
+	}{
+		{name: "missing equals", content: "A=1\nNOVALUE\n", expectedError: "line 2: expected KEY=VALUE"},
+		{name: "invalid key", content: "1A=1\n", expectedError: "line 1: expected KEY=VALUE"},
+		{name: "unterminated double quote", content: `A="open`, expectedError: "line 1: unterminated quoted value"},
+		{name: "unterminated single quote", content: "A='open", expectedError: "line 1: unterminated quoted value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDotenv(strings.NewReader(tt.content))
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
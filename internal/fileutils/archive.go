@@ -0,0 +1,98 @@
+package fileutils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Archive writes the given files and directories of fsys to dest, as a
+// zip file when dest ends in .zip and a gzipped tarball when it ends in
+// .tar.gz or .tgz. Entries keep their paths and permission bits.
+func Archive(dest string, fsys fs.FS, paths []string) (err error) {
+	var add func(p string, info fs.FileInfo) error
+	var closeArchive func() error
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	switch {
+	case strings.HasSuffix(dest, ".zip"):
+		zw := zip.NewWriter(f)
+		closeArchive = zw.Close
+		add = func(p string, info fs.FileInfo) error {
+			header, err := zip.FileInfoHeader(info)
+			if err != nil {
+				return err
+			}
+			header.Name = p
+			header.Method = zip.Deflate
+			w, err := zw.CreateHeader(header)
+			if err != nil {
+				return err
+			}
+			return copyFrom(fsys, p, w)
+		}
+	case strings.HasSuffix(dest, ".tar.gz"), strings.HasSuffix(dest, ".tgz"):
+		gw := gzip.NewWriter(f)
+		tw := tar.NewWriter(gw)
+		closeArchive = func() error {
+			if err := tw.Close(); err != nil {
+				return err
+			}
+			return gw.Close()
+		}
+		add = func(p string, info fs.FileInfo) error {
+			header, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return err
+			}
+			header.Name = p
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			return copyFrom(fsys, p, tw)
+		}
+	default:
+		return fmt.Errorf("unsupported archive format for %s, expected .tar.gz, .tgz or .zip", filepath.Base(dest))
+	}
+
+	for _, root := range paths {
+		err := fs.WalkDir(fsys, ToSlash(root), func(p string, d fs.DirEntry, err error) error {
+			if err != nil || !d.Type().IsRegular() {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return add(p, info)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return closeArchive()
+}
+
+func copyFrom(fsys fs.FS, p string, w io.Writer) error {
+	src, err := fsys.Open(p)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	_, err = io.Copy(w, src)
+	return err
+}
@@ -0,0 +1,76 @@
+package fileutils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchive_TarGz(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "bin"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "bin", "app"), []byte("binary"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "README.md"), []byte("readme"), 0644))
+	dest := filepath.Join(t.TempDir(), "app.tar.gz")
+
+	require.NoError(t, Archive(dest, os.DirFS(src), []string{"bin", "README.md"}))
+
+	f, err := os.Open(dest)
+	require.NoError(t, err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	entries := map[string]string{}
+	modes := map[string]int64{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		entries[header.Name] = string(data)
+		modes[header.Name] = header.Mode
+	}
+	assert.Equal(t, map[string]string{"bin/app": "binary", "README.md": "readme"}, entries)
+	assert.Equal(t, int64(0755), modes["bin/app"])
+}
+
+func TestArchive_Zip(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(src, "app.exe"), []byte("binary"), 0755))
+	dest := filepath.Join(t.TempDir(), "app.zip")
+
+	require.NoError(t, Archive(dest, os.DirFS(src), []string{"app.exe"}))
+
+	zr, err := zip.OpenReader(dest)
+	require.NoError(t, err)
+	defer zr.Close()
+	require.Len(t, zr.File, 1)
+	assert.Equal(t, "app.exe", zr.File[0].Name)
+	rc, err := zr.File[0].Open()
+	require.NoError(t, err)
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "binary", string(data))
+}
+
+func TestArchive_UnsupportedFormat(t *testing.T) {
+	err := Archive(filepath.Join(t.TempDir(), "app.rar"), os.DirFS(t.TempDir()), nil)
+	assert.ErrorContains(t, err, "unsupported archive format for app.rar")
+}
+
+func TestArchive_MissingPath(t *testing.T) {
+	err := Archive(filepath.Join(t.TempDir(), "app.tgz"), os.DirFS(t.TempDir()), []string{"missing"})
+	assert.Error(t, err)
+}
@@ -23,3 +23,9 @@ func RootDirFromContext(ctx context.Context) fs.FS {
 	}
 	return rootDir
 }
+
+// LookupRootDir returns the root dir in the context, if there is one.
+func LookupRootDir(ctx context.Context) (fs.FS, bool) {
+	rootDir, ok := ctx.Value(rootDirKey).(fs.FS)
+	return rootDir, ok
+}
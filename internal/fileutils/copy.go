@@ -25,7 +25,12 @@ func CopyFile(srcFS fs.FS, srcRelPath string, dstPath string) (err error) {
 		return err
 	}
 
-	dstFile, err := os.Create(dstPath)
+	// Keep the execute bits, so copied binaries stay executable
+	info, err := srcFile.Stat()
+	if err != nil {
+		return err
+	}
+	dstFile, err := os.OpenFile(dstPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o666|info.Mode()&0o111)
 	if err != nil {
 		return err
 	}
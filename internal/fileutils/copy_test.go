@@ -96,3 +96,21 @@ func TestCopyLocalFile_CannotReadSrc(t *testing.T) {
 	err := CopyFile(fileSystem, "/path/does/not/exist.txt", "destination.txt")
 	assert.ErrorContains(t, err, "file does not exist")
 }
+
+func TestCopyLocalFile_KeepsExecuteBits(t *testing.T) {
+	tmpDir := t.TempDir()
+	fileSystem := fstest.MapFS{
+		"app":       {Mode: 0o755},
+		"notes.txt": {Mode: 0o644},
+	}
+
+	assert.NoError(t, CopyFile(fileSystem, "app", filepath.Join(tmpDir, "app")))
+	assert.NoError(t, CopyFile(fileSystem, "notes.txt", filepath.Join(tmpDir, "notes.txt")))
+
+	info, err := os.Stat(filepath.Join(tmpDir, "app"))
+	assert.NoError(t, err)
+	assert.NotZero(t, info.Mode()&0o100)
+	info, err = os.Stat(filepath.Join(tmpDir, "notes.txt"))
+	assert.NoError(t, err)
+	assert.Zero(t, info.Mode()&0o111)
+}
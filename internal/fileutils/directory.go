@@ -9,3 +9,18 @@ func IsDir(path string) bool {
 	}
 	return fileInfo.IsDir()
 }
+
+// IsWritableDir creates the directory if needed and checks that a file
+// can be written inside it.
+func IsWritableDir(path string) error {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return err
+	}
+	probe, err := os.CreateTemp(path, ".devops-write-check-*")
+	if err != nil {
+		return err
+	}
+	name := probe.Name()
+	_ = probe.Close()
+	return os.Remove(name)
+}
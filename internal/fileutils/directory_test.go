@@ -48,3 +48,28 @@ func TestIsDir(t *testing.T) {
 		})
 	}
 }
+
+func TestIsWritableDir(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	t.Run("creates missing directory", func(t *testing.T) {
+		target := path.Join(tmpDir, "nested", "cache")
+		assert.NoError(t, IsWritableDir(target))
+		assert.True(t, IsDir(target))
+		entries, err := os.ReadDir(target)
+		require.NoError(t, err)
+		assert.Empty(t, entries, "probe file should be removed")
+	})
+
+	t.Run("path is a file", func(t *testing.T) {
+		file := path.Join(tmpDir, "file")
+		require.NoError(t, os.WriteFile(file, []byte("x"), 0644))
+		assert.Error(t, IsWritableDir(file))
+	})
+}
+
+func TestFreeDiskSpace(t *testing.T) {
+	free, err := FreeDiskSpace(t.TempDir())
+	require.NoError(t, err)
+	assert.Greater(t, free, uint64(0))
+}
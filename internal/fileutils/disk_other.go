@@ -0,0 +1,10 @@
+//go:build !unix
+
+package fileutils
+
+import "errors"
+
+// FreeDiskSpace is not supported on this platform.
+func FreeDiskSpace(path string) (uint64, error) {
+	return 0, errors.New("free disk space lookup is not supported on this platform")
+}
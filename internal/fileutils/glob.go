@@ -0,0 +1,77 @@
+package fileutils
+
+import (
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Glob returns the paths in fsys matching pattern, sorted. Besides the
+// syntax of path.Match, a "**" segment matches any number of directories.
+// Backslashes in pattern are treated as separators, so patterns written on
+// Windows behave the same everywhere.
+// Directories named in skip are not descended into.
+func Glob(fsys fs.FS, pattern string, skip ...string) ([]string, error) {
+	pattern = path.Clean(strings.TrimPrefix(ToSlash(pattern), "./"))
+	if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+		return nil, err
+	}
+	patternParts := strings.Split(pattern, "/")
+
+	matches := []string{}
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == "." {
+			return nil
+		}
+		if d.IsDir() {
+			for _, dir := range skip {
+				if p == dir {
+					return fs.SkipDir
+				}
+			}
+		}
+		if matchSegments(patternParts, strings.Split(p, "/")) {
+			matches = append(matches, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// matchSegments matches path segments against pattern segments, letting
+// "**" stand for zero or more segments.
+func matchSegments(pattern []string, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
+
+// Match reports whether the slash-separated path matches pattern, with the
+// same syntax as Glob.
+func Match(pattern string, p string) bool {
+	pattern = path.Clean(strings.TrimPrefix(ToSlash(pattern), "./"))
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(path.Clean(p), "/"))
+}
@@ -0,0 +1,54 @@
+package fileutils
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlob(t *testing.T) {
+	fsys := fstest.MapFS{
+		"core.1234":                     {},
+		"pkg/api/testlogs/api.log":      {},
+		"pkg/testlogs/unit.log":         {},
+		"testlogs/root.log":             {},
+		"pkg/api/handler.go":            {},
+		".git/testlogs/ignored.log":     {},
+		".devops/testlogs/ignored.log":  {},
+		"nested/deeper/core.dump/inner": {},
+	}
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{pattern: "core.*", expected: []string{"core.1234"}},
+		{pattern: "**/testlogs/*", expected: []string{"pkg/api/testlogs/api.log", "pkg/testlogs/unit.log", "testlogs/root.log"}},
+		{pattern: "pkg/**/*.log", expected: []string{"pkg/api/testlogs/api.log", "pkg/testlogs/unit.log"}},
+		{pattern: "./pkg/*/handler.go", expected: []string{"pkg/api/handler.go"}},
+		{pattern: "missing/**", expected: []string{}},
+		{pattern: `pkg\**\*.log`, expected: []string{"pkg/api/testlogs/api.log", "pkg/testlogs/unit.log"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			matches, err := Glob(fsys, tt.pattern, ".git", ".devops")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matches)
+		})
+	}
+
+	_, err := Glob(fsys, "[invalid")
+	assert.Error(t, err)
+}
+
+func TestMatch(t *testing.T) {
+	assert.True(t, Match("**/node_modules", "node_modules"))
+	assert.True(t, Match("**/node_modules", "web/node_modules"))
+	assert.True(t, Match("./bin/*", "bin/app"))
+	assert.True(t, Match("**/*.log", "logs/api/run.log"))
+	assert.False(t, Match("bin/*", "cmd/bin/app"))
+	assert.False(t, Match("**/*.log", "main.go"))
+}
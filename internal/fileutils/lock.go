@@ -0,0 +1,7 @@
+package fileutils
+
+import "errors"
+
+// ErrLockUnsupported is returned by TryLock on platforms without file
+// locks.
+var ErrLockUnsupported = errors.New("file locks are not supported on this platform")
@@ -0,0 +1,10 @@
+//go:build !unix && !windows
+
+package fileutils
+
+import "os"
+
+// TryLock is not supported on this platform; it returns ErrLockUnsupported.
+func TryLock(f *os.File) (bool, error) {
+	return false, ErrLockUnsupported
+}
@@ -0,0 +1,34 @@
+//go:build unix || windows
+
+package fileutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTryLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lock")
+	first, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	require.NoError(t, err)
+	second, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	require.NoError(t, err)
+	defer second.Close()
+
+	ok, err := TryLock(first)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = TryLock(second)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, first.Close())
+	ok, err = TryLock(second)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -0,0 +1,20 @@
+//go:build unix
+
+package fileutils
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// TryLock takes an exclusive advisory lock on the open file without
+// waiting. It reports false if another process, or another open file of
+// this one, holds the lock. The lock is released when the file is closed.
+func TryLock(f *os.File) (bool, error) {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,24 @@
+//go:build windows
+
+package fileutils
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// TryLock takes an exclusive lock on the open file without waiting. It
+// reports false if another process, or another open file of this one,
+// holds the lock. The lock is released when the file is closed.
+func TryLock(f *os.File) (bool, error) {
+	// Windows locks are mandatory, so a byte far past the end of the file
+	// is locked, leaving the content readable by whoever waits for it.
+	overlapped := &windows.Overlapped{Offset: 0xFFFFFFFF, OffsetHigh: 0x7FFFFFFF}
+	err := windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, overlapped)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,57 @@
+package fileutils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// binarySniffLen is how much of a file is inspected to decide whether it
+// is text, matching the heuristic git uses.
+const binarySniffLen = 8000
+
+// ToSlash converts both Windows and Unix separators to forward slashes,
+// regardless of the host OS, so paths written on Windows match elsewhere.
+func ToSlash(p string) string {
+	return strings.ReplaceAll(p, `\`, "/")
+}
+
+// NormalizeNewlines converts CRLF line endings to LF.
+func NormalizeNewlines(data []byte) []byte {
+	return bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+}
+
+// IsText reports whether data looks like text, i.e. has no NUL byte near
+// its start.
+func IsText(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), binarySniffLen)], 0) < 0
+}
+
+// HashFiles returns a SHA-256 over the given files in fsys. Paths are
+// hashed with forward slashes and text content with LF line endings, so
+// the same checkout hashes identically on Windows and Linux.
+func HashFiles(fsys fs.FS, paths []string) (string, error) {
+	sorted := make([]string, len(paths))
+	for i, p := range paths {
+		sorted[i] = ToSlash(p)
+	}
+	sort.Strings(sorted)
+
+	h := sha256.New()
+	for _, p := range sorted {
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", p, err)
+		}
+		if IsText(data) {
+			data = NormalizeNewlines(data)
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", p, len(data))
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,52 @@
+package fileutils
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToSlash(t *testing.T) {
+	assert.Equal(t, "build/out/app.exe", ToSlash(`build\out\app.exe`))
+	assert.Equal(t, "build/out", ToSlash("build/out"))
+}
+
+func TestNormalizeNewlines(t *testing.T) {
+	assert.Equal(t, "a\nb\n", string(NormalizeNewlines([]byte("a\r\nb\r\n"))))
+	assert.Equal(t, "a\rb", string(NormalizeNewlines([]byte("a\rb"))))
+}
+
+func TestHashFiles_MatchesAcrossPlatforms(t *testing.T) {
+	unix := fstest.MapFS{
+		"src/main.go": {Data: []byte("package main\n\nfunc main() {}\n")},
+		"logo.png":    {Data: []byte("\x89PNG\x00\r\n")},
+	}
+	windows := fstest.MapFS{
+		"src/main.go": {Data: []byte("package main\r\n\r\nfunc main() {}\r\n")},
+		"logo.png":    {Data: []byte("\x89PNG\x00\r\n")},
+	}
+
+	unixHash, err := HashFiles(unix, []string{"src/main.go", "logo.png"})
+	require.NoError(t, err)
+	windowsHash, err := HashFiles(windows, []string{"logo.png", `src\main.go`})
+	require.NoError(t, err)
+	assert.Equal(t, unixHash, windowsHash)
+}
+
+func TestHashFiles_BinaryContentNotNormalized(t *testing.T) {
+	lf := fstest.MapFS{"blob": {Data: []byte("\x00\n")}}
+	crlf := fstest.MapFS{"blob": {Data: []byte("\x00\r\n")}}
+
+	lfHash, err := HashFiles(lf, []string{"blob"})
+	require.NoError(t, err)
+	crlfHash, err := HashFiles(crlf, []string{"blob"})
+	require.NoError(t, err)
+	assert.NotEqual(t, lfHash, crlfHash)
+}
+
+func TestHashFiles_MissingFile(t *testing.T) {
+	_, err := HashFiles(fstest.MapFS{}, []string{"missing"})
+	assert.ErrorContains(t, err, "failed to read missing")
+}
@@ -0,0 +1,53 @@
+package fileutils
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Permissions are the modes given to collected files. A zero mode leaves
+// the matching entries unchanged.
+type Permissions struct {
+	File       fs.FileMode
+	Executable fs.FileMode
+	Dir        fs.FileMode
+}
+
+// IsZero reports whether no mode is set.
+func (p Permissions) IsZero() bool {
+	return p == Permissions{}
+}
+
+// ApplyPermissions sets the mode of path and, for a directory, of
+// everything below it. Files with any execute bit get p.Executable and
+// other files get p.File. Symlinks are left alone.
+func ApplyPermissions(path string, p Permissions) error {
+	if p.IsZero() {
+		return nil
+	}
+	return filepath.WalkDir(path, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		var mode fs.FileMode
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			return nil
+		case info.IsDir():
+			mode = p.Dir
+		case info.Mode()&0o111 != 0:
+			mode = p.Executable
+		default:
+			mode = p.File
+		}
+		if mode == 0 || info.Mode().Perm() == mode {
+			return nil
+		}
+		return os.Chmod(current, mode)
+	})
+}
@@ -0,0 +1,39 @@
+package fileutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func modeOf(t *testing.T, path string) os.FileMode {
+	t.Helper()
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	return info.Mode().Perm()
+}
+
+func TestApplyPermissions(t *testing.T) {
+	dir := t.TempDir()
+	dist := filepath.Join(dir, "dist")
+	require.NoError(t, os.MkdirAll(filepath.Join(dist, "bin"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dist, "bin", "app"), nil, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dist, "README"), nil, 0o600))
+
+	require.NoError(t, ApplyPermissions(dist, Permissions{File: 0o644, Executable: 0o755, Dir: 0o755}))
+	assert.Equal(t, os.FileMode(0o755), modeOf(t, dist))
+	assert.Equal(t, os.FileMode(0o755), modeOf(t, filepath.Join(dist, "bin")))
+	assert.Equal(t, os.FileMode(0o755), modeOf(t, filepath.Join(dist, "bin", "app")))
+	assert.Equal(t, os.FileMode(0o644), modeOf(t, filepath.Join(dist, "README")))
+}
+
+func TestApplyPermissions_ZeroModesUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+
+	require.NoError(t, ApplyPermissions(path, Permissions{Executable: 0o755}))
+	assert.Equal(t, os.FileMode(0o600), modeOf(t, path))
+}
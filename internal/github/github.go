@@ -0,0 +1,125 @@
+// Package github creates releases through the GitHub REST API.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jgfranco17/devops/internal/outbound"
+)
+
+// DefaultAPIURL is the base URL of the public GitHub API.
+const DefaultAPIURL = "https://api.github.com"
+
+// Client calls the GitHub API with a token.
+type Client struct {
+	APIURL string
+	Token  string
+	HTTP   *outbound.Client
+}
+
+// Release is a GitHub release.
+type Release struct {
+	ID        int64  `json:"id"`
+	HTMLURL   string `json:"html_url"`
+	UploadURL string `json:"upload_url"`
+}
+
+// ParseRepo extracts the owner and name of a repository from its HTTPS or
+// SSH URL, e.g. https://github.com/owner/name.git or
+// git@github.com:owner/name.git.
+func ParseRepo(repoURL string) (string, string, error) {
+	rest := repoURL
+	switch {
+	case strings.HasPrefix(rest, "git@"):
+		_, rest, _ = strings.Cut(rest, ":")
+	default:
+		u, err := url.Parse(rest)
+		if err != nil || u.Host == "" {
+			return "", "", fmt.Errorf("invalid repository URL '%s'", repoURL)
+		}
+		rest = u.Path
+	}
+	parts := strings.Split(strings.Trim(strings.TrimSuffix(rest, ".git"), "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("repository URL '%s' does not name an owner and repository", repoURL)
+	}
+	return parts[0], parts[1], nil
+}
+
+// CreateRelease publishes a release of an existing tag, with notes
+// generated by GitHub from the changes since the previous release.
+func (c *Client) CreateRelease(ctx context.Context, owner string, repo string, tag string) (Release, error) {
+	body, err := json.Marshal(map[string]any{
+		"tag_name":               tag,
+		"name":                   tag,
+		"generate_release_notes": true,
+	})
+	if err != nil {
+		return Release{}, err
+	}
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/releases", strings.TrimSuffix(c.APIURL, "/"), owner, repo)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return Release{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var release Release
+	if err := c.do(ctx, req, &release); err != nil {
+		return Release{}, fmt.Errorf("failed to create release %s: %w", tag, err)
+	}
+	return release, nil
+}
+
+// UploadAsset attaches a file to a release under its base name.
+func (c *Client) UploadAsset(ctx context.Context, release Release, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	// The upload URL is a template such as ".../assets{?name,label}".
+	base, _, _ := strings.Cut(release.UploadURL, "{")
+	endpoint := base + "?name=" + url.QueryEscape(filepath.Base(path))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if err := c.do(ctx, req, nil); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// do sends an authenticated request and decodes the JSON response into out.
+func (c *Client) do(ctx context.Context, req *http.Request, out any) error {
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	resp, err := c.HTTP.Do(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("unexpected status %d %s: %s", resp.StatusCode, http.StatusText(resp.StatusCode), apiErr.Message)
+		}
+		return fmt.Errorf("unexpected status %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,89 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jgfranco17/devops/internal/outbound"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRepo(t *testing.T) {
+	tests := []struct {
+		url   string
+		owner string
+		repo  string
+		err   string
+	}{
+		{url: "https://github.com/jgfranco17/devops", owner: "jgfranco17", repo: "devops"},
+		{url: "https://github.com/jgfranco17/devops.git", owner: "jgfranco17", repo: "devops"},
+		{url: "git@github.com:jgfranco17/devops.git", owner: "jgfranco17", repo: "devops"},
+		{url: "https://github.com/jgfranco17", err: "repository URL 'https://github.com/jgfranco17' does not name an owner and repository"},
+		{url: "devops", err: "invalid repository URL 'devops'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			owner, repo, err := ParseRepo(tt.url)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.owner, owner)
+			assert.Equal(t, tt.repo, repo)
+		})
+	}
+}
+
+func TestClient_Release(t *testing.T) {
+	var created map[string]any
+	var uploaded string
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/repos/owner/app/releases":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			w.WriteHeader(http.StatusCreated)
+			_, _ = io.WriteString(w, `{"id": 7, "html_url": "https://github.com/owner/app/releases/tag/v1.0.0", "upload_url": "`+server.URL+`/uploads/7/assets{?name,label}"}`)
+		case "/uploads/7/assets":
+			assert.Equal(t, "app-1.0.0.tar.gz", r.URL.Query().Get("name"))
+			data, _ := io.ReadAll(r.Body)
+			uploaded = string(data)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	client := &Client{APIURL: server.URL, Token: "token", HTTP: &outbound.Client{HTTP: server.Client()}}
+
+	release, err := client.CreateRelease(context.Background(), "owner", "app", "v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), release.ID)
+	assert.Equal(t, map[string]any{"tag_name": "v1.0.0", "name": "v1.0.0", "generate_release_notes": true}, created)
+
+	path := filepath.Join(t.TempDir(), "app-1.0.0.tar.gz")
+	require.NoError(t, os.WriteFile(path, []byte("archive"), 0644))
+	require.NoError(t, client.UploadAsset(context.Background(), release, path))
+	assert.Equal(t, "archive", uploaded)
+}
+
+func TestClient_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = io.WriteString(w, `{"message": "Validation Failed"}`)
+	}))
+	t.Cleanup(server.Close)
+	client := &Client{APIURL: server.URL, Token: "token", HTTP: &outbound.Client{HTTP: server.Client()}}
+
+	_, err := client.CreateRelease(context.Background(), "owner", "app", "v1.0.0")
+	assert.EqualError(t, err, "failed to create release v1.0.0: unexpected status 422 Unprocessable Entity: Validation Failed")
+}
@@ -0,0 +1,148 @@
+// Package hostinfo probes the machine devops runs on: CPUs, memory, free
+// disk space, OS release and whether it is inside a container.
+package hostinfo
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/jgfranco17/devops/internal/fileutils"
+)
+
+// Info describes the host. Values that could not be determined are left
+// zero.
+type Info struct {
+	OS            string `json:"os"`
+	Arch          string `json:"arch"`
+	OSRelease     string `json:"os_release,omitempty"`
+	CPUs          int    `json:"cpus"`
+	MemoryBytes   uint64 `json:"memory_bytes,omitempty"`
+	DiskFreeBytes uint64 `json:"disk_free_bytes,omitempty"`
+	Container     string `json:"container,omitempty"`
+}
+
+// hostFS is the root filesystem that /proc, /etc and /run files are read
+// from.
+var hostFS fs.FS = os.DirFS("/")
+
+// Collect probes the host, measuring free disk space in dir.
+func Collect(dir string) Info {
+	info := Info{
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		OSRelease: OSRelease(),
+		CPUs:      CPUs(),
+		Container: Container(),
+	}
+	if memory, err := Memory(); err == nil {
+		info.MemoryBytes = memory
+	}
+	if free, err := DiskFree(dir); err == nil {
+		info.DiskFreeBytes = free
+	}
+	return info
+}
+
+// CPUs returns the number of logical CPUs usable by the process.
+func CPUs() int {
+	return runtime.NumCPU()
+}
+
+// DiskFree returns the bytes available to unprivileged users on the
+// filesystem containing path.
+func DiskFree(path string) (uint64, error) {
+	return fileutils.FreeDiskSpace(path)
+}
+
+// Memory returns the total physical memory in bytes. It is only supported
+// where /proc/meminfo exists.
+func Memory() (uint64, error) {
+	return meminfo("MemTotal")
+}
+
+// AvailableMemory returns the bytes of memory available for new processes
+// without swapping. It is only supported where /proc/meminfo exists.
+func AvailableMemory() (uint64, error) {
+	return meminfo("MemAvailable")
+}
+
+// meminfo reads a field of /proc/meminfo in bytes.
+func meminfo(field string) (uint64, error) {
+	file, err := hostFS.Open("proc/meminfo")
+	if err != nil {
+		return 0, fmt.Errorf("memory lookup is not supported on this platform: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != field+":" {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s in /proc/meminfo: %w", field, err)
+		}
+		return kb * 1024, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("%s not found in /proc/meminfo", field)
+}
+
+// OSRelease returns the distribution name from /etc/os-release, such as
+// "Ubuntu 24.04 LTS", or an empty string where there is none.
+func OSRelease() string {
+	data, err := fs.ReadFile(hostFS, "etc/os-release")
+	if err != nil {
+		return ""
+	}
+	values := map[string]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if ok {
+			values[key] = strings.Trim(value, `"'`)
+		}
+	}
+	if values["PRETTY_NAME"] != "" {
+		return values["PRETTY_NAME"]
+	}
+	return strings.TrimSpace(values["NAME"] + " " + values["VERSION_ID"])
+}
+
+// Container returns the container runtime devops runs in, one of
+// "kubernetes", "podman", "docker" or "containerd", or an empty string
+// when it does not appear to run in a container.
+func Container() string {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return "kubernetes"
+	}
+	if _, err := fs.Stat(hostFS, "run/.containerenv"); err == nil {
+		return "podman"
+	}
+	if _, err := fs.Stat(hostFS, ".dockerenv"); err == nil {
+		return "docker"
+	}
+	cgroup, err := fs.ReadFile(hostFS, "proc/1/cgroup")
+	if err != nil {
+		return ""
+	}
+	for _, probe := range []struct{ marker, name string }{
+		{"kubepods", "kubernetes"},
+		{"docker", "docker"},
+		{"libpod", "podman"},
+		{"containerd", "containerd"},
+	} {
+		if strings.Contains(string(cgroup), probe.marker) {
+			return probe.name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+package hostinfo
+
+import (
+	"runtime"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withHostFS(t *testing.T, fsys fstest.MapFS) {
+	t.Helper()
+	original := hostFS
+	hostFS = fsys
+	t.Cleanup(func() { hostFS = original })
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+}
+
+func TestMemory(t *testing.T) {
+	withHostFS(t, fstest.MapFS{
+		"proc/meminfo": {Data: []byte("MemTotal:       16318412 kB\nMemFree:         1234 kB\n")},
+	})
+	memory, err := Memory()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(16318412*1024), memory)
+
+	withHostFS(t, fstest.MapFS{})
+	_, err = Memory()
+	assert.ErrorContains(t, err, "memory lookup is not supported on this platform")
+}
+
+func TestAvailableMemory(t *testing.T) {
+	withHostFS(t, fstest.MapFS{
+		"proc/meminfo": {Data: []byte("MemTotal:       16318412 kB\nMemFree:         1234 kB\nMemAvailable:    8000000 kB\n")},
+	})
+	memory, err := AvailableMemory()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(8000000*1024), memory)
+
+	withHostFS(t, fstest.MapFS{
+		"proc/meminfo": {Data: []byte("MemTotal:       16318412 kB\n")},
+	})
+	_, err = AvailableMemory()
+	assert.EqualError(t, err, "MemAvailable not found in /proc/meminfo")
+}
+
+func TestOSRelease(t *testing.T) {
+	withHostFS(t, fstest.MapFS{
+		"etc/os-release": {Data: []byte("NAME=\"Ubuntu\"\nVERSION_ID=\"24.04\"\nPRETTY_NAME=\"Ubuntu 24.04 LTS\"\n")},
+	})
+	assert.Equal(t, "Ubuntu 24.04 LTS", OSRelease())
+
+	withHostFS(t, fstest.MapFS{
+		"etc/os-release": {Data: []byte("NAME=Alpine\nVERSION_ID=3.20\n")},
+	})
+	assert.Equal(t, "Alpine 3.20", OSRelease())
+
+	withHostFS(t, fstest.MapFS{})
+	assert.Empty(t, OSRelease())
+}
+
+func TestContainer(t *testing.T) {
+	testCases := []struct {
+		name string
+		fsys fstest.MapFS
+		want string
+	}{
+		{"none", fstest.MapFS{"proc/1/cgroup": {Data: []byte("0::/init.scope\n")}}, ""},
+		{"docker marker", fstest.MapFS{".dockerenv": {}}, "docker"},
+		{"podman marker", fstest.MapFS{"run/.containerenv": {}}, "podman"},
+		{"kubernetes cgroup", fstest.MapFS{"proc/1/cgroup": {Data: []byte("11:memory:/kubepods/besteffort/pod1\n")}}, "kubernetes"},
+		{"docker cgroup", fstest.MapFS{"proc/1/cgroup": {Data: []byte("12:cpu:/docker/3f2a\n")}}, "docker"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			withHostFS(t, tc.fsys)
+			assert.Equal(t, tc.want, Container())
+		})
+	}
+
+	withHostFS(t, fstest.MapFS{})
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+	assert.Equal(t, "kubernetes", Container())
+}
+
+func TestCollect(t *testing.T) {
+	withHostFS(t, fstest.MapFS{
+		"proc/meminfo":   {Data: []byte("MemTotal: 1024 kB\n")},
+		"etc/os-release": {Data: []byte("PRETTY_NAME=Debian\n")},
+		".dockerenv":     {},
+	})
+	info := Collect(t.TempDir())
+	assert.Equal(t, runtime.GOOS, info.OS)
+	assert.Equal(t, runtime.GOARCH, info.Arch)
+	assert.Equal(t, runtime.NumCPU(), info.CPUs)
+	assert.Equal(t, uint64(1024*1024), info.MemoryBytes)
+	assert.Equal(t, "Debian", info.OSRelease)
+	assert.Equal(t, "docker", info.Container)
+}
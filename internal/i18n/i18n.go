@@ -0,0 +1,145 @@
+// Package i18n translates user-facing messages. Messages are looked up by
+// their English text, so anything missing from a catalog stays in English.
+package i18n
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLocale is the locale messages are written in.
+const DefaultLocale = "en"
+
+// Catalog maps English messages to their translation.
+type Catalog map[string]string
+
+//go:embed locales/*.yaml
+var embedded embed.FS
+
+var (
+	mu      sync.RWMutex
+	locale  = DefaultLocale
+	catalog = Catalog{}
+)
+
+// Detect returns preferred if set, otherwise the locale from LC_ALL,
+// LC_MESSAGES or LANG, normalized to a tag such as "pt_BR". The C and
+// POSIX locales map to English.
+func Detect(preferred string) string {
+	candidates := []string{preferred, os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")}
+	for _, candidate := range candidates {
+		tag, _, _ := strings.Cut(candidate, ".")
+		tag, _, _ = strings.Cut(tag, "@")
+		tag = strings.ReplaceAll(tag, "-", "_")
+		switch tag {
+		case "":
+			continue
+		case "C", "POSIX":
+			return DefaultLocale
+		}
+		return tag
+	}
+	return DefaultLocale
+}
+
+// SetLocale switches messages to the given locale, trying the full tag
+// (pt_BR) before the language (pt). Catalogs found in dirs, such as the
+// user's locales directory, take precedence over the built-in ones. It
+// returns the locale in use, which is English when no catalog exists. On
+// error the locale is left unchanged.
+func SetLocale(tag string, dirs ...string) (string, error) {
+	selected, loaded := DefaultLocale, Catalog{}
+	for _, name := range fallbacks(tag) {
+		found, err := loadCatalog(name, dirs)
+		if err != nil {
+			return Locale(), err
+		}
+		if found != nil {
+			selected, loaded = name, found
+			break
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	locale, catalog = selected, loaded
+	return selected, nil
+}
+
+// Locale returns the locale in use.
+func Locale() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return locale
+}
+
+func fallbacks(tag string) []string {
+	if tag == "" || tag == DefaultLocale {
+		return nil
+	}
+	names := []string{tag}
+	if language, _, ok := strings.Cut(tag, "_"); ok {
+		names = append(names, language)
+	}
+	return names
+}
+
+// loadCatalog merges the embedded catalog for name with those in dirs. It
+// returns nil when there is none.
+func loadCatalog(name string, dirs []string) (Catalog, error) {
+	var merged Catalog
+	add := func(fsys fs.FS, path string) error {
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		var entries Catalog
+		if err := yaml.Unmarshal(data, &entries); err != nil {
+			return fmt.Errorf("invalid message catalog %s: %w", path, err)
+		}
+		if merged == nil {
+			merged = Catalog{}
+		}
+		for message, translation := range entries {
+			merged[message] = translation
+		}
+		return nil
+	}
+
+	if err := add(embedded, "locales/"+name+".yaml"); err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		if err := add(os.DirFS(dir), filepath.ToSlash(name+".yaml")); err != nil {
+			return nil, err
+		}
+	}
+	return merged, nil
+}
+
+// Translate returns the translation of message in the current locale, or
+// message itself when there is none.
+func Translate(message string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	if translation, ok := catalog[message]; ok && translation != "" {
+		return translation
+	}
+	return message
+}
+
+// T translates a format string and formats it with args.
+func T(format string, args ...any) string {
+	return fmt.Sprintf(Translate(format), args...)
+}
@@ -0,0 +1,77 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetLocale(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		_, _ = SetLocale(DefaultLocale)
+	})
+}
+
+func TestDetect(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "pt_BR.UTF-8")
+	assert.Equal(t, "pt_BR", Detect(""))
+	assert.Equal(t, "es", Detect("es"))
+	assert.Equal(t, "de_DE", Detect("de-DE"))
+
+	t.Setenv("LC_ALL", "C")
+	assert.Equal(t, DefaultLocale, Detect(""))
+
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LANG", "")
+	assert.Equal(t, DefaultLocale, Detect(""))
+}
+
+func TestSetLocale_Embedded(t *testing.T) {
+	resetLocale(t)
+
+	selected, err := SetLocale("es_MX")
+	require.NoError(t, err)
+	assert.Equal(t, "es", selected)
+	assert.Equal(t, "es", Locale())
+	assert.Equal(t, "Sugerencias:", Translate("Suggestions:"))
+	assert.Equal(t, "Pasos de prueba (3)", T("Test steps (%d)", 3))
+	assert.Equal(t, "Not in any catalog", Translate("Not in any catalog"))
+
+	selected, err = SetLocale("xx")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultLocale, selected)
+	assert.Equal(t, "Suggestions:", Translate("Suggestions:"))
+}
+
+func TestSetLocale_UserCatalog(t *testing.T) {
+	resetLocale(t)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "es.yaml"), []byte("\"Fixes:\": \"Arreglos:\"\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pt_BR.yaml"), []byte("\"Fixes:\": \"Correções:\"\n"), 0o644))
+
+	_, err := SetLocale("es", dir)
+	require.NoError(t, err)
+	assert.Equal(t, "Arreglos:", Translate("Fixes:"))
+	assert.Equal(t, "Sugerencias:", Translate("Suggestions:"))
+
+	selected, err := SetLocale("pt_BR", dir)
+	require.NoError(t, err)
+	assert.Equal(t, "pt_BR", selected)
+	assert.Equal(t, "Correções:", Translate("Fixes:"))
+}
+
+func TestSetLocale_InvalidCatalog(t *testing.T) {
+	resetLocale(t)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fr.yaml"), []byte("- not a mapping\n"), 0o644))
+
+	selected, err := SetLocale("fr", dir)
+	assert.ErrorContains(t, err, "invalid message catalog fr.yaml")
+	assert.Equal(t, DefaultLocale, selected)
+}
@@ -0,0 +1,101 @@
+// Package objectstore writes objects to remote stores such as S3, Google
+// Cloud Storage or a plain HTTP server, for sharing cache entries and
+// publishing artifacts.
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jgfranco17/devops/internal/outbound"
+)
+
+// Store holds objects by key.
+type Store interface {
+	Has(ctx context.Context, key string) (bool, error)
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// NewClient returns an outbound client suited to object stores: no rate
+// limit, few retries and a store that keeps failing is skipped quickly, so
+// an unreachable cache costs little more than a cache miss.
+func NewClient() *outbound.Client {
+	return &outbound.Client{
+		HTTP:             &http.Client{Timeout: 10 * time.Second},
+		MaxRetries:       2,
+		BaseDelay:        200 * time.Millisecond,
+		MaxDelay:         2 * time.Second,
+		FailureThreshold: 3,
+		Cooldown:         5 * time.Minute,
+	}
+}
+
+// HTTPStore keeps objects under a base URL, answering HEAD and PUT on
+// <URL>/<key>. It fits plain cache servers and object stores accepting
+// bearer tokens, such as the Google Cloud Storage XML API.
+type HTTPStore struct {
+	URL    string
+	Token  string
+	Client *outbound.Client
+}
+
+func (s *HTTPStore) Has(ctx context.Context, key string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, s.objectURL(key), nil)
+	if err != nil {
+		return false, err
+	}
+	s.authorize(req)
+	return exists(ctx, s.Client, req)
+}
+
+func (s *HTTPStore) Put(ctx context.Context, key string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	s.authorize(req)
+	return put(ctx, s.Client, req)
+}
+
+func (s *HTTPStore) objectURL(key string) string {
+	return strings.TrimSuffix(s.URL, "/") + "/" + key
+}
+
+func (s *HTTPStore) authorize(req *http.Request) {
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+}
+
+// exists sends a HEAD request for an object.
+func exists(ctx context.Context, client *outbound.Client, req *http.Request) (bool, error) {
+	resp, err := client.Do(ctx, req)
+	if err != nil {
+		return false, err
+	}
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode >= 300:
+		return false, fmt.Errorf("%s: unexpected status %d %s", req.URL.Host, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	return true, nil
+}
+
+// put sends a request creating an object.
+func put(ctx context.Context, client *outbound.Client, req *http.Request) error {
+	resp, err := client.Do(ctx, req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: unexpected status %d %s", req.URL.Host, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	return nil
+}
@@ -0,0 +1,314 @@
+// Package outbound sends requests to external services such as chat and
+// code hosting APIs. Each endpoint is rate limited, failed requests are
+// retried with jittered backoff and an endpoint that keeps failing is
+// short-circuited for a while, so a flaky service cannot stall a run.
+package outbound
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+)
+
+// ErrCircuitOpen is returned without sending when an endpoint has failed
+// too often recently.
+var ErrCircuitOpen = errors.New("endpoint disabled after repeated failures")
+
+// Failure is a request that could not be delivered.
+type Failure struct {
+	Endpoint string
+	Err      error
+	Time     time.Time
+}
+
+// Client sends requests with rate limiting, retries and circuit breaking
+// per endpoint, where the endpoint is the request host.
+type Client struct {
+	HTTP *http.Client
+
+	// MinInterval is the minimum time between two requests to the same
+	// endpoint.
+	MinInterval time.Duration
+
+	// MaxRetries is how many times a failed request is retried. Retries
+	// wait a random duration up to BaseDelay doubled per attempt, capped
+	// at MaxDelay.
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+
+	// FailureThreshold consecutive failures open the circuit of an
+	// endpoint for Cooldown, after which a single request is let through.
+	FailureThreshold int
+	Cooldown         time.Duration
+
+	mu        sync.Mutex
+	endpoints map[string]*endpoint
+	failures  []Failure
+
+	now    func() time.Time
+	sleep  func(ctx context.Context, d time.Duration) error
+	jitter func(max time.Duration) time.Duration
+}
+
+// endpoint is the rate limiting and circuit state of one host.
+type endpoint struct {
+	next      time.Time
+	failures  int
+	openUntil time.Time
+}
+
+// New returns a client with defaults suited to notification endpoints.
+func New() *Client {
+	return &Client{
+		HTTP:             &http.Client{Timeout: 10 * time.Second},
+		MinInterval:      time.Second,
+		MaxRetries:       3,
+		BaseDelay:        500 * time.Millisecond,
+		MaxDelay:         10 * time.Second,
+		FailureThreshold: 5,
+		Cooldown:         time.Minute,
+	}
+}
+
+// Do sends the request, waiting for the endpoint's rate limit and retrying
+// network errors, 429 and 5xx responses. The request body must be
+// replayable through GetBody, as http.NewRequest sets up for in-memory
+// bodies. Undelivered requests are recorded as failures.
+func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	resp, err := c.do(ctx, host, req)
+	if err != nil {
+		c.mu.Lock()
+		c.failures = append(c.failures, Failure{Endpoint: host, Err: err, Time: c.clock()})
+		c.mu.Unlock()
+	}
+	return resp, err
+}
+
+// Deliver sends a request whose outcome must not affect the run, such as a
+// notification. A failed delivery is logged as a warning and reported as
+// false instead of returning an error.
+func (c *Client) Deliver(ctx context.Context, req *http.Request) bool {
+	resp, err := c.Do(ctx, req)
+	if err != nil {
+		logging.FromContext(ctx).Warnf("Failed to deliver to %s: %v", req.URL.Host, err)
+		return false
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		err := fmt.Errorf("%s: unexpected status %d %s", req.URL.Host, resp.StatusCode, http.StatusText(resp.StatusCode))
+		c.mu.Lock()
+		c.failures = append(c.failures, Failure{Endpoint: req.URL.Host, Err: err, Time: c.clock()})
+		c.mu.Unlock()
+		logging.FromContext(ctx).Warnf("Failed to deliver: %v", err)
+		return false
+	}
+	return true
+}
+
+func (c *Client) do(ctx context.Context, host string, req *http.Request) (*http.Response, error) {
+	if err := c.allow(host); err != nil {
+		return nil, err
+	}
+	var lastErr error
+	for attempt := 0; attempt <= c.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if err := c.wait(ctx, c.backoff(attempt, lastErr)); err != nil {
+				return nil, err
+			}
+		}
+		if err := c.wait(ctx, c.reserve(host)); err != nil {
+			return nil, err
+		}
+		attemptReq, err := replay(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.HTTP.Do(attemptReq)
+		if err == nil && !retryable(resp.StatusCode) {
+			c.record(host, true)
+			return resp, nil
+		}
+		if err == nil {
+			lastErr = &StatusError{Code: resp.StatusCode, RetryAfter: retryAfter(resp)}
+			_ = resp.Body.Close()
+		} else {
+			lastErr = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	c.record(host, false)
+	return nil, fmt.Errorf("%s: %w", host, lastErr)
+}
+
+// StatusError is a response status worth retrying.
+type StatusError struct {
+	Code       int
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d %s", e.Code, http.StatusText(e.Code))
+}
+
+// Failures returns the requests that could not be delivered so far.
+func (c *Client) Failures() []Failure {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Failure(nil), c.failures...)
+}
+
+// Summary describes the undelivered requests per endpoint, or is empty if
+// every request was delivered.
+func (c *Client) Summary() string {
+	counts := map[string]int{}
+	order := []string{}
+	for _, failure := range c.Failures() {
+		if counts[failure.Endpoint] == 0 {
+			order = append(order, failure.Endpoint)
+		}
+		counts[failure.Endpoint]++
+	}
+	summary := ""
+	for _, host := range order {
+		if summary != "" {
+			summary += ", "
+		}
+		summary += fmt.Sprintf("%s (%d failed)", host, counts[host])
+	}
+	return summary
+}
+
+// allow fails fast while the endpoint's circuit is open.
+func (c *Client) allow(host string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	state := c.endpoint(host)
+	if c.clock().Before(state.openUntil) {
+		return fmt.Errorf("%s: %w", host, ErrCircuitOpen)
+	}
+	return nil
+}
+
+// reserve claims the next request slot of the endpoint and returns how
+// long to wait for it.
+func (c *Client) reserve(host string) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	state := c.endpoint(host)
+	now := c.clock()
+	slot := state.next
+	if slot.Before(now) {
+		slot = now
+	}
+	state.next = slot.Add(c.MinInterval)
+	return slot.Sub(now)
+}
+
+// record updates the circuit of the endpoint after a delivery attempt.
+func (c *Client) record(host string, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	state := c.endpoint(host)
+	if ok {
+		state.failures = 0
+		return
+	}
+	state.failures++
+	if c.FailureThreshold > 0 && state.failures >= c.FailureThreshold {
+		state.openUntil = c.clock().Add(c.Cooldown)
+		state.failures = c.FailureThreshold - 1
+	}
+}
+
+func (c *Client) endpoint(host string) *endpoint {
+	if c.endpoints == nil {
+		c.endpoints = map[string]*endpoint{}
+	}
+	state, ok := c.endpoints[host]
+	if !ok {
+		state = &endpoint{}
+		c.endpoints[host] = state
+	}
+	return state
+}
+
+// backoff returns the delay before a retry, honouring Retry-After.
+func (c *Client) backoff(attempt int, lastErr error) time.Duration {
+	var statusErr *StatusError
+	if errors.As(lastErr, &statusErr) && statusErr.RetryAfter > 0 {
+		return min(statusErr.RetryAfter, c.MaxDelay)
+	}
+	limit := c.BaseDelay << (attempt - 1)
+	if limit <= 0 || limit > c.MaxDelay {
+		limit = c.MaxDelay
+	}
+	if c.jitter != nil {
+		return c.jitter(limit)
+	}
+	return rand.N(limit + 1)
+}
+
+func (c *Client) wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	if c.sleep != nil {
+		return c.sleep(ctx, d)
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (c *Client) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// replay returns a copy of req with a fresh body for another attempt.
+func replay(ctx context.Context, req *http.Request) (*http.Request, error) {
+	clone := req.Clone(ctx)
+	if req.Body == nil || req.Body == http.NoBody {
+		return clone, nil
+	}
+	if req.GetBody == nil {
+		return nil, errors.New("request body cannot be replayed for retries")
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	clone.Body = body
+	return clone, nil
+}
+
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// retryAfter reads a Retry-After header given in seconds.
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,182 @@
+package outbound
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jgfranco17/dev-tooling-go/logging"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient returns a client that records its waits instead of sleeping
+// and uses the upper bound of each backoff.
+func newTestClient(now *time.Time) (*Client, *[]time.Duration) {
+	waits := []time.Duration{}
+	client := New()
+	client.now = func() time.Time { return *now }
+	client.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		*now = now.Add(d)
+		return ctx.Err()
+	}
+	client.jitter = func(max time.Duration) time.Duration { return max }
+	return client, &waits
+}
+
+func newRequest(t *testing.T, url string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(`{"text":"done"}`))
+	require.NoError(t, err)
+	return req
+}
+
+func TestDo_RetriesWithBackoff(t *testing.T) {
+	var calls atomic.Int32
+	bodies := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	now := time.Unix(0, 0)
+	client, waits := newTestClient(&now)
+	client.MinInterval = 0
+	resp, err := client.Do(context.Background(), newRequest(t, server.URL))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []time.Duration{500 * time.Millisecond, time.Second}, *waits)
+	assert.Equal(t, []string{`{"text":"done"}`, `{"text":"done"}`, `{"text":"done"}`}, bodies)
+	assert.Empty(t, client.Failures())
+}
+
+func TestDo_HonoursRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	now := time.Unix(0, 0)
+	client, waits := newTestClient(&now)
+	client.MinInterval = 0
+	resp, err := client.Do(context.Background(), newRequest(t, server.URL))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, []time.Duration{3 * time.Second}, *waits)
+}
+
+func TestDo_RateLimitsPerEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	now := time.Unix(0, 0)
+	client, waits := newTestClient(&now)
+	client.MinInterval = 2 * time.Second
+	client.sleep = func(ctx context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return nil
+	}
+	for range 3 {
+		resp, err := client.Do(context.Background(), newRequest(t, server.URL))
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, []time.Duration{2 * time.Second, 4 * time.Second}, *waits)
+}
+
+func TestDo_OpensCircuitAfterRepeatedFailures(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	now := time.Unix(0, 0)
+	client, _ := newTestClient(&now)
+	client.MaxRetries = 0
+	client.FailureThreshold = 2
+	client.Cooldown = time.Minute
+
+	for range 2 {
+		_, err := client.Do(context.Background(), newRequest(t, server.URL))
+		assert.ErrorContains(t, err, "unexpected status 502 Bad Gateway")
+	}
+	_, err := client.Do(context.Background(), newRequest(t, server.URL))
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int32(2), calls.Load())
+
+	now = now.Add(time.Minute)
+	_, err = client.Do(context.Background(), newRequest(t, server.URL))
+	assert.ErrorContains(t, err, "unexpected status 502")
+	assert.Equal(t, int32(3), calls.Load())
+
+	_, err = client.Do(context.Background(), newRequest(t, server.URL))
+	assert.ErrorIs(t, err, ErrCircuitOpen, "a failure while half open reopens the circuit")
+}
+
+func TestDeliver_LogsFailuresWithoutError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	now := time.Unix(0, 0)
+	client, _ := newTestClient(&now)
+	client.MaxRetries = 1
+
+	assert.False(t, client.Deliver(ctx, newRequest(t, server.URL)))
+	assert.False(t, client.Deliver(ctx, newRequest(t, server.URL+"/missing")))
+
+	failures := client.Failures()
+	require.Len(t, failures, 2)
+	host := strings.TrimPrefix(server.URL, "http://")
+	assert.Equal(t, host, failures[0].Endpoint)
+	assert.ErrorContains(t, failures[1].Err, "unexpected status 404 Not Found")
+	assert.Equal(t, host+" (2 failed)", client.Summary())
+}
+
+func TestDeliver_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx := logging.WithContext(context.Background(), logging.New(os.Stderr, logrus.InfoLevel))
+	now := time.Unix(0, 0)
+	client, _ := newTestClient(&now)
+
+	assert.True(t, client.Deliver(ctx, newRequest(t, server.URL)))
+	assert.Empty(t, client.Summary())
+}
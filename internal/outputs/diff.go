@@ -0,0 +1,150 @@
+package outputs
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// UnifiedDiff writes a colored unified diff of before and after to w,
+// labelling both sides with name. It reports whether the contents differ,
+// so commands that rewrite a file can share it for both --check and
+// --write.
+func UnifiedDiff(w io.Writer, name string, before, after string) bool {
+	lines := diffLines(splitLines(before), splitLines(after))
+	hunks := diffHunks(lines)
+	if len(hunks) == 0 {
+		return false
+	}
+
+	bold := color.New(color.Bold).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+
+	fmt.Fprintln(w, bold("--- a/"+name))
+	fmt.Fprintln(w, bold("+++ b/"+name))
+
+	// oldLine and newLine hold the 1-based position before each entry.
+	oldLine := make([]int, len(lines)+1)
+	newLine := make([]int, len(lines)+1)
+	oldLine[0], newLine[0] = 1, 1
+	for i, line := range lines {
+		oldLine[i+1], newLine[i+1] = oldLine[i], newLine[i]
+		if line.kind != '+' {
+			oldLine[i+1]++
+		}
+		if line.kind != '-' {
+			newLine[i+1]++
+		}
+	}
+
+	for _, hunk := range hunks {
+		lo, hi := hunk[0], hunk[1]
+		oldCount := oldLine[hi] - oldLine[lo]
+		newCount := newLine[hi] - newLine[lo]
+		fmt.Fprintln(w, cyan(fmt.Sprintf("@@ -%s +%s @@", hunkRange(oldLine[lo], oldCount), hunkRange(newLine[lo], newCount))))
+		for _, line := range lines[lo:hi] {
+			text := string(line.kind) + line.text
+			switch line.kind {
+			case '-':
+				text = red(text)
+			case '+':
+				text = green(text)
+			}
+			fmt.Fprintln(w, text)
+		}
+	}
+	return true
+}
+
+// hunkRange formats a start,count pair, using the line before the hunk
+// as the start when it is empty.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func splitLines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines computes a line diff from the longest common subsequence of
+// a and b. Definition files are small, so the quadratic table is fine.
+func diffLines(a, b []string) []diffLine {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, diffLine{'+', b[j]})
+	}
+	return lines
+}
+
+// diffHunks groups changed lines into [lo, hi) ranges with surrounding
+// context, merging changes whose context would overlap.
+func diffHunks(lines []diffLine) [][2]int {
+	var hunks [][2]int
+	for i, line := range lines {
+		if line.kind == ' ' {
+			continue
+		}
+		lo := max(0, i-diffContext)
+		hi := min(len(lines), i+diffContext+1)
+		if n := len(hunks); n > 0 && lo <= hunks[n-1][1] {
+			hunks[n-1][1] = hi
+			continue
+		}
+		hunks = append(hunks, [2]int{lo, hi})
+	}
+	return hunks
+}
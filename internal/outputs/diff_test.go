@@ -0,0 +1,63 @@
+package outputs
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = noColor })
+
+	before := "id: demo\ncodebase:\n  build:\n    steps:\n      - make\n"
+	after := "id: demo\ncodebase:\n  build:\n    steps:\n      - run: make\n        name: Build\n"
+
+	var buf bytes.Buffer
+	assert.True(t, UnifiedDiff(&buf, ".devops.yaml", before, after))
+	assert.Equal(t, `--- a/.devops.yaml
++++ b/.devops.yaml
+@@ -2,4 +2,5 @@
+ codebase:
+   build:
+     steps:
+-      - make
++      - run: make
++        name: Build
+`, buf.String())
+}
+
+func TestUnifiedDiff_SeparateHunks(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = noColor })
+
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	after := "A\nb\nc\nd\ne\nf\ng\nh\ni\n"
+
+	var buf bytes.Buffer
+	assert.True(t, UnifiedDiff(&buf, "f", before, after))
+	assert.Equal(t, `--- a/f
++++ b/f
+@@ -1,4 +1,4 @@
+-a
++A
+ b
+ c
+ d
+@@ -7,4 +7,3 @@
+ g
+ h
+ i
+-j
+`, buf.String())
+}
+
+func TestUnifiedDiff_NoChanges(t *testing.T) {
+	var buf bytes.Buffer
+	assert.False(t, UnifiedDiff(&buf, "f", "a\r\nb\r\n", "a\nb\n"))
+	assert.Empty(t, buf.String())
+}
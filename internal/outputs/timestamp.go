@@ -0,0 +1,35 @@
+package outputs
+
+import (
+	"context"
+	"time"
+)
+
+type contextKey string
+
+const localTimeKey contextKey = "local-time"
+
+// WithLocalTime makes timestamps in the context render in the local time
+// zone instead of UTC.
+func WithLocalTime(ctx context.Context) context.Context {
+	return context.WithValue(ctx, localTimeKey, true)
+}
+
+// LocalTimeFromContext reports whether timestamps should use local time.
+func LocalTimeFromContext(ctx context.Context) bool {
+	local, _ := ctx.Value(localTimeKey).(bool)
+	return local
+}
+
+// FormatTimestamp renders t as RFC3339, in UTC unless local is set.
+func FormatTimestamp(t time.Time, local bool) string {
+	if !local {
+		t = t.UTC()
+	}
+	return t.Format(time.RFC3339)
+}
+
+// Timestamp renders t as RFC3339 in the time zone chosen for the context.
+func Timestamp(ctx context.Context, t time.Time) string {
+	return FormatTimestamp(t, LocalTimeFromContext(ctx))
+}
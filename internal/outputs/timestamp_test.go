@@ -0,0 +1,18 @@
+package outputs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimestamp(t *testing.T) {
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	ts := time.Date(2026, 3, 1, 14, 30, 0, 0, zone)
+
+	ctx := context.Background()
+	assert.Equal(t, "2026-03-01T12:30:00Z", Timestamp(ctx, ts))
+	assert.Equal(t, "2026-03-01T14:30:00+02:00", Timestamp(WithLocalTime(ctx), ts))
+}
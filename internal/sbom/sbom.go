@@ -0,0 +1,185 @@
+// Package sbom renders software bills of materials in the CycloneDX and
+// SPDX JSON formats.
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Component is a dependency listed in a bill of materials.
+type Component struct {
+	Name    string
+	Version string
+	PURL    string
+}
+
+// Document describes the subject of a bill of materials and what it
+// depends on.
+type Document struct {
+	Name        string
+	Version     string
+	Tool        string
+	ToolVersion string
+	Created     time.Time
+	Components  []Component
+}
+
+// GoComponent returns the component of a Go module.
+func GoComponent(module string, version string) Component {
+	return Component{Name: module, Version: version, PURL: fmt.Sprintf("pkg:golang/%s@%s", module, version)}
+}
+
+// ParseGoMod returns the module path and the required modules of a go.mod
+// file, direct and indirect.
+func ParseGoMod(data []byte) (string, []Component, error) {
+	module := ""
+	components := []Component{}
+	inRequire := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(text)
+		switch {
+		case len(fields) == 0:
+			continue
+		case inRequire && fields[0] == ")":
+			inRequire = false
+			continue
+		case inRequire:
+		case fields[0] == "module" && len(fields) == 2:
+			module = strings.Trim(fields[1], `"`)
+			continue
+		case fields[0] == "require" && len(fields) == 2 && fields[1] == "(":
+			inRequire = true
+			continue
+		case fields[0] == "require":
+			fields = fields[1:]
+		default:
+			continue
+		}
+		if len(fields) != 2 {
+			return "", nil, fmt.Errorf("invalid requirement on line %d", line)
+		}
+		components = append(components, GoComponent(strings.Trim(fields[0], `"`), fields[1]))
+	}
+	if err := scanner.Err(); err != nil {
+		return "", nil, err
+	}
+	if module == "" {
+		return "", nil, errors.New("no module directive")
+	}
+	return module, components, nil
+}
+
+// CycloneDX renders the document as CycloneDX 1.5 JSON.
+func CycloneDX(doc Document) ([]byte, error) {
+	type component struct {
+		Type    string `json:"type"`
+		BOMRef  string `json:"bom-ref,omitempty"`
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+		PURL    string `json:"purl,omitempty"`
+	}
+	components := make([]component, 0, len(doc.Components))
+	for _, c := range doc.Components {
+		components = append(components, component{Type: "library", BOMRef: c.PURL, Name: c.Name, Version: c.Version, PURL: c.PURL})
+	}
+	serial, err := uuid()
+	if err != nil {
+		return nil, err
+	}
+	bom := map[string]any{
+		"bomFormat":    "CycloneDX",
+		"specVersion":  "1.5",
+		"serialNumber": "urn:uuid:" + serial,
+		"version":      1,
+		"metadata": map[string]any{
+			"timestamp": doc.Created.UTC().Format(time.RFC3339),
+			"tools": map[string]any{
+				"components": []component{{Type: "application", Name: doc.Tool, Version: doc.ToolVersion}},
+			},
+			"component": component{Type: "application", BOMRef: doc.Name, Name: doc.Name, Version: doc.Version},
+		},
+		"components": components,
+	}
+	return json.MarshalIndent(bom, "", "  ")
+}
+
+// SPDX renders the document as SPDX 2.3 JSON.
+func SPDX(doc Document) ([]byte, error) {
+	type externalRef struct {
+		Category string `json:"referenceCategory"`
+		Type     string `json:"referenceType"`
+		Locator  string `json:"referenceLocator"`
+	}
+	type spdxPackage struct {
+		Name             string        `json:"name"`
+		SPDXID           string        `json:"SPDXID"`
+		VersionInfo      string        `json:"versionInfo,omitempty"`
+		DownloadLocation string        `json:"downloadLocation"`
+		ExternalRefs     []externalRef `json:"externalRefs,omitempty"`
+	}
+	type relationship struct {
+		Element string `json:"spdxElementId"`
+		Type    string `json:"relationshipType"`
+		Related string `json:"relatedSpdxElement"`
+	}
+	namespace, err := uuid()
+	if err != nil {
+		return nil, err
+	}
+	root := "SPDXRef-Package-" + spdxID(doc.Name)
+	packages := []spdxPackage{{Name: doc.Name, SPDXID: root, VersionInfo: doc.Version, DownloadLocation: "NOASSERTION"}}
+	relationships := []relationship{{Element: "SPDXRef-DOCUMENT", Type: "DESCRIBES", Related: root}}
+	for i, c := range doc.Components {
+		id := fmt.Sprintf("SPDXRef-Package-%d-%s", i+1, spdxID(c.Name))
+		pkg := spdxPackage{Name: c.Name, SPDXID: id, VersionInfo: c.Version, DownloadLocation: "NOASSERTION"}
+		if c.PURL != "" {
+			pkg.ExternalRefs = []externalRef{{Category: "PACKAGE-MANAGER", Type: "purl", Locator: c.PURL}}
+		}
+		packages = append(packages, pkg)
+		relationships = append(relationships, relationship{Element: root, Type: "DEPENDS_ON", Related: id})
+	}
+	document := map[string]any{
+		"spdxVersion":       "SPDX-2.3",
+		"dataLicense":       "CC0-1.0",
+		"SPDXID":            "SPDXRef-DOCUMENT",
+		"name":              doc.Name,
+		"documentNamespace": fmt.Sprintf("https://spdx.org/spdxdocs/%s-%s", spdxID(doc.Name), namespace),
+		"creationInfo": map[string]any{
+			"created":  doc.Created.UTC().Format(time.RFC3339),
+			"creators": []string{fmt.Sprintf("Tool: %s-%s", doc.Tool, doc.ToolVersion)},
+		},
+		"packages":      packages,
+		"relationships": relationships,
+	}
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// spdxID replaces the characters SPDX identifiers do not allow.
+func spdxID(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '-'
+	}, name)
+}
+
+// uuid returns a random version 4 UUID.
+func uuid() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
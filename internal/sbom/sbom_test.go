@@ -0,0 +1,113 @@
+package sbom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const goMod = `module github.com/acme/app
+
+go 1.24
+
+require github.com/spf13/cobra v1.9.1
+
+require (
+	github.com/sirupsen/logrus v1.9.3
+	golang.org/x/sys v0.36.0 // indirect
+)
+
+replace example.com/old => example.com/new v1.0.0
+`
+
+func TestParseGoMod(t *testing.T) {
+	module, components, err := ParseGoMod([]byte(goMod))
+	require.NoError(t, err)
+	assert.Equal(t, "github.com/acme/app", module)
+	assert.Equal(t, []Component{
+		GoComponent("github.com/spf13/cobra", "v1.9.1"),
+		GoComponent("github.com/sirupsen/logrus", "v1.9.3"),
+		GoComponent("golang.org/x/sys", "v0.36.0"),
+	}, components)
+	assert.Equal(t, "pkg:golang/golang.org/x/sys@v0.36.0", components[2].PURL)
+
+	_, _, err = ParseGoMod([]byte("go 1.24\n"))
+	assert.EqualError(t, err, "no module directive")
+
+	_, _, err = ParseGoMod([]byte("module m\nrequire (\n\tbroken\n)\n"))
+	assert.EqualError(t, err, "invalid requirement on line 3")
+}
+
+func testDocument() Document {
+	return Document{
+		Name:        "app",
+		Version:     "1.0.0",
+		Tool:        "devops",
+		ToolVersion: "0.1.0",
+		Created:     time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC),
+		Components:  []Component{GoComponent("github.com/spf13/cobra", "v1.9.1")},
+	}
+}
+
+func TestCycloneDX(t *testing.T) {
+	data, err := CycloneDX(testDocument())
+	require.NoError(t, err)
+	var bom struct {
+		BOMFormat    string `json:"bomFormat"`
+		SpecVersion  string `json:"specVersion"`
+		SerialNumber string `json:"serialNumber"`
+		Metadata     struct {
+			Timestamp string `json:"timestamp"`
+			Component struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"component"`
+		} `json:"metadata"`
+		Components []struct {
+			Type string `json:"type"`
+			Name string `json:"name"`
+			PURL string `json:"purl"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(data, &bom))
+	assert.Equal(t, "CycloneDX", bom.BOMFormat)
+	assert.Equal(t, "1.5", bom.SpecVersion)
+	assert.Regexp(t, `^urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, bom.SerialNumber)
+	assert.Equal(t, "2026-05-01T12:00:00Z", bom.Metadata.Timestamp)
+	assert.Equal(t, "app", bom.Metadata.Component.Name)
+	require.Len(t, bom.Components, 1)
+	assert.Equal(t, "library", bom.Components[0].Type)
+	assert.Equal(t, "pkg:golang/github.com/spf13/cobra@v1.9.1", bom.Components[0].PURL)
+}
+
+func TestSPDX(t *testing.T) {
+	data, err := SPDX(testDocument())
+	require.NoError(t, err)
+	var document struct {
+		SPDXVersion string `json:"spdxVersion"`
+		Packages    []struct {
+			Name         string `json:"name"`
+			SPDXID       string `json:"SPDXID"`
+			ExternalRefs []struct {
+				Locator string `json:"referenceLocator"`
+			} `json:"externalRefs"`
+		} `json:"packages"`
+		Relationships []struct {
+			Element string `json:"spdxElementId"`
+			Type    string `json:"relationshipType"`
+			Related string `json:"relatedSpdxElement"`
+		} `json:"relationships"`
+	}
+	require.NoError(t, json.Unmarshal(data, &document))
+	assert.Equal(t, "SPDX-2.3", document.SPDXVersion)
+	require.Len(t, document.Packages, 2)
+	assert.Equal(t, "SPDXRef-Package-1-github.com-spf13-cobra", document.Packages[1].SPDXID)
+	assert.Equal(t, "pkg:golang/github.com/spf13/cobra@v1.9.1", document.Packages[1].ExternalRefs[0].Locator)
+	require.Len(t, document.Relationships, 2)
+	assert.Equal(t, "DESCRIBES", document.Relationships[0].Type)
+	assert.Equal(t, "DEPENDS_ON", document.Relationships[1].Type)
+	assert.Equal(t, document.Packages[1].SPDXID, document.Relationships[1].Related)
+}
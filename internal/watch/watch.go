@@ -0,0 +1,178 @@
+// Package watch reports changes to the files of a directory tree. The tree
+// is polled rather than watched through OS notifications, which keeps it
+// portable and free of extra dependencies at the cost of a short delay.
+package watch
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jgfranco17/devops/internal/fileutils"
+)
+
+// IgnoreFile lists patterns of paths the watcher does not report, in
+// .gitignore syntax.
+const IgnoreFile = ".gitignore"
+
+// Watcher polls a file tree for changes.
+type Watcher struct {
+	FS fs.FS
+
+	// Ignore holds patterns, in fileutils.Glob syntax, of paths that are
+	// not reported. A matching directory is not descended into.
+	Ignore []string
+
+	// Interval is the time between two scans of the tree.
+	Interval time.Duration
+
+	// Debounce is how long the tree must stay unchanged before a batch of
+	// changes is reported, so a burst of saves triggers a single run.
+	Debounce time.Duration
+}
+
+// fileState is what a scan remembers of a file to detect changes.
+type fileState struct {
+	size    int64
+	modTime time.Time
+	mode    fs.FileMode
+}
+
+// Changes scans the tree until ctx is done and sends each debounced batch
+// of changed paths, sorted. The channel is closed when ctx is done.
+// Files that are added, removed or modified count as changed.
+func (w *Watcher) Changes(ctx context.Context) <-chan []string {
+	changes := make(chan []string)
+	go func() {
+		defer close(changes)
+		previous, _ := w.scan()
+		pending := map[string]bool{}
+		var quietSince time.Time
+		ticker := time.NewTicker(w.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				current, err := w.scan()
+				if err != nil {
+					continue
+				}
+				if changed := diff(previous, current); len(changed) > 0 {
+					for _, p := range changed {
+						pending[p] = true
+					}
+					quietSince = now
+				}
+				previous = current
+				if len(pending) == 0 || now.Sub(quietSince) < w.Debounce {
+					continue
+				}
+				batch := make([]string, 0, len(pending))
+				for p := range pending {
+					batch = append(batch, p)
+				}
+				sort.Strings(batch)
+				pending = map[string]bool{}
+				select {
+				case changes <- batch:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return changes
+}
+
+// scan records the state of every file in the tree that is not ignored.
+func (w *Watcher) scan() (map[string]fileState, error) {
+	files := map[string]fileState{}
+	err := fs.WalkDir(w.FS, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if p == "." {
+			return nil
+		}
+		if w.ignored(p) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		files[p] = fileState{size: info.Size(), modTime: info.ModTime(), mode: info.Mode()}
+		return nil
+	})
+	return files, err
+}
+
+func (w *Watcher) ignored(p string) bool {
+	for _, pattern := range w.Ignore {
+		if fileutils.Match(pattern, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// diff returns the paths added, removed or modified between two scans.
+func diff(previous map[string]fileState, current map[string]fileState) []string {
+	changed := []string{}
+	for p, state := range current {
+		if old, ok := previous[p]; !ok || old != state {
+			changed = append(changed, p)
+		}
+	}
+	for p := range previous {
+		if _, ok := current[p]; !ok {
+			changed = append(changed, p)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// IgnorePatterns reads the ignore file at the root of fsys and returns its
+// patterns in fileutils.Glob syntax. Negated patterns are not supported
+// and are skipped. A missing file yields no patterns.
+func IgnorePatterns(fsys fs.FS) []string {
+	f, err := fsys.Open(IgnoreFile)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	patterns := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		line = strings.TrimSuffix(line, "/")
+		if strings.TrimPrefix(line, "/") == "" {
+			continue
+		}
+		if strings.Contains(strings.TrimPrefix(line, "/"), "/") || strings.HasPrefix(line, "/") {
+			patterns = append(patterns, strings.TrimPrefix(line, "/"))
+		} else {
+			patterns = append(patterns, "**/"+line)
+		}
+	}
+	return patterns
+}
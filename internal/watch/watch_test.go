@@ -0,0 +1,75 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnorePatterns(t *testing.T) {
+	fsys := fstest.MapFS{
+		".gitignore": {Data: []byte("# build output\n/bin/\nnode_modules/\n*.log\n!keep.log\ndocs/generated\n/\n")},
+	}
+	assert.Equal(t, []string{"bin", "**/node_modules", "**/*.log", "docs/generated"}, IgnorePatterns(fsys))
+	assert.Nil(t, IgnorePatterns(fstest.MapFS{}))
+}
+
+func TestWatcher_Scan(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.go":               {Data: []byte("package main")},
+		"bin/app":               {Data: []byte("binary")},
+		"web/node_modules/x.js": {Data: []byte("x")},
+		"web/app.js":            {Data: []byte("app")},
+	}
+	w := &Watcher{FS: fsys, Ignore: []string{"bin", "**/node_modules"}}
+	files, err := w.scan()
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Contains(t, files, "main.go")
+	assert.Contains(t, files, "web/app.js")
+}
+
+func TestDiff(t *testing.T) {
+	now := time.Now()
+	previous := map[string]fileState{
+		"kept.go":    {size: 1, modTime: now},
+		"edited.go":  {size: 1, modTime: now},
+		"removed.go": {size: 1, modTime: now},
+	}
+	current := map[string]fileState{
+		"kept.go":   {size: 1, modTime: now},
+		"edited.go": {size: 2, modTime: now.Add(time.Second)},
+		"added.go":  {size: 1, modTime: now},
+	}
+	assert.Equal(t, []string{"added.go", "edited.go", "removed.go"}, diff(previous, current))
+}
+
+func TestWatcher_Changes(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0o644))
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Watcher{FS: os.DirFS(dir), Ignore: []string{"*.log"}, Interval: 10 * time.Millisecond, Debounce: 30 * time.Millisecond}
+	changes := w.Changes(ctx)
+	time.Sleep(20 * time.Millisecond)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "debug.log"), []byte("ignored"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "util.go"), []byte("package main"), 0o644))
+
+	select {
+	case batch := <-changes:
+		assert.Equal(t, []string{"main.go", "util.go"}, batch)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no changes reported")
+	}
+
+	cancel()
+	_, open := <-changes
+	assert.False(t, open, "the channel is closed when the context is done")
+}
@@ -17,6 +17,9 @@ import (
 //go:embed specs.json
 var embeddedConfig []byte
 
+//go:embed docs/project-definition-schema.yaml
+var definitionSchema []byte
+
 type ProjectMetadata struct {
 	Author      string `json:"author"`
 	Name        string `json:"name"`
@@ -32,18 +35,40 @@ func main() {
 		os.Exit(1)
 	}
 
-	executor := &executor.DefaultExecutor{}
+	executor := executor.Chain(&executor.DefaultExecutor{}, executor.Logging(), executor.Masking())
 	command := core.NewCommandRegistry(metadata.Name, metadata.Description, metadata.Version)
 	commandsList := []*cobra.Command{
 		core.GetBuildCommand(executor),
 		core.GetTestCommand(executor),
+		core.GetRunCommand(executor),
+		core.GetWatchCommand(executor),
+		core.GetPipelineCommand(executor),
+		core.GetCICommand(executor),
+		core.GetPromoteCommand(),
+		core.GetArtifactsCommand(),
+		core.GetReleaseCommand(executor),
+		core.GetImageCommand(executor),
+		core.GetLoginCommand(executor),
+		core.GetDeployCommand(executor),
+		core.GetRollbackCommand(executor),
+		core.GetWorkspaceCommand(executor),
+		core.GetExecCommand(executor),
 		core.GetDoctorCommand(executor),
 		core.GetManifestCommand(),
+		core.GetSBOMCommand(),
+		core.GetVerifyCommand(),
+		core.GetPruneCommand(),
+		core.GetLogsCommand(),
+		core.GetHistoryCommand(),
+		core.GetStatusCommand(),
+		core.GetFeaturesCommand(),
+		core.GetCompletionConfigCommand(definitionSchema),
 		core.GetDocsCommand(),
 	}
 	command.RegisterCommands(commandsList)
 
 	if err := command.Execute(); err != nil {
 		log.Error(err.Error())
+		os.Exit(1)
 	}
 }